@@ -0,0 +1,145 @@
+//go:build !noaudio
+
+// The real player links ebitens audio stack, which needs the platform sound
+// libraries (ALSA on linux). Headless builds and CI without them compile
+// with -tags noaudio and use the no-op player instead
+
+package audio
+
+import (
+	"bytes"
+	"math/rand"
+	"os"
+
+	eaudio "github.com/hajimehoshi/ebiten/v2/audio"
+	"github.com/hajimehoshi/ebiten/v2/audio/wav"
+)
+
+const sampleRate = 44100
+
+// EbitenPlayer is the real implementation on top of ebitens audio context.
+// A key that maps to nothing, or to a file that cannot be read, degrades to
+// silence with one logged warning per key instead of spamming or crashing
+type EbitenPlayer struct {
+	Map SoundMap
+
+	ctx     *eaudio.Context
+	rng     *rand.Rand
+	volumes map[string]float64
+	warned  warnTracker
+
+	music *eaudio.Player
+}
+
+func NewEbitenPlayer(soundMap SoundMap, seed int64) *EbitenPlayer {
+
+	ctx := eaudio.CurrentContext()
+	if ctx == nil {
+		ctx = eaudio.NewContext(sampleRate)
+	}
+
+	return &EbitenPlayer{
+		Map:     soundMap,
+		ctx:     ctx,
+		rng:     rand.New(rand.NewSource(seed)),
+		volumes: map[string]float64{ChannelSFX: 1, ChannelMusic: 1},
+		warned:  warnTracker{},
+	}
+}
+
+func (p *EbitenPlayer) PlaySFX(key string) {
+
+	player, volume := p.load(key)
+	if player == nil {
+		return
+	}
+
+	player.SetVolume(volume * p.volumes[ChannelSFX])
+	player.Play()
+}
+
+func (p *EbitenPlayer) PlayMusic(key string, loop bool) {
+
+	if p.music != nil {
+		p.music.Close()
+		p.music = nil
+	}
+
+	file, volume, ok := p.Map.Resolve(key, p.rng)
+	if !ok {
+		p.warned.warn(key, "no sound mapped")
+		return
+	}
+
+	contents, err := os.ReadFile(file)
+	if err != nil {
+		p.warned.warn(key, "could not read "+file)
+		return
+	}
+
+	stream, err := wav.DecodeWithSampleRate(sampleRate, bytes.NewReader(contents))
+	if err != nil {
+		p.warned.warn(key, "could not decode "+file)
+		return
+	}
+
+	var player *eaudio.Player
+	if loop {
+		player, err = p.ctx.NewPlayer(eaudio.NewInfiniteLoop(stream, stream.Length()))
+	} else {
+		player, err = p.ctx.NewPlayer(stream)
+	}
+	if err != nil {
+		p.warned.warn(key, "player creation failed")
+		return
+	}
+
+	player.SetVolume(volume * p.volumes[ChannelMusic])
+	player.Play()
+	p.music = player
+}
+
+func (p *EbitenPlayer) SetVolume(channel string, volume float64) {
+
+	if volume < 0 {
+		volume = 0
+	}
+	if volume > 1 {
+		volume = 1
+	}
+	p.volumes[channel] = volume
+
+	if channel == ChannelMusic && p.music != nil {
+		p.music.SetVolume(volume)
+	}
+
+}
+
+func (p *EbitenPlayer) load(key string) (*eaudio.Player, float64) {
+
+	file, volume, ok := p.Map.Resolve(key, p.rng)
+	if !ok {
+		p.warned.warn(key, "no sound mapped")
+		return nil, 0
+	}
+
+	contents, err := os.ReadFile(file)
+	if err != nil {
+		p.warned.warn(key, "could not read "+file)
+		return nil, 0
+	}
+
+	stream, err := wav.DecodeWithSampleRate(sampleRate, bytes.NewReader(contents))
+	if err != nil {
+		p.warned.warn(key, "could not decode "+file)
+		return nil, 0
+	}
+
+	player, err := p.ctx.NewPlayer(stream)
+	if err != nil {
+		p.warned.warn(key, "player creation failed")
+		return nil, 0
+	}
+
+	return player, volume
+}
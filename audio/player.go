@@ -0,0 +1,70 @@
+package audio
+
+import "game_main/squads"
+
+// The sound subsystem keeps a deliberately narrow surface: game code emits
+// event keys through the SoundPlayer interface and everything else - file
+// mapping, variations, volumes - is data in the sound map. Headless runs and
+// tests plug in the no-op player, which never touches files, randomness, or
+// timing
+
+// The mixer channels SetVolume addresses
+const (
+	ChannelSFX   = "sfx"
+	ChannelMusic = "music"
+)
+
+// The event keys the game emits. The sound map decides what they sound like
+const (
+	SFXAttackHit      = "attack.hit"
+	SFXAttackMiss     = "attack.miss"
+	SFXAttackCrit     = "attack.crit"
+	SFXAttackKill     = "attack.kill"
+	SFXSquadDestroyed = "squad.destroyed"
+	SFXButtonClick    = "ui.click"
+	SFXModeEnter      = "ui.mode"
+	SFXTurnStart      = "turn.start"
+	MusicVictory      = "battle.victory"
+	MusicDefeat       = "battle.defeat"
+)
+
+type SoundPlayer interface {
+	PlaySFX(key string)
+	PlayMusic(key string, loop bool)
+	SetVolume(channel string, volume float64)
+}
+
+// NoopPlayer is the headless implementation: every call returns immediately
+// and consumes nothing, so simulations with it attached behave exactly like
+// simulations without audio
+type NoopPlayer struct{}
+
+func (NoopPlayer) PlaySFX(key string)                       {}
+func (NoopPlayer) PlayMusic(key string, loop bool)          {}
+func (NoopPlayer) SetVolume(channel string, volume float64) {}
+
+// ForAttackEvent maps a resolved attack to its event key. killed says the
+// blow finished the defender, which the event itself does not carry
+func ForAttackEvent(event squads.AttackEvent, killed bool) string {
+
+	switch {
+	case killed:
+		return SFXAttackKill
+	case event.Dodged:
+		return SFXAttackMiss
+	case event.Crit:
+		return SFXAttackCrit
+	default:
+		return SFXAttackHit
+	}
+}
+
+// ForBattleOutcome picks the stinger for the battle end screen
+func ForBattleOutcome(playerWon bool) string {
+
+	if playerWon {
+		return MusicVictory
+	}
+
+	return MusicDefeat
+}
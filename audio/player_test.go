@@ -0,0 +1,83 @@
+package audio
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"game_main/randgen"
+	"game_main/simulation"
+	"game_main/squads"
+)
+
+// Attack events map onto the right keys, with the kill taking precedence
+func TestAttackEventKeys(t *testing.T) {
+
+	if got := ForAttackEvent(squads.AttackEvent{Dodged: true}, false); got != SFXAttackMiss {
+		t.Errorf("a dodge should sound like a miss, got %s", got)
+	}
+	if got := ForAttackEvent(squads.AttackEvent{Crit: true}, false); got != SFXAttackCrit {
+		t.Errorf("a crit should sound like a crit, got %s", got)
+	}
+	if got := ForAttackEvent(squads.AttackEvent{Crit: true}, true); got != SFXAttackKill {
+		t.Errorf("the killing blow should override the crit, got %s", got)
+	}
+	if got := ForAttackEvent(squads.AttackEvent{}, false); got != SFXAttackHit {
+		t.Errorf("a plain hit should sound like one, got %s", got)
+	}
+
+}
+
+// The no-op player consumes nothing: a seeded run with sounds firing through
+// it between every battle produces byte-identical output to a silent one
+func TestNoopPlayerKeepsTheSimulatorByteIdentical(t *testing.T) {
+
+	simulation.RegisterScenarioTemplates([]simulation.ScenarioTemplate{
+		{Name: "AudioTank", Class: "Knight", MaxHealth: 20, ArmorClass: 10, MovementSpeed: 1, MinDamage: 2, MaxDamage: 4},
+	})
+
+	scenario := simulation.Scenario{
+		Name:       "Audio",
+		Iterations: 20,
+		Attacker:   []simulation.ScenarioUnit{{Template: "AudioTank", Row: 0, Col: 0}},
+		Defender:   []simulation.ScenarioUnit{{Template: "AudioTank", Row: 0, Col: 0}},
+	}
+
+	sim := simulation.NewSimulator()
+
+	run := func(player SoundPlayer) []byte {
+
+		randgen.Seed(99)
+		defer randgen.ClearSeed()
+
+		if player != nil {
+
+			player.SetVolume(ChannelSFX, 0.5)
+			for _, key := range []string{SFXAttackHit, SFXAttackCrit, SFXSquadDestroyed, SFXTurnStart} {
+				player.PlaySFX(key)
+			}
+			player.PlayMusic(MusicVictory, true)
+
+		}
+
+		result, err := sim.RunScenario(scenario)
+		if err != nil {
+			t.Fatalf("the run failed: %v", err)
+		}
+
+		contents, err := json.Marshal(result)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		return contents
+	}
+
+	silent := run(nil)
+	withAudio := run(NoopPlayer{})
+
+	if !bytes.Equal(silent, withAudio) {
+		t.Errorf("the no-op player should leave the run byte-identical:\n%s\n%s", withAudio, silent)
+	}
+
+}
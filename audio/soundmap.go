@@ -0,0 +1,81 @@
+package audio
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"math/rand"
+	"os"
+)
+
+// The sound map is data: event key to file, volume, and optional variations
+// so repeated events don't drone. Designers edit the JSON, the code never
+// hardcodes a file path
+
+type SoundDef struct {
+	File   string  `json:"file,omitempty"`
+	Volume float64 `json:"volume,omitempty"`
+
+	//When set, each play picks one of these at random instead of File
+	Variations []string `json:"variations,omitempty"`
+}
+
+type SoundMap map[string]SoundDef
+
+func LoadSoundMap(path string) (SoundMap, error) {
+
+	contents, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var m SoundMap
+	if err := json.Unmarshal(contents, &m); err != nil {
+		return nil, fmt.Errorf("sound map %s did not parse: %w", path, err)
+	}
+
+	return m, nil
+}
+
+// Resolve picks the file and volume for an event key. Variations draw from
+// the given rng so a seeded player picks reproducibly. The bool is false when
+// the key maps to nothing
+func (m SoundMap) Resolve(key string, rng *rand.Rand) (string, float64, bool) {
+
+	def, ok := m[key]
+	if !ok {
+		return "", 0, false
+	}
+
+	volume := def.Volume
+	if volume <= 0 {
+		volume = 1
+	}
+
+	if len(def.Variations) > 0 {
+		return def.Variations[rng.Intn(len(def.Variations))], volume, true
+	}
+
+	if def.File == "" {
+		return "", 0, false
+	}
+
+	return def.File, volume, true
+}
+
+// warnTracker logs at most one warning per event key, then stays quiet. A
+// missing sound should degrade to silence, not flood the log every frame
+type warnTracker map[string]bool
+
+// warn logs the message the first time the key comes up and reports whether
+// it did
+func (w warnTracker) warn(key string, message string) bool {
+
+	if w[key] {
+		return false
+	}
+
+	w[key] = true
+	log.Printf("audio: %s for key %q, staying silent", message, key)
+	return true
+}
@@ -0,0 +1,97 @@
+package audio
+
+import (
+	"math/rand"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func testSoundMap(t *testing.T) SoundMap {
+
+	path := filepath.Join(t.TempDir(), "sounds.json")
+	contents := `{
+		"attack.hit": {"variations": ["hit1.wav", "hit2.wav", "hit3.wav"]},
+		"ui.click": {"file": "click.wav", "volume": 0.4},
+		"turn.start": {"file": "horn.wav"}
+	}`
+	if err := os.WriteFile(path, []byte(contents), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	m, err := LoadSoundMap(path)
+	if err != nil {
+		t.Fatalf("loading the sound map failed: %v", err)
+	}
+
+	return m
+}
+
+// Keys resolve to their file and volume, with 1.0 as the default volume
+func TestSoundMapResolution(t *testing.T) {
+
+	m := testSoundMap(t)
+	rng := rand.New(rand.NewSource(1))
+
+	file, volume, ok := m.Resolve("ui.click", rng)
+	if !ok || file != "click.wav" || volume != 0.4 {
+		t.Errorf("ui.click should resolve to click.wav at 0.4, got %s at %.1f", file, volume)
+	}
+
+	if _, volume, _ := m.Resolve("turn.start", rng); volume != 1 {
+		t.Errorf("an unset volume should default to 1, got %.1f", volume)
+	}
+
+	if _, _, ok := m.Resolve("battle.victory", rng); ok {
+		t.Error("an unmapped key should resolve to nothing")
+	}
+
+}
+
+// Variations draw from the callers rng, so the same seed always picks the
+// same sequence and different plays spread across the files
+func TestVariationSelectionIsSeeded(t *testing.T) {
+
+	m := testSoundMap(t)
+
+	first := []string{}
+	rng := rand.New(rand.NewSource(7))
+	for i := 0; i < 10; i++ {
+		file, _, _ := m.Resolve("attack.hit", rng)
+		first = append(first, file)
+	}
+
+	rng = rand.New(rand.NewSource(7))
+	distinct := map[string]bool{}
+	for i := 0; i < 10; i++ {
+
+		file, _, _ := m.Resolve("attack.hit", rng)
+		if file != first[i] {
+			t.Fatalf("the same seed should pick the same sequence, play %d got %s over %s", i, file, first[i])
+		}
+		distinct[file] = true
+
+	}
+
+	if len(distinct) < 2 {
+		t.Error("ten plays should spread across more than one variation")
+	}
+
+}
+
+// Each missing key warns exactly once, then stays silent
+func TestMissingSoundsWarnOncePerKey(t *testing.T) {
+
+	warned := warnTracker{}
+
+	if !warned.warn("attack.hit", "no sound mapped") {
+		t.Error("the first miss should log")
+	}
+	if warned.warn("attack.hit", "no sound mapped") {
+		t.Error("the second miss of the same key should stay quiet")
+	}
+	if !warned.warn("ui.click", "no sound mapped") {
+		t.Error("a different key should still get its one warning")
+	}
+
+}
@@ -0,0 +1,49 @@
+package balance
+
+// Aggregated results of simulated squad matchups. The simulator fills these in
+// and the reporting tools read them, so this package stays free of any
+// rendering or ECS dependencies.
+
+// SideStats are the average stats of one side of a matchup, used by the
+// suggestion heuristics to guess which attribute is out of line
+type SideStats struct {
+	Name          string
+	AvgMaxHealth  float64
+	AvgProtection float64
+	AvgDamage     float64
+}
+
+// MatchupStats is the aggregate outcome of running one matchup many times
+type MatchupStats struct {
+	Attacker SideStats
+	Defender SideStats
+
+	Battles      int
+	AttackerWins int
+	AvgRounds    float64
+}
+
+func (m MatchupStats) AttackerWinRate() float64 {
+
+	if m.Battles == 0 {
+		return 0
+	}
+
+	return float64(m.AttackerWins) / float64(m.Battles)
+}
+
+// BalanceScore is 1.0 for a perfect 50/50 matchup and falls towards 0.0 the
+// more lopsided the matchup gets
+func (m MatchupStats) BalanceScore() float64 {
+
+	diff := m.AttackerWinRate() - 0.5
+	if diff < 0 {
+		diff = -diff
+	}
+
+	return 1.0 - diff*2.0
+}
+
+type BalanceReport struct {
+	Matchups []MatchupStats
+}
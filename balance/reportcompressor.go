@@ -0,0 +1,100 @@
+package balance
+
+import (
+	"fmt"
+	"io"
+)
+
+// The report compressor boils many matchups down to the ones a designer should
+// look at. A matchup whose balance score drops below AlertThreshold raises an
+// alert, and the compressor now also suggests which attribute to nudge to pull
+// the win rate back towards 50%
+
+var AlertThreshold = 0.5
+
+type Alert struct {
+	Matchup    MatchupStats
+	Suggestion string
+}
+
+// SuggestFix picks the losing sides weakest attribute relative to the winner
+// and estimates a nudge for it. The sensitivity estimate is deliberately crude:
+// one point of protection is worth about one point of incoming damage per hit,
+// so closing half of the effective-damage gap is usually enough to move a
+// lopsided matchup noticeably without overshooting
+func SuggestFix(m MatchupStats) string {
+
+	winner, loser := m.Attacker, m.Defender
+	if m.AttackerWinRate() < 0.5 {
+		winner, loser = m.Defender, m.Attacker
+	}
+
+	effectiveDamage := winner.AvgDamage - loser.AvgProtection
+	if effectiveDamage < 1 {
+		effectiveDamage = 1
+	}
+
+	//Low armor is the most common culprit, so check it first. If the loser
+	//already soaks most of the winners damage, look at health and damage instead
+	if loser.AvgProtection < winner.AvgDamage/2 {
+
+		nudge := int(effectiveDamage/2 + 0.5)
+		if nudge < 1 {
+			nudge = 1
+		}
+		return fmt.Sprintf("+%d armor to %s brings win rate toward 50%%", nudge, loser.Name)
+
+	}
+
+	if loser.AvgMaxHealth < winner.AvgMaxHealth {
+
+		nudge := int((winner.AvgMaxHealth-loser.AvgMaxHealth)/2 + 0.5)
+		if nudge < 1 {
+			nudge = 1
+		}
+		return fmt.Sprintf("+%d max health to %s brings win rate toward 50%%", nudge, loser.Name)
+
+	}
+
+	nudge := int(effectiveDamage/4 + 0.5)
+	if nudge < 1 {
+		nudge = 1
+	}
+	return fmt.Sprintf("+%d damage to %s brings win rate toward 50%%", nudge, loser.Name)
+}
+
+// DetectAlerts returns every matchup whose balance score falls below the
+// threshold, with a suggested fix attached
+func DetectAlerts(report BalanceReport) []Alert {
+
+	alerts := make([]Alert, 0)
+	for _, m := range report.Matchups {
+
+		if m.BalanceScore() < AlertThreshold {
+			alerts = append(alerts, Alert{Matchup: m, Suggestion: SuggestFix(m)})
+		}
+
+	}
+
+	return alerts
+}
+
+// WriteCompressedReport writes one line per matchup plus alert and suggestion
+// lines for the lopsided ones
+func WriteCompressedReport(w io.Writer, report BalanceReport) {
+
+	for _, m := range report.Matchups {
+
+		fmt.Fprintf(w, "%s vs %s: %.0f%% attacker win rate over %d battles, balance score %.2f\n",
+			m.Attacker.Name, m.Defender.Name, m.AttackerWinRate()*100, m.Battles, m.BalanceScore())
+
+	}
+
+	for _, a := range DetectAlerts(report) {
+
+		fmt.Fprintf(w, "ALERT %s vs %s is lopsided (score %.2f). Suggestion: %s\n",
+			a.Matchup.Attacker.Name, a.Matchup.Defender.Name, a.Matchup.BalanceScore(), a.Suggestion)
+
+	}
+
+}
@@ -0,0 +1,54 @@
+package balance
+
+import (
+	"strings"
+	"testing"
+)
+
+func lowArmorMatchup() MatchupStats {
+
+	return MatchupStats{
+		Attacker: SideStats{Name: "Knights", AvgMaxHealth: 20, AvgProtection: 3, AvgDamage: 8},
+		Defender: SideStats{Name: "Peasants", AvgMaxHealth: 20, AvgProtection: 0, AvgDamage: 6},
+		Battles:  100, AttackerWins: 90, AvgRounds: 4,
+	}
+}
+
+func TestBalanceScore(t *testing.T) {
+
+	m := lowArmorMatchup()
+	if m.BalanceScore() > 0.3 {
+		t.Errorf("a 90%% matchup should score poorly, got %.2f", m.BalanceScore())
+	}
+
+	m.AttackerWins = 50
+	if m.BalanceScore() != 1.0 {
+		t.Errorf("a 50/50 matchup should score 1.0, got %.2f", m.BalanceScore())
+	}
+}
+
+// A matchup where the loser has clearly low armor yields an armor suggestion
+func TestLowArmorLoserGetsArmorSuggestion(t *testing.T) {
+
+	suggestion := SuggestFix(lowArmorMatchup())
+
+	if !strings.Contains(suggestion, "armor") || !strings.Contains(suggestion, "Peasants") {
+		t.Errorf("expected an armor increase suggestion for Peasants, got %q", suggestion)
+	}
+}
+
+func TestCompressedReportContainsSuggestions(t *testing.T) {
+
+	report := BalanceReport{Matchups: []MatchupStats{lowArmorMatchup()}}
+
+	var sb strings.Builder
+	WriteCompressedReport(&sb, report)
+
+	out := sb.String()
+	if !strings.Contains(out, "ALERT") {
+		t.Error("expected the lopsided matchup to raise an alert")
+	}
+	if !strings.Contains(out, "Suggestion:") {
+		t.Error("expected the alert to carry a suggestion")
+	}
+}
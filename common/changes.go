@@ -0,0 +1,84 @@
+package common
+
+// Change notification for the GUI. Mutation helpers notify the subsystem
+// they touched, and GUI panels subscribe to the subsystems they render,
+// rebuilding their widgets only when their subscription fires instead of
+// every frame. Mutations that bypass the helpers will not notify, so new
+// write paths should route through them
+
+// The subsystems panels subscribe to
+const (
+	//A squads contents or stats changed: units, health, buffs, morale
+	SquadsChanged = "squads"
+
+	//The set of squads itself changed: one was created or destroyed
+	RosterChanged = "roster"
+
+	//Consumable bags changed
+	InventoryChanged = "inventory"
+
+	//A squad changed map position. Fired by the authoritative position
+	//writer, so map overlays can redraw only when something actually moved
+	SquadMoved = "squadMoved"
+)
+
+// ChangeTracker is a monotonically increasing generation counter plus the
+// generation each subsystem last changed at. One lives on every
+// EntityManager, created lazily
+type ChangeTracker struct {
+	generation uint64
+	changed    map[string]uint64
+}
+
+// Generation increases by one on every notified mutation, across all
+// subsystems. Useful as a cheap "did anything move" check
+func (t *ChangeTracker) Generation() uint64 {
+	return t.generation
+}
+
+func (t *ChangeTracker) Notify(subsystem string) {
+
+	t.generation++
+	t.changed[subsystem] = t.generation
+}
+
+// Subscribe gives a panel its own cursor into the subsystems change stream
+func (t *ChangeTracker) Subscribe(subsystem string) *ChangeSubscription {
+	return &ChangeSubscription{tracker: t, subsystem: subsystem}
+}
+
+// ChangeSubscription is one panels view of one subsystem. Fired reports
+// whether the subsystem changed since the last time Fired returned true,
+// so any number of mutations between two polls costs exactly one rebuild
+type ChangeSubscription struct {
+	tracker   *ChangeTracker
+	subsystem string
+	seen      uint64
+}
+
+func (s *ChangeSubscription) Fired() bool {
+
+	latest := s.tracker.changed[s.subsystem]
+	if latest > s.seen {
+		s.seen = latest
+		return true
+	}
+
+	return false
+}
+
+// Changes is the managers tracker, created on first use so the struct
+// literals that build EntityManagers all over the code keep working
+func (em *EntityManager) Changes() *ChangeTracker {
+
+	if em.changes == nil {
+		em.changes = &ChangeTracker{changed: make(map[string]uint64)}
+	}
+
+	return em.changes
+}
+
+// NotifyChanged is what the mutation helpers call
+func (em *EntityManager) NotifyChanged(subsystem string) {
+	em.Changes().Notify(subsystem)
+}
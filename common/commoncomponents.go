@@ -2,7 +2,6 @@ package common
 
 import (
 	"fmt"
-	"game_main/graphics"
 	"math"
 )
 
@@ -40,22 +39,13 @@ func (p *Position) InRange(other *Position, distance int) bool {
 
 }
 
-// A TileBasedShape returns indices that correspond to the tiles on the GameMap
-// The TileBasedShape uses World Coordnates
-func GetTilePositions(indices []int, dungeinWidth int) []Position {
-
-	pos := make([]Position, len(indices))
-
-	x, y := 0, 0
-	for i, tileIndex := range indices {
-
-		x, y = graphics.CoordTransformer.LogicalXYFromIndex(tileIndex)
-		pos[i] = Position{X: x, Y: y}
-
-	}
-
-	return pos
-
+// SquadDistance is THE distance metric for squad combat: range gating,
+// engagement pairing, the GUI range preview, and the AI all go through it so
+// they can never disagree about what is in reach. It is Chebyshev distance,
+// because squads move in 8 directions and a diagonal step covers one tile of
+// range just like a straight one
+func (p *Position) SquadDistance(other *Position) int {
+	return p.ChebyshevDistance(other)
 }
 
 type Attributes struct {
@@ -72,6 +62,10 @@ type Attributes struct {
 	TotalMovementSpeed int
 	TotalAttackSpeed   int
 	CanMove            bool
+
+	//Focus makes a unit hit reliably: each point raises the floor of its
+	//damage roll and adds a percent of critical hit chance
+	Focus int
 }
 
 func NewBaseAttributes(maxHealth, attackBonus, baseAC, baseProt, baseMovSpeed int, dodge float32) Attributes {
@@ -17,6 +17,9 @@ var (
 type EntityManager struct {
 	World     *ecs.Manager
 	WorldTags map[string]ecs.Tag
+
+	//Lazily created change tracker for GUI refresh, see changes.go
+	changes *ChangeTracker
 }
 
 // A wrapper around the ECS libraries GetComponentData.
@@ -0,0 +1,75 @@
+package common
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/bytearena/ecs"
+)
+
+// Entity IDs are runtime-assigned, so any serialized ecs.EntityID is only
+// meaningful inside the world that wrote it. Every deserialization path runs
+// its stored references through an IDRemapper: entities record their old->new
+// mapping as they are recreated, references resolve through Remap, and
+// VerifyComplete fails the load if anything never resolved. A silently wrong
+// ID is exactly the bug this exists to catch, so there is no zero fallback
+
+type IDRemapper struct {
+	mapping map[ecs.EntityID]ecs.EntityID
+
+	//Old IDs that Remap was asked for but never learned. Kept so
+	//VerifyComplete can report every broken reference at once
+	missing map[ecs.EntityID]bool
+}
+
+func NewIDRemapper() *IDRemapper {
+
+	return &IDRemapper{
+		mapping: make(map[ecs.EntityID]ecs.EntityID),
+		missing: make(map[ecs.EntityID]bool),
+	}
+}
+
+// Record stores the mapping for one recreated entity. Recording the same old
+// ID twice is a corrupt save, not a situation to paper over
+func (r *IDRemapper) Record(oldID ecs.EntityID, newID ecs.EntityID) error {
+
+	if existing, ok := r.mapping[oldID]; ok && existing != newID {
+		return fmt.Errorf("old entity ID %d already remapped to %d, cannot remap to %d", oldID, existing, newID)
+	}
+
+	r.mapping[oldID] = newID
+
+	//The entity may have been referenced before it was recreated
+	delete(r.missing, oldID)
+
+	return nil
+}
+
+// Remap resolves a stored reference. Unknown IDs are a hard error
+func (r *IDRemapper) Remap(oldID ecs.EntityID) (ecs.EntityID, error) {
+
+	if newID, ok := r.mapping[oldID]; ok {
+		return newID, nil
+	}
+
+	r.missing[oldID] = true
+	return 0, fmt.Errorf("stored reference to entity ID %d was never recreated", oldID)
+}
+
+// VerifyComplete is the final gate of a load: it fails if any stored
+// reference never resolved to a recreated entity
+func (r *IDRemapper) VerifyComplete() error {
+
+	if len(r.missing) == 0 {
+		return nil
+	}
+
+	ids := make([]int, 0, len(r.missing))
+	for id := range r.missing {
+		ids = append(ids, int(id))
+	}
+	sort.Ints(ids)
+
+	return fmt.Errorf("%d stored references never resolved: %v", len(ids), ids)
+}
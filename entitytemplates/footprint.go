@@ -0,0 +1,87 @@
+package entitytemplates
+
+import "fmt"
+
+// Footprint validation for multi-cell monsters. A footprint is a set of
+// [row, col] offsets from the units anchor cell; the squad grid code treats
+// it as gospel, so everything unsound gets rejected here at load time
+
+// ValidateFootprint checks a footprint is usable on a gridWidth x gridHeight
+// grid: it must include the anchor offset {0, 0}, hold no duplicate cells,
+// be one connected piece (4-adjacency), and be small enough that at least
+// one anchor position fits the whole shape on the grid. An empty footprint
+// is valid and means the single-cell default
+func ValidateFootprint(footprint [][2]int, gridWidth int, gridHeight int) error {
+
+	if len(footprint) == 0 {
+		return nil
+	}
+
+	cells := make(map[[2]int]bool, len(footprint))
+	hasAnchor := false
+	minRow, maxRow := footprint[0][0], footprint[0][0]
+	minCol, maxCol := footprint[0][1], footprint[0][1]
+
+	for _, cell := range footprint {
+
+		if cells[cell] {
+			return fmt.Errorf("footprint lists cell (%d, %d) twice", cell[0], cell[1])
+		}
+		cells[cell] = true
+
+		if cell == [2]int{0, 0} {
+			hasAnchor = true
+		}
+
+		if cell[0] < minRow {
+			minRow = cell[0]
+		}
+		if cell[0] > maxRow {
+			maxRow = cell[0]
+		}
+		if cell[1] < minCol {
+			minCol = cell[1]
+		}
+		if cell[1] > maxCol {
+			maxCol = cell[1]
+		}
+
+	}
+
+	if !hasAnchor {
+		return fmt.Errorf("footprint must include the anchor offset (0, 0)")
+	}
+
+	if maxRow-minRow >= gridHeight || maxCol-minCol >= gridWidth {
+		return fmt.Errorf("footprint spans %dx%d cells, which fits no anchor on a %dx%d grid",
+			maxRow-minRow+1, maxCol-minCol+1, gridHeight, gridWidth)
+	}
+
+	//Flood fill from the first cell: every cell must be reachable through
+	//edge-adjacent neighbors, so a footprint cannot be several islands
+	reached := map[[2]int]bool{footprint[0]: true}
+	frontier := [][2]int{footprint[0]}
+
+	for len(frontier) > 0 {
+
+		cell := frontier[0]
+		frontier = frontier[1:]
+
+		for _, n := range [][2]int{
+			{cell[0] - 1, cell[1]}, {cell[0] + 1, cell[1]},
+			{cell[0], cell[1] - 1}, {cell[0], cell[1] + 1},
+		} {
+			if cells[n] && !reached[n] {
+				reached[n] = true
+				frontier = append(frontier, n)
+			}
+		}
+
+	}
+
+	if len(reached) != len(cells) {
+		return fmt.Errorf("footprint is disconnected: only %d of its %d cells touch", len(reached), len(cells))
+	}
+
+	return nil
+}
@@ -0,0 +1,47 @@
+package entitytemplates
+
+import "testing"
+
+// The shapes design actually wants all pass: rectangles, the L, the phalanx
+// with a tail
+func TestValidFootprintsPass(t *testing.T) {
+
+	footprints := map[string][][2]int{
+		"empty default": nil,
+		"single cell":   {{0, 0}},
+		"L-shape":       {{0, 0}, {1, 0}, {1, 1}},
+		"phalanx tail":  {{0, 0}, {0, 1}, {1, 0}},
+	}
+
+	for name, footprint := range footprints {
+		if err := ValidateFootprint(footprint, 3, 3); err != nil {
+			t.Errorf("%s should validate, got %v", name, err)
+		}
+	}
+
+}
+
+func TestDisconnectedFootprintIsRejected(t *testing.T) {
+
+	//Two islands: the anchor, and a cell that touches it only diagonally
+	if err := ValidateFootprint([][2]int{{0, 0}, {1, 1}}, 3, 3); err == nil {
+		t.Error("a diagonal-only footprint should be rejected as disconnected")
+	}
+
+}
+
+func TestUnsoundFootprintsAreRejected(t *testing.T) {
+
+	if err := ValidateFootprint([][2]int{{0, 0}, {0, 1}, {0, 1}}, 3, 3); err == nil {
+		t.Error("a duplicate cell should be rejected")
+	}
+
+	if err := ValidateFootprint([][2]int{{0, 1}, {0, 2}}, 3, 3); err == nil {
+		t.Error("a footprint without the anchor offset should be rejected")
+	}
+
+	if err := ValidateFootprint([][2]int{{0, 0}, {0, 1}, {0, 2}, {0, 3}}, 3, 3); err == nil {
+		t.Error("a footprint wider than the grid fits no anchor and should be rejected")
+	}
+
+}
@@ -0,0 +1,126 @@
+package entitytemplates
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// Template inheritance for the monster JSON. A template can name a parent with
+// "extends" and only spell out what differs. Resolution deep-merges the parent
+// first and then applies the childs explicitly-set fields, so a child setting
+// a value to 0 really overrides the parent instead of inheriting it. The
+// presence check works on the raw JSON maps, since the unmarshalled structs
+// can't tell "absent" from "zero". Resolution runs before validation so the
+// validator only ever sees final values
+
+// ResolveTemplateInheritance resolves every "extends" chain in a list of raw
+// template objects. Multi-level chains are allowed, cycles are rejected with
+// an error naming the templates involved
+func ResolveTemplateInheritance(entries []json.RawMessage) ([]json.RawMessage, error) {
+
+	byName := make(map[string]map[string]any)
+	names := make([]string, 0, len(entries))
+
+	for _, raw := range entries {
+
+		fields := make(map[string]any)
+		if err := json.Unmarshal(raw, &fields); err != nil {
+			return nil, err
+		}
+
+		name, _ := fields["name"].(string)
+		if name == "" {
+			return nil, fmt.Errorf("template with no name cannot take part in inheritance")
+		}
+
+		byName[name] = fields
+		names = append(names, name)
+
+	}
+
+	resolved := make(map[string]map[string]any)
+
+	var resolve func(name string, chain []string) (map[string]any, error)
+	resolve = func(name string, chain []string) (map[string]any, error) {
+
+		if fields, ok := resolved[name]; ok {
+			return fields, nil
+		}
+
+		for _, seen := range chain {
+			if seen == name {
+				cycle := append(chain, name)
+				return nil, fmt.Errorf("template inheritance cycle: %s", strings.Join(cycle, " -> "))
+			}
+		}
+
+		fields, ok := byName[name]
+		if !ok {
+			return nil, fmt.Errorf("extends references unknown template %s", name)
+		}
+
+		parentName, _ := fields["extends"].(string)
+		if parentName == "" {
+			resolved[name] = fields
+			return fields, nil
+		}
+
+		parent, err := resolve(parentName, append(chain, name))
+		if err != nil {
+			return nil, err
+		}
+
+		merged := deepMerge(parent, fields)
+		delete(merged, "extends")
+
+		resolved[name] = merged
+		return merged, nil
+
+	}
+
+	out := make([]json.RawMessage, 0, len(entries))
+	for _, name := range names {
+
+		fields, err := resolve(name, nil)
+		if err != nil {
+			return nil, err
+		}
+
+		raw, err := json.Marshal(fields)
+		if err != nil {
+			return nil, err
+		}
+
+		out = append(out, raw)
+
+	}
+
+	return out, nil
+}
+
+// deepMerge overlays the childs fields on a copy of the parents. Nested
+// objects merge key by key, everything else the child wins outright,
+// including explicit zeroes and nulls
+func deepMerge(parent map[string]any, child map[string]any) map[string]any {
+
+	merged := make(map[string]any, len(parent)+len(child))
+	for k, v := range parent {
+		merged[k] = v
+	}
+
+	for k, childVal := range child {
+
+		parentMap, parentIsMap := merged[k].(map[string]any)
+		childMap, childIsMap := childVal.(map[string]any)
+
+		if parentIsMap && childIsMap {
+			merged[k] = deepMerge(parentMap, childMap)
+		} else {
+			merged[k] = childVal
+		}
+
+	}
+
+	return merged
+}
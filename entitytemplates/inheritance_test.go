@@ -0,0 +1,104 @@
+package entitytemplates
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func rawTemplates(t *testing.T, docs ...string) []json.RawMessage {
+
+	out := make([]json.RawMessage, 0, len(docs))
+	for _, d := range docs {
+		out = append(out, json.RawMessage(d))
+	}
+
+	return out
+}
+
+func unmarshalByName(t *testing.T, resolved []json.RawMessage) map[string]JSONMonster {
+
+	byName := make(map[string]JSONMonster)
+	for _, raw := range resolved {
+
+		var m JSONMonster
+		if err := json.Unmarshal(raw, &m); err != nil {
+			t.Fatalf("resolved template did not unmarshal: %v", err)
+		}
+		byName[m.Name] = m
+
+	}
+
+	return byName
+}
+
+func TestMultiLevelInheritanceChain(t *testing.T) {
+
+	resolved, err := ResolveTemplateInheritance(rawTemplates(t,
+		`{"name": "GoblinBase", "attributes": {"MaxHealth": 10, "AttackBonus": 2}, "armor": {"protection": 3}}`,
+		`{"name": "GoblinWarrior", "extends": "GoblinBase", "attributes": {"AttackBonus": 4}}`,
+		`{"name": "GoblinChampion", "extends": "GoblinWarrior", "attributes": {"MaxHealth": 20}}`,
+	))
+	if err != nil {
+		t.Fatalf("resolution failed: %v", err)
+	}
+
+	byName := unmarshalByName(t, resolved)
+
+	champion := byName["GoblinChampion"]
+	if champion.Attributes.MaxHealth != 20 {
+		t.Errorf("expected the champions own MaxHealth 20, got %d", champion.Attributes.MaxHealth)
+	}
+	if champion.Attributes.AttackBonus != 4 {
+		t.Errorf("expected AttackBonus 4 inherited through the warrior, got %d", champion.Attributes.AttackBonus)
+	}
+	if champion.Armor == nil || champion.Armor.Protection != 3 {
+		t.Error("expected the base armor to survive two levels of inheritance")
+	}
+	if champion.Extends != "" {
+		t.Error("extends should be stripped from resolved templates")
+	}
+}
+
+// A child explicitly setting a value to 0 must not inherit the parents value
+func TestZeroValueOverride(t *testing.T) {
+
+	resolved, err := ResolveTemplateInheritance(rawTemplates(t,
+		`{"name": "Armored", "armor": {"protection": 3}}`,
+		`{"name": "Naked", "extends": "Armored", "armor": {"protection": 0}}`,
+	))
+	if err != nil {
+		t.Fatalf("resolution failed: %v", err)
+	}
+
+	byName := unmarshalByName(t, resolved)
+
+	if byName["Naked"].Armor.Protection != 0 {
+		t.Errorf("expected the explicit 0 to override the parents 3, got %d", byName["Naked"].Armor.Protection)
+	}
+}
+
+func TestInheritanceCycleDetection(t *testing.T) {
+
+	_, err := ResolveTemplateInheritance(rawTemplates(t,
+		`{"name": "A", "extends": "B"}`,
+		`{"name": "B", "extends": "A"}`,
+	))
+	if err == nil {
+		t.Fatal("expected a cycle error")
+	}
+
+	if !strings.Contains(err.Error(), "A") || !strings.Contains(err.Error(), "B") {
+		t.Errorf("expected the error to name the templates in the cycle, got %q", err.Error())
+	}
+}
+
+func TestExtendingUnknownTemplateFails(t *testing.T) {
+
+	_, err := ResolveTemplateInheritance(rawTemplates(t,
+		`{"name": "Orphan", "extends": "Missing"}`,
+	))
+	if err == nil {
+		t.Fatal("expected an unknown parent error")
+	}
+}
@@ -14,11 +14,12 @@ type JSONAttributes struct {
 	BaseProtection    int     `json:"BaseProtection"`
 	BaseDodgeChance   float32 `json:"BaseDodgeChance"`
 	BaseMovementSpeed int     `json:"BaseMovementSpeed"`
+	Focus             int     `json:"Focus,omitempty"`
 }
 
 func (attr JSONAttributes) NewAttributesFromJson() common.Attributes {
 
-	return common.NewBaseAttributes(
+	attributes := common.NewBaseAttributes(
 		attr.MaxHealth,
 		attr.AttackBonus,
 		attr.BaseArmorClass,
@@ -26,6 +27,8 @@ func (attr JSONAttributes) NewAttributesFromJson() common.Attributes {
 		attr.BaseMovementSpeed,
 		attr.BaseDodgeChance)
 
+	attributes.Focus = attr.Focus
+	return attributes
 }
 
 type JSONArmor struct {
@@ -125,12 +128,28 @@ func NewJSONRangedWeapon(r JSONWeapon) JSONRangedWeapon {
 }
 
 type JSONMonster struct {
-	Name         string            `json:"name"`
-	ImageName    string            `json:"imgname"`
+	Name      string `json:"name"`
+	ImageName string `json:"imgname"`
+
+	// Optional name of a parent template this one inherits from.
+	// Resolved by ResolveTemplateInheritance before validation
+	Extends string `json:"extends,omitempty"`
+
 	Attributes   JSONAttributes    `json:"attributes"`
 	Armor        *JSONArmor        `json:"armor"`       // Use pointer to allow null values
 	MeleeWeapon  *JSONMeleeWeapon  `json:"meleeWeapon"` // Use pointer to allow null values
 	RangedWeapon *JSONRangedWeapon `json:"rangedWeapon"`
+
+	// How many distinct units one attack can damage. 0 means unlimited
+	MaxTargetsPerAttack int `json:"maxTargetsPerAttack,omitempty"`
+
+	// Optional multi-cell footprint as [row, col] offsets from the anchor,
+	// for units occupying more than one squad grid cell. Empty means a
+	// single cell. Validated by ValidateFootprint at load
+	Footprint [][2]int `json:"footprint,omitempty"`
+
+	// Optional explicit squad-budget cost. 0 means derive it from the stats
+	Cost float64 `json:"cost,omitempty"`
 }
 
 func NewJSONMonster(m JSONMonster) JSONMonster {
@@ -142,6 +161,10 @@ func NewJSONMonster(m JSONMonster) JSONMonster {
 		Armor:        m.Armor,
 		MeleeWeapon:  m.MeleeWeapon,
 		RangedWeapon: m.RangedWeapon,
+
+		MaxTargetsPerAttack: m.MaxTargetsPerAttack,
+		Footprint:           m.Footprint,
+		Cost:                m.Cost,
 	}
 }
 
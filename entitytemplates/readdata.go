@@ -34,20 +34,50 @@ func ReadMonsterData() {
 		panic(err)
 	}
 
-	// Parse JSON
-	var monsters MonstersData
-	err = json.Unmarshal(data, &monsters)
+	// Parse JSON. The raw pass keeps field presence information so template
+	// inheritance can tell explicit zeroes from absent fields
+	var rawMonsters struct {
+		Monsters []json.RawMessage `json:"monsters"`
+	}
+	err = json.Unmarshal(data, &rawMonsters)
 
 	if err != nil {
 		panic(err)
 	}
 
+	resolved, err := ResolveTemplateInheritance(rawMonsters.Monsters)
+	if err != nil {
+		panic(err)
+	}
+
+	var monsters MonstersData
+	for _, raw := range resolved {
+
+		var monster JSONMonster
+		if err := json.Unmarshal(raw, &monster); err != nil {
+			panic(err)
+		}
+		monsters.Monsters = append(monsters.Monsters, monster)
+
+	}
+
 	// Iterate over monsters
 	for _, monster := range monsters.Monsters {
 
 		if monster.RangedWeapon != nil {
 			CreateTargetArea(monster.RangedWeapon.TargetArea)
 		}
+
+		if monster.MaxTargetsPerAttack < 0 {
+			panic("maxTargetsPerAttack must not be negative for " + monster.Name)
+		}
+
+		//The 3x3 squad grid; entitytemplates cannot import squads for the
+		//constants without a cycle
+		if err := ValidateFootprint(monster.Footprint, 3, 3); err != nil {
+			panic("footprint of " + monster.Name + " is invalid: " + err.Error())
+		}
+
 		MonsterTemplates = append(MonsterTemplates, NewJSONMonster(monster))
 
 	}
@@ -0,0 +1,93 @@
+package entitytemplates
+
+import (
+	"fmt"
+)
+
+// Template diffing for review tooling: DiffTemplates lists every numeric
+// field two monster templates disagree on, with the delta, so a CLI can
+// audit what a data change actually did. Optional sections a template lacks
+// compare as all zeros, which makes "armor added" read as diffs against 0
+
+// AttributeDiff is one field the two templates disagree on
+type AttributeDiff struct {
+	//Dotted field path, like "attributes.MaxHealth" or "armor.protection"
+	Field string
+
+	A float64
+	B float64
+
+	//B minus A, so a positive delta means the second template is stronger
+	//on that field
+	Delta float64
+}
+
+func (d AttributeDiff) String() string {
+	return fmt.Sprintf("%s: %g -> %g (%+g)", d.Field, d.A, d.B, d.Delta)
+}
+
+// DiffTemplates compares every numeric field of two monster templates and
+// returns the ones that differ. Identical templates diff to nothing
+func DiffTemplates(a JSONMonster, b JSONMonster) []AttributeDiff {
+
+	diffs := []AttributeDiff{}
+
+	compare := func(field string, av float64, bv float64) {
+		if av != bv {
+			diffs = append(diffs, AttributeDiff{Field: field, A: av, B: bv, Delta: bv - av})
+		}
+	}
+
+	compare("attributes.MaxHealth", float64(a.Attributes.MaxHealth), float64(b.Attributes.MaxHealth))
+	compare("attributes.AttackBonus", float64(a.Attributes.AttackBonus), float64(b.Attributes.AttackBonus))
+	compare("attributes.BaseArmorClass", float64(a.Attributes.BaseArmorClass), float64(b.Attributes.BaseArmorClass))
+	compare("attributes.BaseProtection", float64(a.Attributes.BaseProtection), float64(b.Attributes.BaseProtection))
+	compare("attributes.BaseDodgeChance", float64(a.Attributes.BaseDodgeChance), float64(b.Attributes.BaseDodgeChance))
+	compare("attributes.BaseMovementSpeed", float64(a.Attributes.BaseMovementSpeed), float64(b.Attributes.BaseMovementSpeed))
+	compare("attributes.Focus", float64(a.Attributes.Focus), float64(b.Attributes.Focus))
+
+	aArmor, bArmor := armorOrZero(a.Armor), armorOrZero(b.Armor)
+	compare("armor.armorClass", float64(aArmor.ArmorClass), float64(bArmor.ArmorClass))
+	compare("armor.protection", float64(aArmor.Protection), float64(bArmor.Protection))
+	compare("armor.dodgeChance", float64(aArmor.DodgeChance), float64(bArmor.DodgeChance))
+
+	aMelee, bMelee := meleeOrZero(a.MeleeWeapon), meleeOrZero(b.MeleeWeapon)
+	compare("meleeWeapon.minDamage", float64(aMelee.MinDamage), float64(bMelee.MinDamage))
+	compare("meleeWeapon.maxDamage", float64(aMelee.MaxDamage), float64(bMelee.MaxDamage))
+	compare("meleeWeapon.attackSpeed", float64(aMelee.AttackSpeed), float64(bMelee.AttackSpeed))
+
+	aRanged, bRanged := rangedOrZero(a.RangedWeapon), rangedOrZero(b.RangedWeapon)
+	compare("rangedWeapon.minDamage", float64(aRanged.MinDamage), float64(bRanged.MinDamage))
+	compare("rangedWeapon.maxDamage", float64(aRanged.MaxDamage), float64(bRanged.MaxDamage))
+	compare("rangedWeapon.shootingRange", float64(aRanged.ShootingRange), float64(bRanged.ShootingRange))
+	compare("rangedWeapon.attackSpeed", float64(aRanged.AttackSpeed), float64(bRanged.AttackSpeed))
+
+	compare("maxTargetsPerAttack", float64(a.MaxTargetsPerAttack), float64(b.MaxTargetsPerAttack))
+	compare("cost", a.Cost, b.Cost)
+
+	return diffs
+}
+
+func armorOrZero(armor *JSONArmor) JSONArmor {
+
+	if armor == nil {
+		return JSONArmor{}
+	}
+	return *armor
+}
+
+func meleeOrZero(weapon *JSONMeleeWeapon) JSONMeleeWeapon {
+
+	if weapon == nil {
+		return JSONMeleeWeapon{}
+	}
+	return *weapon
+}
+
+func rangedOrZero(weapon *JSONRangedWeapon) JSONRangedWeapon {
+
+	if weapon == nil {
+		return JSONRangedWeapon{}
+	}
+	return *weapon
+}
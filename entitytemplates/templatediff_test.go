@@ -0,0 +1,71 @@
+package entitytemplates
+
+import (
+	"testing"
+)
+
+func diffFixtureMonster() JSONMonster {
+
+	return JSONMonster{
+		Name: "Grunt",
+		Attributes: JSONAttributes{
+			MaxHealth:         20,
+			AttackBonus:       2,
+			BaseArmorClass:    10,
+			BaseProtection:    1,
+			BaseMovementSpeed: 1,
+		},
+		Armor:       &JSONArmor{ArmorClass: 2, Protection: 3},
+		MeleeWeapon: &JSONMeleeWeapon{MinDamage: 4, MaxDamage: 6, AttackSpeed: 5},
+	}
+}
+
+// Two templates differing only in armor protection diff to exactly that
+// one entry, with the delta signed from the first to the second
+func TestDiffFindsTheOneChangedField(t *testing.T) {
+
+	a := diffFixtureMonster()
+	b := diffFixtureMonster()
+	b.Armor = &JSONArmor{ArmorClass: 2, Protection: 5}
+
+	diffs := DiffTemplates(a, b)
+
+	if len(diffs) != 1 {
+		t.Fatalf("expected exactly 1 diff entry, got %d: %v", len(diffs), diffs)
+	}
+
+	diff := diffs[0]
+	if diff.Field != "armor.protection" {
+		t.Errorf("expected the armor.protection field, got %s", diff.Field)
+	}
+	if diff.A != 3 || diff.B != 5 || diff.Delta != 2 {
+		t.Errorf("expected 3 -> 5 with delta +2, got %g -> %g (%g)", diff.A, diff.B, diff.Delta)
+	}
+}
+
+func TestIdenticalTemplatesDiffToNothing(t *testing.T) {
+
+	if diffs := DiffTemplates(diffFixtureMonster(), diffFixtureMonster()); len(diffs) != 0 {
+		t.Errorf("identical templates should produce no diffs, got %v", diffs)
+	}
+}
+
+// A template that drops its weapon entirely diffs against zeros, so the
+// removal shows up field by field
+func TestMissingSectionsCompareAsZero(t *testing.T) {
+
+	a := diffFixtureMonster()
+	b := diffFixtureMonster()
+	b.MeleeWeapon = nil
+
+	diffs := DiffTemplates(a, b)
+
+	if len(diffs) != 3 {
+		t.Fatalf("expected 3 diffs for the removed weapon, got %d: %v", len(diffs), diffs)
+	}
+	for _, diff := range diffs {
+		if diff.B != 0 {
+			t.Errorf("%s should compare the missing side as 0, got %g", diff.Field, diff.B)
+		}
+	}
+}
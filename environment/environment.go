@@ -0,0 +1,170 @@
+package environment
+
+import (
+	"fmt"
+	"math/rand"
+)
+
+// The strategic layers day/night and weather cycle. A Cycle advances once
+// per strategic turn: the phase walks dawn-day-dusk-night on a fixed cadence
+// and the weather is a small seeded Markov chain, so the same seed always
+// plays out the same campaign weather
+
+type Phase int
+
+const (
+	Dawn Phase = iota
+	Day
+	Dusk
+	Night
+)
+
+func (p Phase) String() string {
+	switch p {
+	case Dawn:
+		return "Dawn"
+	case Day:
+		return "Day"
+	case Dusk:
+		return "Dusk"
+	default:
+		return "Night"
+	}
+}
+
+type Weather int
+
+const (
+	Clear Weather = iota
+	Rain
+	Fog
+)
+
+func (w Weather) String() string {
+	switch w {
+	case Clear:
+		return "Clear"
+	case Rain:
+		return "Rain"
+	default:
+		return "Fog"
+	}
+}
+
+// CycleConfig holds the tuning knobs: how many strategic turns each phase
+// lasts and the chance per turn of moving from one weather to another.
+// Anything not listed in Transitions means the weather stays put
+type CycleConfig struct {
+	TurnsPerPhase int
+
+	//Transition probability from the current weather to each other state,
+	//checked once per turn in order. The remainder is "stay"
+	Transitions map[Weather]map[Weather]float64
+}
+
+// DefaultCycleConfig is mild: weather mostly persists, rain and fog come
+// and go now and then
+func DefaultCycleConfig() CycleConfig {
+
+	return CycleConfig{
+		TurnsPerPhase: 4,
+		Transitions: map[Weather]map[Weather]float64{
+			Clear: {Rain: 0.15, Fog: 0.10},
+			Rain:  {Clear: 0.30},
+			Fog:   {Clear: 0.40},
+		},
+	}
+}
+
+type Cycle struct {
+	Phase   Phase
+	Weather Weather
+	Turn    int
+
+	config CycleConfig
+	rng    *rand.Rand
+}
+
+// NewCycle starts at dawn of turn zero under clear skies
+func NewCycle(seed int64, config CycleConfig) *Cycle {
+
+	if config.TurnsPerPhase <= 0 {
+		config.TurnsPerPhase = 4
+	}
+
+	return &Cycle{
+		config: config,
+		rng:    rand.New(rand.NewSource(seed)),
+	}
+}
+
+// AdvanceTurn moves the clock one strategic turn: the phase rolls over on
+// its cadence and the weather gets one transition roll
+func (c *Cycle) AdvanceTurn() {
+
+	c.Turn++
+	if c.Turn%c.config.TurnsPerPhase == 0 {
+		c.Phase = (c.Phase + 1) % 4
+	}
+
+	roll := c.rng.Float64()
+	cumulative := 0.0
+	for _, next := range []Weather{Clear, Rain, Fog} {
+
+		chance, ok := c.config.Transitions[c.Weather][next]
+		if !ok {
+			continue
+		}
+
+		cumulative += chance
+		if roll < cumulative {
+			c.Weather = next
+			break
+		}
+
+	}
+
+}
+
+// SightRadius is the fog-of-war radius under the current conditions. Night
+// halves it, fog takes two more tiles off, and the floor is one tile
+func (c *Cycle) SightRadius(base int) int {
+
+	radius := base
+	if c.Phase == Night {
+		radius /= 2
+	}
+	if c.Weather == Fog {
+		radius -= 2
+	}
+
+	if radius < 1 {
+		radius = 1
+	}
+
+	return radius
+}
+
+// VisibleTiles counts the tiles inside the sight radius, Chebyshev like all
+// squad distances, so tests and the fog-of-war overlay agree on the area
+func (c *Cycle) VisibleTiles(base int) int {
+
+	radius := c.SightRadius(base)
+	return (2*radius + 1) * (2*radius + 1)
+}
+
+// MoveCostMultiplier is applied to the terrain cost of non-road tiles. Rain
+// turns the ground to mud; roads stay firm
+func (c *Cycle) MoveCostMultiplier(onRoad bool) float64 {
+
+	if c.Weather == Rain && !onRoad {
+		return 1.5
+	}
+
+	return 1.0
+}
+
+// Describe is the HUD line: "Night, Rain (turn 12)"
+func (c *Cycle) Describe() string {
+	return fmt.Sprintf("%s, %s (turn %d)", c.Phase, c.Weather, c.Turn)
+}
@@ -0,0 +1,79 @@
+package environment
+
+import (
+	"testing"
+)
+
+// The same seed must play out the same weather, turn for turn
+func TestWeatherProgressionIsReproducible(t *testing.T) {
+
+	first := NewCycle(99, DefaultCycleConfig())
+	second := NewCycle(99, DefaultCycleConfig())
+
+	for turn := 0; turn < 200; turn++ {
+
+		first.AdvanceTurn()
+		second.AdvanceTurn()
+
+		if first.Weather != second.Weather || first.Phase != second.Phase {
+			t.Fatalf("turn %d: cycles diverged, %s vs %s", turn, first.Describe(), second.Describe())
+		}
+
+	}
+}
+
+// The phase walks dawn-day-dusk-night on the configured cadence
+func TestPhaseCadence(t *testing.T) {
+
+	cycle := NewCycle(1, CycleConfig{TurnsPerPhase: 2, Transitions: nil})
+
+	expected := []Phase{Dawn, Day, Day, Dusk, Dusk, Night, Night, Dawn}
+	for i, want := range expected {
+
+		cycle.AdvanceTurn()
+		if cycle.Phase != want {
+			t.Errorf("turn %d: expected %s, got %s", i+1, want, cycle.Phase)
+		}
+
+	}
+}
+
+// Night and fog each shrink the visible area, and stacked they shrink it
+// further, never below a single ring
+func TestSightRadiusShrinks(t *testing.T) {
+
+	cycle := NewCycle(1, DefaultCycleConfig())
+	base := 6
+
+	clearDay := cycle.VisibleTiles(base)
+
+	cycle.Phase = Night
+	night := cycle.VisibleTiles(base)
+	if night >= clearDay {
+		t.Errorf("night should shrink visibility: %d vs %d tiles", night, clearDay)
+	}
+
+	cycle.Weather = Fog
+	foggyNight := cycle.VisibleTiles(base)
+	if foggyNight >= night {
+		t.Errorf("fog at night should shrink visibility further: %d vs %d tiles", foggyNight, night)
+	}
+
+	if cycle.SightRadius(0) != 1 {
+		t.Error("the sight radius should never drop below one tile")
+	}
+}
+
+// Rain only punishes off-road movement
+func TestRainSlowsOffRoadMovement(t *testing.T) {
+
+	cycle := NewCycle(1, DefaultCycleConfig())
+	cycle.Weather = Rain
+
+	if cycle.MoveCostMultiplier(false) <= 1.0 {
+		t.Error("rain should slow movement off roads")
+	}
+	if cycle.MoveCostMultiplier(true) != 1.0 {
+		t.Error("roads should stay firm in the rain")
+	}
+}
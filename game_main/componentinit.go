@@ -1,11 +1,12 @@
 package main
 
 import (
-	"game_main/behavior"
 	"game_main/common"
 	"game_main/gear"
+	"game_main/internal/behavior"
 	"game_main/monsters"
 	"game_main/rendering"
+	"game_main/squads"
 	"game_main/timesystem"
 
 	"github.com/bytearena/ecs"
@@ -38,6 +39,7 @@ func InitializeECS(ecsmanager *common.EntityManager) {
 
 	behavior.InitializeMovementComponents(manager, tags)
 	gear.InitializeItemComponents(manager, tags)
+	squads.InitializeSquadComponents(manager, tags)
 
 	timesystem.ActionQueueComponent = manager.NewComponent()
 
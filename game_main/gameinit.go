@@ -4,8 +4,8 @@ import (
 	"game_main/avatar"
 	"game_main/common"
 	"game_main/gear"
+	"game_main/internal/testing"
 	"game_main/rendering"
-	"game_main/testing"
 	"game_main/timesystem"
 	tracker "game_main/trackers"
 	"game_main/worldmap"
@@ -16,22 +16,25 @@ import (
 )*/
 
 import (
+	"flag"
 	"fmt"
 	"game_main/avatar"
-	"game_main/behavior"
 	"game_main/common"
 	"game_main/entitytemplates"
 	"game_main/gear"
 	"game_main/graphics"
+	"game_main/internal/behavior"
+	resmanager "game_main/internal/resourcemanager"
 	"game_main/rendering"
-	resmanager "game_main/resourcemanager"
 	"math"
+	"os"
 	"runtime"
 
-	"game_main/gui"
-	"game_main/input"
-	"game_main/spawning"
-	"game_main/testing"
+	"game_main/internal/gui"
+	"game_main/internal/input"
+	"game_main/internal/spawning"
+	"game_main/internal/testing"
+	"game_main/squads"
 	"game_main/timesystem"
 	"game_main/worldmap"
 	_ "image/png"
@@ -57,6 +60,11 @@ type Game struct {
 	gameMap    worldmap.GameMap //Logical map
 
 	ts timesystem.GameTurn
+
+	//Last screen size Draw saw, so a window resize reflows the HUD once
+	//instead of every frame
+	lastScreenW int
+	lastScreenH int
 }
 
 // NewGame creates a new Game Object and initializes the data
@@ -176,6 +184,12 @@ func ManageTurn(g *Game) {
 
 		resmanager.RemoveDeadEntities(&g.em, &g.gameMap)
 
+		if DEBUG_MODE {
+			for _, problem := range squads.ValidateWorldConsistency(&g.em, nil) {
+				fmt.Println("world consistency:", problem)
+			}
+		}
+
 	}
 
 }
@@ -221,6 +235,16 @@ func (g *Game) Draw(screen *ebiten.Image) {
 	graphics.ScreenInfo.ScreenWidth = screen.Bounds().Dx()
 	graphics.ScreenInfo.ScreenHeight = screen.Bounds().Dy()
 
+	//The window is resizable: the first frame just records the size, after
+	//that any change reflows the HUD panels
+	if graphics.ScreenInfo.ScreenWidth != g.lastScreenW || graphics.ScreenInfo.ScreenHeight != g.lastScreenH {
+		if g.lastScreenW != 0 {
+			g.gameUI.OnResize(graphics.ScreenInfo.ScreenWidth, graphics.ScreenInfo.ScreenHeight)
+		}
+		g.lastScreenW = graphics.ScreenInfo.ScreenWidth
+		g.lastScreenH = graphics.ScreenInfo.ScreenHeight
+	}
+
 	if graphics.MAP_SCROLLING_ENABLED {
 		g.gameMap.DrawLevelCenteredSquare(screen, g.playerData.Pos, graphics.ViewableSquareSize, DEBUG_MODE)
 		rendering.ProcessRenderablesInSquare(&g.em, g.gameMap, screen, g.playerData.Pos, graphics.ViewableSquareSize, DEBUG_MODE)
@@ -260,6 +284,14 @@ func main() {
 
 	//log.Println(http.ListenAndServe("localhost:6060", nil))
 
+	smoke := flag.Bool("smoke", false, "run the headless smoke test and exit")
+	smokeTicks := flag.Int("smoke-ticks", 10, "number of simulated update ticks for the smoke test")
+	flag.Parse()
+
+	if *smoke {
+		os.Exit(RunSmokeTest(*smokeTicks))
+	}
+
 	BenchmarkSetup()
 	g := NewGame()
 
@@ -0,0 +1,129 @@
+package main
+
+import (
+	"fmt"
+	"game_main/common"
+	"game_main/entitytemplates"
+	"game_main/graphics"
+	"game_main/internal/spawning"
+	"game_main/internal/testing"
+	"game_main/squads"
+	"game_main/timesystem"
+	"game_main/worldmap"
+)
+
+// The smoke test walks the same initialization path as NewGame but step by step,
+// so that CI and contributors without a display can tell which subsystem broke.
+// It never calls ebiten.RunGame and never draws, so it runs without a GPU.
+// Returns 0 on success and 1 on the first failing step
+
+func runSmokeStep(name string, step func()) (ok bool) {
+
+	defer func() {
+		if r := recover(); r != nil {
+			fmt.Println("smoke test failed in", name, ":", r)
+			ok = false
+		}
+	}()
+
+	step()
+	return true
+}
+
+func RunSmokeTest(ticks int) int {
+
+	g := &Game{}
+
+	steps := []struct {
+		name string
+		step func()
+	}{
+		{"map generation", func() {
+			g.gameMap = worldmap.NewGameMap()
+		}},
+		{"template loading", func() {
+			entitytemplates.ReadGameData()
+			if len(entitytemplates.MonsterTemplates) == 0 {
+				panic("no monster templates were loaded")
+			}
+		}},
+		{"ECS initialization", func() {
+			InitializeECS(&g.em)
+		}},
+		{"player initialization", func() {
+			graphics.ScreenInfo.ScaleFactor = 1
+			if graphics.MAP_SCROLLING_ENABLED {
+				graphics.ScreenInfo.ScaleFactor = 3
+			}
+			InitializePlayerData(&g.em, &g.playerData, &g.gameMap)
+			spawning.InitLootSpawnTables()
+		}},
+		{"test content spawning", func() {
+			g.ts.Turn = timesystem.PlayerTurn
+			g.ts.TurnCounter = 0
+
+			testing.CreateTestItems(g.em.World, g.em.WorldTags, &g.gameMap)
+			testing.UpdateContentsForTest(&g.em, &g.gameMap)
+			spawning.SpawnStartingCreatures(0, &g.em, &g.gameMap, &g.playerData)
+			testing.CreateTestConsumables(&g.em, &g.gameMap)
+			testing.InitTestActionManager(&g.em, &g.playerData, &g.ts)
+
+			g.ts.ActionDispatcher.ResetActionManager()
+			timesystem.TurnManager = &g.ts
+			spawning.SpawnStartingEquipment(&g.em, &g.gameMap, &g.playerData)
+			AddCreaturesToTracker(&g.em)
+		}},
+		{"UI creation", func() {
+			g.gameUI.CreateMainInterface(&g.playerData, &g.em)
+		}},
+		{"update ticks", func() {
+			for i := 0; i < ticks; i++ {
+				//Synthetic input. Pretending the player acted lets the
+				//turn manager run the monster side of the loop too
+				g.playerData.InputStates.HasKeyInput = true
+				ManageTurn(g)
+			}
+		}},
+		{"auto-resolved battle", func() {
+			smokeTestBattle(&g.em)
+		}},
+	}
+
+	for _, s := range steps {
+		if !runSmokeStep(s.name, s.step) {
+			return 1
+		}
+	}
+
+	fmt.Println("smoke test passed")
+	return 0
+}
+
+// Runs a small squad battle to completion so the combat path gets exercised
+func smokeTestBattle(em *common.EntityManager) {
+
+	squads.Units["SmokeKnight"] = squads.UnitTemplate{
+		Name:      "SmokeKnight",
+		Class:     "Knight",
+		Attr:      common.NewBaseAttributes(15, 2, 10, 1, 1, 0),
+		MinDamage: 2,
+		MaxDamage: 4,
+	}
+
+	attackers := squads.CreateSquad(em, "Smoke Attackers", "Player", common.Position{X: 1, Y: 1}, squads.DirDown)
+	defenders := squads.CreateSquad(em, "Smoke Defenders", "Enemy", common.Position{X: 1, Y: 3}, squads.DirUp)
+
+	att := squads.CreateUnitFromTemplate(em, squads.Units["SmokeKnight"], attackers.SquadID, 0, 0)
+	def := squads.CreateUnitFromTemplate(em, squads.Units["SmokeKnight"], defenders.SquadID, 0, 0)
+
+	for round := 0; round < 100; round++ {
+
+		squads.ResolveUnitAttack(em, att.GetID(), def.GetID(), squads.DirDown)
+		if common.GetAttributes(def).CurrentHealth <= 0 {
+			return
+		}
+
+	}
+
+	panic("battle did not resolve within 100 rounds")
+}
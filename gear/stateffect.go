@@ -433,6 +433,7 @@ func InitializeItemComponents(manager *ecs.Manager, tags map[string]ecs.Tag) {
 
 	ConsumableComponent = manager.NewComponent()
 	ConsEffectTrackerComponent = manager.NewComponent()
+	UnitEquipmentComponent = manager.NewComponent()
 
 	AllItemEffects = append(AllItemEffects, StickyComponent, BurningComponent, FreezingComponent, ThrowableComponent)
 
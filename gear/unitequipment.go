@@ -0,0 +1,136 @@
+package gear
+
+import (
+	"fmt"
+	"game_main/common"
+
+	"github.com/bytearena/ecs"
+)
+
+var UnitEquipmentComponent *ecs.Component
+
+// UnitEquipment is gear carried by a single squad unit. Squad artifacts buff the
+// whole squad; this only changes the attributes of the unit wearing it.
+// Armor bonuses are applied to the units attribute totals when equipped and
+// removed again on unequip. Weapon damage is rolled at attack time through
+// UnitWeaponDamage
+type UnitEquipment struct {
+	EqWeapon *ecs.Entity
+	EqArmor  *ecs.Entity
+}
+
+func GetUnitEquipment(e *ecs.Entity) *UnitEquipment {
+	return common.GetComponentType[*UnitEquipment](e, UnitEquipmentComponent)
+}
+
+// Adds the UnitEquipment component the first time a unit equips something
+func unitEquipmentFor(unit *ecs.Entity) *UnitEquipment {
+
+	if !unit.HasComponent(UnitEquipmentComponent) {
+		unit.AddComponent(UnitEquipmentComponent, &UnitEquipment{})
+	}
+
+	return GetUnitEquipment(unit)
+}
+
+// Equips a weapon or armor on a single unit. The gear entity needs an Armor,
+// MeleeWeapon, or RangedWeapon component. Armor changes the units attribute
+// totals right away
+func EquipUnitGear(unitID ecs.EntityID, gearID ecs.EntityID, manager *common.EntityManager) error {
+
+	unit := manager.World.GetEntityByID(unitID)
+	gearEnt := manager.World.GetEntityByID(gearID)
+
+	if unit == nil || gearEnt == nil {
+		return fmt.Errorf("equip failed, unit or gear entity does not exist")
+	}
+
+	attr := common.GetAttributes(unit.Entity)
+	if attr == nil {
+		return fmt.Errorf("equip failed, unit has no attributes")
+	}
+
+	eq := unitEquipmentFor(unit.Entity)
+
+	switch KindOfItem(gearEnt.Entity) {
+
+	case ArmorType:
+
+		if eq.EqArmor != nil {
+			removeUnitArmorBonus(unit.Entity, eq.EqArmor)
+		}
+
+		eq.EqArmor = gearEnt.Entity
+		armor := GetArmor(gearEnt.Entity)
+		attr.TotalArmorClass += armor.ArmorClass
+		attr.TotalProtection += armor.Protection
+		attr.TotalDodgeChance += armor.DodgeChance
+
+	case MeleeWeaponType, RangedWeaponType:
+		eq.EqWeapon = gearEnt.Entity
+
+	default:
+		return fmt.Errorf("equip failed, entity is not a weapon or armor")
+
+	}
+
+	return nil
+}
+
+// Removes a single piece of gear from a unit and reverses what equipping it did
+func UnequipUnitGear(unitID ecs.EntityID, gearID ecs.EntityID, manager *common.EntityManager) error {
+
+	unit := manager.World.GetEntityByID(unitID)
+	gearEnt := manager.World.GetEntityByID(gearID)
+
+	if unit == nil || gearEnt == nil {
+		return fmt.Errorf("unequip failed, unit or gear entity does not exist")
+	}
+
+	eq := GetUnitEquipment(unit.Entity)
+	if eq == nil {
+		return fmt.Errorf("unequip failed, unit has no equipment")
+	}
+
+	if eq.EqArmor == gearEnt.Entity {
+		removeUnitArmorBonus(unit.Entity, eq.EqArmor)
+		eq.EqArmor = nil
+	} else if eq.EqWeapon == gearEnt.Entity {
+		eq.EqWeapon = nil
+	} else {
+		return fmt.Errorf("unequip failed, unit does not have that gear equipped")
+	}
+
+	return nil
+}
+
+func removeUnitArmorBonus(unit *ecs.Entity, armorEnt *ecs.Entity) {
+
+	attr := common.GetAttributes(unit)
+	armor := GetArmor(armorEnt)
+
+	attr.TotalArmorClass -= armor.ArmorClass
+	attr.TotalProtection -= armor.Protection
+	attr.TotalDodgeChance -= armor.DodgeChance
+
+}
+
+// Rolls the damage of the weapon a unit has equipped. Returns 0 for units
+// without a weapon so the caller can always add it to the base damage
+func UnitWeaponDamage(unit *ecs.Entity) int {
+
+	eq := GetUnitEquipment(unit)
+	if eq == nil || eq.EqWeapon == nil {
+		return 0
+	}
+
+	if w := common.GetComponentType[*MeleeWeapon](eq.EqWeapon, MeleeWeaponComponent); w != nil {
+		return w.CalculateDamage()
+	}
+
+	if w := common.GetComponentType[*RangedWeapon](eq.EqWeapon, RangedWeaponComponent); w != nil {
+		return w.CalculateDamage()
+	}
+
+	return 0
+}
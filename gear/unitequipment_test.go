@@ -0,0 +1,98 @@
+package gear
+
+import (
+	"game_main/common"
+	"testing"
+
+	"github.com/bytearena/ecs"
+)
+
+func newEquipmentTestManager() *common.EntityManager {
+
+	manager := ecs.NewManager()
+	tags := make(map[string]ecs.Tag)
+
+	common.PositionComponent = manager.NewComponent()
+	common.NameComponent = manager.NewComponent()
+	common.AttributeComponent = manager.NewComponent()
+
+	//These are registered in game_main during the real init
+	ArmorComponent = manager.NewComponent()
+	MeleeWeaponComponent = manager.NewComponent()
+	RangedWeaponComponent = manager.NewComponent()
+	InventoryComponent = manager.NewComponent()
+
+	InitializeItemComponents(manager, tags)
+
+	return &common.EntityManager{World: manager, WorldTags: tags}
+}
+
+func createTestUnit(em *common.EntityManager) *ecs.Entity {
+
+	attr := common.NewBaseAttributes(10, 1, 10, 1, 1, 0)
+	attr.TotalArmorClass = attr.BaseArmorClass
+	attr.TotalProtection = attr.BaseProtection
+
+	return em.World.NewEntity().AddComponent(common.AttributeComponent, &attr)
+}
+
+// Equipping a weapon on one unit raises only that units damage
+func TestEquipUnitWeaponOnlyAffectsThatUnit(t *testing.T) {
+
+	em := newEquipmentTestManager()
+
+	unitA := createTestUnit(em)
+	unitB := createTestUnit(em)
+
+	weapon := em.World.NewEntity().
+		AddComponent(MeleeWeaponComponent, &MeleeWeapon{MinDamage: 3, MaxDamage: 3})
+
+	if err := EquipUnitGear(unitA.GetID(), weapon.GetID(), em); err != nil {
+		t.Fatalf("equip failed: %v", err)
+	}
+
+	if UnitWeaponDamage(unitA) != 3 {
+		t.Errorf("expected unit A to deal 3 weapon damage, got %d", UnitWeaponDamage(unitA))
+	}
+	if UnitWeaponDamage(unitB) != 0 {
+		t.Errorf("expected unit B to be unaffected, got %d weapon damage", UnitWeaponDamage(unitB))
+	}
+
+	if err := UnequipUnitGear(unitA.GetID(), weapon.GetID(), em); err != nil {
+		t.Fatalf("unequip failed: %v", err)
+	}
+
+	if UnitWeaponDamage(unitA) != 0 {
+		t.Errorf("expected 0 weapon damage after unequip, got %d", UnitWeaponDamage(unitA))
+	}
+}
+
+// Armor bonuses apply on equip and reverse cleanly on unequip
+func TestEquipUnitArmorReversesCleanly(t *testing.T) {
+
+	em := newEquipmentTestManager()
+
+	unit := createTestUnit(em)
+	attr := common.GetAttributes(unit)
+	baseAC := attr.TotalArmorClass
+	baseProt := attr.TotalProtection
+
+	armor := em.World.NewEntity().
+		AddComponent(ArmorComponent, &Armor{ArmorClass: 4, Protection: 2})
+
+	if err := EquipUnitGear(unit.GetID(), armor.GetID(), em); err != nil {
+		t.Fatalf("equip failed: %v", err)
+	}
+
+	if attr.TotalArmorClass != baseAC+4 || attr.TotalProtection != baseProt+2 {
+		t.Errorf("armor bonuses not applied, AC %d Prot %d", attr.TotalArmorClass, attr.TotalProtection)
+	}
+
+	if err := UnequipUnitGear(unit.GetID(), armor.GetID(), em); err != nil {
+		t.Fatalf("unequip failed: %v", err)
+	}
+
+	if attr.TotalArmorClass != baseAC || attr.TotalProtection != baseProt {
+		t.Errorf("armor bonuses not reversed, AC %d Prot %d", attr.TotalArmorClass, attr.TotalProtection)
+	}
+}
@@ -0,0 +1,94 @@
+package graphics
+
+// The staged playback controller paces battle events for the observer: each
+// staged step waits its scaled delay, then applies its gameplay consequence
+// via the callback. In instant mode Update drains the whole queue in one
+// call, so the final state is identical to watching it at 1x, just without
+// the waiting
+
+type PlaybackStep struct {
+	//Frames to wait before applying, at 1x speed
+	BaseDelay int
+
+	//The gameplay consequence of the step. Always runs, even when skipped
+	//straight to the end state
+	Apply func()
+}
+
+type PlaybackController struct {
+	queue []PlaybackStep
+
+	//Frames left before the head of the queue applies
+	countdown int
+	started   bool
+}
+
+func NewPlaybackController() *PlaybackController {
+	return &PlaybackController{}
+}
+
+func (p *PlaybackController) Enqueue(step PlaybackStep) {
+	p.queue = append(p.queue, step)
+}
+
+func (p *PlaybackController) Pending() int {
+	return len(p.queue)
+}
+
+// Update advances playback by one frame. The current time scale is read every
+// frame, so speed changes mid-battle take effect immediately
+func (p *PlaybackController) Update() {
+
+	if IsInstant() {
+		p.SkipToEnd()
+		return
+	}
+
+	for len(p.queue) > 0 {
+
+		if !p.started {
+			p.countdown = ScaleFrames(p.queue[0].BaseDelay)
+			p.started = true
+		}
+
+		if p.countdown > 0 {
+			p.countdown--
+			return
+		}
+
+		p.applyHead()
+
+	}
+
+}
+
+// SkipToEnd applies every remaining step immediately. Gameplay consequences
+// still happen, only the pacing is skipped
+func (p *PlaybackController) SkipToEnd() {
+
+	for len(p.queue) > 0 {
+		p.applyHead()
+	}
+
+}
+
+func (p *PlaybackController) applyHead() {
+
+	step := p.queue[0]
+	p.queue = p.queue[1:]
+	p.started = false
+
+	if step.Apply != nil {
+		step.Apply()
+	}
+
+}
+
+// The artificial pause between AI squad activations, in frames. Routed
+// through the same time scale as playback so "instant AI turns" falls out of
+// the one setting
+const baseAITurnDelayFrames = 30
+
+func AITurnDelayFrames() int {
+	return ScaleFrames(baseAITurnDelayFrames)
+}
@@ -0,0 +1,110 @@
+package graphics
+
+import "testing"
+
+// Resets the speed setting, since it is package state shared across tests
+func resetSpeed() {
+	SetCombatSpeed(1.0)
+}
+
+func enqueueCountingSteps(p *PlaybackController, applied *[]int, count int, baseDelay int) {
+
+	for i := 0; i < count; i++ {
+		step := i
+		p.Enqueue(PlaybackStep{BaseDelay: baseDelay, Apply: func() {
+			*applied = append(*applied, step)
+		}})
+	}
+
+}
+
+func TestPlaybackHonorsSpeedMultiplier(t *testing.T) {
+
+	defer resetSpeed()
+
+	//At 2x a 10 frame delay becomes 5 frames
+	SetCombatSpeed(2.0)
+
+	applied := []int{}
+	p := NewPlaybackController()
+	enqueueCountingSteps(p, &applied, 1, 10)
+
+	for i := 0; i < 5; i++ {
+		p.Update()
+	}
+	if len(applied) != 0 {
+		t.Fatal("the step applied before its scaled delay elapsed")
+	}
+
+	p.Update()
+	if len(applied) != 1 {
+		t.Fatal("the step should apply once the scaled delay has elapsed")
+	}
+}
+
+// Instant mode and 1x must end in the same final state, instant just skips
+// the waiting
+func TestInstantModeMatchesFinalState(t *testing.T) {
+
+	defer resetSpeed()
+
+	SetCombatSpeed(1.0)
+	paced := []int{}
+	p := NewPlaybackController()
+	enqueueCountingSteps(p, &paced, 3, 2)
+	for i := 0; i < 100 && p.Pending() > 0; i++ {
+		p.Update()
+	}
+
+	SetInstantMode()
+	instant := []int{}
+	q := NewPlaybackController()
+	enqueueCountingSteps(q, &instant, 3, 2)
+	q.Update()
+
+	if q.Pending() != 0 {
+		t.Fatal("instant mode should drain the whole queue in one update")
+	}
+
+	if len(paced) != len(instant) {
+		t.Fatalf("instant mode applied %d steps, 1x applied %d", len(instant), len(paced))
+	}
+	for i := range paced {
+		if paced[i] != instant[i] {
+			t.Errorf("step order diverged at %d: %v vs %v", i, paced, instant)
+		}
+	}
+}
+
+func TestAIDelayHonorsTimeScale(t *testing.T) {
+
+	defer resetSpeed()
+
+	SetCombatSpeed(1.0)
+	base := AITurnDelayFrames()
+
+	SetCombatSpeed(4.0)
+	if AITurnDelayFrames() != base/4 {
+		t.Errorf("expected the AI delay to shrink to %d at 4x, got %d", base/4, AITurnDelayFrames())
+	}
+
+	SetInstantMode()
+	if AITurnDelayFrames() != 0 {
+		t.Error("instant mode should remove the AI delay entirely")
+	}
+}
+
+func TestSpeedSettingClampsToSupportedRange(t *testing.T) {
+
+	defer resetSpeed()
+
+	SetCombatSpeed(100)
+	if TimeScale() != MaxCombatSpeed {
+		t.Errorf("expected the speed to clamp to %v, got %v", MaxCombatSpeed, TimeScale())
+	}
+
+	SetCombatSpeed(0.01)
+	if TimeScale() != MinCombatSpeed {
+		t.Errorf("expected the speed to clamp to %v, got %v", MinCombatSpeed, TimeScale())
+	}
+}
@@ -0,0 +1,65 @@
+package graphics
+
+// One global combat speed setting instead of per-system pacing constants.
+// Playback delays, visual effect durations and AI turn pauses all route their
+// frame counts through ScaleFrames, so changing the speed mid-battle affects
+// everything at once. Instant mode collapses every delay to zero; the systems
+// themselves stay responsible for still applying their gameplay consequences
+
+const (
+	MinCombatSpeed = 0.5
+	MaxCombatSpeed = 4.0
+)
+
+var combatSpeed = 1.0
+var instantMode = false
+
+// SetCombatSpeed clamps into the supported 0.5x-4x range and leaves instant
+// mode. Safe to call mid-battle
+func SetCombatSpeed(multiplier float64) {
+
+	if multiplier < MinCombatSpeed {
+		multiplier = MinCombatSpeed
+	}
+	if multiplier > MaxCombatSpeed {
+		multiplier = MaxCombatSpeed
+	}
+
+	combatSpeed = multiplier
+	instantMode = false
+}
+
+// SetInstantMode skips all pacing entirely, everything resolves immediately
+func SetInstantMode() {
+	instantMode = true
+}
+
+func TimeScale() float64 {
+
+	if instantMode {
+		return 0
+	}
+
+	return combatSpeed
+}
+
+func IsInstant() bool {
+	return instantMode
+}
+
+// ScaleFrames converts a base delay or duration in frames to the current
+// speed. Higher speed means fewer frames, instant means none. Anything that
+// was nonzero stays at least one frame so animations cannot divide by zero
+func ScaleFrames(baseFrames int) int {
+
+	if instantMode || baseFrames <= 0 {
+		return 0
+	}
+
+	scaled := int(float64(baseFrames) / combatSpeed)
+	if scaled < 1 {
+		scaled = 1
+	}
+
+	return scaled
+}
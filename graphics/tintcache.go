@@ -0,0 +1,94 @@
+package graphics
+
+import (
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// Banner tinting draws a squads sprite through a color scale. Doing that per
+// frame is wasteful, so tinted images are cached per (sprite, color) pair and
+// evicted least-recently-used once the cache fills up
+
+type TintKey struct {
+	Sprite  *ebiten.Image
+	R, G, B uint8
+}
+
+type TintCache struct {
+	maxEntries int
+	images     map[TintKey]*ebiten.Image
+
+	//Oldest key first. Small enough that a slice beats a linked list
+	order []TintKey
+}
+
+func NewTintCache(maxEntries int) *TintCache {
+
+	return &TintCache{
+		maxEntries: maxEntries,
+		images:     make(map[TintKey]*ebiten.Image),
+	}
+}
+
+// Tinted returns the cached tinted copy of the sprite, building it on a miss
+func (c *TintCache) Tinted(sprite *ebiten.Image, r uint8, g uint8, b uint8) *ebiten.Image {
+
+	key := TintKey{Sprite: sprite, R: r, G: g, B: b}
+
+	if img, ok := c.images[key]; ok {
+		c.touch(key)
+		return img
+	}
+
+	img := buildTintedImage(sprite, r, g, b)
+	c.insert(key, img)
+
+	return img
+}
+
+func (c *TintCache) Len() int {
+	return len(c.images)
+}
+
+func (c *TintCache) Contains(key TintKey) bool {
+	_, ok := c.images[key]
+	return ok
+}
+
+func (c *TintCache) insert(key TintKey, img *ebiten.Image) {
+
+	if len(c.images) >= c.maxEntries && c.maxEntries > 0 {
+
+		oldest := c.order[0]
+		c.order = c.order[1:]
+		delete(c.images, oldest)
+
+	}
+
+	c.images[key] = img
+	c.order = append(c.order, key)
+}
+
+// Move the key to the back of the eviction order
+func (c *TintCache) touch(key TintKey) {
+
+	for i, k := range c.order {
+		if k == key {
+			c.order = append(c.order[:i], c.order[i+1:]...)
+			c.order = append(c.order, key)
+			return
+		}
+	}
+
+}
+
+func buildTintedImage(sprite *ebiten.Image, r uint8, g uint8, b uint8) *ebiten.Image {
+
+	bounds := sprite.Bounds()
+	img := ebiten.NewImage(bounds.Dx(), bounds.Dy())
+
+	opts := &ebiten.DrawImageOptions{}
+	opts.ColorScale.Scale(float32(r)/255, float32(g)/255, float32(b)/255, 1)
+	img.DrawImage(sprite, opts)
+
+	return img
+}
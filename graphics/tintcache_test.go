@@ -0,0 +1,58 @@
+package graphics
+
+import (
+	"testing"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// The cache must key on the (sprite, color) pair: same pair hits, different
+// color on the same sprite misses
+func TestTintCacheKeying(t *testing.T) {
+
+	cache := NewTintCache(8)
+	sprite := ebiten.NewImage(4, 4)
+
+	first := cache.Tinted(sprite, 200, 50, 50)
+	again := cache.Tinted(sprite, 200, 50, 50)
+
+	if first != again {
+		t.Error("the same sprite and color should hit the cache")
+	}
+	if cache.Len() != 1 {
+		t.Errorf("expected 1 cache entry, got %d", cache.Len())
+	}
+
+	other := cache.Tinted(sprite, 50, 200, 50)
+	if other == first {
+		t.Error("a different color on the same sprite must build a new image")
+	}
+	if cache.Len() != 2 {
+		t.Errorf("expected 2 cache entries, got %d", cache.Len())
+	}
+}
+
+// Once full, the least recently used entry gets evicted
+func TestTintCacheEviction(t *testing.T) {
+
+	cache := NewTintCache(2)
+	sprite := ebiten.NewImage(4, 4)
+
+	cache.Tinted(sprite, 1, 0, 0)
+	cache.Tinted(sprite, 2, 0, 0)
+
+	//Touch the first entry so the second one is now the oldest
+	cache.Tinted(sprite, 1, 0, 0)
+
+	cache.Tinted(sprite, 3, 0, 0)
+
+	if cache.Len() != 2 {
+		t.Fatalf("expected the cache to stay at 2 entries, got %d", cache.Len())
+	}
+	if !cache.Contains(TintKey{Sprite: sprite, R: 1}) {
+		t.Error("the recently used entry should survive eviction")
+	}
+	if cache.Contains(TintKey{Sprite: sprite, R: 2}) {
+		t.Error("the least recently used entry should have been evicted")
+	}
+}
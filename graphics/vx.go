@@ -323,10 +323,11 @@ func NewFireEffect(startX, startY, flickerTimer, duration int, scale, opacity fl
 			startX:    float64(startX),
 			startY:    float64(startY),
 		},
-		flickerTimer:     flickerTimer,
-		startTime:        time.Now(),
-		duration:         duration,
-		originalDuration: duration,
+		flickerTimer: flickerTimer,
+		startTime:    time.Now(),
+		//Durations honor the combat speed setting at creation time
+		duration:         ScaleFrames(duration),
+		originalDuration: ScaleFrames(duration),
 		scale:            scale,
 		opacity:          opacity,
 	}
@@ -432,14 +433,15 @@ func NewIceEffect(startX, startY int, duration int) *IceEffect {
 
 	vxImg, _, _ := ebitenutil.NewImageFromFile("../assets/effects/frost0.png")
 	return &IceEffect{
-		img:              vxImg,
-		startX:           float64(startX),
-		startY:           float64(startY),
-		scale:            1.0, // Initial scale
-		opacity:          1.0, // Initial opacity
-		startTime:        time.Now(),
-		duration:         duration,
-		originalDuration: duration,
+		img:       vxImg,
+		startX:    float64(startX),
+		startY:    float64(startY),
+		scale:     1.0, // Initial scale
+		opacity:   1.0, // Initial opacity
+		startTime: time.Now(),
+		//Durations honor the combat speed setting at creation time
+		duration:         ScaleFrames(duration),
+		originalDuration: ScaleFrames(duration),
 		completed:        false,
 	}
 }
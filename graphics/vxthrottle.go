@@ -0,0 +1,157 @@
+package graphics
+
+// When two full squads trade attacks the effect bridge queues dozens of
+// projectiles and bursts in the same frame. The throttle turns that pile of
+// requests into something drawable: same-type effects between the same two
+// squads get merged into one volley with an intensity, effects stacking on one
+// tile get staggered start times, and purely cosmetic effects are dropped
+// first under load. This is pure scheduling logic, the VXHandler draws the
+// result
+
+// EffectRequest describes one queued combat effect before it becomes drawable
+type EffectRequest struct {
+	EffectType string
+
+	AttackerSquadID int
+	DefenderSquadID int
+
+	TileX int
+	TileY int
+
+	//Kill bursts are never merged or dropped so deaths stay visible
+	IsKillBurst bool
+
+	//Misses are purely cosmetic and are the first thing dropped under load
+	IsMiss bool
+}
+
+type ThrottlePolicy struct {
+	//Cap on simultaneous same-type effects between the same two squads.
+	//Extras merge into a single volley effect
+	MaxPerPairAndType int
+
+	//More than this many effects on one tile get staggered start times
+	MaxPerTile int
+
+	//Frames added per extra effect on an overloaded tile
+	StaggerFrames int
+
+	//Total effect budget per frame. Beyond it, misses are dropped
+	MaxTotal int
+}
+
+var DefaultThrottlePolicy = ThrottlePolicy{
+	MaxPerPairAndType: 3,
+	MaxPerTile:        2,
+	StaggerFrames:     4,
+	MaxTotal:          24,
+}
+
+// ScheduledEffect is what the throttle hands to the effect spawner
+type ScheduledEffect struct {
+	Request EffectRequest
+
+	//Frames to wait before the effect starts
+	StartDelay int
+
+	//How many requests this effect stands in for. 1 for a normal effect,
+	//more for a merged volley
+	MergedCount int
+
+	//Scales the volley effect. 1.0 for a single effect
+	Intensity float64
+}
+
+type pairTypeKey struct {
+	effectType       string
+	attacker, target int
+}
+
+type tileKey struct {
+	x, y int
+}
+
+// ThrottleEffects applies the policy to one frames worth of requests
+func ThrottleEffects(requests []EffectRequest, policy ThrottlePolicy) []ScheduledEffect {
+
+	//Drop cosmetic effects first when over the total budget
+	if policy.MaxTotal > 0 && len(requests) > policy.MaxTotal {
+
+		kept := make([]EffectRequest, 0, len(requests))
+		for _, r := range requests {
+			if !r.IsMiss {
+				kept = append(kept, r)
+			}
+		}
+
+		if len(kept) < len(requests) {
+			requests = kept
+		}
+
+	}
+
+	//Merge same-type effects between the same two squads beyond the cap
+	groups := make(map[pairTypeKey][]EffectRequest)
+	order := make([]pairTypeKey, 0)
+
+	scheduled := make([]ScheduledEffect, 0, len(requests))
+
+	for _, r := range requests {
+
+		if r.IsKillBurst {
+			scheduled = append(scheduled, ScheduledEffect{Request: r, MergedCount: 1, Intensity: 1.0})
+			continue
+		}
+
+		key := pairTypeKey{r.EffectType, r.AttackerSquadID, r.DefenderSquadID}
+		if _, ok := groups[key]; !ok {
+			order = append(order, key)
+		}
+		groups[key] = append(groups[key], r)
+
+	}
+
+	for _, key := range order {
+
+		group := groups[key]
+
+		cap := policy.MaxPerPairAndType
+		if cap <= 0 || len(group) <= cap {
+
+			for _, r := range group {
+				scheduled = append(scheduled, ScheduledEffect{Request: r, MergedCount: 1, Intensity: 1.0})
+			}
+			continue
+
+		}
+
+		//Keep cap-1 individual effects and fold the rest into one volley
+		for _, r := range group[:cap-1] {
+			scheduled = append(scheduled, ScheduledEffect{Request: r, MergedCount: 1, Intensity: 1.0})
+		}
+
+		merged := len(group) - (cap - 1)
+		scheduled = append(scheduled, ScheduledEffect{
+			Request:     group[cap-1],
+			MergedCount: merged,
+			Intensity:   1.0 + 0.25*float64(merged-1),
+		})
+
+	}
+
+	//Stagger start times when too many effects target the same tile
+	perTile := make(map[tileKey]int)
+	for i := range scheduled {
+
+		key := tileKey{scheduled[i].Request.TileX, scheduled[i].Request.TileY}
+		count := perTile[key]
+		perTile[key] = count + 1
+
+		if policy.MaxPerTile > 0 && count >= policy.MaxPerTile {
+			scheduled[i].StartDelay = (count - policy.MaxPerTile + 1) * policy.StaggerFrames
+		}
+
+	}
+
+	return scheduled
+}
@@ -0,0 +1,115 @@
+package graphics
+
+import "testing"
+
+// A 9v9 engagements worth of projectile requests between the same two squads
+func nineVersusNineRequests() []EffectRequest {
+
+	requests := make([]EffectRequest, 0, 81)
+	for i := 0; i < 81; i++ {
+
+		requests = append(requests, EffectRequest{
+			EffectType:      "projectile",
+			AttackerSquadID: 1,
+			DefenderSquadID: 2,
+			TileX:           10 + i%3,
+			TileY:           20 + (i/3)%3,
+		})
+
+	}
+
+	return requests
+}
+
+func TestSameTypeEffectsMergeIntoVolley(t *testing.T) {
+
+	policy := ThrottlePolicy{MaxPerPairAndType: 3, MaxPerTile: 100, StaggerFrames: 4, MaxTotal: 0}
+	scheduled := ThrottleEffects(nineVersusNineRequests(), policy)
+
+	if len(scheduled) != 3 {
+		t.Fatalf("expected 81 requests to collapse to 3 effects, got %d", len(scheduled))
+	}
+
+	volley := scheduled[2]
+	if volley.MergedCount != 79 {
+		t.Errorf("expected the volley to stand in for 79 requests, got %d", volley.MergedCount)
+	}
+	if volley.Intensity <= 1.0 {
+		t.Errorf("expected a merged volley to have raised intensity, got %f", volley.Intensity)
+	}
+}
+
+func TestKillBurstsAreNeverMergedOrDropped(t *testing.T) {
+
+	requests := nineVersusNineRequests()
+	for i := 0; i < 4; i++ {
+		requests = append(requests, EffectRequest{
+			EffectType:      "killburst",
+			AttackerSquadID: 1,
+			DefenderSquadID: 2,
+			TileX:           10,
+			TileY:           20,
+			IsKillBurst:     true,
+		})
+	}
+
+	policy := ThrottlePolicy{MaxPerPairAndType: 3, MaxPerTile: 100, StaggerFrames: 4, MaxTotal: 10}
+	scheduled := ThrottleEffects(requests, policy)
+
+	bursts := 0
+	for _, s := range scheduled {
+		if s.Request.IsKillBurst {
+			bursts++
+			if s.MergedCount != 1 {
+				t.Error("kill bursts must not be merged")
+			}
+		}
+	}
+
+	if bursts != 4 {
+		t.Errorf("expected all 4 kill bursts to survive, got %d", bursts)
+	}
+}
+
+func TestMissesDroppedFirstUnderLoad(t *testing.T) {
+
+	requests := nineVersusNineRequests()
+	for i := range requests {
+		if i%2 == 0 {
+			requests[i].IsMiss = true
+		}
+	}
+
+	policy := ThrottlePolicy{MaxPerPairAndType: 0, MaxPerTile: 0, MaxTotal: 50}
+	scheduled := ThrottleEffects(requests, policy)
+
+	for _, s := range scheduled {
+		if s.Request.IsMiss {
+			t.Fatal("expected misses to be dropped when over the total budget")
+		}
+	}
+}
+
+func TestStaggeredStartTimesOnCrowdedTile(t *testing.T) {
+
+	requests := make([]EffectRequest, 0, 4)
+	for i := 0; i < 4; i++ {
+		requests = append(requests, EffectRequest{
+			EffectType:      "burst",
+			AttackerSquadID: 1,
+			DefenderSquadID: 2,
+			TileX:           5,
+			TileY:           5,
+		})
+	}
+
+	policy := ThrottlePolicy{MaxPerPairAndType: 100, MaxPerTile: 2, StaggerFrames: 4, MaxTotal: 0}
+	scheduled := ThrottleEffects(requests, policy)
+
+	if scheduled[0].StartDelay != 0 || scheduled[1].StartDelay != 0 {
+		t.Error("effects within the per-tile budget should start immediately")
+	}
+	if scheduled[2].StartDelay != 4 || scheduled[3].StartDelay != 8 {
+		t.Errorf("expected stagger offsets 4 and 8, got %d and %d", scheduled[2].StartDelay, scheduled[3].StartDelay)
+	}
+}
@@ -0,0 +1,6 @@
+// Package integration holds the end-to-end tests that load the real shipped
+// game data and run full squad battle workflows. The tests only build with
+// the integration tag:
+//
+//	go test ./... -tags=integration
+package integration
@@ -0,0 +1,218 @@
+//go:build integration
+
+package integration
+
+import (
+	"game_main/common"
+	"game_main/entitytemplates"
+	"game_main/gear"
+	"game_main/simulation"
+	"game_main/squads"
+	"math"
+	"testing"
+
+	"github.com/bytearena/ecs"
+)
+
+// The full data pipeline: monsterdata.json -> CreateUnitTemplates -> squads
+// built from real templates -> battles with counterattacks and equipped gear
+// -> battle log export -> reload and aggregation. Everything below runs on
+// the shipped JSON, not synthetic fixtures
+
+// Boots a world with every component the pipeline touches, the way
+// game_mains InitializeECS does
+func newIntegrationManager() *common.EntityManager {
+
+	manager := ecs.NewManager()
+	tags := make(map[string]ecs.Tag)
+
+	common.PositionComponent = manager.NewComponent()
+	common.NameComponent = manager.NewComponent()
+	common.AttributeComponent = manager.NewComponent()
+
+	gear.ArmorComponent = manager.NewComponent()
+	gear.MeleeWeaponComponent = manager.NewComponent()
+	gear.RangedWeaponComponent = manager.NewComponent()
+	gear.InventoryComponent = manager.NewComponent()
+	gear.InitializeItemComponents(manager, tags)
+
+	squads.InitializeSquadComponents(manager, tags)
+
+	return &common.EntityManager{World: manager, WorldTags: tags}
+}
+
+// Loads the shipped monster data once. ReadMonsterData reads relative to the
+// package directory, which sits next to assets like game_main does
+func loadRealTemplates(t *testing.T) []string {
+
+	t.Helper()
+
+	if len(entitytemplates.MonsterTemplates) == 0 {
+		entitytemplates.ReadMonsterData()
+	}
+	squads.CreateUnitTemplates(entitytemplates.MonsterTemplates)
+
+	names := make([]string, 0, len(entitytemplates.MonsterTemplates))
+	for _, m := range entitytemplates.MonsterTemplates {
+		names = append(names, m.Name)
+	}
+
+	if len(names) < 2 {
+		t.Fatalf("expected at least 2 shipped monster templates, got %d", len(names))
+	}
+
+	return names
+}
+
+// Fills a squads front row with real templates, cycling through the names
+func buildSquadFromTemplates(em *common.EntityManager, name string, faction string, pos common.Position, facing squads.Direction, templateNames []string) *squads.SquadData {
+
+	squad := squads.CreateSquad(em, name, faction, pos, facing)
+
+	for col := 0; col < squads.GridWidth; col++ {
+		template := squads.Units[templateNames[col%len(templateNames)]]
+		squads.CreateUnitFromTemplate(em, template, squad.SquadID, 0, col)
+	}
+
+	return squad
+}
+
+func TestFullPipelineFromRealData(t *testing.T) {
+
+	names := loadRealTemplates(t)
+	logDir := t.TempDir()
+
+	sim := simulation.NewSimulator()
+	outcomes := make([]simulation.IterationOutcome, 0)
+
+	const battles = 5
+	for i := 0; i < battles; i++ {
+
+		em := newIntegrationManager()
+
+		att := buildSquadFromTemplates(em, "Raiders", "Player", common.Position{X: 5, Y: 3}, squads.DirDown, names)
+		def := buildSquadFromTemplates(em, "Garrison", "Enemy", common.Position{X: 5, Y: 5}, squads.DirUp, names)
+
+		//One attacker fights with equipped gear so artifact effects go
+		//through the full stack too
+		weapon := em.World.NewEntity().
+			AddComponent(gear.MeleeWeaponComponent, &gear.MeleeWeapon{MinDamage: 2, MaxDamage: 2})
+		bearer := squads.SquadUnits(em, att.SquadID)[0]
+		if err := gear.EquipUnitGear(bearer.GetID(), weapon.GetID(), em); err != nil {
+			t.Fatalf("equipping real gear failed: %v", err)
+		}
+
+		outcome, record := sim.RunBattle(em, att.SquadID, def.SquadID)
+		outcomes = append(outcomes, outcome)
+
+		assertBattleInvariants(t, em, record)
+
+		if _, err := squads.SaveBattleRecord(logDir, record); err != nil {
+			t.Fatalf("exporting the battle log failed: %v", err)
+		}
+
+	}
+
+	//Every exported log must re-load, and reload to the same battle
+	paths, err := squads.FindAllBattles(logDir)
+	if err != nil {
+		t.Fatalf("walking the log dir failed: %v", err)
+	}
+	if len(paths) != battles {
+		t.Fatalf("expected %d exported logs, found %d", battles, len(paths))
+	}
+
+	totalRounds := 0
+	for _, path := range paths {
+
+		record, err := squads.LoadBattleRecord(path)
+		if err != nil {
+			t.Fatalf("exported log did not re-load: %v", err)
+		}
+		if record.WinnerSquadID == 0 || record.Rounds == 0 || len(record.Events) == 0 {
+			t.Errorf("reloaded record %s lost data: %+v", path, record)
+		}
+
+		totalRounds += record.Rounds
+
+	}
+
+	//Aggregation totals must match the in-memory outcomes
+	result := sim.Aggregate("RealDataPipeline", outcomes)
+
+	if result.Iterations != battles {
+		t.Errorf("aggregation counted %d iterations, expected %d", result.Iterations, battles)
+	}
+
+	wins := 0
+	rounds := 0
+	for _, o := range outcomes {
+		if o.AttackerWon {
+			wins++
+		}
+		rounds += o.Rounds
+	}
+
+	if result.AttackerWins != wins {
+		t.Errorf("aggregation counted %d attacker wins, in-memory outcomes say %d", result.AttackerWins, wins)
+	}
+	if math.Abs(result.AvgRounds-float64(rounds)/battles) > 1e-9 {
+		t.Errorf("aggregated AvgRounds %f does not match the in-memory mean", result.AvgRounds)
+	}
+	if rounds != totalRounds {
+		t.Errorf("exported logs total %d rounds, in-memory outcomes %d", totalRounds, rounds)
+	}
+}
+
+// The structural invariants every battle must satisfy regardless of data
+func assertBattleInvariants(t *testing.T, em *common.EntityManager, record *squads.BattleRecord) {
+
+	t.Helper()
+
+	maxEventDamage := 0
+	for _, e := range record.Events {
+
+		if e.Damage < 0 {
+			t.Errorf("negative damage event: %+v", e)
+		}
+		if e.Damage > maxEventDamage {
+			maxEventDamage = e.Damage
+		}
+
+	}
+
+	//No unit keeps taking hits after it died: overkill can only ever be one
+	//killing blow deep
+	damageTaken := make(map[ecs.EntityID]int)
+	for _, e := range record.Events {
+		damageTaken[e.DefenderID] += e.Damage
+	}
+
+	for squadID := range map[int]bool{record.AttackerSquadID: true, record.DefenderSquadID: true} {
+
+		units := squads.SquadUnits(em, squadID)
+		if len(units) > squads.GridWidth*squads.GridHeight {
+			t.Errorf("squad %d exceeds grid capacity with %d units", squadID, len(units))
+		}
+
+		occupied := make(map[[2]int]bool)
+		for _, u := range units {
+
+			data := squads.GetUnitData(u)
+			cell := [2]int{data.Row, data.Col}
+			if occupied[cell] {
+				t.Errorf("squad %d has two units in cell %v", squadID, cell)
+			}
+			occupied[cell] = true
+
+			maxHealth := common.GetAttributes(u).MaxHealth
+			if damageTaken[u.GetID()] > maxHealth+maxEventDamage {
+				t.Errorf("unit %d took %d damage with only %d health, it was hit after death",
+					u.GetID(), damageTaken[u.GetID()], maxHealth)
+			}
+
+		}
+
+	}
+
+}
@@ -4,7 +4,7 @@ import (
 	"fmt"
 
 	"game_main/avatar"
-	"game_main/combat"
+	"game_main/internal/combat"
 
 	"game_main/common"
 	"game_main/gear"
@@ -5,7 +5,7 @@ import (
 	"game_main/graphics"
 	"game_main/monsters"
 
-	"game_main/pathfinding"
+	"game_main/internal/pathfinding"
 	"game_main/randgen"
 	"game_main/timesystem"
 	"game_main/worldmap"
@@ -6,8 +6,8 @@ import (
 	"game_main/common"
 	"game_main/gear"
 	"game_main/graphics"
+	resmanager "game_main/internal/resourcemanager"
 	"game_main/randgen"
-	resmanager "game_main/resourcemanager"
 	"game_main/worldmap"
 	"log"
 	"strconv"
@@ -0,0 +1,90 @@
+package gui
+
+import (
+	"game_main/common"
+	"game_main/gear"
+	"game_main/squads"
+	"game_main/worldmap"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+)
+
+// BattleSummaryMode shows the PostBattleSummary after tactical combat.
+// Enter with a fresh summary via ShowSummary. Dismissing applies the accepted
+// loot exactly once and returns to the previous mode
+
+type BattleSummaryMode struct {
+	Summary *squads.PostBattleSummary
+
+	ecsmanager *common.EntityManager
+	inventory  *gear.Inventory
+	gameMap    *worldmap.GameMap
+	manager    *UIModeManager
+}
+
+func NewBattleSummaryMode(manager *UIModeManager, ecsmanager *common.EntityManager, inv *gear.Inventory, gm *worldmap.GameMap) *BattleSummaryMode {
+
+	return &BattleSummaryMode{
+		manager:    manager,
+		ecsmanager: ecsmanager,
+		inventory:  inv,
+		gameMap:    gm,
+	}
+}
+
+func (b *BattleSummaryMode) Name() string {
+	return "battlesummary"
+}
+
+func (b *BattleSummaryMode) Enter() {
+}
+
+// ShowSummary sets the summary and makes this the active mode
+func (b *BattleSummaryMode) ShowSummary(summary *squads.PostBattleSummary) {
+
+	b.Summary = summary
+	b.manager.Push(b.Name())
+
+}
+
+// Dismiss applies the loot choices and returns to the previous mode. Declined
+// drops stay on the map as lootable entities
+func (b *BattleSummaryMode) Dismiss() {
+
+	if b.Summary != nil {
+
+		left := b.Summary.ApplyLoot(b.ecsmanager, b.inventory)
+		for _, ent := range left {
+
+			if pos := common.GetPosition(ent); pos != nil && b.gameMap != nil {
+				b.gameMap.AddEntityToTile(ent, pos)
+			}
+
+		}
+
+	}
+
+	b.manager.Pop()
+}
+
+func (b *BattleSummaryMode) Update() {
+
+	//Enter and Escape both dismiss the screen
+	if inpututil.IsKeyJustPressed(ebiten.KeyEnter) || inpututil.IsKeyJustPressed(ebiten.KeyEscape) {
+		b.Dismiss()
+	}
+
+}
+
+func (b *BattleSummaryMode) Draw(screen *ebiten.Image) {
+
+	if b.Summary == nil {
+		return
+	}
+
+	//Todo replace with ebitenui widgets once the summary layout settles
+	ebitenutil.DebugPrintAt(screen, b.Summary.DisplayString(), 32, 32)
+
+}
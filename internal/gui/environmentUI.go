@@ -0,0 +1,16 @@
+package gui
+
+import (
+	"game_main/environment"
+)
+
+// EnvironmentHUDText is the strategic HUD line for the current conditions.
+// Plain text like the rest of the debug-print HUD until the widget rework
+func EnvironmentHUDText(cycle *environment.Cycle) string {
+
+	if cycle == nil {
+		return ""
+	}
+
+	return cycle.Describe()
+}
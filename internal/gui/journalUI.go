@@ -0,0 +1,152 @@
+package gui
+
+import (
+	"fmt"
+	"game_main/squads"
+	"strings"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+)
+
+// JournalMode shows the campaign journal as a scrollable timeline. Tab cycles
+// the event-type filter, up/down scroll, Escape returns to the previous mode
+
+// How many timeline lines fit on screen at once
+const journalPageSize = 20
+
+// The filter cycle Tab walks through. The empty string shows everything
+var journalTypeFilters = []string{
+	"",
+	squads.JournalBattle,
+	squads.JournalSquadCreated,
+	squads.JournalSquadDestroyed,
+}
+
+type JournalMode struct {
+	Journal *squads.Journal
+
+	manager    *UIModeManager
+	filterStep int
+	scroll     int
+}
+
+func NewJournalMode(manager *UIModeManager, journal *squads.Journal) *JournalMode {
+
+	return &JournalMode{
+		manager: manager,
+		Journal: journal,
+	}
+}
+
+func (j *JournalMode) Name() string {
+	return "journal"
+}
+
+// Opening the journal always starts at the most recent events, unfiltered
+func (j *JournalMode) Enter() {
+	j.filterStep = 0
+	j.scrollToEnd()
+}
+
+func (j *JournalMode) Filter() squads.JournalFilter {
+	return squads.JournalFilter{Type: journalTypeFilters[j.filterStep]}
+}
+
+func (j *JournalMode) visible() []squads.JournalEvent {
+
+	if j.Journal == nil {
+		return nil
+	}
+
+	return j.Journal.Filtered(j.Filter())
+}
+
+func (j *JournalMode) scrollToEnd() {
+
+	j.scroll = len(j.visible()) - journalPageSize
+	if j.scroll < 0 {
+		j.scroll = 0
+	}
+
+}
+
+// CycleFilter advances to the next event-type filter and rescrolls to the end
+func (j *JournalMode) CycleFilter() {
+
+	j.filterStep = (j.filterStep + 1) % len(journalTypeFilters)
+	j.scrollToEnd()
+
+}
+
+// Scroll moves the window by delta lines, clamped to the timeline
+func (j *JournalMode) Scroll(delta int) {
+
+	j.scroll += delta
+
+	if max := len(j.visible()) - journalPageSize; j.scroll > max {
+		j.scroll = max
+	}
+	if j.scroll < 0 {
+		j.scroll = 0
+	}
+
+}
+
+func (j *JournalMode) Update() {
+
+	if inpututil.IsKeyJustPressed(ebiten.KeyEscape) {
+		j.manager.Pop()
+		return
+	}
+
+	if inpututil.IsKeyJustPressed(ebiten.KeyTab) {
+		j.CycleFilter()
+	}
+
+	if inpututil.IsKeyJustPressed(ebiten.KeyUp) {
+		j.Scroll(-1)
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyDown) {
+		j.Scroll(1)
+	}
+
+}
+
+// DisplayString is the whole timeline page as text, header included.
+// Separate from Draw so tests can check it without a screen
+func (j *JournalMode) DisplayString() string {
+
+	var sb strings.Builder
+
+	filterName := journalTypeFilters[j.filterStep]
+	if filterName == "" {
+		filterName = "all"
+	}
+	fmt.Fprintf(&sb, "Campaign Journal [%s] - Tab filters, up/down scroll\n", filterName)
+
+	events := j.visible()
+	if len(events) == 0 {
+		sb.WriteString("  nothing recorded yet\n")
+		return sb.String()
+	}
+
+	end := j.scroll + journalPageSize
+	if end > len(events) {
+		end = len(events)
+	}
+
+	for _, event := range events[j.scroll:end] {
+		fmt.Fprintf(&sb, "  %s\n", event.DisplayString())
+	}
+
+	return sb.String()
+}
+
+func (j *JournalMode) Draw(screen *ebiten.Image) {
+
+	//Todo replace with ebitenui widgets once the timeline layout settles
+	ebitenutil.DebugPrintAt(screen, j.DisplayString(), 32, 32)
+
+}
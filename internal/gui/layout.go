@@ -0,0 +1,82 @@
+package gui
+
+import (
+	"game_main/graphics"
+	"image"
+)
+
+// The window is resizable but the HUD panels were placed once at startup
+// from the initial canvas size, so resizing left them stranded off screen or
+// piled in a corner. ComputeScreenLayout is the one place the responsive
+// panel rectangles come from: a resize recomputes them and re-applies the
+// positions to the existing widgets
+
+const (
+	//Below this the side column and the map cannot coexist no matter how
+	//the panels are arranged, so the layout clamps and lets ebiten scale
+	//the canvas instead
+	MinLayoutWidth  = 800
+	MinLayoutHeight = 600
+
+	//The side column never gets narrower than this or the stat text wraps
+	//unreadably
+	minSidePanelWidth = 240
+
+	//Height reserved for the inventory button row over the map
+	inventoryBarHeight = 48
+)
+
+// ScreenLayout is the set of panel rectangles for one screen size
+type ScreenLayout struct {
+	Width  int
+	Height int
+
+	//The map canvas fills everything left of the side column
+	Map image.Rectangle
+
+	//The side column: stats on top, the message log underneath
+	Stats    image.Rectangle
+	Messages image.Rectangle
+
+	//The inventory buttons sit over the top middle of the map
+	InventoryBar image.Rectangle
+}
+
+// ComputeScreenLayout lays the panels out for the given screen size. Sizes
+// below the minimums clamp, so a tiny window gets the smallest legal layout
+// rather than overlapping panels
+func ComputeScreenLayout(width int, height int) ScreenLayout {
+
+	if width < MinLayoutWidth {
+		width = MinLayoutWidth
+	}
+	if height < MinLayoutHeight {
+		height = MinLayoutHeight
+	}
+
+	//The side column keeps its startup width on big screens but gives
+	//ground to the map on small ones
+	sideWidth := graphics.StatsUIOffset
+	if sideWidth > width/3 {
+		sideWidth = width / 3
+	}
+	if sideWidth < minSidePanelWidth {
+		sideWidth = minSidePanelWidth
+	}
+
+	mapWidth := width - sideWidth
+	statsHeight := height / 4
+
+	//The message log starts one tile under the stats panel, as it always has
+	messagesTop := statsHeight + graphics.ScreenInfo.TileSize
+
+	return ScreenLayout{
+		Width:  width,
+		Height: height,
+
+		Map:          image.Rect(0, 0, mapWidth, height),
+		Stats:        image.Rect(mapWidth, 0, width, statsHeight),
+		Messages:     image.Rect(mapWidth, messagesTop, width, messagesTop+height/4),
+		InventoryBar: image.Rect(mapWidth/2, 0, mapWidth/2+mapWidth/4, inventoryBarHeight),
+	}
+}
@@ -0,0 +1,132 @@
+package gui
+
+import (
+	"image"
+	"testing"
+)
+
+// At every reasonable window size the panels stay on screen, never overlap
+// each other, and the side column starts where the map ends
+func TestLayoutPanelsStayOnScreen(t *testing.T) {
+
+	sizes := [][2]int{{800, 600}, {1024, 768}, {1366, 768}, {1920, 1080}, {2560, 1440}}
+
+	for _, size := range sizes {
+
+		layout := ComputeScreenLayout(size[0], size[1])
+		screen := image.Rect(0, 0, layout.Width, layout.Height)
+
+		panels := map[string]image.Rectangle{
+			"stats":        layout.Stats,
+			"messages":     layout.Messages,
+			"inventoryBar": layout.InventoryBar,
+		}
+
+		for name, panel := range panels {
+
+			if panel.Empty() {
+				t.Errorf("%dx%d: the %s panel collapsed to nothing", size[0], size[1], name)
+			}
+			if !panel.In(screen) {
+				t.Errorf("%dx%d: the %s panel %v leaves the screen", size[0], size[1], name, panel)
+			}
+
+		}
+
+		for name, panel := range panels {
+			for other, otherPanel := range panels {
+				if name < other && panel.Overlaps(otherPanel) {
+					t.Errorf("%dx%d: the %s and %s panels overlap: %v %v", size[0], size[1], name, other, panel, otherPanel)
+				}
+			}
+		}
+
+		if layout.Map.Max.X != layout.Stats.Min.X {
+			t.Errorf("%dx%d: the side column should start where the map ends, got map %v stats %v", size[0], size[1], layout.Map, layout.Stats)
+		}
+
+	}
+
+}
+
+// A window below the minimums gets the smallest legal layout instead of
+// panels squeezed on top of each other
+func TestLayoutClampsTinyWindows(t *testing.T) {
+
+	layout := ComputeScreenLayout(100, 80)
+
+	if layout.Width != MinLayoutWidth || layout.Height != MinLayoutHeight {
+		t.Fatalf("a tiny window should clamp to %dx%d, got %dx%d", MinLayoutWidth, MinLayoutHeight, layout.Width, layout.Height)
+	}
+	if layout.Stats.Dx() < minSidePanelWidth {
+		t.Errorf("the clamped side column is too narrow at %d", layout.Stats.Dx())
+	}
+	if layout.Stats.Overlaps(layout.Messages) {
+		t.Errorf("the clamped stats and message panels overlap: %v %v", layout.Stats, layout.Messages)
+	}
+
+}
+
+// A mode that records the sizes it was told about
+type resizableMode struct {
+	countingMode
+	sizes [][2]int
+}
+
+func (r *resizableMode) OnResize(width int, height int) {
+	r.sizes = append(r.sizes, [2]int{width, height})
+}
+
+// The active mode hears about a resize immediately; a background mode only
+// when it next becomes active, and never twice for the same size
+func TestResizeReachesModesLazily(t *testing.T) {
+
+	m := NewUIModeManager()
+	front := &resizableMode{countingMode: countingMode{name: "front"}}
+	back := &resizableMode{countingMode: countingMode{name: "back"}}
+	m.Register(front)
+	m.Register(back)
+
+	m.Push("front")
+	m.Resize(1024, 768)
+
+	if len(front.sizes) != 1 || front.sizes[0] != [2]int{1024, 768} {
+		t.Fatalf("the active mode should reflow immediately, got %v", front.sizes)
+	}
+	if len(back.sizes) != 0 {
+		t.Fatalf("a background mode should not reflow, got %v", back.sizes)
+	}
+
+	m.Resize(1024, 768)
+	if len(front.sizes) != 1 {
+		t.Errorf("a resize to the same size should not reflow again, got %v", front.sizes)
+	}
+
+	m.Push("back")
+	if len(back.sizes) != 1 || back.sizes[0] != [2]int{1024, 768} {
+		t.Fatalf("a mode should catch up on entry, got %v", back.sizes)
+	}
+
+	m.Resize(800, 600)
+	if len(front.sizes) != 1 {
+		t.Errorf("the mode underneath should sleep through the resize, got %v", front.sizes)
+	}
+
+	m.Pop()
+	if len(front.sizes) != 2 || front.sizes[1] != [2]int{800, 600} {
+		t.Errorf("popping back should reflow the stale mode once, got %v", front.sizes)
+	}
+
+}
+
+// Modes that do not care about sizes keep working untouched
+func TestResizeIgnoresUnawareModes(t *testing.T) {
+
+	m := NewUIModeManager()
+	m.Register(&countingMode{name: "plain"})
+	m.Push("plain")
+
+	m.Resize(1024, 768)
+	m.Resize(1920, 1080)
+
+}
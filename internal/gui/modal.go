@@ -0,0 +1,241 @@
+package gui
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"game_main/audio"
+	"game_main/common"
+	"game_main/squads"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+)
+
+// Confirmation modals for destructive actions: disbanding a squad, deleting
+// a save, abandoning a battle. Dialogs stack on top of whatever mode is
+// active, swallow all input beneath them, and answer to Enter and Escape.
+// A dialog with a preference key offers a dont-ask-again checkbox whose
+// choice persists with the settings
+
+type ConfirmDialog struct {
+	Title   string
+	Message string
+
+	//Key under which dont-ask-again persists. Empty means always ask
+	PreferenceKey string
+
+	//The checkbox state, toggled with D while the dialog is up
+	DontAskAgain bool
+
+	OnConfirm func()
+	OnCancel  func()
+}
+
+// DisplayString is the whole dialog as text, keyboard hints included
+func (d *ConfirmDialog) DisplayString() string {
+
+	text := fmt.Sprintf("%s\n\n%s\n\n[Enter] confirm    [Esc] cancel", d.Title, d.Message)
+
+	if d.PreferenceKey != "" {
+
+		state := "off"
+		if d.DontAskAgain {
+			state = "on"
+		}
+		text += fmt.Sprintf("\n[D] don't ask again: %s", state)
+
+	}
+
+	return text
+}
+
+// DialogPreferences remembers which confirmations the player muted through
+// the dont-ask-again checkbox
+type DialogPreferences struct {
+	SkipConfirm map[string]bool `json:"skipConfirm"`
+}
+
+func NewDialogPreferences() *DialogPreferences {
+	return &DialogPreferences{SkipConfirm: make(map[string]bool)}
+}
+
+func (p *DialogPreferences) Skip(key string) bool {
+	return key != "" && p.SkipConfirm[key]
+}
+
+func (p *DialogPreferences) Mute(key string) {
+
+	if key != "" {
+		p.SkipConfirm[key] = true
+	}
+
+}
+
+// LoadDialogPreferences reads the preferences file. A missing file is not an
+// error, it just means nothing was muted yet
+func LoadDialogPreferences(path string) (*DialogPreferences, error) {
+
+	contents, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return NewDialogPreferences(), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	prefs := NewDialogPreferences()
+	if err := json.Unmarshal(contents, prefs); err != nil {
+		return nil, fmt.Errorf("dialog preferences %s did not parse: %w", path, err)
+	}
+
+	return prefs, nil
+}
+
+func (p *DialogPreferences) Save(path string) error {
+
+	contents, err := json.MarshalIndent(p, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(path, append(contents, '\n'), 0644)
+}
+
+// ShowDialog layers a confirmation over the active mode. A confirmation the
+// player muted earlier resolves immediately without appearing
+func (m *UIModeManager) ShowDialog(dialog *ConfirmDialog) {
+
+	if m.DialogPrefs != nil && m.DialogPrefs.Skip(dialog.PreferenceKey) {
+
+		if dialog.OnConfirm != nil {
+			dialog.OnConfirm()
+		}
+		return
+
+	}
+
+	if m.Timer != nil {
+		m.Timer.Pause()
+	}
+
+	m.dialogs = append(m.dialogs, dialog)
+}
+
+// ActiveDialog is the dialog currently swallowing input, nil when none is up
+func (m *UIModeManager) ActiveDialog() *ConfirmDialog {
+
+	if len(m.dialogs) == 0 {
+		return nil
+	}
+
+	return m.dialogs[len(m.dialogs)-1]
+}
+
+// ConfirmActiveDialog resolves the top dialog as confirmed, recording the
+// dont-ask-again choice when the player ticked it
+func (m *UIModeManager) ConfirmActiveDialog() {
+
+	dialog := m.ActiveDialog()
+	if dialog == nil {
+		return
+	}
+
+	if dialog.DontAskAgain && m.DialogPrefs != nil {
+		m.DialogPrefs.Mute(dialog.PreferenceKey)
+	}
+
+	m.closeTopDialog()
+
+	if dialog.OnConfirm != nil {
+		dialog.OnConfirm()
+	}
+
+}
+
+// CancelActiveDialog resolves the top dialog as cancelled
+func (m *UIModeManager) CancelActiveDialog() {
+
+	dialog := m.ActiveDialog()
+	if dialog == nil {
+		return
+	}
+
+	m.closeTopDialog()
+
+	if dialog.OnCancel != nil {
+		dialog.OnCancel()
+	}
+
+}
+
+// Mode transitions requested while a dialog was up run once the last dialog
+// resolves
+func (m *UIModeManager) closeTopDialog() {
+
+	m.dialogs = m.dialogs[:len(m.dialogs)-1]
+
+	if m.Timer != nil {
+		m.Timer.Resume()
+	}
+
+	if len(m.dialogs) > 0 {
+		return
+	}
+
+	pending := m.pendingPush
+	m.pendingPush = nil
+	for _, name := range pending {
+		m.Push(name)
+	}
+
+}
+
+func (m *UIModeManager) updateActiveDialog() {
+
+	dialog := m.ActiveDialog()
+
+	if dialog.PreferenceKey != "" && inpututil.IsKeyJustPressed(ebiten.KeyD) {
+		dialog.DontAskAgain = !dialog.DontAskAgain
+	}
+
+	if inpututil.IsKeyJustPressed(ebiten.KeyEnter) {
+		m.playSFX(audio.SFXButtonClick)
+		m.ConfirmActiveDialog()
+	} else if inpututil.IsKeyJustPressed(ebiten.KeyEscape) {
+		m.playSFX(audio.SFXButtonClick)
+		m.CancelActiveDialog()
+	}
+
+}
+
+func (m *UIModeManager) drawActiveDialog(screen *ebiten.Image) {
+
+	if dialog := m.ActiveDialog(); dialog != nil {
+		//Todo replace with ebitenui widgets alongside the summary screens
+		ebitenutil.DebugPrintAt(screen, dialog.DisplayString(), 96, 96)
+	}
+
+}
+
+// ConfirmDisbandSquad routes disbanding through a confirmation. Nothing
+// happens to the squad until the player confirms
+func ConfirmDisbandSquad(m *UIModeManager, em *common.EntityManager, stacks *squads.SquadStacks, squadID int) {
+
+	squad := squads.GetSquadByID(em, squadID)
+	if squad == nil {
+		return
+	}
+
+	m.ShowDialog(&ConfirmDialog{
+		Title:         fmt.Sprintf("Disband %s?", squad.Name),
+		Message:       "Its units are released and cannot be recovered.",
+		PreferenceKey: "disband-squad",
+		OnConfirm: func() {
+			squads.ProcessSquadDestruction(em, stacks, squadID)
+		},
+	})
+
+}
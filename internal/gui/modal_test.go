@@ -0,0 +1,218 @@
+package gui
+
+import (
+	"path/filepath"
+	"testing"
+
+	"game_main/common"
+	"game_main/squads"
+
+	"github.com/bytearena/ecs"
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// A mode that just counts how often it gets updated, to prove dialogs
+// swallow the frames underneath them
+type countingMode struct {
+	name    string
+	updates int
+}
+
+func (c *countingMode) Name() string              { return c.name }
+func (c *countingMode) Enter()                    {}
+func (c *countingMode) Update()                   { c.updates++ }
+func (c *countingMode) Draw(screen *ebiten.Image) {}
+
+func TestDialogStackPushesAndPops(t *testing.T) {
+
+	m := NewUIModeManager()
+
+	first := &ConfirmDialog{Title: "first"}
+	second := &ConfirmDialog{Title: "second"}
+	m.ShowDialog(first)
+	m.ShowDialog(second)
+
+	if m.ActiveDialog() != second {
+		t.Fatal("the newest dialog should be on top")
+	}
+
+	m.CancelActiveDialog()
+	if m.ActiveDialog() != first {
+		t.Fatal("cancelling should return to the dialog underneath")
+	}
+
+	m.ConfirmActiveDialog()
+	if m.ActiveDialog() != nil {
+		t.Fatal("resolving the last dialog should empty the stack")
+	}
+}
+
+func TestOpenDialogSwallowsModeUpdates(t *testing.T) {
+
+	m := NewUIModeManager()
+	mode := &countingMode{name: "battlefield"}
+	m.Register(mode)
+	m.Push("battlefield")
+
+	m.ShowDialog(&ConfirmDialog{Title: "sure?"})
+
+	m.Update()
+	if mode.updates != 0 {
+		t.Errorf("the mode under a dialog should not update, got %d updates", mode.updates)
+	}
+
+	m.ConfirmActiveDialog()
+	m.Update()
+	if mode.updates != 1 {
+		t.Errorf("the mode should update again once the dialog resolved, got %d updates", mode.updates)
+	}
+}
+
+// A push requested while a dialog is up waits until the dialog resolves
+func TestPendingTransitionWaitsForTheDialog(t *testing.T) {
+
+	m := NewUIModeManager()
+	base := &countingMode{name: "base"}
+	next := &countingMode{name: "next"}
+	m.Register(base)
+	m.Register(next)
+	m.Push("base")
+
+	m.ShowDialog(&ConfirmDialog{Title: "hold on"})
+	if err := m.Push("next"); err != nil {
+		t.Fatalf("queueing a push behind a dialog failed: %v", err)
+	}
+
+	if m.Active() != base {
+		t.Fatal("the transition should wait while the dialog is up")
+	}
+
+	m.CancelActiveDialog()
+	if m.Active() != next {
+		t.Fatal("the queued transition should apply once the dialog resolved")
+	}
+}
+
+// Ticking dont-ask-again persists through a save/load round trip and mutes
+// the same confirmation afterwards
+func TestDontAskAgainPersists(t *testing.T) {
+
+	path := filepath.Join(t.TempDir(), "dialogs.json")
+
+	m := NewUIModeManager()
+	m.DialogPrefs = NewDialogPreferences()
+
+	confirmed := 0
+	dialog := &ConfirmDialog{
+		Title:         "Delete save?",
+		PreferenceKey: "delete-save",
+		DontAskAgain:  true,
+		OnConfirm:     func() { confirmed++ },
+	}
+	m.ShowDialog(dialog)
+	m.ConfirmActiveDialog()
+
+	if err := m.DialogPrefs.Save(path); err != nil {
+		t.Fatalf("saving the preferences failed: %v", err)
+	}
+
+	prefs, err := LoadDialogPreferences(path)
+	if err != nil {
+		t.Fatalf("loading the preferences failed: %v", err)
+	}
+
+	reloaded := NewUIModeManager()
+	reloaded.DialogPrefs = prefs
+	reloaded.ShowDialog(&ConfirmDialog{
+		Title:         "Delete save?",
+		PreferenceKey: "delete-save",
+		OnConfirm:     func() { confirmed++ },
+	})
+
+	if reloaded.ActiveDialog() != nil {
+		t.Fatal("a muted confirmation should not appear")
+	}
+	if confirmed != 2 {
+		t.Errorf("the muted dialog should confirm immediately, got %d confirmations", confirmed)
+	}
+}
+
+// Dialogs hold the turn clock while they are up, and nested dialogs release
+// it only once the last one resolves
+func TestDialogsHoldTheTurnClock(t *testing.T) {
+
+	timer := squads.NewTurnTimer(squads.TurnTimerConfig{Enabled: true, Seconds: 30})
+	timer.StartFactionTurn(false)
+
+	m := NewUIModeManager()
+	m.Timer = timer
+
+	m.ShowDialog(&ConfirmDialog{Title: "outer"})
+	m.ShowDialog(&ConfirmDialog{Title: "inner"})
+
+	if timer.Update(10); timer.Remaining() != 30 {
+		t.Fatalf("the clock should hold while dialogs are up, got %f", timer.Remaining())
+	}
+
+	m.CancelActiveDialog()
+	if timer.Update(10); timer.Remaining() != 30 {
+		t.Fatalf("one open dialog should still hold the clock, got %f", timer.Remaining())
+	}
+
+	m.CancelActiveDialog()
+	if timer.Update(10); timer.Remaining() != 20 {
+		t.Errorf("the clock should run once the last dialog resolved, got %f", timer.Remaining())
+	}
+
+}
+
+func newModalTestManager() *common.EntityManager {
+
+	manager := ecs.NewManager()
+	tags := make(map[string]ecs.Tag)
+
+	common.PositionComponent = manager.NewComponent()
+	common.NameComponent = manager.NewComponent()
+	common.AttributeComponent = manager.NewComponent()
+
+	squads.InitializeSquadComponents(manager, tags)
+
+	return &common.EntityManager{World: manager, WorldTags: tags}
+}
+
+// Cancelling a disband leaves the squad exactly as it was; confirming
+// destroys it
+func TestCancelledDisbandLeavesTheSquadAlone(t *testing.T) {
+
+	em := newModalTestManager()
+
+	squads.Units["ModalKnight"] = squads.UnitTemplate{
+		Name:      "ModalKnight",
+		Class:     "Knight",
+		Attr:      common.NewBaseAttributes(20, 0, 10, 0, 1, 0),
+		MinDamage: 4,
+		MaxDamage: 4,
+	}
+
+	squad := squads.CreateSquad(em, "Doomed", "Player", common.Position{X: 2, Y: 2}, squads.DirDown)
+	squads.CreateUnitFromTemplate(em, squads.Units["ModalKnight"], squad.SquadID, 0, 0)
+
+	m := NewUIModeManager()
+
+	ConfirmDisbandSquad(m, em, nil, squad.SquadID)
+	m.CancelActiveDialog()
+
+	if squads.GetSquadByID(em, squad.SquadID) == nil {
+		t.Fatal("a cancelled disband must leave the squad in place")
+	}
+	if len(squads.SquadUnits(em, squad.SquadID)) != 1 {
+		t.Fatal("a cancelled disband must leave the units in place")
+	}
+
+	ConfirmDisbandSquad(m, em, nil, squad.SquadID)
+	m.ConfirmActiveDialog()
+
+	if squads.GetSquadByID(em, squad.SquadID) != nil {
+		t.Fatal("a confirmed disband should destroy the squad")
+	}
+}
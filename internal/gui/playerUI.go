@@ -18,6 +18,9 @@ type PlayerUI struct {
 	MsgUI               PlayerMessageUI
 	InformationUI       InfoUI
 	MainPlayerInterface *ebitenui.UI
+
+	//Held so OnResize can re-place the button row without rebuilding it
+	inventoryBarContainer *widget.Container
 }
 
 // Throwing an item will show a square to represent the AOE of the throwable.
@@ -77,6 +80,7 @@ func CreatePlayerUI(playerUI *PlayerUI, inv *gear.Inventory, pl *avatar.PlayerDa
 
 	SetContainerLocation(playerUI.MsgUI.msgUIContainer, graphics.ScreenInfo.GetCanvasWidth(), graphics.ScreenInfo.GetCanvasHeight()/4+graphics.ScreenInfo.TileSize) //Placing it one tile under the Stats Container
 	playerUI.InformationUI = CreateInfoUI(ecsmanager, &ui)
+	playerUI.inventoryBarContainer = itemDisplayOptionsContainer
 
 	ui.Container = rootContainer
 
@@ -84,6 +88,19 @@ func CreatePlayerUI(playerUI *PlayerUI, inv *gear.Inventory, pl *avatar.PlayerDa
 
 }
 
+// OnResize recomputes the responsive panel rectangles for the new screen
+// size and re-applies the positions to the existing containers, so the HUD
+// tracks the window instead of keeping its startup placement
+func (p *PlayerUI) OnResize(width int, height int) {
+
+	layout := ComputeScreenLayout(width, height)
+
+	SetContainerLocation(p.inventoryBarContainer, layout.InventoryBar.Min.X, layout.InventoryBar.Min.Y)
+	SetContainerLocation(p.StatsUI.StatUIContainer, layout.Stats.Min.X, layout.Stats.Min.Y)
+	SetContainerLocation(p.MsgUI.msgUIContainer, layout.Messages.Min.X, layout.Messages.Min.Y)
+
+}
+
 // Creating the button that opens the crafting menu. Other buttons will be added
 // Doing it inside a function makes the code easier to follow
 func CreateOpenThrowablesButton(playerUI *PlayerUI, inv *gear.Inventory, ui *ebitenui.UI) *widget.Button {
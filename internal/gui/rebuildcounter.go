@@ -0,0 +1,47 @@
+package gui
+
+import (
+	"fmt"
+	"time"
+)
+
+// RebuildCounter counts how often a panel rebuilt its widgets, for the debug
+// overlay. A panel that polls a change subscription should rebuild a handful
+// of times per minute; a number near the frame rate means something still
+// rebuilds every frame
+type RebuildCounter struct {
+	Count int
+
+	start time.Time
+	now   func() time.Time
+}
+
+func (c *RebuildCounter) Note() {
+
+	if c.now == nil {
+		c.now = time.Now
+	}
+	if c.start.IsZero() {
+		c.start = c.now()
+	}
+
+	c.Count++
+}
+
+func (c *RebuildCounter) PerMinute() float64 {
+
+	if c.Count == 0 {
+		return 0
+	}
+
+	elapsed := c.now().Sub(c.start)
+	if elapsed < time.Second {
+		elapsed = time.Second
+	}
+
+	return float64(c.Count) / elapsed.Minutes()
+}
+
+func (c *RebuildCounter) DebugString() string {
+	return fmt.Sprintf("%d rebuilds (%.1f/min)", c.Count, c.PerMinute())
+}
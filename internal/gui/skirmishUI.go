@@ -0,0 +1,135 @@
+package gui
+
+import (
+	"fmt"
+	"strings"
+
+	"game_main/audio"
+	"game_main/simulation"
+	"game_main/squads"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+)
+
+// SkirmishMode is the one-off battle picker on the main menu: choose a
+// scenario from the simulator suites, launch it in the real combat systems
+// without a campaign around it, and read the result. Everything runs in a
+// throwaway skirmish session, so backing out leaves no trace
+
+type SkirmishMode struct {
+	manager *UIModeManager
+	sim     *simulation.Simulator
+
+	//The scenario suite the picker lists. Loaded once by the caller, so the
+	//mode itself never touches the filesystem
+	file simulation.ScenarioFile
+
+	cursor int
+
+	//The narrative of the last battle, shown until the next launch or exit
+	result string
+}
+
+func NewSkirmishMode(manager *UIModeManager, sim *simulation.Simulator, file simulation.ScenarioFile) *SkirmishMode {
+
+	return &SkirmishMode{
+		manager: manager,
+		sim:     sim,
+		file:    file,
+	}
+}
+
+func (s *SkirmishMode) Name() string {
+	return "skirmish"
+}
+
+func (s *SkirmishMode) Enter() {
+	s.result = ""
+}
+
+// Launch fights the scenario under the cursor in a skirmish session and
+// keeps the narrative for the result screen. The session is torn down before
+// this returns, so a skirmish never leaks state whatever the outcome
+func (s *SkirmishMode) Launch() error {
+
+	if s.cursor >= len(s.file.Scenarios) {
+		return fmt.Errorf("no scenario selected")
+	}
+
+	skirmish, err := simulation.SetupSkirmish(simulation.SkirmishConfig{
+		Scenario:  s.file.Scenarios[s.cursor],
+		Templates: s.file.Templates,
+	})
+	if err != nil {
+		return err
+	}
+	defer skirmish.Teardown()
+
+	_, record := skirmish.Resolve(s.sim)
+	s.result = squads.ExplainOutcome(record)
+
+	return nil
+}
+
+func (s *SkirmishMode) Update() {
+
+	if inpututil.IsKeyJustPressed(ebiten.KeyEscape) {
+		s.manager.Pop()
+		return
+	}
+
+	if inpututil.IsKeyJustPressed(ebiten.KeyArrowUp) && s.cursor > 0 {
+		s.cursor--
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyArrowDown) && s.cursor < len(s.file.Scenarios)-1 {
+		s.cursor++
+	}
+
+	if inpututil.IsKeyJustPressed(ebiten.KeyEnter) {
+
+		if err := s.Launch(); err != nil {
+			s.result = err.Error()
+		}
+		s.playClick()
+
+	}
+
+}
+
+func (s *SkirmishMode) playClick() {
+
+	if s.manager != nil {
+		s.manager.playSFX(audio.SFXButtonClick)
+	}
+
+}
+
+// DisplayString renders the picker and the last result as text, separate
+// from Draw so tests can read the screen
+func (s *SkirmishMode) DisplayString() string {
+
+	var b strings.Builder
+
+	b.WriteString("SKIRMISH - Enter fights, Escape leaves\n")
+	for i, scenario := range s.file.Scenarios {
+
+		marker := "  "
+		if i == s.cursor {
+			marker = "> "
+		}
+		fmt.Fprintf(&b, "%s%s\n", marker, scenario.Name)
+
+	}
+
+	if s.result != "" {
+		fmt.Fprintf(&b, "\n%s\n", s.result)
+	}
+
+	return b.String()
+}
+
+func (s *SkirmishMode) Draw(screen *ebiten.Image) {
+	ebitenutil.DebugPrintAt(screen, s.DisplayString(), 32, 32)
+}
@@ -0,0 +1,61 @@
+package gui
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"game_main/audio"
+)
+
+// SoundSettings is the players volume choices, persisted next to the dialog
+// preferences. Apply pushes them into whatever player is wired up
+
+type SoundSettings struct {
+	SFXVolume   float64 `json:"sfxVolume"`
+	MusicVolume float64 `json:"musicVolume"`
+}
+
+func NewSoundSettings() *SoundSettings {
+	return &SoundSettings{SFXVolume: 1, MusicVolume: 1}
+}
+
+func (s *SoundSettings) Apply(player audio.SoundPlayer) {
+
+	if player == nil {
+		return
+	}
+
+	player.SetVolume(audio.ChannelSFX, s.SFXVolume)
+	player.SetVolume(audio.ChannelMusic, s.MusicVolume)
+}
+
+// LoadSoundSettings reads the settings file. A missing file just means the
+// player never changed the defaults
+func LoadSoundSettings(path string) (*SoundSettings, error) {
+
+	contents, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return NewSoundSettings(), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	settings := NewSoundSettings()
+	if err := json.Unmarshal(contents, settings); err != nil {
+		return nil, fmt.Errorf("sound settings %s did not parse: %w", path, err)
+	}
+
+	return settings, nil
+}
+
+func (s *SoundSettings) Save(path string) error {
+
+	contents, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(path, append(contents, '\n'), 0644)
+}
@@ -0,0 +1,208 @@
+package gui
+
+import (
+	"fmt"
+	"game_main/common"
+	"game_main/simulation"
+	"game_main/squads"
+	"strings"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+)
+
+// SquadEditorMode is where a player tunes a squads grid layout. Right now it
+// carries the "Test Squad" panel: pick an opponent squad and fight the
+// matchup a few hundred times in the background, without ever touching the
+// real squads. The run happens on cloned entities in a goroutine, so the
+// editor stays responsive and the progress indicator just polls
+
+type SquadEditorMode struct {
+	ecsmanager *common.EntityManager
+	manager    *UIModeManager
+	simulator  *simulation.Simulator
+
+	SquadID    int
+	OpponentID int
+	Iterations int
+
+	testRun *simulation.SquadTestRun
+
+	//The header only rebuilds when the squads subscription fires, not every
+	//frame. Rebuilds feeds the debug overlay
+	squadChanges *common.ChangeSubscription
+	header       string
+	Rebuilds     RebuildCounter
+}
+
+func NewSquadEditorMode(manager *UIModeManager, ecsmanager *common.EntityManager) *SquadEditorMode {
+
+	return &SquadEditorMode{
+		manager:    manager,
+		ecsmanager: ecsmanager,
+		simulator:  simulation.NewSimulator(),
+		Iterations: simulation.DefaultTestIterations,
+	}
+}
+
+func (s *SquadEditorMode) Name() string {
+	return "squadeditor"
+}
+
+func (s *SquadEditorMode) Enter() {
+
+	if s.squadChanges == nil {
+		s.squadChanges = s.ecsmanager.Changes().Subscribe(common.SquadsChanged)
+	}
+	s.rebuildHeader()
+}
+
+// EditSquad opens the editor on a squad and makes this the active mode
+func (s *SquadEditorMode) EditSquad(squadID int) {
+
+	s.SquadID = squadID
+	s.testRun = nil
+	s.manager.Push(s.Name())
+
+}
+
+func (s *SquadEditorMode) Update() {
+
+	if s.squadChanges != nil && s.squadChanges.Fired() {
+		s.rebuildHeader()
+	}
+
+	if inpututil.IsKeyJustPressed(ebiten.KeyEscape) {
+
+		if s.testRun != nil {
+			s.testRun.Cancel()
+		}
+		s.manager.Pop()
+		return
+
+	}
+
+	state := simulation.TestRunIdle
+	if s.testRun != nil {
+		state, _, _ = s.testRun.Status()
+	}
+
+	//T starts a test run, C cancels the one in flight
+	if inpututil.IsKeyJustPressed(ebiten.KeyT) && state != simulation.TestRunRunning {
+		s.testRun = s.simulator.StartSquadTest(s.ecsmanager, s.SquadID, s.OpponentID, s.Iterations)
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyC) && state == simulation.TestRunRunning {
+		s.testRun.Cancel()
+	}
+
+}
+
+// The header is the part worth caching: it walks the squad. It only
+// rebuilds when the squads subscription fires
+func (s *SquadEditorMode) rebuildHeader() {
+
+	s.Rebuilds.Note()
+
+	name := fmt.Sprintf("squad %d", s.SquadID)
+	warning := ""
+	if squad := squads.GetSquadByID(s.ecsmanager, s.SquadID); squad != nil {
+
+		name = squad.Name
+		if squad.Leaderless {
+			warning = "LEADERLESS - no units can join until a leader is chosen\n"
+		}
+
+	}
+
+	s.header = fmt.Sprintf("Editing %s\n%s%s\n", name, warning, s.gridPreview())
+}
+
+// The footprint preview: one character per grid cell, a units initial on its
+// anchor and a + on the rest of its footprint, so L-shapes and other odd
+// shapes read at a glance
+func (s *SquadEditorMode) gridPreview() string {
+
+	var sb strings.Builder
+
+	gridW, gridH := squads.SquadGridSize(s.ecsmanager, s.SquadID)
+	for row := 0; row < gridH; row++ {
+
+		for col := 0; col < gridW; col++ {
+
+			cell := "."
+			if unit := squads.UnitOccupyingCell(s.ecsmanager, s.SquadID, row, col); unit != nil {
+
+				data := squads.GetUnitData(unit)
+				if data.Row == row && data.Col == col {
+
+					//Wounded units show lowercase so attrition is visible
+					//in the preview
+					cell = strings.ToUpper(data.TemplateName[:1])
+					if squads.IsWounded(unit) {
+						cell = strings.ToLower(cell)
+					}
+
+				} else {
+					cell = "+"
+				}
+
+			}
+			sb.WriteString(cell)
+
+		}
+		sb.WriteString("\n")
+
+	}
+
+	return sb.String()
+}
+
+func (s *SquadEditorMode) Draw(screen *ebiten.Image) {
+
+	var sb strings.Builder
+
+	sb.WriteString(s.header)
+	sb.WriteString(s.testPanelText())
+
+	//Todo replace with ebitenui widgets along with the other mode screens
+	ebitenutil.DebugPrintAt(screen, sb.String(), 32, 32)
+
+}
+
+// The test panel: instructions when idle, a progress bar while running, and
+// the compact result card afterwards
+func (s *SquadEditorMode) testPanelText() string {
+
+	if s.testRun == nil {
+		return "T to test this squad against the selected opponent"
+	}
+
+	state, completed, total := s.testRun.Status()
+
+	switch state {
+	case simulation.TestRunRunning:
+		return fmt.Sprintf("Testing... %d/%d (C to cancel)", completed, total)
+	case simulation.TestRunCancelled:
+		return "Test cancelled. T to run again"
+	}
+
+	result := s.testRun.Result()
+	if result == nil {
+		return ""
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "Win rate %.0f%% (%.0f%% - %.0f%% over %d battles)\n",
+		result.WinRate*100, result.WinRateLow*100, result.WinRateHigh*100, result.Iterations)
+	fmt.Fprintf(&sb, "Avg rounds %.1f\n", result.AvgRounds)
+
+	if len(result.FirstDeaths) > 0 {
+		fmt.Fprintf(&sb, "Dies first: %s\n", strings.Join(result.FirstDeaths, ", "))
+	}
+	if result.Bursty {
+		sb.WriteString("Burst matchup: the opening volleys decide it\n")
+	}
+
+	return sb.String()
+}
@@ -0,0 +1,151 @@
+package gui
+
+import (
+	"fmt"
+	"game_main/common"
+	"game_main/squads"
+	"strings"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+)
+
+// StackInspectorMode is the popup that opens when the player inspects a tile
+// holding more than one squad. It lists the stack top first, and lets the
+// player promote a squad to the top or split one off onto a free
+// neighbouring tile
+
+type StackInspectorMode struct {
+	ecsmanager *common.EntityManager
+	manager    *UIModeManager
+
+	stacks   *squads.SquadStacks
+	tile     common.Position
+	selected int
+}
+
+func NewStackInspectorMode(manager *UIModeManager, ecsmanager *common.EntityManager) *StackInspectorMode {
+
+	return &StackInspectorMode{
+		manager:    manager,
+		ecsmanager: ecsmanager,
+	}
+}
+
+func (s *StackInspectorMode) Name() string {
+	return "stackinspector"
+}
+
+func (s *StackInspectorMode) Enter() {
+}
+
+// InspectTile opens the inspector on a tile and makes this the active mode
+func (s *StackInspectorMode) InspectTile(stacks *squads.SquadStacks, tile common.Position) {
+
+	s.stacks = stacks
+	s.tile = tile
+	s.selected = 0
+	s.manager.Push(s.Name())
+
+}
+
+// The stack as the inspector shows it: top of the stack first
+func (s *StackInspectorMode) displayOrder() []int {
+
+	stack := s.stacks.StackAt(s.tile)
+	for i, j := 0, len(stack)-1; i < j; i, j = i+1, j-1 {
+		stack[i], stack[j] = stack[j], stack[i]
+	}
+
+	return stack
+}
+
+func (s *StackInspectorMode) Update() {
+
+	if inpututil.IsKeyJustPressed(ebiten.KeyEscape) {
+		s.manager.Pop()
+		return
+	}
+
+	stack := s.displayOrder()
+	if len(stack) == 0 {
+		s.manager.Pop()
+		return
+	}
+
+	if inpututil.IsKeyJustPressed(ebiten.KeyArrowDown) && s.selected < len(stack)-1 {
+		s.selected++
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyArrowUp) && s.selected > 0 {
+		s.selected--
+	}
+
+	//R promotes the selected squad to the top of the stack
+	if inpututil.IsKeyJustPressed(ebiten.KeyR) {
+		s.stacks.Promote(s.tile, stack[s.selected])
+		s.selected = 0
+	}
+
+	//S splits the selected squad off onto the first free neighbouring tile
+	if inpututil.IsKeyJustPressed(ebiten.KeyS) {
+		s.splitOff(stack[s.selected])
+	}
+
+}
+
+func (s *StackInspectorMode) splitOff(squadID int) {
+
+	neighbors := []common.Position{
+		{X: s.tile.X, Y: s.tile.Y - 1},
+		{X: s.tile.X, Y: s.tile.Y + 1},
+		{X: s.tile.X - 1, Y: s.tile.Y},
+		{X: s.tile.X + 1, Y: s.tile.Y},
+	}
+
+	for _, dest := range neighbors {
+
+		if err := s.stacks.MoveSquad(s.ecsmanager, squadID, dest); err == nil {
+
+			if s.selected > 0 {
+				s.selected--
+			}
+			return
+
+		}
+
+	}
+
+}
+
+func (s *StackInspectorMode) Draw(screen *ebiten.Image) {
+
+	if s.stacks == nil {
+		return
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "Squads on tile (%d,%d):\n", s.tile.X, s.tile.Y)
+
+	for i, squadID := range s.displayOrder() {
+
+		marker := "  "
+		if i == s.selected {
+			marker = "> "
+		}
+
+		name := fmt.Sprintf("squad %d", squadID)
+		if squad := squads.GetSquadByID(s.ecsmanager, squadID); squad != nil {
+			name = fmt.Sprintf("%s (%s)", squad.Name, squad.Faction)
+		}
+
+		fmt.Fprintf(&sb, "%s%d. %s\n", marker, i+1, name)
+
+	}
+
+	sb.WriteString("\nR promote to top, S split off, Esc close")
+
+	//Todo replace with ebitenui widgets along with the battle summary screen
+	ebitenutil.DebugPrintAt(screen, sb.String(), 32, 32)
+
+}
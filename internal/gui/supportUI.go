@@ -0,0 +1,47 @@
+package gui
+
+import (
+	"fmt"
+	"game_main/common"
+	"game_main/squads"
+	"strings"
+)
+
+// Text helpers for support abilities. The combat modes draw with debug text
+// for now, so these produce the strings; once the modes move to ebitenui
+// widgets these become the label sources
+
+// SupportActionText lists the castable support abilities in a squad, one per
+// line, with cooldown state. Empty when the squad has none
+func SupportActionText(em *common.EntityManager, squadID int) string {
+
+	var sb strings.Builder
+	for _, u := range squads.SquadUnits(em, squadID) {
+
+		ability := squads.GetSupportAbilityData(u)
+		if ability == nil {
+			continue
+		}
+
+		if ability.CooldownLeft > 0 {
+			fmt.Fprintf(&sb, "%s (cooldown %d)\n", ability.AbilityID, ability.CooldownLeft)
+		} else {
+			fmt.Fprintf(&sb, "%s: +%d %s for %d rounds\n",
+				ability.AbilityID, ability.Amount, ability.Effect, ability.DurationRounds)
+		}
+
+	}
+
+	return sb.String()
+}
+
+// SquadBuffBadge is the short marker the turn-order timeline appends to a
+// squads name while it has active buffs
+func SquadBuffBadge(em *common.EntityManager, squadID int) string {
+
+	if squads.SquadHasActiveBuffs(em, squadID) {
+		return " [buffed]"
+	}
+
+	return ""
+}
@@ -0,0 +1,180 @@
+package gui
+
+import (
+	"fmt"
+	"game_main/common"
+	"game_main/squads"
+	"strings"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+)
+
+// AttackTargetingMode is the combat modes targeting flow. Entering it
+// collects every enemy squad, runs each through CheckAttackEligibility, and
+// lets the player Tab through the valid ones closest first. Enter confirms,
+// Escape cancels, and selecting an invalid target shows the concrete reason
+// instead of silently doing nothing
+
+type AttackTargetingMode struct {
+	ecsmanager *common.EntityManager
+	manager    *UIModeManager
+
+	AttackerSquadID int
+	AttackRange     int
+
+	//Called with the confirmed target squad. The combat mode supplies it
+	OnConfirm func(targetSquadID int)
+
+	candidates []int
+	selected   int
+}
+
+func NewAttackTargetingMode(manager *UIModeManager, ecsmanager *common.EntityManager) *AttackTargetingMode {
+
+	return &AttackTargetingMode{
+		manager:    manager,
+		ecsmanager: ecsmanager,
+	}
+}
+
+func (a *AttackTargetingMode) Name() string {
+	return "attacktargeting"
+}
+
+func (a *AttackTargetingMode) Enter() {
+}
+
+// BeginTargeting opens the flow for an attacker and makes this the active
+// mode. Candidates are every squad of another faction, cycle-ordered
+func (a *AttackTargetingMode) BeginTargeting(attackerSquadID int, attackRange int) {
+
+	a.AttackerSquadID = attackerSquadID
+	a.AttackRange = attackRange
+	a.selected = 0
+
+	attacker := squads.GetSquadByID(a.ecsmanager, attackerSquadID)
+
+	candidates := []int{}
+	for _, s := range a.ecsmanager.World.Query(a.ecsmanager.WorldTags["squads"]) {
+
+		data := squads.GetSquadData(s.Entity)
+		if attacker == nil || data.Faction == attacker.Faction {
+			continue
+		}
+
+		candidates = append(candidates, data.SquadID)
+
+	}
+
+	a.candidates = squads.TargetCycleOrder(a.ecsmanager, attackerSquadID, candidates)
+	a.manager.Push(a.Name())
+
+}
+
+func (a *AttackTargetingMode) Update() {
+
+	if inpututil.IsKeyJustPressed(ebiten.KeyEscape) {
+		a.manager.Pop()
+		return
+	}
+
+	if len(a.candidates) == 0 {
+		return
+	}
+
+	//Tab cycles forward, Shift-Tab backwards
+	if inpututil.IsKeyJustPressed(ebiten.KeyTab) {
+
+		step := 1
+		if ebiten.IsKeyPressed(ebiten.KeyShift) {
+			step = len(a.candidates) - 1
+		}
+		a.selected = (a.selected + step) % len(a.candidates)
+
+	}
+
+	if inpututil.IsKeyJustPressed(ebiten.KeyEnter) {
+
+		target := a.candidates[a.selected]
+		code, _ := squads.CheckAttackEligibility(a.ecsmanager, a.AttackerSquadID, target, a.AttackRange)
+
+		if code == squads.EligibilityOK {
+
+			a.manager.Pop()
+			if a.OnConfirm != nil {
+				a.OnConfirm(target)
+			}
+
+		}
+
+	}
+
+}
+
+func (a *AttackTargetingMode) Draw(screen *ebiten.Image) {
+
+	var sb strings.Builder
+	sb.WriteString("Choose a target:\n")
+
+	for i, targetID := range a.candidates {
+
+		marker := "  "
+		if i == a.selected {
+			marker = "> "
+		}
+
+		name := fmt.Sprintf("squad %d", targetID)
+		if squad := squads.GetSquadByID(a.ecsmanager, targetID); squad != nil {
+			name = squad.Name
+		}
+
+		code, reason := squads.CheckAttackEligibility(a.ecsmanager, a.AttackerSquadID, targetID, a.AttackRange)
+		if code != squads.EligibilityOK {
+			fmt.Fprintf(&sb, "%s%s - %s\n", marker, name, reason)
+			continue
+		}
+
+		fmt.Fprintf(&sb, "%s%s\n", marker, name)
+
+	}
+
+	if len(a.candidates) == 0 {
+		sb.WriteString("  no enemy squads\n")
+	}
+
+	sb.WriteString("\n")
+	sb.WriteString(a.previewText())
+	sb.WriteString("Tab cycle, Enter confirm, Esc cancel")
+
+	//Todo replace with ebitenui widgets along with the other mode screens
+	ebitenutil.DebugPrintAt(screen, sb.String(), 32, 32)
+
+}
+
+// The preview panel: who would swing at the selected target this round
+func (a *AttackTargetingMode) previewText() string {
+
+	if len(a.candidates) == 0 {
+		return ""
+	}
+
+	participants := squads.PreviewSquadAttack(a.ecsmanager, a.AttackerSquadID)
+	if len(participants) == 0 {
+		return "No unit can attack this round\n\n"
+	}
+
+	var sb strings.Builder
+	sb.WriteString("Attacking with:\n")
+	for _, id := range participants {
+
+		if unit := squads.FindUnitByID(a.ecsmanager, id); unit != nil {
+			fmt.Fprintf(&sb, "  %s\n", squads.GetUnitData(unit).TemplateName)
+		}
+
+	}
+	sb.WriteString("\n")
+
+	return sb.String()
+}
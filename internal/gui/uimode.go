@@ -0,0 +1,191 @@
+package gui
+
+import (
+	"fmt"
+	"game_main/audio"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// UI modes are full-screen states like the battle summary or a future
+// skirmish setup screen. The manager keeps a stack so a mode can return to
+// whatever was open underneath it
+
+type UIMode interface {
+	Name() string
+
+	//Called when the mode becomes the active one
+	Enter()
+
+	Update()
+	Draw(screen *ebiten.Image)
+}
+
+// ResizeAware is implemented by modes that lay widgets out from the screen
+// dimensions. The manager tells the active mode about a new size right away;
+// a mode that slept through the resize hears about it when it next becomes
+// active, so background modes never reflow for nothing
+type ResizeAware interface {
+	OnResize(width int, height int)
+}
+
+type UIModeManager struct {
+	modes map[string]UIMode
+	stack []UIMode
+
+	//Confirmation dialogs layered over the mode stack, top one active.
+	//While any dialog is up the modes underneath see no input
+	dialogs []*ConfirmDialog
+
+	//Mode pushes requested while a dialog was up, applied once it resolves
+	pendingPush []string
+
+	//The players dont-ask-again choices. Optional; nil always asks
+	DialogPrefs *DialogPreferences
+
+	//Where UI sounds go. Optional; nil plays nothing
+	Sounds audio.SoundPlayer
+
+	//The tactical turn clock, held while dialogs are up so modal decisions
+	//never eat combat time. Optional; nil means no timer is in play
+	Timer TurnClock
+
+	//The last screen size Resize saw, and the size each mode was last told
+	//about, so stale modes reflow exactly once on entry
+	screenW       int
+	screenH       int
+	notifiedSizes map[string][2]int
+}
+
+// TurnClock is the slice of the turn timer the UI needs: something it can
+// hold while input is captured elsewhere. Pauses nest, so playback and a
+// dialog can both hold the clock at once
+type TurnClock interface {
+	Pause()
+	Resume()
+}
+
+// playSFX is nil-safe so modes emit sounds without caring whether audio is
+// wired up
+func (m *UIModeManager) playSFX(key string) {
+
+	if m.Sounds != nil {
+		m.Sounds.PlaySFX(key)
+	}
+
+}
+
+func NewUIModeManager() *UIModeManager {
+	return &UIModeManager{modes: make(map[string]UIMode)}
+}
+
+func (m *UIModeManager) Register(mode UIMode) {
+	m.modes[mode.Name()] = mode
+}
+
+// Push makes the named mode active on top of the current one. While a
+// dialog is up the transition waits and applies once the dialog resolves
+func (m *UIModeManager) Push(name string) error {
+
+	mode, ok := m.modes[name]
+	if !ok {
+		return fmt.Errorf("no UI mode registered with name %s", name)
+	}
+
+	if len(m.dialogs) > 0 {
+		m.pendingPush = append(m.pendingPush, name)
+		return nil
+	}
+
+	m.stack = append(m.stack, mode)
+	m.syncModeSize(mode)
+	mode.Enter()
+	m.playSFX(audio.SFXModeEnter)
+	return nil
+}
+
+// Resize records the new screen size and reflows the active mode. Everything
+// else on the stack catches up lazily when it next becomes active
+func (m *UIModeManager) Resize(width int, height int) {
+
+	if width == m.screenW && height == m.screenH {
+		return
+	}
+
+	m.screenW = width
+	m.screenH = height
+
+	if active := m.Active(); active != nil {
+		m.syncModeSize(active)
+	}
+
+}
+
+// syncModeSize tells the mode the current screen size if it cares and has
+// not heard about this one yet
+func (m *UIModeManager) syncModeSize(mode UIMode) {
+
+	aware, ok := mode.(ResizeAware)
+	if !ok || m.screenW == 0 {
+		return
+	}
+
+	size := [2]int{m.screenW, m.screenH}
+	if m.notifiedSizes[mode.Name()] == size {
+		return
+	}
+
+	if m.notifiedSizes == nil {
+		m.notifiedSizes = make(map[string][2]int)
+	}
+	m.notifiedSizes[mode.Name()] = size
+	aware.OnResize(m.screenW, m.screenH)
+}
+
+// Pop closes the active mode and returns to the one underneath
+func (m *UIModeManager) Pop() {
+
+	if len(m.stack) == 0 {
+		return
+	}
+
+	m.stack = m.stack[:len(m.stack)-1]
+
+	if active := m.Active(); active != nil {
+		m.syncModeSize(active)
+		active.Enter()
+	}
+
+}
+
+func (m *UIModeManager) Active() UIMode {
+
+	if len(m.stack) == 0 {
+		return nil
+	}
+
+	return m.stack[len(m.stack)-1]
+}
+
+func (m *UIModeManager) Update() {
+
+	//An open dialog swallows every frame; the mode underneath stands still
+	if m.ActiveDialog() != nil {
+		m.updateActiveDialog()
+		return
+	}
+
+	if active := m.Active(); active != nil {
+		active.Update()
+	}
+
+}
+
+func (m *UIModeManager) Draw(screen *ebiten.Image) {
+
+	if active := m.Active(); active != nil {
+		active.Draw(screen)
+	}
+
+	m.drawActiveDialog(screen)
+}
@@ -3,11 +3,11 @@ package input
 import (
 	"fmt"
 	"game_main/avatar"
-	"game_main/combat"
 	"game_main/common"
 	"game_main/gear"
 	"game_main/graphics"
-	"game_main/gui"
+	"game_main/internal/combat"
+	"game_main/internal/gui"
 	"game_main/monsters"
 	"game_main/rendering"
 	"game_main/worldmap"
@@ -40,7 +40,7 @@ func ApplyThrowable(ecsmanager *common.EntityManager, item *gear.Item, pl *avata
 	}
 
 	//t.ReadyThrowAreaVX()
-	pos := common.GetTilePositions(t.Shape.GetIndices(), graphics.ScreenInfo.DungeonWidth)
+	pos := graphics.GetTilePositions(t.Shape.GetIndices(), graphics.ScreenInfo.DungeonWidth)
 
 	//TODO, this will be slow in case there are a lot of creatures
 	for _, c := range ecsmanager.World.Query(ecsmanager.WorldTags["monsters"]) {
@@ -6,7 +6,7 @@ import (
 	"game_main/avatar"
 	"game_main/common"
 	"game_main/graphics"
-	"game_main/gui"
+	"game_main/internal/gui"
 	"game_main/monsters"
 	"game_main/timesystem"
 	"game_main/worldmap"
@@ -2,10 +2,10 @@ package spawning
 
 import (
 	"game_main/avatar"
-	"game_main/behavior"
 	"game_main/common"
 	"game_main/entitytemplates"
 	"game_main/graphics"
+	"game_main/internal/behavior"
 
 	//"game_main/entitytemplates"
 
@@ -8,6 +8,11 @@ import (
 
 // Todo remove later once you change teh random number generation. The same function is in another aprt of the code
 func GetDiceRoll(num int) int {
+
+	if roll, ok := seededRoll(num); ok {
+		return roll
+	}
+
 	x, _ := cryptorand.Int(cryptorand.Reader, big.NewInt(int64(num)))
 	return int(x.Int64()) + 1
 
@@ -0,0 +1,33 @@
+package randgen
+
+import (
+	"math/rand"
+)
+
+// Optional deterministic mode. The golden-log regression tests and anything
+// else that needs reproducible battles call Seed before rolling; normal play
+// never does and keeps the crypto source
+
+var seeded *rand.Rand
+
+// Seed switches every roll to a deterministic source. The same seed always
+// produces the same roll sequence
+func Seed(seed int64) {
+	seeded = rand.New(rand.NewSource(seed))
+}
+
+// ClearSeed goes back to the crypto source
+func ClearSeed() {
+	seeded = nil
+}
+
+// seededRoll is GetDiceRoll under the deterministic source. Returns false
+// when no seed is active
+func seededRoll(num int) (int, bool) {
+
+	if seeded == nil {
+		return 0, false
+	}
+
+	return seeded.Intn(num) + 1, true
+}
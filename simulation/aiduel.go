@@ -0,0 +1,129 @@
+package simulation
+
+import (
+	"fmt"
+	"game_main/common"
+	"game_main/squads"
+	"strings"
+)
+
+// AI-versus-AI evaluation: the same scenario fought with each side driven by
+// a named controller from the squads AI registry. Where RunScenario always
+// trades volleys, the duel gives both controllers the full action vocabulary,
+// so "does the cautious profile actually lose to the default one" is a
+// measurable question
+
+// RunAIDuel fights the scenario for its iteration count with the attacker
+// driven by attackerAI and the defender by defenderAI
+func (s *Simulator) RunAIDuel(scenario Scenario, attackerAI string, defenderAI string) (SimulationResult, error) {
+
+	attacker, ok := squads.AIControllerByName(attackerAI)
+	if !ok {
+		return SimulationResult{}, fmt.Errorf("unknown AI controller %q, registered: %s", attackerAI, strings.Join(squads.AIControllerNames(), ", "))
+	}
+
+	defender, ok := squads.AIControllerByName(defenderAI)
+	if !ok {
+		return SimulationResult{}, fmt.Errorf("unknown AI controller %q, registered: %s", defenderAI, strings.Join(squads.AIControllerNames(), ", "))
+	}
+
+	iterations := scenario.Iterations
+	if iterations <= 0 {
+		iterations = 1
+	}
+
+	outcomes := make([]IterationOutcome, 0, iterations)
+	for i := 0; i < iterations; i++ {
+
+		outcome, err := s.runAIDuelOnce(scenario, attacker, defender)
+		if err != nil {
+			return SimulationResult{}, err
+		}
+
+		outcomes = append(outcomes, outcome)
+
+	}
+
+	name := fmt.Sprintf("%s (%s vs %s)", scenario.Name, attackerAI, defenderAI)
+	return s.Aggregate(name, outcomes), nil
+}
+
+// One duel iteration in a fresh world. The round skeleton mirrors runBattle -
+// advance, effects, wipe checks - but each sides turn is a controller
+// activation instead of a forced volley
+func (s *Simulator) runAIDuelOnce(scenario Scenario, attacker squads.SquadAIController, defender squads.SquadAIController) (IterationOutcome, error) {
+
+	em := newBattleManager()
+
+	distance := scenario.Distance
+	if distance <= 0 {
+		distance = 2
+	}
+
+	att := squads.CreateSquad(em, scenario.Name+" attacker", "Attacker", common.Position{X: 5, Y: 5 - distance}, squads.DirDown)
+	def := squads.CreateSquad(em, scenario.Name+" defender", "Defender", common.Position{X: 5, Y: 5}, squads.DirUp)
+
+	if err := placeScenarioUnits(em, scenario.Attacker, att.SquadID); err != nil {
+		return IterationOutcome{}, err
+	}
+	if err := placeScenarioUnits(em, scenario.Defender, def.SquadID); err != nil {
+		return IterationOutcome{}, err
+	}
+
+	//The starting distance is the engagement range, so neither controller
+	//has to close in before its attacks become legal
+	attackRange := squads.GetSquadDistance(em, att.SquadID, def.SquadID)
+
+	outcome := IterationOutcome{}
+	for round := 1; round <= s.MaxRounds; round++ {
+
+		outcome.Rounds = round
+
+		squads.AdvanceSquadRound(att)
+		squads.AdvanceSquadRound(def)
+
+		for _, squadID := range []int{att.SquadID, def.SquadID} {
+			for _, event := range squads.TickEffects(em, squadID) {
+				outcome.TotalDamage += event.Damage
+			}
+		}
+
+		if squadWipedOut(em, def.SquadID) {
+			outcome.AttackerWon = true
+			return s.finishDuel(em, outcome, att.SquadID), nil
+		}
+		if squadWipedOut(em, att.SquadID) {
+			return s.finishDuel(em, outcome, def.SquadID), nil
+		}
+
+		for _, event := range squads.ExecuteActivation(em, att.SquadID, attacker, attackRange).Events {
+			outcome.TotalDamage += event.Damage
+		}
+		if squadWipedOut(em, def.SquadID) {
+			outcome.AttackerWon = true
+			return s.finishDuel(em, outcome, att.SquadID), nil
+		}
+
+		for _, event := range squads.ExecuteActivation(em, def.SquadID, defender, attackRange).Events {
+			outcome.TotalDamage += event.Damage
+		}
+		if squadWipedOut(em, att.SquadID) {
+			return s.finishDuel(em, outcome, def.SquadID), nil
+		}
+
+	}
+
+	//Hitting the round limit counts as a defender win, same as runBattle
+	return s.finishDuel(em, outcome, def.SquadID), nil
+}
+
+func (s *Simulator) finishDuel(em *common.EntityManager, outcome IterationOutcome, winnerSquadID int) IterationOutcome {
+
+	for _, u := range squads.SquadUnits(em, winnerSquadID) {
+		if common.GetAttributes(u).CurrentHealth > 0 {
+			outcome.WinnerSurvivors++
+		}
+	}
+
+	return outcome
+}
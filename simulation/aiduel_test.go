@@ -0,0 +1,77 @@
+package simulation
+
+import (
+	"game_main/common"
+	"game_main/randgen"
+	"game_main/squads"
+	"reflect"
+	"testing"
+)
+
+func duelScenario() Scenario {
+
+	squads.Units["DuelKnight"] = squads.UnitTemplate{
+		Name:      "DuelKnight",
+		Class:     "Knight",
+		Attr:      common.NewBaseAttributes(24, 0, 10, 1, 1, 0),
+		MinDamage: 3,
+		MaxDamage: 5,
+	}
+
+	return Scenario{
+		Name:       "Duel",
+		Iterations: 3,
+		Attacker: []ScenarioUnit{
+			{Template: "DuelKnight", Row: 0, Col: 0},
+			{Template: "DuelKnight", Row: 0, Col: 2},
+		},
+		Defender: []ScenarioUnit{
+			{Template: "DuelKnight", Row: 0, Col: 1},
+			{Template: "DuelKnight", Row: 1, Col: 1},
+		},
+	}
+}
+
+// The same controllers under the same seed must replay the identical battles
+func TestAIDuelIsDeterministic(t *testing.T) {
+
+	scenario := duelScenario()
+	sim := NewSimulator()
+
+	randgen.Seed(31)
+	first, err := sim.RunAIDuel(scenario, "default", "cautious")
+	randgen.ClearSeed()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	randgen.Seed(31)
+	second, err := sim.RunAIDuel(scenario, "default", "cautious")
+	randgen.ClearSeed()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(first, second) {
+		t.Errorf("the same seed should reproduce the duel exactly:\n%+v\n%+v", first, second)
+	}
+
+	if first.AvgRounds <= 0 || first.AvgTotalDamage <= 0 {
+		t.Errorf("the duel should actually fight, got %+v", first)
+	}
+
+}
+
+// An unknown controller name fails with the registered names in the message
+func TestAIDuelRejectsUnknownController(t *testing.T) {
+
+	sim := NewSimulator()
+
+	if _, err := sim.RunAIDuel(duelScenario(), "default", "no-such-ai"); err == nil {
+		t.Fatal("an unknown controller name should error")
+	}
+	if _, err := sim.RunAIDuel(duelScenario(), "no-such-ai", "default"); err == nil {
+		t.Fatal("an unknown controller name should error")
+	}
+
+}
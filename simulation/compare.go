@@ -0,0 +1,89 @@
+package simulation
+
+import (
+	"fmt"
+	"game_main/common"
+	"game_main/squads"
+)
+
+// Formation comparison: fight two placements of the same squad against the
+// same opponent and report the win-rate delta. The squad editor uses it to
+// answer "does moving the tank to the front actually help" with numbers
+// instead of a hunch
+
+type FormationComparison struct {
+	Iterations int
+
+	BaseWinRate    float64
+	VariantWinRate float64
+
+	//VariantWinRate minus BaseWinRate, so positive means the variant is better
+	Delta float64
+}
+
+// CompareFormations runs the base squad and the variant squad against the
+// same opponent for the given number of iterations each. Both squads are
+// serialized up front and every battle fights clones in a fresh world, like
+// the editors test runs, so nothing in the callers world changes
+func (s *Simulator) CompareFormations(em *common.EntityManager, baseSquadID int, variantSquadID int, opponentID int, iterations int) (FormationComparison, error) {
+
+	if iterations <= 0 {
+		iterations = DefaultTestIterations
+	}
+	if iterations > MaxTestIterations {
+		iterations = MaxTestIterations
+	}
+
+	saved := squads.SerializeSquads(em)
+
+	registry := captureRegistry()
+	defer registry.restore()
+
+	comparison := FormationComparison{Iterations: iterations}
+
+	var err error
+	if comparison.BaseWinRate, err = s.winRateAgainst(pickSaved(saved, baseSquadID, opponentID), baseSquadID, opponentID, iterations); err != nil {
+		return comparison, err
+	}
+	if comparison.VariantWinRate, err = s.winRateAgainst(pickSaved(saved, variantSquadID, opponentID), variantSquadID, opponentID, iterations); err != nil {
+		return comparison, err
+	}
+	comparison.Delta = comparison.VariantWinRate - comparison.BaseWinRate
+
+	return comparison, nil
+}
+
+func pickSaved(saved []squads.SavedSquad, squadIDs ...int) []squads.SavedSquad {
+
+	picked := []squads.SavedSquad{}
+	for _, savedSquad := range saved {
+		for _, id := range squadIDs {
+			if savedSquad.Squad.SquadID == id {
+				picked = append(picked, savedSquad)
+			}
+		}
+	}
+
+	return picked
+}
+
+// winRateAgainst fails instead of averaging over battles that never ran: a
+// deserialization error would otherwise deflate the rate silently
+func (s *Simulator) winRateAgainst(saved []squads.SavedSquad, squadID int, opponentID int, iterations int) (float64, error) {
+
+	wins := 0
+	for i := 0; i < iterations; i++ {
+
+		clone := newBattleManager()
+		if err := squads.DeserializeSquads(clone, saved); err != nil {
+			return 0, fmt.Errorf("restoring squad %d for iteration %d: %w", squadID, i+1, err)
+		}
+
+		if _, record := s.RunBattle(clone, squadID, opponentID); record.WinnerSquadID == squadID {
+			wins++
+		}
+
+	}
+
+	return float64(wins) / float64(iterations), nil
+}
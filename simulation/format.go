@@ -0,0 +1,92 @@
+package simulation
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// FormatBalanceReport writes the human-readable summary of a batch of
+// simulation results, including the variance fields so a designer can tell a
+// swingy matchup from a stable one
+func FormatBalanceReport(w io.Writer, results []SimulationResult) {
+
+	for _, r := range results {
+
+		fmt.Fprintf(w, "%s: %d iterations, %.0f%% attacker win rate\n",
+			r.ScenarioName, r.Iterations, r.AttackerWinRate()*100)
+		fmt.Fprintf(w, "  rounds: avg %.1f, stddev %.2f\n", r.AvgRounds, r.StdDevRounds)
+		fmt.Fprintf(w, "  total damage: avg %.1f, stddev %.2f\n", r.AvgTotalDamage, r.StdDevTotalDamage)
+
+		for _, warning := range r.PreflightWarnings {
+			fmt.Fprintf(w, "  pre-flight warning: %s\n", warning)
+		}
+
+		formatVictoryMargins(w, r.VictoryMargins)
+		formatSurvivability(w, r.Survivability)
+
+	}
+
+}
+
+// The dodge economy per defender template: how much of the incoming damage a
+// template simply refuses, and how that stacks up against its armor
+func formatSurvivability(w io.Writer, stats map[string]SurvivabilityStats) {
+
+	if len(stats) == 0 {
+		return
+	}
+
+	templates := make([]string, 0, len(stats))
+	for template := range stats {
+		templates = append(templates, template)
+	}
+	sort.Strings(templates)
+
+	fmt.Fprintln(w, "  survivability:")
+	for _, template := range templates {
+
+		s := stats[template]
+		fmt.Fprintf(w, "    %s: %.0f%% of %d attacks invalidated, %d damage avoided (%.2f/attack, dodge vs armor %.2f)\n",
+			s.Template, s.InvalidationRate()*100, s.AttacksIncoming, s.DamageAvoided,
+			s.AvgDamageAvoidedPerAttack(), s.DodgeVsArmor())
+
+	}
+
+}
+
+// One bar per survivor count, so a designer can see at a glance whether wins
+// are close or crushing
+func formatVictoryMargins(w io.Writer, margins map[int]int) {
+
+	if len(margins) == 0 {
+		return
+	}
+
+	counts := make([]int, 0, len(margins))
+	maxCount := 0
+	for survivors, count := range margins {
+		counts = append(counts, survivors)
+		if count > maxCount {
+			maxCount = count
+		}
+	}
+	sort.Ints(counts)
+
+	//Bars scale to the largest bucket so long sweeps stay readable
+	const maxBarWidth = 40
+
+	fmt.Fprintln(w, "  victory margins (survivors on the winning side):")
+	for _, survivors := range counts {
+
+		width := margins[survivors] * maxBarWidth / maxCount
+		if width < 1 {
+			width = 1
+		}
+
+		fmt.Fprintf(w, "    %d | %s (%d)\n", survivors, strings.Repeat("#", width), margins[survivors])
+
+	}
+
+}
@@ -0,0 +1,134 @@
+package simulation
+
+import (
+	"fmt"
+
+	"game_main/squads"
+)
+
+// The formation counter report fights one squad composition against a set of
+// archetypal enemy formations and tabulates the win rate per formation. A
+// composition that cracks turtles but folds against a ranged line shows up
+// immediately, which is the whole point: hard counters hide inside aggregate
+// win rates
+
+// FormationMatchup is the attackers showing against one enemy archetype
+type FormationMatchup struct {
+	Formation string
+	Result    SimulationResult
+}
+
+type FormationCounterReport struct {
+	//The attacking composition the report is about, for the report title
+	SquadName string
+
+	//One entry per archetype, in the order they were given
+	Matchups []FormationMatchup
+}
+
+// ArchetypePresets is the standard enemy lineup: the melee template packed
+// into the turtle and wedge layouts, and the ranged template spread as a
+// skirmish line. Callers wanting other archetypes build their own preset list
+func ArchetypePresets(meleeTemplate string, rangedTemplate string) []ScenarioPreset {
+
+	return []ScenarioPreset{
+		{Formation: "turtle", Template: meleeTemplate},
+		{Formation: "wedge", Template: meleeTemplate},
+		{Formation: "skirmish", Template: rangedTemplate},
+	}
+}
+
+// RunFormationCounters fights the attacker composition against every enemy
+// preset for the given iteration count, each matchup in fresh worlds
+func (s *Simulator) RunFormationCounters(squadName string, attacker []ScenarioUnit, enemies []ScenarioPreset, iterations int) (FormationCounterReport, error) {
+
+	if len(attacker) == 0 {
+		return FormationCounterReport{}, fmt.Errorf("formation report needs an attacking squad")
+	}
+	if len(enemies) == 0 {
+		return FormationCounterReport{}, fmt.Errorf("formation report needs enemy formations to test against")
+	}
+
+	report := FormationCounterReport{SquadName: squadName}
+
+	for _, preset := range enemies {
+
+		if _, ok := squads.UnitTemplateByName(preset.Template); !ok {
+			return FormationCounterReport{}, fmt.Errorf("formation report references unknown template %s", preset.Template)
+		}
+
+		defender, err := ExpandPreset(preset)
+		if err != nil {
+			return FormationCounterReport{}, err
+		}
+
+		scenario := Scenario{
+			Name:       fmt.Sprintf("%s vs %s", squadName, preset.Formation),
+			Iterations: iterations,
+			Attacker:   attacker,
+			Defender:   defender,
+		}
+
+		result, err := s.RunScenario(scenario)
+		if err != nil {
+			return FormationCounterReport{}, err
+		}
+
+		report.Matchups = append(report.Matchups, FormationMatchup{Formation: preset.Formation, Result: result})
+
+	}
+
+	return report, nil
+}
+
+// HardestCounter is the formation the attacker struggles against the most
+func (r FormationCounterReport) HardestCounter() string {
+
+	worst := ""
+	worstRate := 2.0
+
+	for _, m := range r.Matchups {
+		if rate := m.Result.AttackerWinRate(); rate < worstRate {
+			worst = m.Formation
+			worstRate = rate
+		}
+	}
+
+	return worst
+}
+
+// WinRateFor is the attackers win rate against one formation, -1 when the
+// formation was not part of the run
+func (r FormationCounterReport) WinRateFor(formation string) float64 {
+
+	for _, m := range r.Matchups {
+		if m.Formation == formation {
+			return m.Result.AttackerWinRate()
+		}
+	}
+
+	return -1
+}
+
+// Report shapes the matchups for the export sinks, one row per formation
+func (r FormationCounterReport) Report() Report {
+
+	rows := [][]string{}
+	for _, m := range r.Matchups {
+		rows = append(rows, []string{
+			m.Formation,
+			fmt.Sprintf("%.3f", m.Result.AttackerWinRate()),
+			fmt.Sprintf("%.1f", m.Result.AvgRounds),
+		})
+	}
+
+	return Report{
+		Meta: ReportMeta{
+			Title:     fmt.Sprintf("%s vs enemy formations", r.SquadName),
+			Scenarios: len(r.Matchups),
+		},
+		Sections: []ReportSection{
+			{Name: "formations", Columns: []string{"formation", "winRate", "avgRounds"}, Rows: rows},
+		},
+	}
+}
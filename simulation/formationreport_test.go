@@ -0,0 +1,110 @@
+package simulation
+
+import (
+	"game_main/common"
+	"game_main/randgen"
+	"game_main/squads"
+	"testing"
+)
+
+// A glass-cannon archer and the tanky knight the turtle is built from. The
+// archer has enough dodge that mirror matches can go either way
+func registerFormationTemplates() {
+
+	squads.Units["FormArcher"] = squads.UnitTemplate{
+		Name:      "FormArcher",
+		Class:     "Archer",
+		Attr:      common.NewBaseAttributes(12, 0, 10, 0, 1, 0.25),
+		MinDamage: 6,
+		MaxDamage: 8,
+	}
+
+	squads.Units["FormKnight"] = squads.UnitTemplate{
+		Name:       "FormKnight",
+		Class:      "Knight",
+		Attr:       common.NewBaseAttributes(20, 0, 12, 1, 1, 0.05),
+		MinDamage:  2,
+		MaxDamage:  4,
+		CoverValue: 2,
+	}
+
+}
+
+// A ranged-heavy squad shreds a slow turtle but struggles in an archer
+// mirror, so the skirmish line is its hard counter and the report says so
+func TestFormationReportRevealsHardCounters(t *testing.T) {
+
+	registerFormationTemplates()
+
+	randgen.Seed(1317)
+	defer randgen.ClearSeed()
+
+	attacker := []ScenarioUnit{
+		{Template: "FormArcher", Row: 1, Col: 0},
+		{Template: "FormArcher", Row: 1, Col: 1},
+		{Template: "FormArcher", Row: 1, Col: 2},
+	}
+
+	sim := NewSimulator()
+	report, err := sim.RunFormationCounters("archer line", attacker, ArchetypePresets("FormKnight", "FormArcher"), 150)
+	if err != nil {
+		t.Fatalf("running the report failed: %v", err)
+	}
+
+	vsTurtle := report.WinRateFor("turtle")
+	vsLine := report.WinRateFor("skirmish")
+	if vsTurtle < 0 || vsLine < 0 {
+		t.Fatalf("the run should cover both archetypes, got %+v", report.Matchups)
+	}
+
+	if vsTurtle <= vsLine {
+		t.Errorf("archers should beat the turtle more often than the ranged line, got %.3f against %.3f", vsTurtle, vsLine)
+	}
+	if counter := report.HardestCounter(); counter != "skirmish" {
+		t.Errorf("the ranged line should be the hard counter, got %q", counter)
+	}
+
+}
+
+// The export view carries one row per formation tested
+func TestFormationReportHasOneRowPerFormation(t *testing.T) {
+
+	registerFormationTemplates()
+
+	randgen.Seed(99)
+	defer randgen.ClearSeed()
+
+	attacker := []ScenarioUnit{{Template: "FormArcher", Row: 0, Col: 0}}
+	enemies := ArchetypePresets("FormKnight", "FormArcher")
+
+	sim := NewSimulator()
+	counters, err := sim.RunFormationCounters("solo archer", attacker, enemies, 5)
+	if err != nil {
+		t.Fatalf("running the report failed: %v", err)
+	}
+
+	report := counters.Report()
+	if len(report.Sections) != 1 || report.Sections[0].Name != "formations" {
+		t.Fatalf("expected one formations section, got %+v", report.Sections)
+	}
+	if rows := len(report.Sections[0].Rows); rows != len(enemies) {
+		t.Errorf("expected %d rows, one per formation, got %d", len(enemies), rows)
+	}
+
+}
+
+func TestFormationReportRejectsBadInput(t *testing.T) {
+
+	registerFormationTemplates()
+	sim := NewSimulator()
+
+	if _, err := sim.RunFormationCounters("empty", nil, ArchetypePresets("FormKnight", "FormArcher"), 5); err == nil {
+		t.Error("an empty attacker should fail")
+	}
+
+	attacker := []ScenarioUnit{{Template: "FormArcher", Row: 0, Col: 0}}
+	if _, err := sim.RunFormationCounters("unknown enemy", attacker, []ScenarioPreset{{Formation: "turtle", Template: "NoSuchTemplate"}}, 5); err == nil {
+		t.Error("an unknown enemy template should fail")
+	}
+
+}
@@ -0,0 +1,265 @@
+package simulation
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"math"
+	"os"
+	"strings"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/basicfont"
+	"golang.org/x/image/math/fixed"
+)
+
+// The heatmap renderer turns a win-rate matrix into shareable PNGs: one cell
+// per matchup on a diverging scale centered at 50%, with the percentage and
+// sample size printed in the cell. It only uses the standard image packages
+// and the basicfont bitmap face, so the tools stay free of font files
+
+type HeatmapOptions struct {
+	//Pixel size of a cell at full size. 0 means 64
+	CellSize int
+
+	//Cells shrink down to this floor before the matrix splits into several
+	//images. 0 means 28
+	MinCellSize int
+
+	//How many cells per axis a single image holds at full cell size. 0
+	//means 24
+	MaxCellsPerImage int
+
+	//Matchups with fewer battles than this render grey instead of colored,
+	//so thin data does not masquerade as signal. 0 greys nothing
+	MinSamples int
+}
+
+func (o HeatmapOptions) withDefaults() HeatmapOptions {
+
+	if o.CellSize <= 0 {
+		o.CellSize = 64
+	}
+	if o.MinCellSize <= 0 {
+		o.MinCellSize = 28
+	}
+	if o.MaxCellsPerImage <= 0 {
+		o.MaxCellsPerImage = 24
+	}
+
+	return o
+}
+
+// The diverging scale endpoints and the low-sample grey
+var (
+	heatLow  = color.RGBA{33, 102, 172, 255}
+	heatMid  = color.RGBA{255, 255, 255, 255}
+	heatHigh = color.RGBA{178, 24, 43, 255}
+	heatGrey = color.RGBA{200, 200, 200, 255}
+)
+
+// heatColor maps a win rate onto the diverging scale: blue below 50%, white
+// at it, red above
+func heatColor(winRate float64) color.RGBA {
+
+	if winRate <= 0.5 {
+		return lerpColor(heatLow, heatMid, winRate/0.5)
+	}
+
+	return lerpColor(heatMid, heatHigh, (winRate-0.5)/0.5)
+}
+
+func lerpColor(from color.RGBA, to color.RGBA, t float64) color.RGBA {
+
+	lerp := func(a uint8, b uint8) uint8 {
+		return uint8(math.Round(float64(a) + (float64(b)-float64(a))*t))
+	}
+
+	return color.RGBA{lerp(from.R, to.R), lerp(from.G, to.G), lerp(from.B, to.B), 255}
+}
+
+// The pixel geometry of one heatmap image: the label gutters and cell size
+type heatmapLayout struct {
+	left, top, cell int
+}
+
+func layoutFor(m WinRateMatrix, opts HeatmapOptions) heatmapLayout {
+
+	longest := 0
+	for _, name := range m.Templates {
+		if len(name) > longest {
+			longest = len(name)
+		}
+	}
+
+	cell := opts.CellSize
+	span := opts.CellSize * opts.MaxCellsPerImage
+	if n := len(m.Templates); n*cell > span {
+		cell = span / n
+	}
+	if cell < opts.MinCellSize {
+		cell = opts.MinCellSize
+	}
+
+	return heatmapLayout{
+		left: longest*7 + 12,
+		top:  basicfont.Face7x13.Height + 8,
+		cell: cell,
+	}
+}
+
+// cellSamples is the sample size behind a cell. The matrix carries one
+// iteration count for every cell unless per-cell samples are attached
+func (m WinRateMatrix) cellSamples(row int, col int) int {
+
+	if m.Samples != nil {
+		return m.Samples[row][col]
+	}
+
+	return m.Iterations
+}
+
+// RenderWinRateHeatmap draws the matrix as one or more images. Large rosters
+// first shrink the cells to the floor, then split row- and column-wise into
+// tiles so every label stays readable
+func RenderWinRateHeatmap(m WinRateMatrix, opts HeatmapOptions) []*image.RGBA {
+
+	opts = opts.withDefaults()
+	layout := layoutFor(m, opts)
+
+	perTile := opts.CellSize * opts.MaxCellsPerImage / layout.cell
+	n := len(m.Templates)
+
+	images := []*image.RGBA{}
+	for rowStart := 0; rowStart < n; rowStart += perTile {
+
+		rowEnd := rowStart + perTile
+		if rowEnd > n {
+			rowEnd = n
+		}
+
+		for colStart := 0; colStart < n; colStart += perTile {
+
+			colEnd := colStart + perTile
+			if colEnd > n {
+				colEnd = n
+			}
+
+			images = append(images, renderHeatmapTile(m, opts, layout, rowStart, rowEnd, colStart, colEnd))
+
+		}
+
+	}
+
+	return images
+}
+
+func renderHeatmapTile(m WinRateMatrix, opts HeatmapOptions, layout heatmapLayout, rowStart int, rowEnd int, colStart int, colEnd int) *image.RGBA {
+
+	width := layout.left + (colEnd-colStart)*layout.cell + 4
+	height := layout.top + (rowEnd-rowStart)*layout.cell + 4
+
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	draw.Draw(img, img.Bounds(), &image.Uniform{heatMid}, image.Point{}, draw.Src)
+
+	//Column labels across the top, row labels down the left
+	for col := colStart; col < colEnd; col++ {
+		x := layout.left + (col-colStart)*layout.cell + 2
+		drawHeatmapText(img, m.Templates[col], x, layout.top-4, layout.cell)
+	}
+	for row := rowStart; row < rowEnd; row++ {
+		y := layout.top + (row-rowStart)*layout.cell + layout.cell/2 + basicfont.Face7x13.Height/2
+		drawHeatmapText(img, m.Templates[row], 4, y, layout.left)
+	}
+
+	for row := rowStart; row < rowEnd; row++ {
+		for col := colStart; col < colEnd; col++ {
+
+			x := layout.left + (col-colStart)*layout.cell
+			y := layout.top + (row-rowStart)*layout.cell
+
+			samples := m.cellSamples(row, col)
+			fill := heatColor(m.WinRates[row][col])
+			if opts.MinSamples > 0 && samples < opts.MinSamples {
+				fill = heatGrey
+			}
+
+			rect := image.Rect(x+1, y+1, x+layout.cell-1, y+layout.cell-1)
+			draw.Draw(img, rect, &image.Uniform{fill}, image.Point{}, draw.Src)
+
+			label := fmt.Sprintf("%d%% (%d)", int(math.Round(m.WinRates[row][col]*100)), samples)
+			if len(label)*7 > layout.cell {
+				label = fmt.Sprintf("%d%%", int(math.Round(m.WinRates[row][col]*100)))
+			}
+
+			tx := x + (layout.cell-len(label)*7)/2
+			ty := y + layout.cell/2 + basicfont.Face7x13.Height/2
+			drawHeatmapText(img, label, tx, ty, layout.cell)
+
+		}
+	}
+
+	return img
+}
+
+// drawHeatmapText prints with the embedded bitmap face, truncated to fit the
+// given pixel budget. Tiny cells drop their text entirely
+func drawHeatmapText(img *image.RGBA, text string, x int, y int, budget int) {
+
+	fit := budget / 7
+	if fit < 3 {
+		return
+	}
+	if len(text) > fit {
+		text = text[:fit]
+	}
+
+	drawer := font.Drawer{
+		Dst:  img,
+		Src:  image.NewUniform(color.RGBA{20, 20, 20, 255}),
+		Face: basicfont.Face7x13,
+		Dot:  fixed.P(x, y),
+	}
+	drawer.DrawString(text)
+}
+
+// WriteHeatmapPNGs writes the rendered images. A single image goes to the
+// path as given; several get -1, -2, ... spliced in before the extension.
+// Returns the paths written
+func WriteHeatmapPNGs(path string, images []*image.RGBA) ([]string, error) {
+
+	paths := []string{}
+	for i, img := range images {
+
+		target := path
+		if len(images) > 1 {
+
+			ext := ""
+			if dot := strings.LastIndex(path, "."); dot >= 0 {
+				target, ext = path[:dot], path[dot:]
+			}
+			target = fmt.Sprintf("%s-%d%s", target, i+1, ext)
+
+		}
+
+		f, err := os.Create(target)
+		if err != nil {
+			return paths, err
+		}
+
+		err = png.Encode(f, img)
+		if closeErr := f.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			return paths, err
+		}
+
+		paths = append(paths, target)
+
+	}
+
+	return paths, nil
+}
@@ -0,0 +1,132 @@
+package simulation
+
+import (
+	"image/color"
+	"testing"
+
+	"golang.org/x/image/font/basicfont"
+)
+
+// A 2x2 fixture with the extremes on it: a sure win, a coin flip, a sure
+// loss, and one thin-data cell
+func heatmapFixture() WinRateMatrix {
+
+	return WinRateMatrix{
+		Templates:  []string{"A", "B"},
+		WinRates:   [][]float64{{1.0, 0.5}, {0.0, 0.25}},
+		Iterations: 100,
+	}
+}
+
+// samplePoint is a pixel safely inside a cells fill but away from the
+// centered label text
+func samplePoint(layout heatmapLayout, row int, col int) (int, int) {
+
+	return layout.left + col*layout.cell + 4, layout.top + row*layout.cell + 4
+}
+
+func TestHeatmapCellColors(t *testing.T) {
+
+	matrix := heatmapFixture()
+	opts := HeatmapOptions{}.withDefaults()
+	layout := layoutFor(matrix, opts)
+
+	images := RenderWinRateHeatmap(matrix, HeatmapOptions{})
+	if len(images) != 1 {
+		t.Fatalf("a 2x2 matrix should fit a single image, got %d", len(images))
+	}
+	img := images[0]
+
+	checks := []struct {
+		row, col int
+		want     color.RGBA
+		name     string
+	}{
+		{0, 0, heatHigh, "a sure win should be the full red endpoint"},
+		{0, 1, heatMid, "a coin flip should sit at the white midpoint"},
+		{1, 0, heatLow, "a sure loss should be the full blue endpoint"},
+	}
+
+	for _, check := range checks {
+		x, y := samplePoint(layout, check.row, check.col)
+		if got := img.RGBAAt(x, y); got != check.want {
+			t.Errorf("%s, got %v at (%d,%d)", check.name, got, x, y)
+		}
+	}
+
+	//25% sits between blue and white, so strictly more blue than red
+	x, y := samplePoint(layout, 1, 1)
+	if got := img.RGBAAt(x, y); got.B <= got.R || got == heatMid {
+		t.Errorf("a 25%% cell should lean blue, got %v", got)
+	}
+
+}
+
+func TestHeatmapGreysLowSampleCells(t *testing.T) {
+
+	matrix := heatmapFixture()
+	matrix.Samples = [][]int{{100, 100}, {100, 3}}
+
+	opts := HeatmapOptions{MinSamples: 10}
+	layout := layoutFor(matrix, opts.withDefaults())
+
+	img := RenderWinRateHeatmap(matrix, opts)[0]
+
+	x, y := samplePoint(layout, 1, 1)
+	if got := img.RGBAAt(x, y); got != heatGrey {
+		t.Errorf("the 3-battle cell should render grey, got %v", got)
+	}
+
+	//The well-sampled cells keep their colors
+	x, y = samplePoint(layout, 0, 0)
+	if got := img.RGBAAt(x, y); got != heatHigh {
+		t.Errorf("a well-sampled cell should stay colored, got %v", got)
+	}
+
+}
+
+// Past the cell-size floor the matrix splits into tiles instead of
+// shrinking further
+func TestHeatmapSplitsLargeRosters(t *testing.T) {
+
+	names := []string{"T0", "T1", "T2", "T3", "T4", "T5", "T6", "T7", "T8", "T9"}
+	rates := [][]float64{}
+	for range names {
+		row := make([]float64, len(names))
+		rates = append(rates, row)
+	}
+
+	matrix := WinRateMatrix{Templates: names, WinRates: rates, Iterations: 10}
+	opts := HeatmapOptions{CellSize: 16, MinCellSize: 8, MaxCellsPerImage: 2}
+
+	layout := layoutFor(matrix, opts.withDefaults())
+	if layout.cell != 8 {
+		t.Fatalf("ten templates in a 32px span should pin cells at the 8px floor, got %d", layout.cell)
+	}
+
+	//Four 8px cells fit the 32px span, so ten templates tile 3x3
+	images := RenderWinRateHeatmap(matrix, opts)
+	if len(images) != 9 {
+		t.Errorf("ten templates at four cells per tile should split into 9 images, got %d", len(images))
+	}
+
+}
+
+func TestHeatmapLayoutReservesLabelGutters(t *testing.T) {
+
+	matrix := WinRateMatrix{
+		Templates:  []string{"ShortName", "AMuchLongerTemplateName"},
+		WinRates:   [][]float64{{0.5, 0.5}, {0.5, 0.5}},
+		Iterations: 10,
+	}
+
+	layout := layoutFor(matrix, HeatmapOptions{}.withDefaults())
+
+	if layout.left <= len("ShortName")*7 {
+		t.Errorf("the left gutter should size to the longest name, got %d", layout.left)
+	}
+	if layout.top <= basicfont.Face7x13.Height {
+		t.Errorf("the top gutter should clear the label face, got %d", layout.top)
+	}
+
+}
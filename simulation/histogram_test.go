@@ -0,0 +1,108 @@
+package simulation
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+// Weighted mean survivor count of a histogram, to compare skew
+func histogramMean(h map[int]int) float64 {
+
+	total, weighted := 0, 0
+	for survivors, count := range h {
+		total += count
+		weighted += survivors * count
+	}
+
+	if total == 0 {
+		return 0
+	}
+
+	return float64(weighted) / float64(total)
+}
+
+func TestVictoryMarginHistogramSkew(t *testing.T) {
+
+	RegisterScenarioTemplates([]ScenarioTemplate{
+		{Name: "Crusher", MaxHealth: 40, Protection: 3, MinDamage: 8, MaxDamage: 8},
+		{Name: "Fodder", MaxHealth: 6, MinDamage: 1, MaxDamage: 1},
+		{Name: "Militia", MaxHealth: 15, MinDamage: 3, MaxDamage: 5},
+	})
+
+	sim := NewSimulator()
+
+	//Three crushers against one fodder unit: the winners barely get scratched
+	lopsided := Scenario{
+		Name:       "Lopsided",
+		Iterations: 20,
+		Attacker: []ScenarioUnit{
+			{Template: "Crusher", Row: 0, Col: 0},
+			{Template: "Crusher", Row: 0, Col: 1},
+			{Template: "Crusher", Row: 0, Col: 2},
+		},
+		Defender: []ScenarioUnit{{Template: "Fodder", Row: 0, Col: 0}},
+	}
+
+	//A mirror match grinds both sides down to the last unit
+	close := Scenario{
+		Name:       "Mirror",
+		Iterations: 20,
+		Attacker: []ScenarioUnit{
+			{Template: "Militia", Row: 0, Col: 0},
+			{Template: "Militia", Row: 0, Col: 1},
+			{Template: "Militia", Row: 0, Col: 2},
+		},
+		Defender: []ScenarioUnit{
+			{Template: "Militia", Row: 0, Col: 0},
+			{Template: "Militia", Row: 0, Col: 1},
+			{Template: "Militia", Row: 0, Col: 2},
+		},
+	}
+
+	lopsidedResult, err := sim.RunScenario(lopsided)
+	if err != nil {
+		t.Fatalf("lopsided scenario failed: %v", err)
+	}
+	closeResult, err := sim.RunScenario(close)
+	if err != nil {
+		t.Fatalf("close scenario failed: %v", err)
+	}
+
+	lopsidedMean := histogramMean(lopsidedResult.VictoryMargins)
+	closeMean := histogramMean(closeResult.VictoryMargins)
+
+	if lopsidedMean != 3 {
+		t.Errorf("three crushers should always survive a fodder battle intact, mean margin %f", lopsidedMean)
+	}
+	if closeMean >= lopsidedMean {
+		t.Errorf("a mirror match should produce slimmer margins than a stomp: %f vs %f", closeMean, lopsidedMean)
+	}
+}
+
+func TestBuildVictoryMarginHistogramCounts(t *testing.T) {
+
+	samples := []IterationOutcome{
+		{WinnerSurvivors: 3}, {WinnerSurvivors: 3}, {WinnerSurvivors: 1},
+	}
+
+	h := BuildVictoryMarginHistogram(samples)
+
+	if h[3] != 2 || h[1] != 1 {
+		t.Errorf("unexpected histogram %v", h)
+	}
+}
+
+func TestFormatBalanceReportRendersHistogram(t *testing.T) {
+
+	var buf bytes.Buffer
+	FormatBalanceReport(&buf, []SimulationResult{{
+		ScenarioName:   "Sample",
+		Iterations:     3,
+		VictoryMargins: map[int]int{1: 1, 3: 2},
+	}})
+
+	if !strings.Contains(buf.String(), "victory margins") {
+		t.Errorf("expected the report to include the margin histogram, got:\n%s", buf.String())
+	}
+}
@@ -0,0 +1,148 @@
+package simulation
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"game_main/squads"
+)
+
+// Promoting a recorded battle into the scenario suite. When a generated
+// battle exposes a balance problem, ScenarioFromBattleRecord rebuilds both
+// sides from the records squad snapshots so the matchup can be rerun from a
+// scenario file instead of hand-transcribing the compositions
+
+// LoadBattleRecord reads one battle record from a JSON log file
+func LoadBattleRecord(path string) (*squads.BattleRecord, error) {
+
+	contents, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var record squads.BattleRecord
+	if err := json.Unmarshal(contents, &record); err != nil {
+		return nil, fmt.Errorf("battle log %s did not parse: %w", path, err)
+	}
+
+	return &record, nil
+}
+
+// ScenarioFromBattleRecord rebuilds the matchup a record was fought over:
+// both sides unit configs, the starting distance, and the environment. It
+// refuses records whose snapshots are missing or do not name templates,
+// since those cannot be reconstructed faithfully
+func ScenarioFromBattleRecord(record *squads.BattleRecord) (Scenario, error) {
+
+	if len(record.Attacker.Units) == 0 || len(record.Defender.Units) == 0 {
+		return Scenario{}, fmt.Errorf("the record has no squad snapshots to rebuild from")
+	}
+
+	attacker, err := scenarioUnitsFromSnapshot(record.Attacker)
+	if err != nil {
+		return Scenario{}, err
+	}
+	defender, err := scenarioUnitsFromSnapshot(record.Defender)
+	if err != nil {
+		return Scenario{}, err
+	}
+
+	name := fmt.Sprintf("%s vs %s", record.Attacker.Name, record.Defender.Name)
+	if record.Attacker.Name == "" || record.Defender.Name == "" {
+		name = "imported battle"
+	}
+
+	scenario := Scenario{
+		Name:       name,
+		Iterations: 1,
+		Attacker:   attacker,
+		Defender:   defender,
+		Distance:   record.Attacker.MapPos.SquadDistance(&record.Defender.MapPos),
+		Phase:      record.Environment.Phase,
+		Weather:    record.Environment.Weather,
+	}
+
+	return scenario, nil
+}
+
+// ScenarioFileFromBattleRecord wraps the imported scenario into a complete
+// scenario file, carrying the unit templates along so the file stands alone.
+// Registered templates are copied exactly; a template the registry no longer
+// knows is rebuilt from the stats the snapshot recorded
+func ScenarioFileFromBattleRecord(record *squads.BattleRecord) (ScenarioFile, error) {
+
+	scenario, err := ScenarioFromBattleRecord(record)
+	if err != nil {
+		return ScenarioFile{}, err
+	}
+
+	templates := []ScenarioTemplate{}
+	seen := map[string]bool{}
+
+	for _, snapshot := range []squads.SquadSnapshot{record.Attacker, record.Defender} {
+		for _, unit := range snapshot.Units {
+
+			if seen[unit.TemplateName] {
+				continue
+			}
+			seen[unit.TemplateName] = true
+
+			templates = append(templates, templateForSnapshotUnit(unit))
+
+		}
+	}
+
+	return ScenarioFile{Templates: templates, Scenarios: []Scenario{scenario}}, nil
+}
+
+func scenarioUnitsFromSnapshot(snapshot squads.SquadSnapshot) ([]ScenarioUnit, error) {
+
+	units := make([]ScenarioUnit, 0, len(snapshot.Units))
+	for _, u := range snapshot.Units {
+
+		if u.TemplateName == "" {
+			return nil, fmt.Errorf("squad %s has units without template names; the log is too terse to import", snapshot.Name)
+		}
+
+		units = append(units, ScenarioUnit{Template: u.TemplateName, Row: u.Row, Col: u.Col})
+
+	}
+
+	return units, nil
+}
+
+// Prefer the live registry, which still has the stats a snapshot does not
+// record, and fall back to the snapshots own numbers otherwise
+func templateForSnapshotUnit(unit squads.UnitSnapshot) ScenarioTemplate {
+
+	if t, ok := squads.UnitTemplateByName(unit.TemplateName); ok {
+
+		return ScenarioTemplate{
+			Name:          t.Name,
+			Class:         t.Class,
+			MaxHealth:     t.Attr.MaxHealth,
+			AttackBonus:   t.Attr.AttackBonus,
+			ArmorClass:    t.Attr.BaseArmorClass,
+			Protection:    t.Attr.BaseProtection,
+			MovementSpeed: t.Attr.BaseMovementSpeed,
+			DodgeChance:   t.Attr.BaseDodgeChance,
+			MinDamage:     t.MinDamage,
+			MaxDamage:     t.MaxDamage,
+			CoverValue:    t.CoverValue,
+			BackstabBonus: t.BackstabBonus,
+			Focus:         t.Attr.Focus,
+			Cost:          t.Cost,
+		}
+
+	}
+
+	return ScenarioTemplate{
+		Name:       unit.TemplateName,
+		Class:      unit.Class,
+		MaxHealth:  unit.MaxHealth,
+		Protection: unit.Protection,
+		MinDamage:  unit.MinDamage,
+		MaxDamage:  unit.MaxDamage,
+	}
+}
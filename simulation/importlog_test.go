@@ -0,0 +1,107 @@
+package simulation
+
+import (
+	"game_main/common"
+	"game_main/randgen"
+	"game_main/squads"
+	"testing"
+)
+
+func importTestTemplates() {
+
+	squads.RegisterUnitTemplate(squads.UnitTemplate{
+		Name:      "ImpKnight",
+		Class:     "Knight",
+		Attr:      common.NewBaseAttributes(18, 0, 10, 2, 1, 0),
+		MinDamage: 4,
+		MaxDamage: 6,
+	})
+
+	squads.RegisterUnitTemplate(squads.UnitTemplate{
+		Name:          "ImpRogue",
+		Class:         "Rogue",
+		Attr:          common.NewBaseAttributes(10, 0, 10, 0, 1, 0.2),
+		MinDamage:     3,
+		MaxDamage:     5,
+		BackstabBonus: 4,
+	})
+
+}
+
+// Fight a battle, promote its record into a scenario, and rerun the scenario
+// under the recorded seed. Victor and round count must reproduce
+func TestImportedScenarioReproducesTheBattle(t *testing.T) {
+
+	importTestTemplates()
+
+	randgen.Seed(929)
+	defer randgen.ClearSeed()
+
+	em := newSimTestManager()
+	att := squads.CreateSquad(em, "Raiders", "Player", common.Position{X: 5, Y: 3}, squads.DirDown)
+	def := squads.CreateSquad(em, "Garrison", "Enemy", common.Position{X: 5, Y: 5}, squads.DirUp)
+
+	squads.CreateUnitFromTemplate(em, squads.Units["ImpKnight"], att.SquadID, 0, 0)
+	squads.CreateUnitFromTemplate(em, squads.Units["ImpRogue"], att.SquadID, 1, 1)
+	squads.CreateUnitFromTemplate(em, squads.Units["ImpKnight"], def.SquadID, 0, 0)
+	squads.CreateUnitFromTemplate(em, squads.Units["ImpKnight"], def.SquadID, 0, 1)
+
+	sim := NewSimulator()
+	_, record := sim.RunBattle(em, att.SquadID, def.SquadID)
+
+	file, err := ScenarioFileFromBattleRecord(record)
+	if err != nil {
+		t.Fatalf("converting the record failed: %v", err)
+	}
+
+	scenario := file.Scenarios[0]
+	if scenario.Distance != 2 {
+		t.Errorf("expected the starting distance of 2 to carry over, got %d", scenario.Distance)
+	}
+	if len(scenario.Attacker) != 2 || len(scenario.Defender) != 2 {
+		t.Fatalf("expected 2 units per side, got %d and %d", len(scenario.Attacker), len(scenario.Defender))
+	}
+
+	//The registry still knows ImpRogue, so the carried template keeps the
+	//stats the snapshot alone would have lost
+	for _, template := range file.Templates {
+		if template.Name == "ImpRogue" && template.DodgeChance != 0.2 {
+			t.Errorf("expected the rogue template to keep its dodge chance, got %v", template.DodgeChance)
+		}
+	}
+
+	RegisterScenarioTemplates(file.Templates)
+
+	randgen.Seed(929)
+	outcome, rerun, err := sim.runScenarioOnce(scenario)
+	if err != nil {
+		t.Fatalf("running the imported scenario failed: %v", err)
+	}
+
+	if rerun.Rounds != record.Rounds {
+		t.Errorf("expected the rerun to last %d rounds, got %d", record.Rounds, rerun.Rounds)
+	}
+
+	attackerWonOriginal := record.WinnerSquadID == record.AttackerSquadID
+	if outcome.AttackerWon != attackerWonOriginal {
+		t.Errorf("expected attacker won = %v, got %v", attackerWonOriginal, outcome.AttackerWon)
+	}
+}
+
+// Records without snapshots, or with snapshots that do not name templates,
+// cannot be reconstructed and must be refused
+func TestImportRefusesRecordsMissingDetail(t *testing.T) {
+
+	if _, err := ScenarioFromBattleRecord(&squads.BattleRecord{}); err == nil {
+		t.Error("a record without snapshots must be refused")
+	}
+
+	record := &squads.BattleRecord{
+		Attacker: squads.SquadSnapshot{Name: "Nameless", Units: []squads.UnitSnapshot{{Class: "Knight"}}},
+		Defender: squads.SquadSnapshot{Name: "Foes", Units: []squads.UnitSnapshot{{TemplateName: "ImpKnight"}}},
+	}
+
+	if _, err := ScenarioFromBattleRecord(record); err == nil {
+		t.Error("a record whose units lack template names must be refused")
+	}
+}
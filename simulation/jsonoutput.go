@@ -0,0 +1,87 @@
+package simulation
+
+import (
+	"encoding/json"
+	"io"
+	"time"
+)
+
+// The machine interface behind combatsims -json-output flag. External
+// orchestration (notebooks, sweep scripts) parses stdout, so in this mode the
+// text formatters are never invoked and stdout only ever carries JSON.
+// Errors go to stderr as JSON too
+
+// JSONSchemaVersion is bumped whenever the shape of the emitted documents
+// changes, so external parsers can detect incompatible output
+const JSONSchemaVersion = 1
+
+// JSONReport is the single document emitted in the default JSON mode.
+// Todo: randgen is backed by crypto/rand and cannot be seeded yet. The seed is
+// still recorded so runs can be correlated once the generator becomes seedable
+type JSONReport struct {
+	SchemaVersion int                `json:"schemaVersion"`
+	Seed          int64              `json:"seed"`
+	ElapsedMS     int64              `json:"elapsedMs"`
+	Results       []SimulationResult `json:"results"`
+}
+
+// JSONStreamLine is one NDJSON line in -stream mode, emitted as each scenario
+// finishes so long sweeps can be consumed incrementally
+type JSONStreamLine struct {
+	SchemaVersion int              `json:"schemaVersion"`
+	Seed          int64            `json:"seed"`
+	Result        SimulationResult `json:"result"`
+}
+
+type JSONError struct {
+	Error    string `json:"error"`
+	Scenario string `json:"scenario,omitempty"`
+}
+
+// RunScenariosJSON is the library entry point for JSON mode. Results go to
+// stdout, errors to stderr, both as JSON
+func RunScenariosJSON(stdout io.Writer, stderr io.Writer, file ScenarioFile, seed int64, stream bool) error {
+
+	RegisterScenarioTemplates(file.Templates)
+
+	sim := NewSimulator()
+	started := time.Now()
+
+	results := make([]SimulationResult, 0, len(file.Scenarios))
+	for _, scenario := range file.Scenarios {
+
+		result, err := sim.RunScenario(scenario)
+		if err != nil {
+			writeJSONError(stderr, err, scenario.Name)
+			return err
+		}
+
+		if stream {
+			line := JSONStreamLine{SchemaVersion: JSONSchemaVersion, Seed: seed, Result: result}
+			if err := json.NewEncoder(stdout).Encode(line); err != nil {
+				return err
+			}
+			continue
+		}
+
+		results = append(results, result)
+
+	}
+
+	if stream {
+		return nil
+	}
+
+	report := JSONReport{
+		SchemaVersion: JSONSchemaVersion,
+		Seed:          seed,
+		ElapsedMS:     time.Since(started).Milliseconds(),
+		Results:       results,
+	}
+
+	return json.NewEncoder(stdout).Encode(report)
+}
+
+func writeJSONError(w io.Writer, err error, scenario string) {
+	json.NewEncoder(w).Encode(JSONError{Error: err.Error(), Scenario: scenario})
+}
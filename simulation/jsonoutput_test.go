@@ -0,0 +1,127 @@
+package simulation
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func testScenarioFile() ScenarioFile {
+
+	return ScenarioFile{
+		Templates: []ScenarioTemplate{
+			{Name: "Brute", MaxHealth: 30, Protection: 2, MinDamage: 6, MaxDamage: 6},
+			{Name: "Peasant", MaxHealth: 5, MinDamage: 1, MaxDamage: 1},
+		},
+		Scenarios: []Scenario{
+			{
+				Name:       "BruteVsPeasant",
+				Iterations: 3,
+				Attacker:   []ScenarioUnit{{Template: "Brute", Row: 0, Col: 0}},
+				Defender:   []ScenarioUnit{{Template: "Peasant", Row: 0, Col: 0}},
+			},
+			{
+				Name:       "PeasantMirror",
+				Iterations: 2,
+				Attacker:   []ScenarioUnit{{Template: "Peasant", Row: 0, Col: 0}},
+				Defender:   []ScenarioUnit{{Template: "Peasant", Row: 0, Col: 0}},
+			},
+		},
+	}
+}
+
+// The JSON document on stdout must round-trip back into the exported structs
+func TestJSONOutputRoundTrips(t *testing.T) {
+
+	var stdout, stderr bytes.Buffer
+	if err := RunScenariosJSON(&stdout, &stderr, testScenarioFile(), 42, false); err != nil {
+		t.Fatalf("JSON mode failed: %v", err)
+	}
+
+	if stderr.Len() != 0 {
+		t.Errorf("expected nothing on stderr, got %q", stderr.String())
+	}
+
+	var report JSONReport
+	if err := json.Unmarshal(stdout.Bytes(), &report); err != nil {
+		t.Fatalf("stdout was not a single parseable JSON document: %v", err)
+	}
+
+	if report.SchemaVersion != JSONSchemaVersion {
+		t.Errorf("expected schema version %d, got %d", JSONSchemaVersion, report.SchemaVersion)
+	}
+	if report.Seed != 42 {
+		t.Errorf("expected the seed to be recorded, got %d", report.Seed)
+	}
+	if len(report.Results) != 2 {
+		t.Fatalf("expected 2 scenario results, got %d", len(report.Results))
+	}
+
+	brute := report.Results[0]
+	if brute.ScenarioName != "BruteVsPeasant" || brute.Iterations != 3 {
+		t.Errorf("scenario name and iterations did not round-trip: %+v", brute)
+	}
+	if brute.AttackerWins != 3 {
+		t.Errorf("the brute should win every iteration, got %d wins", brute.AttackerWins)
+	}
+}
+
+// Stream mode emits one parseable line per scenario
+func TestJSONStreamMode(t *testing.T) {
+
+	var stdout, stderr bytes.Buffer
+	if err := RunScenariosJSON(&stdout, &stderr, testScenarioFile(), 7, true); err != nil {
+		t.Fatalf("stream mode failed: %v", err)
+	}
+
+	lines := 0
+	scanner := bufio.NewScanner(&stdout)
+	for scanner.Scan() {
+
+		var line JSONStreamLine
+		if err := json.Unmarshal(scanner.Bytes(), &line); err != nil {
+			t.Fatalf("NDJSON line did not parse: %v", err)
+		}
+
+		if line.SchemaVersion != JSONSchemaVersion || line.Seed != 7 {
+			t.Errorf("stream line missing schema version or seed: %+v", line)
+		}
+
+		lines++
+
+	}
+
+	if lines != 2 {
+		t.Errorf("expected one line per scenario, got %d", lines)
+	}
+}
+
+// A bad scenario produces a JSON error on stderr and nothing on stdout
+func TestJSONErrorsGoToStderr(t *testing.T) {
+
+	file := ScenarioFile{
+		Scenarios: []Scenario{
+			{Name: "Broken", Attacker: []ScenarioUnit{{Template: "NoSuchUnit"}}},
+		},
+	}
+
+	var stdout, stderr bytes.Buffer
+	if err := RunScenariosJSON(&stdout, &stderr, file, 0, false); err == nil {
+		t.Fatal("expected an error for the unknown template")
+	}
+
+	if stdout.Len() != 0 {
+		t.Errorf("stdout must stay clean on error, got %q", stdout.String())
+	}
+
+	var jsonErr JSONError
+	if err := json.Unmarshal(stderr.Bytes(), &jsonErr); err != nil {
+		t.Fatalf("stderr was not JSON: %v", err)
+	}
+
+	if jsonErr.Scenario != "Broken" || !strings.Contains(jsonErr.Error, "NoSuchUnit") {
+		t.Errorf("expected the error to name the scenario and template, got %+v", jsonErr)
+	}
+}
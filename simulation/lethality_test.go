@@ -0,0 +1,63 @@
+package simulation
+
+import (
+	"testing"
+
+	"game_main/common"
+	"game_main/squads"
+)
+
+// Two identical tanks trading fixed 2 damage, so rounds-to-victory is a
+// clean function of the lethality multiplier
+func lethalityRounds(s *Simulator) int {
+
+	squads.Units["LethalityTank"] = squads.UnitTemplate{
+		Name:      "LethalityTank",
+		Class:     "Knight",
+		Attr:      common.NewBaseAttributes(40, 0, 10, 0, 1, 0),
+		MinDamage: 2,
+		MaxDamage: 2,
+	}
+
+	em := newBattleManager()
+
+	att := squads.CreateSquad(em, "Attackers", "Attacker", common.Position{X: 5, Y: 3}, squads.DirDown)
+	def := squads.CreateSquad(em, "Defenders", "Defender", common.Position{X: 5, Y: 5}, squads.DirUp)
+	squads.CreateUnitFromTemplate(em, squads.Units["LethalityTank"], att.SquadID, 0, 0)
+	squads.CreateUnitFromTemplate(em, squads.Units["LethalityTank"], def.SquadID, 0, 0)
+
+	outcome, _ := s.RunBattle(em, att.SquadID, def.SquadID)
+	return outcome.Rounds
+}
+
+// Doubling lethality roughly halves rounds-to-victory, and 1.0 changes
+// nothing. Bleed is off for the measurement so battle length is a pure
+// function of the per-hit damage
+func TestLethalityMultiplierScalesBattleLength(t *testing.T) {
+
+	sim := NewSimulator()
+
+	restore := squads.Lethality
+	restoreBleed := squads.Bleed
+	squads.Bleed.DamagePerStack = 0
+	defer func() {
+		squads.Lethality = restore
+		squads.Bleed = restoreBleed
+	}()
+
+	squads.Lethality.Multiplier = 1.0
+	baseline := lethalityRounds(sim)
+
+	squads.Lethality = restore
+	if got := lethalityRounds(sim); got != baseline {
+		t.Errorf("a 1.0 multiplier should change nothing, got %d rounds against %d", got, baseline)
+	}
+
+	squads.Lethality.Multiplier = 2.0
+	doubled := lethalityRounds(sim)
+
+	if doubled < baseline/2-1 || doubled > baseline/2+1 {
+		t.Errorf("doubled lethality should roughly halve the %d baseline rounds, got %d", baseline, doubled)
+	}
+
+}
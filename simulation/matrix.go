@@ -0,0 +1,109 @@
+package simulation
+
+import (
+	"fmt"
+
+	"game_main/common"
+	"game_main/squads"
+)
+
+// The outcome probability matrix pits every template of a roster against
+// every other in 1v1 squad battles and tabulates attacker win rates. A
+// glance at a row shows which matchups a template dominates, and the
+// diagonal doubles as a sanity check: a mirror match should hover near 50%
+
+type WinRateMatrix struct {
+	//Row and column order, attacker by row and defender by column
+	Templates []string
+
+	//WinRates[i][j] is how often Templates[i] beats Templates[j] as the
+	//attacker
+	WinRates [][]float64
+
+	Iterations int
+
+	//Optional per-cell battle counts for aggregations that do not run every
+	//matchup the same number of times. Nil means every cell ran Iterations
+	Samples [][]int
+}
+
+// BuildWinRateMatrix fights every template pairing for the given iteration
+// count. Every battle runs in a fresh world, so cells cannot leak into each
+// other
+func (s *Simulator) BuildWinRateMatrix(templates []string, iterations int) (WinRateMatrix, error) {
+
+	if iterations <= 0 {
+		iterations = 1
+	}
+
+	for _, name := range templates {
+		if _, ok := squads.UnitTemplateByName(name); !ok {
+			return WinRateMatrix{}, fmt.Errorf("matrix references unknown template %s", name)
+		}
+	}
+
+	matrix := WinRateMatrix{Templates: templates, Iterations: iterations}
+
+	for _, attacker := range templates {
+
+		row := make([]float64, 0, len(templates))
+		for _, defender := range templates {
+			row = append(row, s.duelWinRate(attacker, defender, iterations))
+		}
+
+		matrix.WinRates = append(matrix.WinRates, row)
+
+	}
+
+	return matrix, nil
+}
+
+func (s *Simulator) duelWinRate(attackerTemplate string, defenderTemplate string, iterations int) float64 {
+
+	wins := 0
+	for i := 0; i < iterations; i++ {
+
+		em := newBattleManager()
+
+		att := squads.CreateSquad(em, attackerTemplate, "Attacker", common.Position{X: 5, Y: 3}, squads.DirDown)
+		def := squads.CreateSquad(em, defenderTemplate, "Defender", common.Position{X: 5, Y: 5}, squads.DirUp)
+
+		attTemplate, _ := squads.UnitTemplateByName(attackerTemplate)
+		defTemplate, _ := squads.UnitTemplateByName(defenderTemplate)
+		squads.CreateUnitFromTemplate(em, attTemplate, att.SquadID, 0, 0)
+		squads.CreateUnitFromTemplate(em, defTemplate, def.SquadID, 0, 0)
+
+		outcome, _ := s.RunBattle(em, att.SquadID, def.SquadID)
+		if outcome.AttackerWon {
+			wins++
+		}
+
+	}
+
+	return float64(wins) / float64(iterations)
+}
+
+// Report shapes the matrix for the export sinks: one row per attacker, the
+// defenders across the columns
+func (m WinRateMatrix) Report() Report {
+
+	columns := append([]string{"attacker"}, m.Templates...)
+
+	rows := [][]string{}
+	for i, attacker := range m.Templates {
+
+		row := []string{attacker}
+		for j := range m.Templates {
+			row = append(row, fmt.Sprintf("%.3f", m.WinRates[i][j]))
+		}
+		rows = append(rows, row)
+
+	}
+
+	return Report{
+		Meta: ReportMeta{Title: "win rate matrix", Scenarios: len(m.Templates) * len(m.Templates)},
+		Sections: []ReportSection{
+			{Name: "winRates", Columns: columns, Rows: rows},
+		},
+	}
+}
@@ -0,0 +1,87 @@
+package simulation
+
+import (
+	"game_main/common"
+	"game_main/randgen"
+	"game_main/squads"
+	"strings"
+	"testing"
+)
+
+// Two evenly matched duelists with enough dodge that either side can win
+func registerMatrixTemplates() {
+
+	for _, name := range []string{"MatrixSword", "MatrixAxe"} {
+		squads.Units[name] = squads.UnitTemplate{
+			Name:      name,
+			Class:     "Knight",
+			Attr:      common.NewBaseAttributes(12, 0, 10, 0, 1, 0.3),
+			MinDamage: 3,
+			MaxDamage: 5,
+		}
+	}
+
+}
+
+// A two-template roster builds a 2x2 matrix whose mirror matches sit near
+// 50% and whose off-diagonal pairs sum to roughly one
+func TestWinRateMatrixShape(t *testing.T) {
+
+	registerMatrixTemplates()
+
+	randgen.Seed(4242)
+	defer randgen.ClearSeed()
+
+	sim := NewSimulator()
+	matrix, err := sim.BuildWinRateMatrix([]string{"MatrixSword", "MatrixAxe"}, 200)
+	if err != nil {
+		t.Fatalf("building the matrix failed: %v", err)
+	}
+
+	if len(matrix.WinRates) != 2 || len(matrix.WinRates[0]) != 2 {
+		t.Fatalf("expected a 2x2 matrix, got %v", matrix.WinRates)
+	}
+
+	for i := 0; i < 2; i++ {
+		if diag := matrix.WinRates[i][i]; diag < 0.35 || diag > 0.65 {
+			t.Errorf("mirror match %s should sit near 50%%, got %.3f", matrix.Templates[i], diag)
+		}
+	}
+
+	//The same pairing from both seats: the matchup is symmetric up to the
+	//noise of separately seeded iteration batches
+	pairSum := matrix.WinRates[0][1] + matrix.WinRates[1][0]
+	if pairSum < 0.8 || pairSum > 1.2 {
+		t.Errorf("off-diagonal entries should sum to roughly 1, got %.3f", pairSum)
+	}
+}
+
+func TestWinRateMatrixRejectsUnknownTemplates(t *testing.T) {
+
+	sim := NewSimulator()
+	if _, err := sim.BuildWinRateMatrix([]string{"NoSuchTemplate"}, 10); err == nil {
+		t.Error("an unknown roster entry should fail the build")
+	}
+}
+
+// The report view is one winRates section shaped attacker-by-defender
+func TestWinRateMatrixReportLayout(t *testing.T) {
+
+	matrix := WinRateMatrix{
+		Templates: []string{"A", "B"},
+		WinRates:  [][]float64{{0.5, 0.7}, {0.3, 0.5}},
+	}
+
+	report := matrix.Report()
+	if len(report.Sections) != 1 || report.Sections[0].Name != "winRates" {
+		t.Fatalf("expected one winRates section, got %+v", report.Sections)
+	}
+
+	section := report.Sections[0]
+	if strings.Join(section.Columns, ",") != "attacker,A,B" {
+		t.Errorf("expected defender columns after the attacker column, got %v", section.Columns)
+	}
+	if section.Rows[0][2] != "0.700" {
+		t.Errorf("expected cell (A,B) to read 0.700, got %s", section.Rows[0][2])
+	}
+}
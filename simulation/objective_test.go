@@ -0,0 +1,41 @@
+package simulation
+
+import (
+	"game_main/common"
+	"game_main/squads"
+	"testing"
+)
+
+// Two grinders that would slug for many rounds, except the attacker starts
+// on an objective: the battle ends the round the hold completes, with the
+// objective outcome in the record
+func TestHoldingTheObjectiveEndsTheBattle(t *testing.T) {
+
+	registerWaveTemplates()
+
+	em := newBattleManager()
+
+	att := squads.CreateSquad(em, "Holders", "Attacker", common.Position{X: 5, Y: 3}, squads.DirDown)
+	def := squads.CreateSquad(em, "Grinders", "Defender", common.Position{X: 5, Y: 5}, squads.DirUp)
+	squads.CreateUnitFromTemplate(em, squads.Units["WaveTank"], att.SquadID, 0, 0)
+	squads.CreateUnitFromTemplate(em, squads.Units["WaveTank"], def.SquadID, 0, 0)
+
+	squads.CreateObjective(em, "Supply Cache", att.MapPos, 3)
+
+	sim := NewSimulator()
+	outcome, record := sim.RunBattle(em, att.SquadID, def.SquadID)
+
+	if !outcome.AttackerWon {
+		t.Fatal("holding the objective to term should win the battle")
+	}
+	if outcome.Rounds != 3 {
+		t.Errorf("the battle should end the round the hold completes, got %d rounds", outcome.Rounds)
+	}
+
+	if record.ObjectiveVictor != "Attacker" {
+		t.Errorf("the record should name the objective victor, got %q", record.ObjectiveVictor)
+	}
+	if len(record.Objectives) != 1 || record.Objectives[0].HoldRounds < 3 {
+		t.Errorf("the record should carry the final objective state, got %+v", record.Objectives)
+	}
+}
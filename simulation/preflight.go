@@ -0,0 +1,134 @@
+package simulation
+
+import (
+	"fmt"
+	"game_main/squads"
+)
+
+// Pre-flight checks for scenarios. A misconfigured scenario - a side that
+// cannot land a hit, or one that only ever deals the chip-damage floor -
+// still runs to a clean looking 100%/0% result, and nobody notices until
+// thousands of iterations are already spent. ValidateScenario catches those
+// shapes up front and says exactly which side is inert and why
+
+// PreflightWarning flags one way a scenario is degenerate. Warnings don't
+// stop the run unless the simulator is in strict mode
+type PreflightWarning struct {
+	Scenario string
+	Side     string
+	Message  string
+}
+
+func (w PreflightWarning) String() string {
+	return fmt.Sprintf("scenario %s, %s side: %s", w.Scenario, w.Side, w.Message)
+}
+
+// ValidateScenario resolves both sides and checks that each can plausibly
+// hurt the other. Unknown templates and empty sides are hard errors; inert
+// sides come back as warnings
+func ValidateScenario(scenario Scenario) ([]PreflightWarning, error) {
+
+	attacker, err := resolveSideTemplates(scenario, "attacker", scenario.Attacker)
+	if err != nil {
+		return nil, err
+	}
+	defender, err := resolveSideTemplates(scenario, "defender", scenario.Defender)
+	if err != nil {
+		return nil, err
+	}
+
+	warnings := gridWarnings(scenario, "attacker", scenario.Attacker)
+	warnings = append(warnings, gridWarnings(scenario, "defender", scenario.Defender)...)
+	warnings = append(warnings, inertSideWarnings(scenario, "attacker", attacker, defender)...)
+	warnings = append(warnings, inertSideWarnings(scenario, "defender", defender, attacker)...)
+
+	return warnings, nil
+}
+
+func resolveSideTemplates(scenario Scenario, side string, units []ScenarioUnit) ([]squads.UnitTemplate, error) {
+
+	if len(units) == 0 {
+		return nil, fmt.Errorf("scenario %s has no %s units", scenario.Name, side)
+	}
+
+	templates := make([]squads.UnitTemplate, 0, len(units))
+	for _, u := range units {
+
+		template, ok := squads.UnitTemplateByName(u.Template)
+		if !ok {
+			return nil, fmt.Errorf("scenario %s references unknown template %s", scenario.Name, u.Template)
+		}
+		templates = append(templates, template)
+
+	}
+
+	return templates, nil
+}
+
+// A unit outside the 3x3 grid still gets created and still fights, which is
+// almost never what the author meant
+func gridWarnings(scenario Scenario, side string, units []ScenarioUnit) []PreflightWarning {
+
+	warnings := []PreflightWarning{}
+	for _, u := range units {
+
+		if u.Row < 0 || u.Row >= squads.GridHeight || u.Col < 0 || u.Col >= squads.GridWidth {
+			warnings = append(warnings, PreflightWarning{
+				Scenario: scenario.Name,
+				Side:     side,
+				Message:  fmt.Sprintf("unit %s sits outside the %dx%d grid at row %d, col %d", u.Template, squads.GridHeight, squads.GridWidth, u.Row, u.Col),
+			})
+		}
+
+	}
+
+	return warnings
+}
+
+// The two inert shapes: a side that cannot land a hit because every opposing
+// unit dodges everything, and a side whose hardest hit never beats the
+// toughest opposing protection, so it only ever deals the damage floor
+func inertSideWarnings(scenario Scenario, side string, own []squads.UnitTemplate, opposing []squads.UnitTemplate) []PreflightWarning {
+
+	warnings := []PreflightWarning{}
+
+	allDodge := true
+	for _, t := range opposing {
+		if t.Attr.BaseDodgeChance < 1 {
+			allDodge = false
+			break
+		}
+	}
+	if allDodge {
+		warnings = append(warnings, PreflightWarning{
+			Scenario: scenario.Name,
+			Side:     side,
+			Message:  "inert: every opposing unit dodges 100% of the time, so no attack ever lands",
+		})
+	}
+
+	bestDamage, bestName := 0, ""
+	for _, t := range own {
+		if t.MaxDamage > bestDamage || bestName == "" {
+			bestDamage, bestName = t.MaxDamage, t.Name
+		}
+	}
+
+	toughest, toughestName := 0, ""
+	for _, t := range opposing {
+		if t.Attr.BaseProtection > toughest || toughestName == "" {
+			toughest, toughestName = t.Attr.BaseProtection, t.Name
+		}
+	}
+
+	if bestDamage <= toughest {
+		warnings = append(warnings, PreflightWarning{
+			Scenario: scenario.Name,
+			Side:     side,
+			Message: fmt.Sprintf("inert: only chip damage, the hardest hit (%s for %d) never beats %s protection %d",
+				bestName, bestDamage, toughestName, toughest),
+		})
+	}
+
+	return warnings
+}
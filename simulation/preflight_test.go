@@ -0,0 +1,154 @@
+package simulation
+
+import (
+	"game_main/common"
+	"game_main/squads"
+	"strings"
+	"testing"
+)
+
+// Three templates that build the degenerate shapes: a bruiser, a wall no
+// bruiser hit can beat, and a ghost nothing ever touches
+func registerPreflightTemplates() {
+
+	squads.Units["PreflightBruiser"] = squads.UnitTemplate{
+		Name:      "PreflightBruiser",
+		Class:     "Knight",
+		Attr:      common.NewBaseAttributes(20, 0, 10, 0, 1, 0),
+		MinDamage: 3,
+		MaxDamage: 5,
+	}
+
+	squads.Units["PreflightWall"] = squads.UnitTemplate{
+		Name:      "PreflightWall",
+		Class:     "Knight",
+		Attr:      common.NewBaseAttributes(30, 0, 10, 8, 1, 0),
+		MinDamage: 3,
+		MaxDamage: 5,
+	}
+
+	squads.Units["PreflightGhost"] = squads.UnitTemplate{
+		Name:      "PreflightGhost",
+		Class:     "Rogue",
+		Attr:      common.NewBaseAttributes(10, 0, 10, 0, 1, 1.0),
+		MinDamage: 3,
+		MaxDamage: 5,
+	}
+
+}
+
+func preflightScenario(defenderTemplate string) Scenario {
+
+	return Scenario{
+		Name:     "Preflight",
+		Attacker: []ScenarioUnit{{Template: "PreflightBruiser", Row: 0, Col: 0}},
+		Defender: []ScenarioUnit{{Template: defenderTemplate, Row: 0, Col: 0}},
+	}
+}
+
+// A defender nothing ever hits makes the attacker side inert, with the
+// warning saying why
+func TestPreflightFlagsTheDodgeWall(t *testing.T) {
+
+	registerPreflightTemplates()
+
+	warnings, err := ValidateScenario(preflightScenario("PreflightGhost"))
+	if err != nil {
+		t.Fatalf("validation failed: %v", err)
+	}
+
+	if len(warnings) != 1 {
+		t.Fatalf("expected exactly the dodge warning, got %v", warnings)
+	}
+	if warnings[0].Side != "attacker" {
+		t.Errorf("the inert side is the attacker, got %q", warnings[0].Side)
+	}
+	if !strings.Contains(warnings[0].Message, "dodges 100% of the time") {
+		t.Errorf("the warning should name the dodge wall, got %q", warnings[0].Message)
+	}
+}
+
+// A wall the bruisers hardest hit cannot beat means the attacker only ever
+// deals the chip-damage floor
+func TestPreflightFlagsChipOnlyDamage(t *testing.T) {
+
+	registerPreflightTemplates()
+
+	warnings, err := ValidateScenario(preflightScenario("PreflightWall"))
+	if err != nil {
+		t.Fatalf("validation failed: %v", err)
+	}
+
+	if len(warnings) != 1 {
+		t.Fatalf("expected exactly the chip warning, got %v", warnings)
+	}
+	if !strings.Contains(warnings[0].Message, "only chip damage") ||
+		!strings.Contains(warnings[0].Message, "PreflightBruiser for 5") ||
+		!strings.Contains(warnings[0].Message, "PreflightWall protection 8") {
+		t.Errorf("the warning should spell out the matchup, got %q", warnings[0].Message)
+	}
+}
+
+func TestPreflightPassesAHealthyScenario(t *testing.T) {
+
+	registerPreflightTemplates()
+
+	warnings, err := ValidateScenario(preflightScenario("PreflightBruiser"))
+	if err != nil {
+		t.Fatalf("validation failed: %v", err)
+	}
+	if len(warnings) != 0 {
+		t.Errorf("a healthy scenario should produce no warnings, got %v", warnings)
+	}
+}
+
+func TestPreflightRejectsBrokenScenarios(t *testing.T) {
+
+	registerPreflightTemplates()
+
+	empty := preflightScenario("PreflightBruiser")
+	empty.Defender = nil
+	if _, err := ValidateScenario(empty); err == nil {
+		t.Error("a side with no units should be a hard error")
+	}
+
+	unknown := preflightScenario("NoSuchTemplate")
+	if _, err := ValidateScenario(unknown); err == nil {
+		t.Error("an unknown template should be a hard error")
+	}
+
+	offGrid := preflightScenario("PreflightBruiser")
+	offGrid.Attacker = append(offGrid.Attacker, ScenarioUnit{Template: "PreflightBruiser", Row: 5, Col: 0})
+	warnings, err := ValidateScenario(offGrid)
+	if err != nil {
+		t.Fatalf("validation failed: %v", err)
+	}
+	if len(warnings) != 1 || !strings.Contains(warnings[0].Message, "outside the 3x3 grid") {
+		t.Errorf("an off-grid unit should warn, got %v", warnings)
+	}
+}
+
+// Warnings ride along in the result normally, and fail the run in strict mode
+func TestPreflightWarningsReachTheResult(t *testing.T) {
+
+	registerPreflightTemplates()
+
+	scenario := preflightScenario("PreflightGhost")
+	scenario.Iterations = 1
+
+	sim := NewSimulator()
+	sim.MaxRounds = 3
+
+	result, err := sim.RunScenario(scenario)
+	if err != nil {
+		t.Fatalf("the non-strict run failed: %v", err)
+	}
+	if len(result.PreflightWarnings) != 1 || !strings.Contains(result.PreflightWarnings[0], "attacker side") {
+		t.Errorf("the result should carry the warning line, got %v", result.PreflightWarnings)
+	}
+
+	sim.StrictScenarios = true
+	if _, err := sim.RunScenario(scenario); err == nil {
+		t.Error("strict mode should fail a scenario with pre-flight warnings")
+	}
+}
@@ -0,0 +1,161 @@
+package simulation
+
+import (
+	"fmt"
+	"sort"
+)
+
+// The export pipeline. Every exporter used to walk the result structs on its
+// own, each with its own escaping bugs and missing sections. Now the results
+// normalize once into a Report - ordered sections of string rows - and every
+// output format is a ReportSink that consumes that one representation.
+// Escaping lives inside each sink, in exactly one place
+
+// ReportMeta is the run-level header every sink sees first
+type ReportMeta struct {
+	Title     string
+	Seed      int64
+	Scenarios int
+}
+
+// ReportSection is one named table of the report. Cells are plain strings;
+// whatever escaping a format needs happens in the sink, never here
+type ReportSection struct {
+	Name    string
+	Columns []string
+	Rows    [][]string
+}
+
+// ReportSink consumes a report traversal. Begin sees the meta, WriteSection
+// every section in order, and End flushes whatever the format buffers
+type ReportSink interface {
+	Begin(meta ReportMeta) error
+	WriteSection(section ReportSection) error
+	End() error
+}
+
+type Report struct {
+	Meta     ReportMeta
+	Sections []ReportSection
+}
+
+// WriteTo traverses the report once and feeds every sink as it goes, so
+// exporting to several formats does not re-walk anything
+func (r *Report) WriteTo(sinks ...ReportSink) error {
+
+	for _, sink := range sinks {
+		if err := sink.Begin(r.Meta); err != nil {
+			return err
+		}
+	}
+
+	for _, section := range r.Sections {
+		for _, sink := range sinks {
+			if err := sink.WriteSection(section); err != nil {
+				return err
+			}
+		}
+	}
+
+	for _, sink := range sinks {
+		if err := sink.End(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// BuildSimulationReport normalizes a batch of simulation results into the
+// report representation: one results section, then the victory margins and
+// survivability breakdowns for the scenarios that have them
+func BuildSimulationReport(title string, seed int64, results []SimulationResult) Report {
+
+	report := Report{Meta: ReportMeta{Title: title, Seed: seed, Scenarios: len(results)}}
+
+	resultRows := [][]string{}
+	for _, r := range results {
+		resultRows = append(resultRows, []string{
+			r.ScenarioName,
+			fmt.Sprintf("%d", r.Iterations),
+			fmt.Sprintf("%.4f", r.AttackerWinRate()),
+			fmt.Sprintf("%.2f", r.AvgRounds),
+			fmt.Sprintf("%.2f", r.StdDevRounds),
+			fmt.Sprintf("%.2f", r.AvgTotalDamage),
+			fmt.Sprintf("%.2f", r.StdDevTotalDamage),
+		})
+	}
+
+	report.Sections = append(report.Sections, ReportSection{
+		Name:    "results",
+		Columns: []string{"scenario", "iterations", "attackerWinRate", "avgRounds", "stdDevRounds", "avgTotalDamage", "stdDevTotalDamage"},
+		Rows:    resultRows,
+	})
+
+	if rows := victoryMarginRows(results); len(rows) > 0 {
+		report.Sections = append(report.Sections, ReportSection{
+			Name:    "victoryMargins",
+			Columns: []string{"scenario", "survivors", "battles"},
+			Rows:    rows,
+		})
+	}
+
+	if rows := survivabilityRows(results); len(rows) > 0 {
+		report.Sections = append(report.Sections, ReportSection{
+			Name:    "survivability",
+			Columns: []string{"scenario", "template", "attacksIncoming", "damageAvoided", "invalidationRate"},
+			Rows:    rows,
+		})
+	}
+
+	return report
+}
+
+func victoryMarginRows(results []SimulationResult) [][]string {
+
+	rows := [][]string{}
+	for _, r := range results {
+
+		survivors := make([]int, 0, len(r.VictoryMargins))
+		for s := range r.VictoryMargins {
+			survivors = append(survivors, s)
+		}
+		sort.Ints(survivors)
+
+		for _, s := range survivors {
+			rows = append(rows, []string{r.ScenarioName, fmt.Sprintf("%d", s), fmt.Sprintf("%d", r.VictoryMargins[s])})
+		}
+
+	}
+
+	return rows
+}
+
+func survivabilityRows(results []SimulationResult) [][]string {
+
+	rows := [][]string{}
+	for _, r := range results {
+
+		templates := make([]string, 0, len(r.Survivability))
+		for template := range r.Survivability {
+			templates = append(templates, template)
+		}
+		sort.Strings(templates)
+
+		for _, template := range templates {
+
+			s := r.Survivability[template]
+			rows = append(rows, []string{
+				r.ScenarioName,
+				s.Template,
+				fmt.Sprintf("%d", s.AttacksIncoming),
+				fmt.Sprintf("%d", s.DamageAvoided),
+				fmt.Sprintf("%.4f", s.InvalidationRate()),
+			})
+
+		}
+
+	}
+
+	return rows
+}
@@ -0,0 +1,140 @@
+package simulation
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+// A small report whose scenario name carries every character the sinks have
+// to escape: commas and quotes for CSV, angle brackets for HTML
+func hostileFixtureReport() Report {
+
+	results := []SimulationResult{
+		{
+			ScenarioName:   `Knights, "the <b>bold</b>"`,
+			Iterations:     10,
+			AttackerWins:   7,
+			AvgRounds:      4.5,
+			VictoryMargins: map[int]int{1: 3, 2: 7},
+			Survivability: map[string]SurvivabilityStats{
+				"Rogue": {Template: "Rogue", AttacksIncoming: 20, AttacksInvalidated: 5, DamageAvoided: 25},
+			},
+		},
+	}
+
+	return BuildSimulationReport("fixture run", 99, results)
+}
+
+// The builder produces all three sections, in order, with the rows intact
+func TestReportCarriesEverySection(t *testing.T) {
+
+	report := hostileFixtureReport()
+
+	names := []string{}
+	for _, section := range report.Sections {
+		names = append(names, section.Name)
+	}
+
+	want := []string{"results", "victoryMargins", "survivability"}
+	if strings.Join(names, ",") != strings.Join(want, ",") {
+		t.Fatalf("expected sections %v, got %v", want, names)
+	}
+
+	if len(report.Sections[1].Rows) != 2 {
+		t.Errorf("expected 2 victory margin rows, got %d", len(report.Sections[1].Rows))
+	}
+}
+
+// Several sinks attached to one run each see the complete report
+func TestMultipleSinksShareOneTraversal(t *testing.T) {
+
+	report := hostileFixtureReport()
+
+	var jsonOut, csvOut, htmlOut strings.Builder
+	err := report.WriteTo(NewJSONReportSink(&jsonOut), NewCSVReportSink(&csvOut), NewHTMLReportSink(&htmlOut))
+	if err != nil {
+		t.Fatalf("writing to three sinks failed: %v", err)
+	}
+
+	for name, out := range map[string]string{"json": jsonOut.String(), "csv": csvOut.String(), "html": htmlOut.String()} {
+		for _, section := range []string{"results", "victoryMargins", "survivability"} {
+			if !strings.Contains(out, section) {
+				t.Errorf("the %s sink is missing the %s section", name, section)
+			}
+		}
+	}
+}
+
+// The JSON sink emits a valid document with the hostile name untouched -
+// JSON escaping is the encoders business
+func TestJSONSinkSurvivesHostileNames(t *testing.T) {
+
+	report := hostileFixtureReport()
+	var out strings.Builder
+	if err := report.WriteTo(NewJSONReportSink(&out)); err != nil {
+		t.Fatalf("the JSON sink failed: %v", err)
+	}
+
+	var document struct {
+		Title    string `json:"title"`
+		Sections []struct {
+			Name string     `json:"name"`
+			Rows [][]string `json:"rows"`
+		} `json:"sections"`
+	}
+	if err := json.Unmarshal([]byte(out.String()), &document); err != nil {
+		t.Fatalf("the JSON sink produced an unparseable document: %v", err)
+	}
+
+	if document.Sections[0].Rows[0][0] != `Knights, "the <b>bold</b>"` {
+		t.Errorf("the hostile name should round-trip exactly, got %s", document.Sections[0].Rows[0][0])
+	}
+}
+
+// The CSV sink quotes commas and doubles quotes - proven by parsing it back
+func TestCSVSinkQuotesHostileNames(t *testing.T) {
+
+	report := hostileFixtureReport()
+	var out strings.Builder
+	if err := report.WriteTo(NewCSVReportSink(&out)); err != nil {
+		t.Fatalf("the CSV sink failed: %v", err)
+	}
+
+	reader := csv.NewReader(strings.NewReader(out.String()))
+	reader.FieldsPerRecord = -1
+
+	records, err := reader.ReadAll()
+	if err != nil {
+		t.Fatalf("the CSV sink produced unparseable output: %v", err)
+	}
+
+	found := false
+	for _, record := range records {
+		if len(record) > 0 && record[0] == `Knights, "the <b>bold</b>"` {
+			found = true
+		}
+	}
+	if !found {
+		t.Error("the hostile name should survive a CSV round trip intact")
+	}
+}
+
+// The HTML sink entity-escapes every cell, so no raw markup leaks through
+func TestHTMLSinkEscapesHostileNames(t *testing.T) {
+
+	report := hostileFixtureReport()
+	var out strings.Builder
+	if err := report.WriteTo(NewHTMLReportSink(&out)); err != nil {
+		t.Fatalf("the HTML sink failed: %v", err)
+	}
+
+	page := out.String()
+	if strings.Contains(page, "<b>bold</b>") {
+		t.Error("the hostile name leaked unescaped markup into the page")
+	}
+	if !strings.Contains(page, "&lt;b&gt;bold&lt;/b&gt;") {
+		t.Error("expected the hostile name entity-escaped in a table cell")
+	}
+}
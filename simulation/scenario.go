@@ -0,0 +1,320 @@
+package simulation
+
+import (
+	"encoding/json"
+	"fmt"
+	"game_main/common"
+	"game_main/randgen"
+	"game_main/squads"
+	"os"
+
+	"github.com/bytearena/ecs"
+)
+
+// Scenarios describe a squad versus squad matchup in JSON so balance sweeps
+// can be driven from data files instead of code changes. A scenario file
+// carries its own unit templates, which keeps the simulator independent of
+// the games monster data
+
+type ScenarioUnit struct {
+	Template string `json:"template"`
+	Row      int    `json:"row"`
+	Col      int    `json:"col"`
+
+	//Optional health override so scenarios can field pre-damaged units.
+	//0 means full health
+	StartingHealth int `json:"startingHealth,omitempty"`
+}
+
+// ScenarioTemplate is the JSON shape of a unit template. Flattened compared to
+// squads.UnitTemplate so scenario files don't need to spell out the whole
+// attribute struct
+type ScenarioTemplate struct {
+	Name          string  `json:"name"`
+	Class         string  `json:"class"`
+	MaxHealth     int     `json:"maxHealth"`
+	AttackBonus   int     `json:"attackBonus"`
+	ArmorClass    int     `json:"armorClass"`
+	Protection    int     `json:"protection"`
+	MovementSpeed int     `json:"movementSpeed"`
+	DodgeChance   float32 `json:"dodgeChance"`
+	MinDamage     int     `json:"minDamage"`
+	MaxDamage     int     `json:"maxDamage"`
+	CoverValue    int     `json:"coverValue"`
+	BackstabBonus int     `json:"backstabBonus"`
+	Focus         int     `json:"focus,omitempty"`
+	Cost          float64 `json:"cost,omitempty"`
+}
+
+type Scenario struct {
+	Name       string         `json:"name"`
+	Iterations int            `json:"iterations"`
+	Attacker   []ScenarioUnit `json:"attacker"`
+	Defender   []ScenarioUnit `json:"defender"`
+
+	//A side can reference a named formation preset instead of listing every
+	//unit. The loader expands these into the unit lists above
+	AttackerPreset *ScenarioPreset `json:"attackerPreset,omitempty"`
+	DefenderPreset *ScenarioPreset `json:"defenderPreset,omitempty"`
+
+	//Optional environmental conditions ("Night", "Rain") so weather effects
+	//can be tested in isolation. Empty means fair weather
+	Phase   string `json:"phase,omitempty"`
+	Weather string `json:"weather,omitempty"`
+
+	//Starting distance between the squads in tiles. 0 means the default of 2
+	Distance int `json:"distance,omitempty"`
+
+	//Free-form labels ("melee", "regression", "slow") so the CLIs can run a
+	//slice of a large suite. Filtering lives in tags.go
+	Tags []string `json:"tags,omitempty"`
+
+	//Timed reinforcements for endurance scenarios: each wave joins its side
+	//at the scheduled round. Resolution lives in waves.go
+	Waves []ReinforcementWave `json:"waves,omitempty"`
+}
+
+// ScenarioPreset fills a named formation layout with one template
+type ScenarioPreset struct {
+	Formation string `json:"formation"`
+	Template  string `json:"template"`
+}
+
+// The named formation layouts, as (row, col) grid cells:
+//
+//	turtle:   a shield wall across the whole front row
+//	wedge:    a point unit up front with two flankers behind
+//	column:   a single center column, front to back
+//	skirmish: a loose spread that avoids the front row
+var FormationPresets = map[string][][2]int{
+	"turtle":   {{0, 0}, {0, 1}, {0, 2}},
+	"wedge":    {{0, 1}, {1, 0}, {1, 2}},
+	"column":   {{0, 1}, {1, 1}, {2, 1}},
+	"skirmish": {{1, 0}, {1, 2}, {2, 1}},
+}
+
+// ExpandPreset turns a preset reference into the unit configs it stands for
+func ExpandPreset(preset ScenarioPreset) ([]ScenarioUnit, error) {
+
+	layout, ok := FormationPresets[preset.Formation]
+	if !ok {
+		return nil, fmt.Errorf("unknown formation preset %q", preset.Formation)
+	}
+
+	units := make([]ScenarioUnit, 0, len(layout))
+	for _, cell := range layout {
+		units = append(units, ScenarioUnit{Template: preset.Template, Row: cell[0], Col: cell[1]})
+	}
+
+	return units, nil
+}
+
+// Presets expand at load time so everything downstream only ever sees plain
+// unit lists
+func expandFormationPresets(file *ScenarioFile) error {
+
+	for i := range file.Scenarios {
+
+		scenario := &file.Scenarios[i]
+
+		if scenario.AttackerPreset != nil {
+
+			units, err := ExpandPreset(*scenario.AttackerPreset)
+			if err != nil {
+				return fmt.Errorf("scenario %s attacker: %w", scenario.Name, err)
+			}
+			scenario.Attacker = append(scenario.Attacker, units...)
+
+		}
+
+		if scenario.DefenderPreset != nil {
+
+			units, err := ExpandPreset(*scenario.DefenderPreset)
+			if err != nil {
+				return fmt.Errorf("scenario %s defender: %w", scenario.Name, err)
+			}
+			scenario.Defender = append(scenario.Defender, units...)
+
+		}
+
+	}
+
+	return nil
+}
+
+type ScenarioFile struct {
+	Templates []ScenarioTemplate `json:"templates"`
+	Scenarios []Scenario         `json:"scenarios"`
+}
+
+func LoadScenarioFile(path string) (ScenarioFile, error) {
+
+	contents, err := os.ReadFile(path)
+	if err != nil {
+		return ScenarioFile{}, err
+	}
+
+	var file ScenarioFile
+	if err := json.Unmarshal(contents, &file); err != nil {
+		return ScenarioFile{}, fmt.Errorf("scenario file %s did not parse: %w", path, err)
+	}
+
+	if err := expandFormationPresets(&file); err != nil {
+		return ScenarioFile{}, err
+	}
+
+	return file, nil
+}
+
+// RegisterScenarioTemplates adds the scenario files templates to the shared
+// template map so CreateUnitFromTemplate can find them
+func RegisterScenarioTemplates(templates []ScenarioTemplate) {
+
+	for _, t := range templates {
+
+		attr := common.NewBaseAttributes(t.MaxHealth, t.AttackBonus, t.ArmorClass, t.Protection, t.MovementSpeed, t.DodgeChance)
+		attr.Focus = t.Focus
+
+		squads.RegisterUnitTemplate(squads.UnitTemplate{
+			Name:          t.Name,
+			Class:         t.Class,
+			Attr:          attr,
+			MinDamage:     t.MinDamage,
+			MaxDamage:     t.MaxDamage,
+			CoverValue:    t.CoverValue,
+			BackstabBonus: t.BackstabBonus,
+			Cost:          t.Cost,
+		})
+
+	}
+
+}
+
+// Each iteration fights in a fresh ECS world so iterations can't leak state
+// into each other
+func newBattleManager() *common.EntityManager {
+
+	manager := ecs.NewManager()
+	tags := make(map[string]ecs.Tag)
+
+	common.PositionComponent = manager.NewComponent()
+	common.NameComponent = manager.NewComponent()
+	common.AttributeComponent = manager.NewComponent()
+
+	squads.InitializeSquadComponents(manager, tags)
+
+	return &common.EntityManager{World: manager, WorldTags: tags}
+}
+
+// RunScenario fights one scenario for its configured iteration count and
+// aggregates the outcomes. The attacker starts above the defender, attacking
+// into the defenders front
+func (s *Simulator) RunScenario(scenario Scenario) (SimulationResult, error) {
+
+	preflight, err := ValidateScenario(scenario)
+	if err != nil {
+		return SimulationResult{}, err
+	}
+	if s.StrictScenarios && len(preflight) > 0 {
+		return SimulationResult{}, fmt.Errorf("scenario failed pre-flight: %s", preflight[0])
+	}
+
+	iterations := scenario.Iterations
+	if iterations <= 0 {
+		iterations = 1
+	}
+
+	outcomes := make([]IterationOutcome, 0, iterations)
+	records := make([]*squads.BattleRecord, 0, iterations)
+
+	for i := 0; i < iterations; i++ {
+
+		outcome, record, err := s.runScenarioOnce(scenario)
+		if err != nil {
+			return SimulationResult{}, err
+		}
+
+		outcomes = append(outcomes, outcome)
+		records = append(records, record)
+
+	}
+
+	result := s.Aggregate(scenario.Name, outcomes)
+	result.Survivability = ComputeSurvivability(records)
+	for _, warning := range preflight {
+		result.PreflightWarnings = append(result.PreflightWarnings, warning.String())
+	}
+
+	return result, nil
+}
+
+// One iteration of a scenario in a fresh world
+func (s *Simulator) runScenarioOnce(scenario Scenario) (IterationOutcome, *squads.BattleRecord, error) {
+
+	if scenario.Phase != "" || scenario.Weather != "" {
+		squads.ActiveEnvironment = squads.EnvironmentFor(scenario.Phase, scenario.Weather)
+		defer func() { squads.ActiveEnvironment = squads.BattleEnvironment{} }()
+	}
+
+	em := newBattleManager()
+
+	distance := scenario.Distance
+	if distance <= 0 {
+		distance = 2
+	}
+
+	att := squads.CreateSquad(em, scenario.Name+" attacker", "Attacker", common.Position{X: 5, Y: 5 - distance}, squads.DirDown)
+	def := squads.CreateSquad(em, scenario.Name+" defender", "Defender", common.Position{X: 5, Y: 5}, squads.DirUp)
+
+	if err := placeScenarioUnits(em, scenario.Attacker, att.SquadID); err != nil {
+		return IterationOutcome{}, nil, err
+	}
+	if err := placeScenarioUnits(em, scenario.Defender, def.SquadID); err != nil {
+		return IterationOutcome{}, nil, err
+	}
+
+	waves, err := scheduleWaves(scenario, att.SquadID, def.SquadID)
+	if err != nil {
+		return IterationOutcome{}, nil, err
+	}
+
+	outcome, record := s.runBattle(em, att.SquadID, def.SquadID, waves)
+	return outcome, record, nil
+}
+
+// ChecksumScenario fights a scenario once under the given seed and returns
+// the records combat checksum. CI runs a fixed scenario set through this and
+// compares against committed checksums to catch balance drift
+func (s *Simulator) ChecksumScenario(scenario Scenario, seed int64) (string, error) {
+
+	randgen.Seed(seed)
+	defer randgen.ClearSeed()
+
+	_, record, err := s.runScenarioOnce(scenario)
+	if err != nil {
+		return "", err
+	}
+
+	return squads.CombatChecksum(record), nil
+}
+
+func placeScenarioUnits(em *common.EntityManager, units []ScenarioUnit, squadID int) error {
+
+	for _, u := range units {
+
+		template, ok := squads.UnitTemplateByName(u.Template)
+		if !ok {
+			return fmt.Errorf("scenario references unknown template %s", u.Template)
+		}
+
+		unit := squads.CreateUnitFromTemplate(em, template, squadID, u.Row, u.Col)
+
+		if u.StartingHealth > 0 {
+			common.GetAttributes(unit).CurrentHealth = u.StartingHealth
+			squads.RefreshWoundedState(unit)
+		}
+
+	}
+
+	return nil
+}
@@ -0,0 +1,78 @@
+package simulation
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeScenarioFile(t *testing.T, contents string) string {
+
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "scenarios.json")
+	if err := os.WriteFile(path, []byte(contents), 0644); err != nil {
+		t.Fatalf("could not write scenario file: %v", err)
+	}
+
+	return path
+}
+
+// A side referencing the turtle preset loads with three units across the
+// front row, exactly as the preset documents
+func TestTurtlePresetExpandsAtLoadTime(t *testing.T) {
+
+	path := writeScenarioFile(t, `{
+		"templates": [{"name": "Shieldbearer", "maxHealth": 20}],
+		"scenarios": [{
+			"name": "TurtleTest",
+			"iterations": 1,
+			"attackerPreset": {"formation": "turtle", "template": "Shieldbearer"},
+			"defender": [{"template": "Shieldbearer", "row": 0, "col": 0}]
+		}]
+	}`)
+
+	file, err := LoadScenarioFile(path)
+	if err != nil {
+		t.Fatalf("loading a preset scenario failed: %v", err)
+	}
+
+	attacker := file.Scenarios[0].Attacker
+	if len(attacker) != 3 {
+		t.Fatalf("turtle should expand to 3 units, got %d", len(attacker))
+	}
+
+	for i, want := range [][2]int{{0, 0}, {0, 1}, {0, 2}} {
+
+		unit := attacker[i]
+		if unit.Template != "Shieldbearer" {
+			t.Errorf("unit %d should use the preset template, got %s", i, unit.Template)
+		}
+		if unit.Row != want[0] || unit.Col != want[1] {
+			t.Errorf("unit %d should sit at (%d,%d), got (%d,%d)", i, want[0], want[1], unit.Row, unit.Col)
+		}
+
+	}
+}
+
+// An unknown preset name fails the load with an error naming the scenario
+// and the bad preset
+func TestUnknownPresetErrorsClearly(t *testing.T) {
+
+	path := writeScenarioFile(t, `{
+		"scenarios": [{
+			"name": "BadPreset",
+			"defenderPreset": {"formation": "phalanx", "template": "Shieldbearer"}
+		}]
+	}`)
+
+	_, err := LoadScenarioFile(path)
+	if err == nil {
+		t.Fatal("expected an unknown preset to fail the load")
+	}
+
+	if !strings.Contains(err.Error(), "phalanx") || !strings.Contains(err.Error(), "BadPreset") {
+		t.Errorf("the error should name the scenario and the preset, got %v", err)
+	}
+}
@@ -0,0 +1,368 @@
+package simulation
+
+import (
+	"game_main/common"
+	"game_main/squads"
+	"math"
+
+	"github.com/bytearena/ecs"
+)
+
+// The simulator runs squad battles headlessly, many iterations at a time, and
+// aggregates the outcomes for the balance tooling. It never touches rendering
+
+// IterationOutcome is what one run of a scenario produced
+type IterationOutcome struct {
+	AttackerWon bool
+	Rounds      int
+	TotalDamage int
+
+	//How many units the winning side had left standing, the victory margin
+	WinnerSurvivors int
+}
+
+// SimulationResult aggregates many iterations of one scenario. The variance
+// fields tell a designer how swingy a matchup is, not just who wins
+type SimulationResult struct {
+	ScenarioName string `json:"scenarioName"`
+	Iterations   int    `json:"iterations"`
+	AttackerWins int    `json:"attackerWins"`
+
+	AvgRounds      float64 `json:"avgRounds"`
+	AvgTotalDamage float64 `json:"avgTotalDamage"`
+
+	StdDevRounds      float64 `json:"stdDevRounds"`
+	StdDevTotalDamage float64 `json:"stdDevTotalDamage"`
+
+	//Distribution of victory margins: survivor count -> battles. Shows
+	//whether wins are close or crushing, which the win rate hides
+	VictoryMargins map[int]int `json:"victoryMargins,omitempty"`
+
+	//Dodge economy per defender template across the scenarios battles
+	Survivability map[string]SurvivabilityStats `json:"survivability,omitempty"`
+
+	//Pre-flight findings about the scenario itself, so dashboards can flag
+	//runs whose clean-looking win rates are configuration artifacts
+	PreflightWarnings []string `json:"preflightWarnings,omitempty"`
+}
+
+func (r SimulationResult) AttackerWinRate() float64 {
+
+	if r.Iterations == 0 {
+		return 0
+	}
+
+	return float64(r.AttackerWins) / float64(r.Iterations)
+}
+
+type Simulator struct {
+	//Battles that hit the round limit count as a defender win
+	MaxRounds int
+
+	//In strict mode pre-flight warnings fail the scenario instead of
+	//riding along in the result
+	StrictScenarios bool
+}
+
+func NewSimulator() *Simulator {
+	return &Simulator{MaxRounds: 100}
+}
+
+// RunBattle fights two squads to the death, front rows first, alternating
+// attacker and defender each round. Returns the outcome and the full record
+func (s *Simulator) RunBattle(em *common.EntityManager, attackerSquadID int, defenderSquadID int) (IterationOutcome, *squads.BattleRecord) {
+	return s.runBattle(em, attackerSquadID, defenderSquadID, nil)
+}
+
+// The battle loop proper, with any scheduled reinforcement waves
+func (s *Simulator) runBattle(em *common.EntityManager, attackerSquadID int, defenderSquadID int, waves []scheduledWave) (IterationOutcome, *squads.BattleRecord) {
+
+	record := squads.NewBattleRecord(attackerSquadID, defenderSquadID)
+	record.Attacker = squads.SnapshotSquad(em, attackerSquadID)
+	record.Defender = squads.SnapshotSquad(em, defenderSquadID)
+	outcome := IterationOutcome{}
+
+	attackDir := attackDirBetween(em, attackerSquadID, defenderSquadID)
+
+	for round := 1; round <= s.MaxRounds; round++ {
+
+		record.Rounds = round
+		outcome.Rounds = round
+
+		//Reinforcements arrive before anything else the round does
+		for _, wave := range waves {
+			if wave.round == round {
+				deployWave(em, record, wave, round)
+			}
+		}
+
+		squads.AdvanceSquadRound(squads.GetSquadByID(em, attackerSquadID))
+		squads.AdvanceSquadRound(squads.GetSquadByID(em, defenderSquadID))
+
+		//Round-start effect damage (bleed and friends) before anyone swings
+		for _, squadID := range []int{attackerSquadID, defenderSquadID} {
+			for _, event := range squads.TickEffects(em, squadID) {
+				event.Round = round
+				event.Phase = squads.PhaseEffects
+				record.RecordEvent(event)
+				outcome.TotalDamage += event.Damage
+			}
+		}
+
+		if squadWipedOut(em, defenderSquadID) {
+			outcome.AttackerWon = true
+			record.WinnerSquadID = attackerSquadID
+			return s.finishBattle(em, outcome, record)
+		}
+		if squadWipedOut(em, attackerSquadID) {
+			record.WinnerSquadID = defenderSquadID
+			return s.finishBattle(em, outcome, record)
+		}
+
+		//Simultaneous resolution pairs the two volleys against the pre-round
+		//state; the wipe checks only run once both have resolved
+		if squads.Simultaneous.Enabled && !squads.Counterattack.Interleaved {
+
+			outcome.TotalDamage += s.runSimultaneousRound(em, record, attackerSquadID, defenderSquadID, attackDir)
+
+			if squadWipedOut(em, defenderSquadID) {
+				outcome.AttackerWon = true
+				record.WinnerSquadID = attackerSquadID
+				return s.finishBattle(em, outcome, record)
+			}
+			if squadWipedOut(em, attackerSquadID) {
+				record.WinnerSquadID = defenderSquadID
+				return s.finishBattle(em, outcome, record)
+			}
+
+			if done, attackerWon := s.resolveObjectiveRound(em, attackerSquadID); done {
+				outcome.AttackerWon = attackerWon
+				record.WinnerSquadID = objectiveWinner(attackerWon, attackerSquadID, defenderSquadID)
+				return s.finishBattle(em, outcome, record)
+			}
+
+			continue
+		}
+
+		outcome.TotalDamage += s.runVolley(em, record, attackerSquadID, defenderSquadID, attackDir, squads.PhaseAttack)
+		if squadWipedOut(em, defenderSquadID) {
+			outcome.AttackerWon = true
+			record.WinnerSquadID = attackerSquadID
+			return s.finishBattle(em, outcome, record)
+		}
+
+		//With interleaved counters the defenders already answered inside the
+		//attackers volley, so there is no separate return volley
+		if !squads.Counterattack.Interleaved {
+			outcome.TotalDamage += s.runVolley(em, record, defenderSquadID, attackerSquadID, attackDir.Opposite(), squads.PhaseCounterattack)
+		}
+		if squadWipedOut(em, attackerSquadID) {
+			record.WinnerSquadID = defenderSquadID
+			return s.finishBattle(em, outcome, record)
+		}
+
+		//Objectives resolve once both sides have acted. Holding enough of
+		//them long enough ends the battle regardless of casualties
+		if done, attackerWon := s.resolveObjectiveRound(em, attackerSquadID); done {
+			outcome.AttackerWon = attackerWon
+			record.WinnerSquadID = objectiveWinner(attackerWon, attackerSquadID, defenderSquadID)
+			return s.finishBattle(em, outcome, record)
+		}
+
+	}
+
+	record.WinnerSquadID = defenderSquadID
+	return s.finishBattle(em, outcome, record)
+}
+
+// Runs the end-of-round objective capture and contest rules. Returns whether
+// a faction has won on objectives, and whether that faction is the attackers
+func (s *Simulator) resolveObjectiveRound(em *common.EntityManager, attackerSquadID int) (bool, bool) {
+
+	squads.ResolveObjectives(em)
+
+	victor := squads.ObjectiveVictor(em)
+	if victor == "" {
+		return false, false
+	}
+
+	att := squads.GetSquadByID(em, attackerSquadID)
+	return true, att != nil && att.Faction == victor
+}
+
+func objectiveWinner(attackerWon bool, attackerSquadID int, defenderSquadID int) int {
+
+	if attackerWon {
+		return attackerSquadID
+	}
+
+	return defenderSquadID
+}
+
+// The victory margin is how many units the winner still has standing
+func (s *Simulator) finishBattle(em *common.EntityManager, outcome IterationOutcome, record *squads.BattleRecord) (IterationOutcome, *squads.BattleRecord) {
+
+	for _, u := range squads.SquadUnits(em, record.WinnerSquadID) {
+		if common.GetAttributes(u).CurrentHealth > 0 {
+			outcome.WinnerSurvivors++
+		}
+	}
+
+	if states := squads.SnapshotObjectives(em); len(states) > 0 {
+		record.Objectives = states
+		record.ObjectiveVictor = squads.ObjectiveVictor(em)
+	}
+
+	return outcome, record
+}
+
+// Both volleys against the pre-round state. The defenders health is wound
+// back to the round start before their return volley, so units the attacker
+// just killed still answer, then their casualties land again afterwards
+func (s *Simulator) runSimultaneousRound(em *common.EntityManager, record *squads.BattleRecord, attackerSquadID int, defenderSquadID int, attackDir squads.Direction) int {
+
+	preRound := squads.SnapshotSquadHealth(em, defenderSquadID)
+
+	total := s.runVolley(em, record, attackerSquadID, defenderSquadID, attackDir, squads.PhaseAttack)
+
+	postAttack := squads.SnapshotSquadHealth(em, defenderSquadID)
+	squads.RestoreSquadHealth(em, defenderSquadID, preRound)
+
+	total += s.runVolley(em, record, defenderSquadID, attackerSquadID, attackDir.Opposite(), squads.PhaseCounterattack)
+
+	squads.RestoreSquadHealth(em, defenderSquadID, postAttack)
+
+	return total
+}
+
+// One squads attack round, in initiative order with the squads attack cap.
+// Events get labeled with the given phase; interleaved counters inside the
+// volley label as counterattacks regardless
+func (s *Simulator) runVolley(em *common.EntityManager, record *squads.BattleRecord, attackerSquadID int, defenderSquadID int, attackDir squads.Direction, phase string) int {
+
+	total := 0
+	lost := make(map[ecs.EntityID]bool)
+
+	for _, event := range squads.ExecuteSquadAttack(em, attackerSquadID, defenderSquadID, attackDir) {
+
+		event.Round = record.Rounds
+		event.Phase = phase
+		if event.Counter {
+			event.Phase = squads.PhaseCounterattack
+		}
+		record.RecordEvent(event)
+		total += event.Damage
+
+		defender := squads.FindUnitByID(em, event.DefenderID)
+		if defender != nil && common.GetAttributes(defender).CurrentHealth <= 0 && !lost[event.DefenderID] {
+
+			lost[event.DefenderID] = true
+			name := common.GetComponentType[*common.Name](defender, common.NameComponent)
+
+			//Interleaved counters can kill attackers mid-volley, so the
+			//loss goes to the dead units own squad, not the volley target
+			record.RecordUnitLost(squads.GetUnitData(defender).SquadID, name.NameStr)
+
+		}
+
+	}
+
+	return total
+}
+
+// Aggregate computes the result of many iterations, including the standard
+// deviation of rounds and total damage
+func (s *Simulator) Aggregate(scenarioName string, outcomes []IterationOutcome) SimulationResult {
+
+	result := SimulationResult{ScenarioName: scenarioName, Iterations: len(outcomes)}
+	if len(outcomes) == 0 {
+		return result
+	}
+
+	for _, o := range outcomes {
+
+		if o.AttackerWon {
+			result.AttackerWins++
+		}
+		result.AvgRounds += float64(o.Rounds)
+		result.AvgTotalDamage += float64(o.TotalDamage)
+
+	}
+
+	n := float64(len(outcomes))
+	result.AvgRounds /= n
+	result.AvgTotalDamage /= n
+
+	varRounds, varDamage := 0.0, 0.0
+	for _, o := range outcomes {
+
+		dr := float64(o.Rounds) - result.AvgRounds
+		dd := float64(o.TotalDamage) - result.AvgTotalDamage
+		varRounds += dr * dr
+		varDamage += dd * dd
+
+	}
+
+	result.StdDevRounds = math.Sqrt(varRounds / n)
+	result.StdDevTotalDamage = math.Sqrt(varDamage / n)
+
+	result.VictoryMargins = BuildVictoryMarginHistogram(outcomes)
+
+	return result
+}
+
+// BuildVictoryMarginHistogram buckets outcomes by how many units survived on
+// the winning side
+func BuildVictoryMarginHistogram(samples []IterationOutcome) map[int]int {
+
+	histogram := make(map[int]int)
+	for _, o := range samples {
+		histogram[o.WinnerSurvivors]++
+	}
+
+	return histogram
+}
+
+func squadWipedOut(em *common.EntityManager, squadID int) bool {
+
+	for _, u := range squads.SquadUnits(em, squadID) {
+		if common.GetAttributes(u).CurrentHealth > 0 {
+			return false
+		}
+	}
+
+	return true
+}
+
+// The map-level direction the attack travels between two squads
+func attackDirBetween(em *common.EntityManager, attackerSquadID int, defenderSquadID int) squads.Direction {
+
+	att := squads.GetSquadByID(em, attackerSquadID)
+	def := squads.GetSquadByID(em, defenderSquadID)
+
+	if att == nil || def == nil {
+		return squads.DirDown
+	}
+
+	dx := def.MapPos.X - att.MapPos.X
+	dy := def.MapPos.Y - att.MapPos.Y
+
+	if abs(dx) > abs(dy) {
+		if dx > 0 {
+			return squads.DirRight
+		}
+		return squads.DirLeft
+	}
+
+	if dy > 0 {
+		return squads.DirDown
+	}
+	return squads.DirUp
+}
+
+func abs(v int) int {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
@@ -0,0 +1,110 @@
+package simulation
+
+import (
+	"game_main/common"
+	"game_main/squads"
+	"strings"
+	"testing"
+
+	"github.com/bytearena/ecs"
+)
+
+func newSimTestManager() *common.EntityManager {
+
+	manager := ecs.NewManager()
+	tags := make(map[string]ecs.Tag)
+
+	common.PositionComponent = manager.NewComponent()
+	common.NameComponent = manager.NewComponent()
+	common.AttributeComponent = manager.NewComponent()
+
+	squads.InitializeSquadComponents(manager, tags)
+
+	return &common.EntityManager{World: manager, WorldTags: tags}
+}
+
+// Controlled iteration results with known mean and deviation
+func TestAggregateComputesVariance(t *testing.T) {
+
+	sim := NewSimulator()
+
+	outcomes := []IterationOutcome{
+		{AttackerWon: true, Rounds: 1, TotalDamage: 10},
+		{AttackerWon: false, Rounds: 3, TotalDamage: 20},
+	}
+
+	result := sim.Aggregate("fixture", outcomes)
+
+	if result.AvgRounds != 2.0 {
+		t.Errorf("expected average rounds 2.0, got %f", result.AvgRounds)
+	}
+	if result.StdDevRounds != 1.0 {
+		t.Errorf("expected rounds stddev 1.0, got %f", result.StdDevRounds)
+	}
+	if result.AvgTotalDamage != 15.0 {
+		t.Errorf("expected average damage 15.0, got %f", result.AvgTotalDamage)
+	}
+	if result.StdDevTotalDamage != 5.0 {
+		t.Errorf("expected damage stddev 5.0, got %f", result.StdDevTotalDamage)
+	}
+	if result.AttackerWinRate() != 0.5 {
+		t.Errorf("expected 50%% win rate, got %f", result.AttackerWinRate())
+	}
+}
+
+func TestFormatBalanceReportIncludesVariance(t *testing.T) {
+
+	sim := NewSimulator()
+	result := sim.Aggregate("fixture", []IterationOutcome{
+		{AttackerWon: true, Rounds: 1, TotalDamage: 10},
+		{AttackerWon: false, Rounds: 3, TotalDamage: 20},
+	})
+
+	var sb strings.Builder
+	FormatBalanceReport(&sb, []SimulationResult{result})
+
+	out := sb.String()
+	if !strings.Contains(out, "stddev 1.00") || !strings.Contains(out, "stddev 5.00") {
+		t.Errorf("expected the report to surface both deviations, got:\n%s", out)
+	}
+}
+
+// A strong squad against an unarmed one should always win through RunBattle
+func TestRunBattleResolvesToTheEnd(t *testing.T) {
+
+	em := newSimTestManager()
+
+	squads.Units["SimKnight"] = squads.UnitTemplate{
+		Name:      "SimKnight",
+		Class:     "Knight",
+		Attr:      common.NewBaseAttributes(20, 2, 10, 0, 1, 0),
+		MinDamage: 5,
+		MaxDamage: 5,
+	}
+	squads.Units["SimPeasant"] = squads.UnitTemplate{
+		Name:      "SimPeasant",
+		Class:     "Peasant",
+		Attr:      common.NewBaseAttributes(5, 0, 5, 0, 1, 0),
+		MinDamage: 1,
+		MaxDamage: 1,
+	}
+
+	att := squads.CreateSquad(em, "Knights", "Player", common.Position{X: 1, Y: 1}, squads.DirDown)
+	def := squads.CreateSquad(em, "Peasants", "Enemy", common.Position{X: 1, Y: 3}, squads.DirUp)
+
+	squads.CreateUnitFromTemplate(em, squads.Units["SimKnight"], att.SquadID, 0, 0)
+	squads.CreateUnitFromTemplate(em, squads.Units["SimPeasant"], def.SquadID, 0, 0)
+
+	sim := NewSimulator()
+	outcome, record := sim.RunBattle(em, att.SquadID, def.SquadID)
+
+	if !outcome.AttackerWon {
+		t.Error("expected the knights to win")
+	}
+	if record.WinnerSquadID != att.SquadID {
+		t.Error("expected the record to name the knights as winner")
+	}
+	if len(record.UnitsLost[def.SquadID]) != 1 {
+		t.Error("expected the peasant to be recorded as lost")
+	}
+}
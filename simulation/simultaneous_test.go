@@ -0,0 +1,68 @@
+package simulation
+
+import (
+	"game_main/common"
+	"game_main/squads"
+	"testing"
+)
+
+// A glass cannon duel: the attackers unit one-shots the defenders unit, who
+// would one-shot right back if allowed to answer
+func simultaneousFixture() (*common.EntityManager, int, int) {
+
+	squads.RegisterUnitTemplate(squads.UnitTemplate{
+		Name:      "SimCannon",
+		Class:     "Mage",
+		Attr:      common.NewBaseAttributes(8, 0, 10, 0, 1, 0),
+		MinDamage: 10,
+		MaxDamage: 10,
+	})
+
+	em := newSimTestManager()
+
+	att := squads.CreateSquad(em, "First", "Player", common.Position{X: 5, Y: 4}, squads.DirDown)
+	squads.CreateUnitFromTemplate(em, squads.Units["SimCannon"], att.SquadID, 0, 0)
+
+	def := squads.CreateSquad(em, "Second", "Enemy", common.Position{X: 5, Y: 5}, squads.DirUp)
+	squads.CreateUnitFromTemplate(em, squads.Units["SimCannon"], def.SquadID, 0, 0)
+
+	return em, att.SquadID, def.SquadID
+}
+
+// Sequentially the defender dies before it can answer; simultaneously the
+// dying unit still deals the damage it had coming
+func TestSimultaneousModeLetsTheDyingAnswer(t *testing.T) {
+
+	sim := NewSimulator()
+
+	em, attID, defID := simultaneousFixture()
+	_, record := sim.RunBattle(em, attID, defID)
+
+	if len(record.Events) != 1 {
+		t.Fatalf("expected only the killing blow in sequential mode, got %d events", len(record.Events))
+	}
+
+	squads.Simultaneous.Enabled = true
+	defer func() { squads.Simultaneous.Enabled = false }()
+
+	em, attID, defID = simultaneousFixture()
+	outcome, record := sim.RunBattle(em, attID, defID)
+
+	if len(record.Events) != 2 {
+		t.Fatalf("expected both squads to land their blow, got %d events", len(record.Events))
+	}
+	for i, e := range record.Events {
+		if e.Damage != 10 {
+			t.Errorf("event %d: expected the full 10 damage, got %d", i, e.Damage)
+		}
+	}
+
+	//Mutual destruction: the defender is checked first, so the attacker
+	//takes the round, with nobody left standing
+	if !outcome.AttackerWon {
+		t.Error("expected the attacker to take a mutual-destruction round")
+	}
+	if outcome.WinnerSurvivors != 0 {
+		t.Errorf("expected no survivors after mutual destruction, got %d", outcome.WinnerSurvivors)
+	}
+}
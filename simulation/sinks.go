@@ -0,0 +1,153 @@
+package simulation
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"html"
+	"io"
+)
+
+// The built-in report sinks. Each one owns the escaping rules of its format:
+// the CSV sink leans on encoding/csv for quoting, the HTML sink entity-escapes
+// every cell, and the JSON sink marshals the whole document at End
+
+// JSONReportSink buffers the traversal and writes one indented JSON document
+type JSONReportSink struct {
+	w        io.Writer
+	document struct {
+		Title     string            `json:"title"`
+		Seed      int64             `json:"seed,omitempty"`
+		Scenarios int               `json:"scenarios"`
+		Sections  []jsonSectionView `json:"sections"`
+	}
+}
+
+type jsonSectionView struct {
+	Name    string     `json:"name"`
+	Columns []string   `json:"columns"`
+	Rows    [][]string `json:"rows"`
+}
+
+func NewJSONReportSink(w io.Writer) *JSONReportSink {
+	return &JSONReportSink{w: w}
+}
+
+func (s *JSONReportSink) Begin(meta ReportMeta) error {
+
+	s.document.Title = meta.Title
+	s.document.Seed = meta.Seed
+	s.document.Scenarios = meta.Scenarios
+	return nil
+}
+
+func (s *JSONReportSink) WriteSection(section ReportSection) error {
+
+	s.document.Sections = append(s.document.Sections, jsonSectionView(section))
+	return nil
+}
+
+func (s *JSONReportSink) End() error {
+
+	contents, err := json.MarshalIndent(s.document, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	_, err = s.w.Write(append(contents, '\n'))
+	return err
+}
+
+// CSVReportSink writes every section into one CSV stream, each introduced by
+// a section marker row, so a single file carries the whole report. Quoting
+// is encoding/csv's business
+type CSVReportSink struct {
+	writer *csv.Writer
+}
+
+func NewCSVReportSink(w io.Writer) *CSVReportSink {
+	return &CSVReportSink{writer: csv.NewWriter(w)}
+}
+
+func (s *CSVReportSink) Begin(meta ReportMeta) error {
+	return s.writer.Write([]string{"report", meta.Title, fmt.Sprintf("%d", meta.Scenarios)})
+}
+
+func (s *CSVReportSink) WriteSection(section ReportSection) error {
+
+	if err := s.writer.Write(append([]string{"section"}, section.Name)); err != nil {
+		return err
+	}
+	if err := s.writer.Write(section.Columns); err != nil {
+		return err
+	}
+
+	for _, row := range section.Rows {
+		if err := s.writer.Write(row); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s *CSVReportSink) End() error {
+
+	s.writer.Flush()
+	return s.writer.Error()
+}
+
+// HTMLReportSink writes a self-contained page with one table per section.
+// Every cell passes through html.EscapeString, so hostile scenario names
+// cannot inject markup
+type HTMLReportSink struct {
+	w   io.Writer
+	err error
+}
+
+func NewHTMLReportSink(w io.Writer) *HTMLReportSink {
+	return &HTMLReportSink{w: w}
+}
+
+func (s *HTMLReportSink) printf(format string, args ...any) {
+
+	if s.err != nil {
+		return
+	}
+	_, s.err = fmt.Fprintf(s.w, format, args...)
+}
+
+func (s *HTMLReportSink) Begin(meta ReportMeta) error {
+
+	s.printf("<!DOCTYPE html>\n<html><head><title>%s</title></head><body>\n", html.EscapeString(meta.Title))
+	s.printf("<h1>%s</h1>\n<p>%d scenarios</p>\n", html.EscapeString(meta.Title), meta.Scenarios)
+	return s.err
+}
+
+func (s *HTMLReportSink) WriteSection(section ReportSection) error {
+
+	s.printf("<h2>%s</h2>\n<table>\n<tr>", html.EscapeString(section.Name))
+	for _, column := range section.Columns {
+		s.printf("<th>%s</th>", html.EscapeString(column))
+	}
+	s.printf("</tr>\n")
+
+	for _, row := range section.Rows {
+
+		s.printf("<tr>")
+		for _, cell := range row {
+			s.printf("<td>%s</td>", html.EscapeString(cell))
+		}
+		s.printf("</tr>\n")
+
+	}
+
+	s.printf("</table>\n")
+	return s.err
+}
+
+func (s *HTMLReportSink) End() error {
+
+	s.printf("</body></html>\n")
+	return s.err
+}
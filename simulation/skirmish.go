@@ -0,0 +1,125 @@
+package simulation
+
+import (
+	"fmt"
+
+	"game_main/common"
+	"game_main/squads"
+)
+
+// Skirmish setup assembles a one-off battle from a scenario definition in
+// the real combat systems, with no campaign state around it: its own ECS
+// world, templates registered just for the session, and a teardown that puts
+// every piece of shared state back. The skirmish menu drives this, and it
+// doubles as the harness for testing the GUI against edge-case compositions
+
+type SkirmishConfig struct {
+	Scenario Scenario
+
+	//Unit templates the scenario needs. Registered on setup and rolled back
+	//on teardown, so a skirmish never leaks templates into the campaign
+	Templates []ScenarioTemplate
+}
+
+type Skirmish struct {
+	EM         *common.EntityManager
+	AttackerID int
+	DefenderID int
+
+	scenario Scenario
+
+	//What teardown restores: the shared component registry, the templates
+	//the session replaced, and the names it added outright
+	registry    componentRegistry
+	replaced    map[string]squads.UnitTemplate
+	added       []string
+	environment squads.BattleEnvironment
+}
+
+// SetupSkirmish builds the combat state for a one-off battle. The returned
+// session owns a fresh world with both squads placed; call Teardown when the
+// battle is over, win or lose, to roll the shared state back
+func SetupSkirmish(config SkirmishConfig) (*Skirmish, error) {
+
+	if len(config.Scenario.Attacker) == 0 || len(config.Scenario.Defender) == 0 {
+		return nil, fmt.Errorf("skirmish %q needs units on both sides", config.Scenario.Name)
+	}
+
+	skirmish := &Skirmish{
+		scenario:    config.Scenario,
+		registry:    captureRegistry(),
+		replaced:    make(map[string]squads.UnitTemplate),
+		environment: squads.ActiveEnvironment,
+	}
+
+	for _, t := range config.Templates {
+
+		if previous, ok := squads.GetUnitTemplate(t.Name); ok {
+			skirmish.replaced[t.Name] = previous
+		} else {
+			skirmish.added = append(skirmish.added, t.Name)
+		}
+
+	}
+	RegisterScenarioTemplates(config.Templates)
+
+	if config.Scenario.Phase != "" || config.Scenario.Weather != "" {
+		squads.ActiveEnvironment = squads.EnvironmentFor(config.Scenario.Phase, config.Scenario.Weather)
+	}
+
+	skirmish.EM = newBattleManager()
+
+	distance := config.Scenario.Distance
+	if distance <= 0 {
+		distance = 2
+	}
+
+	att := squads.CreateSquad(skirmish.EM, config.Scenario.Name+" attacker", "Attacker", common.Position{X: 5, Y: 5 - distance}, squads.DirDown)
+	def := squads.CreateSquad(skirmish.EM, config.Scenario.Name+" defender", "Defender", common.Position{X: 5, Y: 5}, squads.DirUp)
+	skirmish.AttackerID = att.SquadID
+	skirmish.DefenderID = def.SquadID
+
+	if err := placeScenarioUnits(skirmish.EM, config.Scenario.Attacker, att.SquadID); err != nil {
+		skirmish.Teardown()
+		return nil, err
+	}
+	if err := placeScenarioUnits(skirmish.EM, config.Scenario.Defender, def.SquadID); err != nil {
+		skirmish.Teardown()
+		return nil, err
+	}
+
+	return skirmish, nil
+}
+
+// Resolve fights the skirmish to its end and returns the record. The session
+// is still valid afterwards, so the result screen can read the world before
+// Teardown
+func (k *Skirmish) Resolve(s *Simulator) (IterationOutcome, *squads.BattleRecord) {
+	return s.RunBattle(k.EM, k.AttackerID, k.DefenderID)
+}
+
+// Teardown disposes the sessions entities and rolls back every piece of
+// shared state the setup touched. Safe to call once whatever happened
+func (k *Skirmish) Teardown() {
+
+	if k.EM != nil {
+
+		for _, squadID := range []int{k.AttackerID, k.DefenderID} {
+			squads.ProcessSquadDestruction(k.EM, nil, squadID)
+		}
+		k.EM = nil
+
+	}
+
+	for _, template := range k.replaced {
+		squads.RegisterUnitTemplate(template)
+	}
+	for _, name := range k.added {
+		squads.UnregisterUnitTemplate(name)
+	}
+	k.replaced = map[string]squads.UnitTemplate{}
+	k.added = nil
+
+	squads.ActiveEnvironment = k.environment
+	k.registry.restore()
+}
@@ -0,0 +1,94 @@
+package simulation
+
+import (
+	"testing"
+
+	"game_main/squads"
+)
+
+func skirmishFixture() SkirmishConfig {
+
+	return SkirmishConfig{
+		Templates: []ScenarioTemplate{
+			{Name: "SkirmishKnight", Class: "Knight", MaxHealth: 20, ArmorClass: 10, MovementSpeed: 1, MinDamage: 4, MaxDamage: 4},
+		},
+		Scenario: Scenario{
+			Name:     "Border Clash",
+			Attacker: []ScenarioUnit{{Template: "SkirmishKnight", Row: 0, Col: 0}},
+			Defender: []ScenarioUnit{{Template: "SkirmishKnight", Row: 0, Col: 1}},
+		},
+	}
+}
+
+// Setup builds a fightable world from the scenario definition: both squads
+// placed, units on their cells, resolvable to an outcome
+func TestSetupSkirmishBuildsValidCombatState(t *testing.T) {
+
+	skirmish, err := SetupSkirmish(skirmishFixture())
+	if err != nil {
+		t.Fatalf("setup failed: %v", err)
+	}
+	defer skirmish.Teardown()
+
+	if len(squads.SquadUnits(skirmish.EM, skirmish.AttackerID)) != 1 {
+		t.Error("the attacker should field its scenario units")
+	}
+	if len(squads.SquadUnits(skirmish.EM, skirmish.DefenderID)) != 1 {
+		t.Error("the defender should field its scenario units")
+	}
+
+	outcome, record := skirmish.Resolve(NewSimulator())
+	if outcome.Rounds == 0 || record.WinnerSquadID == 0 {
+		t.Errorf("the skirmish should fight to a result, got %d rounds and winner %d", outcome.Rounds, record.WinnerSquadID)
+	}
+
+}
+
+// Teardown must leave no trace: no entities in the session world, no session
+// templates in the registry, and the environment back as it was
+func TestSkirmishTeardownLeaksNothing(t *testing.T) {
+
+	squads.ActiveEnvironment = squads.BattleEnvironment{}
+
+	config := skirmishFixture()
+	config.Scenario.Weather = "Rain"
+
+	skirmish, err := SetupSkirmish(config)
+	if err != nil {
+		t.Fatalf("setup failed: %v", err)
+	}
+
+	em := skirmish.EM
+	skirmish.Resolve(NewSimulator())
+	skirmish.Teardown()
+
+	if n := len(em.World.Query(em.WorldTags["squads"])); n != 0 {
+		t.Errorf("the session squads should be disposed, %d remain", n)
+	}
+	if n := len(em.World.Query(em.WorldTags["squadunits"])); n != 0 {
+		t.Errorf("the session units should be disposed, %d remain", n)
+	}
+
+	if _, ok := squads.GetUnitTemplate("SkirmishKnight"); ok {
+		t.Error("the session template should be unregistered")
+	}
+	if squads.ActiveEnvironment != (squads.BattleEnvironment{}) {
+		t.Errorf("the environment should roll back, got %+v", squads.ActiveEnvironment)
+	}
+
+}
+
+// A scenario missing a side never builds a session and leaves nothing behind
+func TestSkirmishRejectsEmptySides(t *testing.T) {
+
+	config := skirmishFixture()
+	config.Scenario.Defender = nil
+
+	if _, err := SetupSkirmish(config); err == nil {
+		t.Fatal("an empty side should fail setup")
+	}
+	if _, ok := squads.GetUnitTemplate("SkirmishKnight"); ok {
+		t.Error("a failed setup should not leak templates either")
+	}
+
+}
@@ -0,0 +1,163 @@
+package simulation
+
+import (
+	"game_main/squads"
+
+	"github.com/bytearena/ecs"
+)
+
+// The dodge/miss economy: how often a templates defense invalidates incoming
+// attacks outright, and how much damage that is worth. Dodge stacking is
+// invisible in raw win rates, this is what makes it show up in the report
+
+type SurvivabilityStats struct {
+	Template string `json:"template"`
+
+	AttacksIncoming    int `json:"attacksIncoming"`
+	AttacksInvalidated int `json:"attacksInvalidated"`
+
+	//Sum of the final damage the dodged attacks would have dealt
+	DamageAvoided int `json:"damageAvoided"`
+
+	//The templates protection, for the dodge-versus-armor comparison
+	Protection int `json:"protection"`
+
+	//Rounds units of this template spent alive but below the wound
+	//threshold, summed over the batch. Only tracked while wounds are enabled
+	RoundsWounded int `json:"roundsWounded,omitempty"`
+}
+
+// InvalidationRate is the fraction of incoming attacks that dealt nothing
+func (s SurvivabilityStats) InvalidationRate() float64 {
+
+	if s.AttacksIncoming == 0 {
+		return 0
+	}
+
+	return float64(s.AttacksInvalidated) / float64(s.AttacksIncoming)
+}
+
+// AvgDamageAvoidedPerAttack is what one point of incoming attack is worth to
+// this templates dodge
+func (s SurvivabilityStats) AvgDamageAvoidedPerAttack() float64 {
+
+	if s.AttacksIncoming == 0 {
+		return 0
+	}
+
+	return float64(s.DamageAvoided) / float64(s.AttacksIncoming)
+}
+
+// DodgeVsArmor compares the marginal value of the templates dodge against its
+// armor: avoided damage per incoming attack divided by the flat protection a
+// landed hit loses. Above 1 means dodge is carrying more than armor
+func (s SurvivabilityStats) DodgeVsArmor() float64 {
+
+	protection := s.Protection
+	if protection < 1 {
+		protection = 1
+	}
+
+	return s.AvgDamageAvoidedPerAttack() / float64(protection)
+}
+
+// ComputeSurvivability aggregates the dodge economy per defender template
+// over a batch of battle records
+func ComputeSurvivability(records []*squads.BattleRecord) map[string]SurvivabilityStats {
+
+	protectionByTemplate := make(map[string]int)
+	for _, record := range records {
+		for _, snapshot := range []squads.SquadSnapshot{record.Attacker, record.Defender} {
+			for _, u := range snapshot.Units {
+				protectionByTemplate[u.TemplateName] = u.Protection
+			}
+		}
+	}
+
+	stats := make(map[string]SurvivabilityStats)
+	for _, record := range records {
+		for _, event := range record.Events {
+
+			if event.DefenderTemplate == "" {
+				continue //effect ticks and consumables are not attacks
+			}
+
+			s := stats[event.DefenderTemplate]
+			s.Template = event.DefenderTemplate
+			s.Protection = protectionByTemplate[event.DefenderTemplate]
+
+			s.AttacksIncoming++
+			if event.Dodged {
+				s.AttacksInvalidated++
+				s.DamageAvoided += event.PotentialDamage
+			}
+
+			stats[event.DefenderTemplate] = s
+
+		}
+	}
+
+	if squads.Wounds.Enabled {
+		for _, record := range records {
+			tallyRoundsWounded(record, stats)
+		}
+	}
+
+	return stats
+}
+
+// Replays one records events round by round and counts, per template, how
+// many rounds each unit spent alive below the wound threshold. Same health
+// replay the post-mortem analyzer uses
+func tallyRoundsWounded(record *squads.BattleRecord, stats map[string]SurvivabilityStats) {
+
+	health := make(map[ecs.EntityID]int)
+	maxHealth := make(map[ecs.EntityID]int)
+	template := make(map[ecs.EntityID]string)
+
+	for _, snapshot := range []squads.SquadSnapshot{record.Attacker, record.Defender} {
+		for _, u := range snapshot.Units {
+			health[u.EntityID] = u.MaxHealth
+			maxHealth[u.EntityID] = u.MaxHealth
+			template[u.EntityID] = u.TemplateName
+		}
+	}
+
+	tally := func() {
+		for id, hp := range health {
+
+			if hp <= 0 || float64(hp) >= float64(maxHealth[id])*squads.Wounds.Threshold {
+				continue
+			}
+
+			s := stats[template[id]]
+			s.Template = template[id]
+			s.RoundsWounded++
+			stats[template[id]] = s
+
+		}
+	}
+
+	round := 0
+	for _, event := range record.Events {
+
+		if event.Round != round {
+
+			if round > 0 {
+				tally()
+			}
+			round = event.Round
+
+		}
+
+		if event.Damage > 0 {
+			health[event.DefenderID] -= event.Damage
+		}
+
+	}
+
+	if round > 0 {
+		tally()
+	}
+
+}
@@ -0,0 +1,44 @@
+package simulation
+
+import "fmt"
+
+// Sweep presets generate families of scenarios that vary one stat at a time,
+// so the report can answer "where should the next point go" questions
+
+// BuildDodgeVsArmorSweep pits variants of one template against a fixed
+// opponent: each step either adds dodge or adds protection to the base
+// template, never both. Comparing the two branches in the survivability
+// section shows which investment buys more. The returned templates must be
+// registered before the scenarios run
+func BuildDodgeVsArmorSweep(base ScenarioTemplate, opponent ScenarioTemplate, steps int, iterations int) ([]ScenarioTemplate, []Scenario) {
+
+	templates := []ScenarioTemplate{base, opponent}
+	scenarios := []Scenario{}
+
+	for step := 1; step <= steps; step++ {
+
+		dodge := base
+		dodge.Name = fmt.Sprintf("%s+Dodge%d", base.Name, step)
+		dodge.DodgeChance += 0.1 * float32(step)
+
+		armor := base
+		armor.Name = fmt.Sprintf("%s+Armor%d", base.Name, step)
+		armor.Protection += step
+
+		templates = append(templates, dodge, armor)
+
+		for _, variant := range []string{dodge.Name, armor.Name} {
+
+			scenarios = append(scenarios, Scenario{
+				Name:       fmt.Sprintf("%s vs %s", opponent.Name, variant),
+				Iterations: iterations,
+				Attacker:   []ScenarioUnit{{Template: opponent.Name, Row: 0, Col: 0}},
+				Defender:   []ScenarioUnit{{Template: variant, Row: 0, Col: 0}},
+			})
+
+		}
+
+	}
+
+	return templates, scenarios
+}
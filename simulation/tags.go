@@ -0,0 +1,114 @@
+package simulation
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Tag filtering for scenario suites. Once a suite grows past a few dozen
+// scenarios, "run everything" is too slow for quick iteration, so scenarios
+// carry tags and the CLIs pass include and exclude lists through here before
+// running anything
+
+// ParseTagList splits a comma-separated flag value into clean tag names.
+// Empty entries are dropped, so a trailing comma is harmless
+func ParseTagList(value string) []string {
+
+	tags := []string{}
+	for _, tag := range strings.Split(value, ",") {
+
+		tag = strings.TrimSpace(tag)
+		if tag != "" {
+			tags = append(tags, tag)
+		}
+
+	}
+
+	return tags
+}
+
+// KnownTags collects every tag the scenario set uses, sorted, so warnings
+// and -list output stay deterministic
+func KnownTags(scenarios []Scenario) []string {
+
+	seen := map[string]bool{}
+	for _, scenario := range scenarios {
+		for _, tag := range scenario.Tags {
+			seen[tag] = true
+		}
+	}
+
+	tags := make([]string, 0, len(seen))
+	for tag := range seen {
+		tags = append(tags, tag)
+	}
+	sort.Strings(tags)
+
+	return tags
+}
+
+// FilterScenarios keeps the scenarios that carry at least one include tag
+// (all of them, when include is empty) and none of the exclude tags. Filter
+// tags no scenario uses come back as warning messages instead of silently
+// matching nothing
+func FilterScenarios(scenarios []Scenario, include []string, exclude []string) ([]Scenario, []string) {
+
+	warnings := unknownTagWarnings(scenarios, append(append([]string{}, include...), exclude...))
+
+	kept := []Scenario{}
+	for _, scenario := range scenarios {
+
+		if len(include) > 0 && !hasAnyTag(scenario, include) {
+			continue
+		}
+		if hasAnyTag(scenario, exclude) {
+			continue
+		}
+
+		kept = append(kept, scenario)
+
+	}
+
+	return kept, warnings
+}
+
+func hasAnyTag(scenario Scenario, tags []string) bool {
+
+	for _, want := range tags {
+		for _, tag := range scenario.Tags {
+			if tag == want {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+func unknownTagWarnings(scenarios []Scenario, filterTags []string) []string {
+
+	known := KnownTags(scenarios)
+
+	isKnown := map[string]bool{}
+	for _, tag := range known {
+		isKnown[tag] = true
+	}
+
+	warnings := []string{}
+	for _, tag := range filterTags {
+
+		if isKnown[tag] {
+			continue
+		}
+
+		if len(known) == 0 {
+			warnings = append(warnings, fmt.Sprintf("no scenario carries the tag %q; the suite has no tags at all", tag))
+		} else {
+			warnings = append(warnings, fmt.Sprintf("no scenario carries the tag %q (known tags: %s)", tag, strings.Join(known, ", ")))
+		}
+
+	}
+
+	return warnings
+}
@@ -0,0 +1,125 @@
+package simulation
+
+import (
+	"strings"
+	"testing"
+)
+
+func taggedSuite() []Scenario {
+	return []Scenario{
+		{Name: "SwordMirror", Tags: []string{"melee", "regression"}},
+		{Name: "ArcherWall", Tags: []string{"ranged", "cover"}},
+		{Name: "GrandMelee", Tags: []string{"melee", "slow"}},
+		{Name: "Untagged"},
+	}
+}
+
+// An empty filter is a no-op: everything runs, nothing warns
+func TestEmptyFilterKeepsEverything(t *testing.T) {
+
+	kept, warnings := FilterScenarios(taggedSuite(), nil, nil)
+
+	if len(kept) != 4 {
+		t.Errorf("expected all 4 scenarios, got %d", len(kept))
+	}
+	if len(warnings) != 0 {
+		t.Errorf("an empty filter should not warn, got %v", warnings)
+	}
+}
+
+// Include keeps scenarios with any listed tag, and untagged scenarios drop
+func TestIncludeTagsSelectMatchingScenarios(t *testing.T) {
+
+	kept, _ := FilterScenarios(taggedSuite(), []string{"melee"}, nil)
+
+	if len(kept) != 2 {
+		t.Fatalf("expected the 2 melee scenarios, got %d", len(kept))
+	}
+	if kept[0].Name != "SwordMirror" || kept[1].Name != "GrandMelee" {
+		t.Errorf("expected SwordMirror and GrandMelee, got %s and %s", kept[0].Name, kept[1].Name)
+	}
+}
+
+// Exclude alone drops matching scenarios and keeps the rest, tagged or not
+func TestExcludeTagsDropMatchingScenarios(t *testing.T) {
+
+	kept, _ := FilterScenarios(taggedSuite(), nil, []string{"slow", "ranged"})
+
+	if len(kept) != 2 {
+		t.Fatalf("expected 2 scenarios after excluding slow and ranged, got %d", len(kept))
+	}
+	if kept[0].Name != "SwordMirror" || kept[1].Name != "Untagged" {
+		t.Errorf("expected SwordMirror and Untagged, got %s and %s", kept[0].Name, kept[1].Name)
+	}
+}
+
+// Exclude wins when a scenario matches both lists
+func TestExcludeTrumpsInclude(t *testing.T) {
+
+	kept, _ := FilterScenarios(taggedSuite(), []string{"melee"}, []string{"slow"})
+
+	if len(kept) != 1 || kept[0].Name != "SwordMirror" {
+		t.Fatalf("expected only SwordMirror, got %d scenarios", len(kept))
+	}
+}
+
+// A filter tag no scenario carries warns and lists the tags that do exist,
+// instead of silently matching nothing
+func TestUnknownFilterTagWarnsWithKnownTags(t *testing.T) {
+
+	kept, warnings := FilterScenarios(taggedSuite(), []string{"magic"}, nil)
+
+	if len(kept) != 0 {
+		t.Errorf("no scenario carries magic, expected 0 kept, got %d", len(kept))
+	}
+	if len(warnings) != 1 {
+		t.Fatalf("expected one warning, got %v", warnings)
+	}
+
+	warning := warnings[0]
+	if !strings.Contains(warning, `"magic"`) {
+		t.Errorf("the warning should name the unknown tag: %s", warning)
+	}
+	for _, known := range []string{"cover", "melee", "ranged", "regression", "slow"} {
+		if !strings.Contains(warning, known) {
+			t.Errorf("the warning should list the known tag %s: %s", known, warning)
+		}
+	}
+}
+
+// Tags round-trip through the scenario file format
+func TestScenarioFileCarriesTags(t *testing.T) {
+
+	path := writeScenarioFile(t, `{
+		"templates": [{"name": "Tagged", "maxHealth": 10}],
+		"scenarios": [{
+			"name": "TagTest",
+			"iterations": 1,
+			"tags": ["ranged", "regression"],
+			"attacker": [{"template": "Tagged", "row": 0, "col": 0}],
+			"defender": [{"template": "Tagged", "row": 0, "col": 0}]
+		}]
+	}`)
+
+	file, err := LoadScenarioFile(path)
+	if err != nil {
+		t.Fatalf("loading a tagged scenario failed: %v", err)
+	}
+
+	tags := file.Scenarios[0].Tags
+	if len(tags) != 2 || tags[0] != "ranged" || tags[1] != "regression" {
+		t.Errorf("expected tags [ranged regression], got %v", tags)
+	}
+}
+
+func TestParseTagListDropsEmptyEntries(t *testing.T) {
+
+	tags := ParseTagList(" melee, slow ,,")
+	if len(tags) != 2 || tags[0] != "melee" || tags[1] != "slow" {
+		t.Errorf("expected [melee slow], got %v", tags)
+	}
+
+	if got := ParseTagList(""); len(got) != 0 {
+		t.Errorf("an empty value should parse to no tags, got %v", got)
+	}
+}
@@ -0,0 +1,266 @@
+package simulation
+
+import (
+	"math"
+	"sort"
+	"sync"
+
+	"game_main/common"
+	"game_main/squads"
+
+	"github.com/bytearena/ecs"
+)
+
+// SquadTestRun is the background state machine behind the editors "Test
+// Squad" button. The squads are serialized once up front and every iteration
+// fights clones in a fresh world, so a test run never mutates the real
+// squads. The GUI polls Status every frame and stays responsive while the
+// goroutine grinds through the iterations
+
+type TestRunState int
+
+const (
+	TestRunIdle TestRunState = iota
+	TestRunRunning
+	TestRunDone
+	TestRunCancelled
+)
+
+const (
+	DefaultTestIterations = 200
+	MaxTestIterations     = 1000
+)
+
+// TestRunResult is the compact result card the editor shows after a run
+type TestRunResult struct {
+	Iterations int
+	WinRate    float64
+
+	//95% confidence interval around the win rate, clamped to 0..1
+	WinRateLow  float64
+	WinRateHigh float64
+
+	AvgRounds float64
+
+	//The squads units that tend to die first, most frequent first
+	FirstDeaths []string
+
+	//Set when battles resolve fast enough that burst damage, not attrition,
+	//decides them. A cue to look at opening volleys rather than sustain
+	Bursty bool
+}
+
+type SquadTestRun struct {
+	mu        sync.Mutex
+	state     TestRunState
+	completed int
+	total     int
+	result    *TestRunResult
+
+	cancel chan struct{}
+	done   chan struct{}
+}
+
+// Status is what the progress indicator polls: the state and how many
+// iterations have finished out of the total
+func (r *SquadTestRun) Status() (TestRunState, int, int) {
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.state, r.completed, r.total
+}
+
+// Result is non-nil once the run is done. A cancelled run has no result
+func (r *SquadTestRun) Result() *TestRunResult {
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.result
+}
+
+// Cancel stops the run after the iteration in flight. Safe to call twice
+func (r *SquadTestRun) Cancel() {
+
+	select {
+	case <-r.cancel:
+	default:
+		close(r.cancel)
+	}
+
+}
+
+// Wait blocks until the goroutine exits, for tests and shutdown
+func (r *SquadTestRun) Wait() {
+	<-r.done
+}
+
+// StartSquadTest serializes the two squads out of the live world and kicks
+// off the background run. The serialization happens here, synchronously, so
+// the goroutine never touches the callers ECS world
+func (s *Simulator) StartSquadTest(em *common.EntityManager, squadID int, opponentID int, iterations int) *SquadTestRun {
+
+	if iterations <= 0 {
+		iterations = DefaultTestIterations
+	}
+	if iterations > MaxTestIterations {
+		iterations = MaxTestIterations
+	}
+
+	saved := []squads.SavedSquad{}
+	for _, savedSquad := range squads.SerializeSquads(em) {
+		if savedSquad.Squad.SquadID == squadID || savedSquad.Squad.SquadID == opponentID {
+			saved = append(saved, savedSquad)
+		}
+	}
+
+	run := &SquadTestRun{
+		state:  TestRunRunning,
+		total:  iterations,
+		cancel: make(chan struct{}),
+		done:   make(chan struct{}),
+	}
+
+	go s.runSquadTest(run, saved, squadID, opponentID)
+
+	return run
+}
+
+// The component registry is package-global and every battle clone
+// re-registers it for its own manager. Captured before a run and restored
+// after, so the callers world reads correctly once the run is over. While a
+// run is in flight the caller must not query the ECS, only poll Status
+type componentRegistry struct {
+	position, name, attribute *ecs.Component
+
+	squad, unit, guardian, appearance, level, bag, effects, support, buffs, pin, overwatch, objective, artifact, leader *ecs.Component
+}
+
+func captureRegistry() componentRegistry {
+
+	return componentRegistry{
+		position:   common.PositionComponent,
+		name:       common.NameComponent,
+		attribute:  common.AttributeComponent,
+		squad:      squads.SquadComponent,
+		unit:       squads.UnitComponent,
+		guardian:   squads.GuardianComponent,
+		appearance: squads.SquadAppearanceComponent,
+		level:      squads.UnitLevelComponent,
+		bag:        squads.ConsumableBagComponent,
+		effects:    squads.UnitEffectsComponent,
+		support:    squads.SupportAbilityComponent,
+		buffs:      squads.SquadBuffsComponent,
+		pin:        squads.PinComponent,
+		overwatch:  squads.OverwatchComponent,
+		objective:  squads.ObjectiveComponent,
+		artifact:   squads.ArtifactComponent,
+		leader:     squads.LeaderComponent,
+	}
+}
+
+func (r componentRegistry) restore() {
+
+	common.PositionComponent = r.position
+	common.NameComponent = r.name
+	common.AttributeComponent = r.attribute
+	squads.SquadComponent = r.squad
+	squads.UnitComponent = r.unit
+	squads.GuardianComponent = r.guardian
+	squads.SquadAppearanceComponent = r.appearance
+	squads.UnitLevelComponent = r.level
+	squads.ConsumableBagComponent = r.bag
+	squads.UnitEffectsComponent = r.effects
+	squads.SupportAbilityComponent = r.support
+	squads.SquadBuffsComponent = r.buffs
+	squads.PinComponent = r.pin
+	squads.OverwatchComponent = r.overwatch
+	squads.ObjectiveComponent = r.objective
+	squads.ArtifactComponent = r.artifact
+	squads.LeaderComponent = r.leader
+
+}
+
+func (s *Simulator) runSquadTest(run *SquadTestRun, saved []squads.SavedSquad, squadID int, opponentID int) {
+
+	defer close(run.done)
+
+	registry := captureRegistry()
+	defer registry.restore()
+
+	wins := 0
+	totalRounds := 0
+	firstDeaths := make(map[string]int)
+
+	completed := 0
+	for i := 0; i < run.total; i++ {
+
+		select {
+		case <-run.cancel:
+			run.mu.Lock()
+			run.state = TestRunCancelled
+			run.mu.Unlock()
+			return
+		default:
+		}
+
+		clone := newBattleManager()
+		if err := squads.DeserializeSquads(clone, saved); err != nil {
+			break
+		}
+
+		outcome, record := s.RunBattle(clone, squadID, opponentID)
+
+		if record.WinnerSquadID == squadID {
+			wins++
+		}
+		totalRounds += outcome.Rounds
+
+		if lost := record.UnitsLost[squadID]; len(lost) > 0 {
+			firstDeaths[lost[0]]++
+		}
+
+		completed++
+		run.mu.Lock()
+		run.completed = completed
+		run.mu.Unlock()
+
+	}
+
+	run.mu.Lock()
+	run.result = buildTestRunResult(completed, wins, totalRounds, firstDeaths)
+	run.state = TestRunDone
+	run.mu.Unlock()
+}
+
+func buildTestRunResult(iterations int, wins int, totalRounds int, firstDeaths map[string]int) *TestRunResult {
+
+	result := &TestRunResult{Iterations: iterations}
+	if iterations == 0 {
+		return result
+	}
+
+	result.WinRate = float64(wins) / float64(iterations)
+	result.AvgRounds = float64(totalRounds) / float64(iterations)
+
+	//Normal approximation of the binomial 95% interval
+	margin := 1.96 * math.Sqrt(result.WinRate*(1-result.WinRate)/float64(iterations))
+	result.WinRateLow = math.Max(0, result.WinRate-margin)
+	result.WinRateHigh = math.Min(1, result.WinRate+margin)
+
+	//Battles over in a few rounds are decided by the opening volleys
+	result.Bursty = result.AvgRounds <= 3
+
+	for name := range firstDeaths {
+		result.FirstDeaths = append(result.FirstDeaths, name)
+	}
+	sort.Slice(result.FirstDeaths, func(i, j int) bool {
+
+		if firstDeaths[result.FirstDeaths[i]] != firstDeaths[result.FirstDeaths[j]] {
+			return firstDeaths[result.FirstDeaths[i]] > firstDeaths[result.FirstDeaths[j]]
+		}
+		return result.FirstDeaths[i] < result.FirstDeaths[j]
+
+	})
+
+	return result
+}
@@ -0,0 +1,156 @@
+package simulation
+
+import (
+	"encoding/json"
+	"game_main/common"
+	"game_main/squads"
+	"testing"
+	"time"
+)
+
+// A lopsided matchup in a live world, returning both squad IDs
+func testRunFixture(t *testing.T) (*common.EntityManager, int, int) {
+
+	t.Helper()
+	em := newSimTestManager()
+
+	squads.Units["RunKnight"] = squads.UnitTemplate{
+		Name:      "RunKnight",
+		Class:     "Knight",
+		Attr:      common.NewBaseAttributes(20, 2, 10, 0, 1, 0),
+		MinDamage: 5,
+		MaxDamage: 5,
+	}
+	squads.Units["RunPeasant"] = squads.UnitTemplate{
+		Name:      "RunPeasant",
+		Class:     "Peasant",
+		Attr:      common.NewBaseAttributes(5, 0, 5, 0, 1, 0),
+		MinDamage: 1,
+		MaxDamage: 1,
+	}
+
+	mine := squads.CreateSquad(em, "Knights", "Player", common.Position{X: 1, Y: 1}, squads.DirDown)
+	opponent := squads.CreateSquad(em, "Peasants", "Enemy", common.Position{X: 1, Y: 3}, squads.DirUp)
+
+	squads.CreateUnitFromTemplate(em, squads.Units["RunKnight"], mine.SquadID, 0, 0)
+	squads.CreateUnitFromTemplate(em, squads.Units["RunPeasant"], opponent.SquadID, 0, 0)
+
+	return em, mine.SquadID, opponent.SquadID
+}
+
+func waitForState(t *testing.T, run *SquadTestRun, want TestRunState) {
+
+	t.Helper()
+	deadline := time.After(5 * time.Second)
+
+	for {
+
+		state, _, _ := run.Status()
+		if state == want {
+			return
+		}
+
+		select {
+		case <-deadline:
+			t.Fatalf("run never reached state %d, stuck at %d", want, state)
+		case <-time.After(time.Millisecond):
+		}
+
+	}
+}
+
+// Start to completion: the run reports progress and ends with a result card
+func TestSquadTestRunCompletes(t *testing.T) {
+
+	em, mine, opponent := testRunFixture(t)
+
+	run := NewSimulator().StartSquadTest(em, mine, opponent, 20)
+	waitForState(t, run, TestRunDone)
+	run.Wait()
+
+	_, completed, total := run.Status()
+	if completed != 20 || total != 20 {
+		t.Errorf("expected 20/20 iterations, got %d/%d", completed, total)
+	}
+
+	result := run.Result()
+	if result == nil {
+		t.Fatal("a completed run must have a result")
+	}
+	if result.WinRate != 1.0 {
+		t.Errorf("the knights should win every battle, got win rate %f", result.WinRate)
+	}
+	if len(result.FirstDeaths) != 0 {
+		t.Errorf("the knights never lose a unit, got %v", result.FirstDeaths)
+	}
+
+	//The same matchup tested from the losing side names its weak point
+	losing := NewSimulator().StartSquadTest(em, opponent, mine, 10)
+	losing.Wait()
+
+	result = losing.Result()
+	if result.WinRate != 0 {
+		t.Errorf("the peasants should lose every battle, got win rate %f", result.WinRate)
+	}
+	if len(result.FirstDeaths) != 1 || result.FirstDeaths[0] != "RunPeasant" {
+		t.Errorf("expected the peasant as the first death, got %v", result.FirstDeaths)
+	}
+}
+
+// Cancelling mid-run ends the goroutine without a result
+func TestSquadTestRunCancellation(t *testing.T) {
+
+	em, mine, opponent := testRunFixture(t)
+
+	run := NewSimulator().StartSquadTest(em, mine, opponent, MaxTestIterations)
+	run.Cancel()
+	run.Cancel() //cancelling twice must be safe
+	run.Wait()
+
+	state, _, _ := run.Status()
+	if state != TestRunCancelled {
+		t.Errorf("expected the cancelled state, got %d", state)
+	}
+	if run.Result() != nil {
+		t.Error("a cancelled run must not publish a result")
+	}
+}
+
+// The iteration cap holds regardless of what the caller asks for
+func TestSquadTestIterationCap(t *testing.T) {
+
+	em, mine, opponent := testRunFixture(t)
+
+	run := NewSimulator().StartSquadTest(em, mine, opponent, MaxTestIterations*10)
+	_, _, total := run.Status()
+	if total != MaxTestIterations {
+		t.Errorf("expected the cap of %d iterations, got %d", MaxTestIterations, total)
+	}
+
+	run.Cancel()
+	run.Wait()
+}
+
+// The real squads come through a test run bit-identical: the clones take all
+// the damage
+func TestSquadTestDoesNotMutateTheRealSquads(t *testing.T) {
+
+	em, mine, opponent := testRunFixture(t)
+
+	before, err := json.Marshal(squads.SerializeSquads(em))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	run := NewSimulator().StartSquadTest(em, mine, opponent, 10)
+	run.Wait()
+
+	after, err := json.Marshal(squads.SerializeSquads(em))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(before) != string(after) {
+		t.Errorf("the real squads changed during a test run:\nbefore %s\nafter  %s", before, after)
+	}
+}
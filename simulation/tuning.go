@@ -0,0 +1,346 @@
+package simulation
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+)
+
+// The tuning assistant closes the balance loop: instead of guessing stat
+// changes and re-running sweeps by hand, it searches one template attribute
+// for the value that brings a matchups win rate closest to a target. A
+// coarse sweep brackets the crossing, bisection narrows it, and each probe
+// stops early once its confidence interval settles on one side of the target
+
+// TuneKnob writes an absolute attribute value into a template copy. Knobs
+// only ever touch copies, the registered originals are restored after a run
+type TuneKnob func(t *ScenarioTemplate, value int)
+
+// TuneKnobs is the attribute registry the -tune-attr flag indexes
+var TuneKnobs = map[string]TuneKnob{
+	"MaxHealth":     func(t *ScenarioTemplate, v int) { t.MaxHealth = v },
+	"Protection":    func(t *ScenarioTemplate, v int) { t.Protection = v },
+	"AttackBonus":   func(t *ScenarioTemplate, v int) { t.AttackBonus = v },
+	"MinDamage":     func(t *ScenarioTemplate, v int) { t.MinDamage = v },
+	"MaxDamage":     func(t *ScenarioTemplate, v int) { t.MaxDamage = v },
+	"Damage":        func(t *ScenarioTemplate, v int) { t.MinDamage = v; t.MaxDamage = v },
+	"Focus":         func(t *ScenarioTemplate, v int) { t.Focus = v },
+	"CoverValue":    func(t *ScenarioTemplate, v int) { t.CoverValue = v },
+	"BackstabBonus": func(t *ScenarioTemplate, v int) { t.BackstabBonus = v },
+}
+
+// TuneAttributes lists the tunable attribute names, sorted for error messages
+func TuneAttributes() []string {
+
+	names := make([]string, 0, len(TuneKnobs))
+	for name := range TuneKnobs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// TuneRequest describes one search: which scenario, which knob, on which
+// templates, towards which win rate, over which value range
+type TuneRequest struct {
+	Scenario  Scenario
+	Templates []ScenarioTemplate
+
+	TargetWinRate float64
+	Attribute     string
+
+	//Which templates the knob turns, as side/template: "Defender/*" tunes
+	//every template the defender fields, "Attacker/Bruiser" just the one
+	Target string
+
+	//The value range the search probes, inclusive
+	Min int
+	Max int
+
+	//Coarse sweep stride before bisection takes over. 0 picks one that
+	//lands about five probes across the range
+	Step int
+
+	//Iteration cap per probe. Early stopping usually quits well under it
+	ProbeIterations int
+}
+
+// TuneProbe is one measurement in the search history
+type TuneProbe struct {
+	Value      int
+	WinRate    float64
+	Iterations int
+}
+
+// TuneResult is the searchs answer: the best value found, how close it got,
+// and every probe on the way there
+type TuneResult struct {
+	Attribute string
+	Value     int
+	WinRate   float64
+
+	//WinRate minus the target, so positive means still winning too often
+	Residual float64
+
+	Probes []TuneProbe
+}
+
+// Probes this far apart in win rate count as a real trend rather than
+// binomial noise when checking monotonicity
+const tuneNoiseTolerance = 0.08
+
+// Iterations between confidence-interval checks during a probe
+const tuneBatchSize = 20
+
+// Tune searches the attribute range for the value whose win rate lands
+// closest to the target. The scenarios templates are registered for the
+// duration and restored unmodified afterwards. It bails out with an error
+// when the win rate is not monotonic in the attribute over the probed range,
+// since bisection would chase noise
+func (s *Simulator) Tune(req TuneRequest) (TuneResult, error) {
+
+	knob, ok := TuneKnobs[req.Attribute]
+	if !ok {
+		return TuneResult{}, fmt.Errorf("no tunable attribute %q, pick one of %s", req.Attribute, strings.Join(TuneAttributes(), ", "))
+	}
+
+	affected, err := tuneTargets(req.Scenario, req.Target)
+	if err != nil {
+		return TuneResult{}, err
+	}
+
+	if req.Min >= req.Max {
+		return TuneResult{}, fmt.Errorf("the tuning range %d..%d is empty", req.Min, req.Max)
+	}
+	if req.TargetWinRate <= 0 || req.TargetWinRate >= 1 {
+		return TuneResult{}, fmt.Errorf("the target win rate %.2f must be between 0 and 1 exclusive", req.TargetWinRate)
+	}
+
+	probeCap := req.ProbeIterations
+	if probeCap <= 0 {
+		probeCap = DefaultTestIterations
+	}
+	if probeCap > MaxTestIterations {
+		probeCap = MaxTestIterations
+	}
+
+	step := req.Step
+	if step <= 0 {
+		step = (req.Max - req.Min) / 4
+		if step < 1 {
+			step = 1
+		}
+	}
+
+	//The originals go back in the registry no matter how the search ends
+	RegisterScenarioTemplates(req.Templates)
+	defer RegisterScenarioTemplates(req.Templates)
+
+	result := TuneResult{Attribute: req.Attribute}
+
+	probeAt := func(value int) (TuneProbe, error) {
+
+		probe, err := s.tuneProbe(req, knob, affected, value, probeCap)
+		if err != nil {
+			return probe, err
+		}
+
+		result.Probes = append(result.Probes, probe)
+		return probe, nil
+	}
+
+	//Coarse sweep across the range, always ending on Max
+	coarse := []TuneProbe{}
+	for value := req.Min; ; value += step {
+
+		if value > req.Max {
+			value = req.Max
+		}
+
+		probe, err := probeAt(value)
+		if err != nil {
+			return TuneResult{}, err
+		}
+		coarse = append(coarse, probe)
+
+		if value == req.Max {
+			break
+		}
+
+	}
+
+	if _, monotone := monotoneDirection(coarse); !monotone {
+		return TuneResult{}, fmt.Errorf("win rate is not monotonic in %s over %d..%d, the search would chase noise: %s",
+			req.Attribute, req.Min, req.Max, formatProbes(coarse))
+	}
+
+	//Bisect inside the coarse pair that straddles the target. When no pair
+	//does, the target sits outside the achievable range and the nearest
+	//endpoint already measured is the answer
+	for i := 0; i+1 < len(coarse); i++ {
+
+		if !straddles(coarse[i].WinRate, coarse[i+1].WinRate, req.TargetWinRate) {
+			continue
+		}
+
+		lo, hi := coarse[i].Value, coarse[i+1].Value
+		loRate := coarse[i].WinRate
+
+		for hi-lo > 1 {
+
+			mid := (lo + hi) / 2
+			probe, err := probeAt(mid)
+			if err != nil {
+				return TuneResult{}, err
+			}
+
+			if straddles(loRate, probe.WinRate, req.TargetWinRate) {
+				hi = probe.Value
+			} else {
+				lo, loRate = probe.Value, probe.WinRate
+			}
+
+		}
+
+		break
+	}
+
+	best := result.Probes[0]
+	for _, probe := range result.Probes[1:] {
+
+		if math.Abs(probe.WinRate-req.TargetWinRate) < math.Abs(best.WinRate-req.TargetWinRate) {
+			best = probe
+		}
+
+	}
+
+	result.Value = best.Value
+	result.WinRate = best.WinRate
+	result.Residual = best.WinRate - req.TargetWinRate
+
+	return result, nil
+}
+
+// tuneProbe measures the win rate with the knob set to value. Iterations run
+// in batches and the probe stops early once the 95% interval around the
+// observed rate sits entirely on one side of the target, since from there on
+// more iterations cannot change which way the search steps
+func (s *Simulator) tuneProbe(req TuneRequest, knob TuneKnob, affected map[string]bool, value int, probeCap int) (TuneProbe, error) {
+
+	modified := make([]ScenarioTemplate, len(req.Templates))
+	copy(modified, req.Templates)
+	for i := range modified {
+		if affected[modified[i].Name] {
+			knob(&modified[i], value)
+		}
+	}
+	RegisterScenarioTemplates(modified)
+
+	wins, done := 0, 0
+	for done < probeCap {
+
+		batch := tuneBatchSize
+		if done+batch > probeCap {
+			batch = probeCap - done
+		}
+
+		for i := 0; i < batch; i++ {
+
+			outcome, _, err := s.runScenarioOnce(req.Scenario)
+			if err != nil {
+				return TuneProbe{}, err
+			}
+
+			if outcome.AttackerWon {
+				wins++
+			}
+			done++
+
+		}
+
+		rate := float64(wins) / float64(done)
+		margin := 1.96 * math.Sqrt(rate*(1-rate)/float64(done))
+
+		if done >= 2*tuneBatchSize && (rate-margin > req.TargetWinRate || rate+margin < req.TargetWinRate) {
+			break
+		}
+
+	}
+
+	return TuneProbe{Value: value, WinRate: float64(wins) / float64(done), Iterations: done}, nil
+}
+
+// tuneTargets resolves a side/template selector against the scenario and
+// returns the template names the knob applies to
+func tuneTargets(scenario Scenario, target string) (map[string]bool, error) {
+
+	parts := strings.SplitN(target, "/", 2)
+	if len(parts) != 2 {
+		return nil, fmt.Errorf("tune target %q must look like Defender/* or Attacker/TemplateName", target)
+	}
+
+	var units []ScenarioUnit
+	switch strings.ToLower(parts[0]) {
+	case "attacker":
+		units = scenario.Attacker
+	case "defender":
+		units = scenario.Defender
+	default:
+		return nil, fmt.Errorf("tune target side %q must be Attacker or Defender", parts[0])
+	}
+
+	affected := map[string]bool{}
+	for _, u := range units {
+
+		if parts[1] == "*" || parts[1] == u.Template {
+			affected[u.Template] = true
+		}
+
+	}
+
+	if len(affected) == 0 {
+		return nil, fmt.Errorf("tune target %s matches no template on that side of scenario %s", target, scenario.Name)
+	}
+
+	return affected, nil
+}
+
+// monotoneDirection reports the trend of the coarse probes: +1 rising, -1
+// falling, and whether no adjacent pair moves against the trend by more than
+// the noise tolerance. A flat series counts as monotone either way
+func monotoneDirection(probes []TuneProbe) (int, bool) {
+
+	direction := 0
+	if probes[len(probes)-1].WinRate > probes[0].WinRate {
+		direction = 1
+	} else if probes[len(probes)-1].WinRate < probes[0].WinRate {
+		direction = -1
+	}
+
+	for i := 0; i+1 < len(probes); i++ {
+
+		move := probes[i+1].WinRate - probes[i].WinRate
+		if float64(direction)*move < -tuneNoiseTolerance {
+			return direction, false
+		}
+
+	}
+
+	return direction, true
+}
+
+func straddles(a float64, b float64, target float64) bool {
+	return (a-target)*(b-target) <= 0
+}
+
+func formatProbes(probes []TuneProbe) string {
+
+	parts := make([]string, 0, len(probes))
+	for _, probe := range probes {
+		parts = append(parts, fmt.Sprintf("%d=%.2f", probe.Value, probe.WinRate))
+	}
+
+	return strings.Join(parts, " ")
+}
@@ -0,0 +1,163 @@
+package simulation
+
+import (
+	"math"
+	"strings"
+	"testing"
+
+	"game_main/squads"
+)
+
+// A bruiser whose damage the search turns against a fixed wall. Protection 2
+// and no dodge make the battles deterministic, so the win rate is a clean
+// step function of the damage value and monotonicity holds exactly
+func tuningFixture() (TuneRequest, []ScenarioTemplate) {
+
+	templates := []ScenarioTemplate{
+		{Name: "TuneBruiser", Class: "Knight", MaxHealth: 30, ArmorClass: 10, MovementSpeed: 1, MinDamage: 3, MaxDamage: 3},
+		{Name: "TuneWall", Class: "Knight", MaxHealth: 30, ArmorClass: 10, Protection: 2, MovementSpeed: 1, MinDamage: 3, MaxDamage: 3},
+	}
+
+	scenario := Scenario{
+		Name:     "Tuning",
+		Attacker: []ScenarioUnit{{Template: "TuneBruiser", Row: 0, Col: 0}},
+		Defender: []ScenarioUnit{{Template: "TuneWall", Row: 0, Col: 0}},
+	}
+
+	return TuneRequest{
+		Scenario:        scenario,
+		Templates:       templates,
+		TargetWinRate:   0.55,
+		Attribute:       "Damage",
+		Target:          "Attacker/*",
+		Min:             1,
+		Max:             8,
+		Step:            2,
+		ProbeIterations: 40,
+	}, templates
+}
+
+// Brute-force reference: the win rate at every value in the range, probed
+// the slow way, and the value that lands closest to the target
+func bruteForceCrossing(t *testing.T, sim *Simulator, req TuneRequest) int {
+
+	knob := TuneKnobs[req.Attribute]
+	best, bestGap := req.Min, math.Inf(1)
+
+	for value := req.Min; value <= req.Max; value++ {
+
+		modified := make([]ScenarioTemplate, len(req.Templates))
+		copy(modified, req.Templates)
+		for i := range modified {
+			if modified[i].Name == "TuneBruiser" {
+				knob(&modified[i], value)
+			}
+		}
+		RegisterScenarioTemplates(modified)
+
+		wins := 0
+		for i := 0; i < 20; i++ {
+
+			outcome, _, err := sim.runScenarioOnce(req.Scenario)
+			if err != nil {
+				t.Fatalf("reference battle failed: %v", err)
+			}
+			if outcome.AttackerWon {
+				wins++
+			}
+
+		}
+
+		if gap := math.Abs(float64(wins)/20 - req.TargetWinRate); gap < bestGap {
+			best, bestGap = value, gap
+		}
+
+	}
+
+	return best
+}
+
+// The search lands within one step of the value the brute-force reference
+// says is closest to the target, and leaves the registry as it found it
+func TestTuneConvergesOnAMonotoneMatchup(t *testing.T) {
+
+	req, templates := tuningFixture()
+	sim := NewSimulator()
+
+	want := bruteForceCrossing(t, sim, req)
+
+	result, err := sim.Tune(req)
+	if err != nil {
+		t.Fatalf("the search failed: %v", err)
+	}
+
+	if diff := result.Value - want; diff < -1 || diff > 1 {
+		t.Errorf("the search should land within one step of the true crossing %d, got %d", want, result.Value)
+	}
+
+	if len(result.Probes) < 3 {
+		t.Errorf("the probe history should cover the coarse sweep at least, got %d probes", len(result.Probes))
+	}
+
+	if result.Residual != result.WinRate-req.TargetWinRate {
+		t.Errorf("the residual should be win rate minus target, got %+.3f", result.Residual)
+	}
+
+	//The originals went back in the registry after the run
+	if bruiser, _ := squads.UnitTemplateByName("TuneBruiser"); bruiser.MinDamage != templates[0].MinDamage {
+		t.Errorf("tuning should restore the registered template, found MinDamage %d", bruiser.MinDamage)
+	}
+
+}
+
+// A probe sequence that dips against its own trend by more than the noise
+// tolerance is not monotone, and a flat-with-noise one is
+func TestMonotonicityGuardrail(t *testing.T) {
+
+	dipping := []TuneProbe{
+		{Value: 1, WinRate: 0.2},
+		{Value: 3, WinRate: 0.6},
+		{Value: 5, WinRate: 0.3},
+		{Value: 7, WinRate: 0.8},
+	}
+	if _, monotone := monotoneDirection(dipping); monotone {
+		t.Error("a 0.3 dip against a rising trend should fail the check")
+	}
+
+	noisy := []TuneProbe{
+		{Value: 1, WinRate: 0.40},
+		{Value: 3, WinRate: 0.38},
+		{Value: 5, WinRate: 0.44},
+		{Value: 7, WinRate: 0.47},
+	}
+	if _, monotone := monotoneDirection(noisy); !monotone {
+		t.Error("wobble inside the noise tolerance should pass the check")
+	}
+
+}
+
+// Bad requests fail up front with messages that say what to fix
+func TestTuneRejectsBadRequests(t *testing.T) {
+
+	req, _ := tuningFixture()
+	sim := NewSimulator()
+
+	bad := req
+	bad.Attribute = "Strength"
+	if _, err := sim.Tune(bad); err == nil || !strings.Contains(err.Error(), "Damage") {
+		t.Errorf("an unknown attribute should list the real knobs, got %v", err)
+	}
+
+	bad = req
+	bad.Target = "Defender/Nobody"
+	if _, err := sim.Tune(bad); err == nil || !strings.Contains(err.Error(), "matches no template") {
+		t.Errorf("a selector matching nothing should say so, got %v", err)
+	}
+
+	bad = req
+	bad.Min, bad.Max = 5, 5
+	if _, err := sim.Tune(bad); err == nil || !strings.Contains(err.Error(), "range") {
+		t.Errorf("an empty range should be rejected, got %v", err)
+	}
+
+}
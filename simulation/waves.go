@@ -0,0 +1,81 @@
+package simulation
+
+import (
+	"fmt"
+
+	"game_main/common"
+	"game_main/squads"
+)
+
+// Reinforcement waves let an endurance scenario feed fresh units into a side
+// mid-battle. The wave units join the sides existing squad at the scheduled
+// round, before anything else that round resolves, and the battle record
+// notes the arrival
+
+// ReinforcementWave is the JSON shape of one wave in a scenario
+type ReinforcementWave struct {
+	Round int `json:"round"`
+
+	//Which side the wave joins: "attacker" or "defender"
+	Side string `json:"side"`
+
+	Units []ScenarioUnit `json:"units"`
+}
+
+// A wave with its side resolved to the squad fighting the battle
+type scheduledWave struct {
+	round   int
+	squadID int
+	units   []ScenarioUnit
+}
+
+// scheduleWaves validates a scenarios waves and resolves their sides to the
+// two squad IDs of the battle
+func scheduleWaves(scenario Scenario, attackerSquadID int, defenderSquadID int) ([]scheduledWave, error) {
+
+	waves := make([]scheduledWave, 0, len(scenario.Waves))
+	for _, wave := range scenario.Waves {
+
+		if wave.Round < 1 {
+			return nil, fmt.Errorf("scenario %s has a wave scheduled for round %d", scenario.Name, wave.Round)
+		}
+
+		squadID := 0
+		switch wave.Side {
+		case "attacker":
+			squadID = attackerSquadID
+		case "defender":
+			squadID = defenderSquadID
+		default:
+			return nil, fmt.Errorf("scenario %s has a wave for unknown side %q", scenario.Name, wave.Side)
+		}
+
+		for _, u := range wave.Units {
+			if _, ok := squads.UnitTemplateByName(u.Template); !ok {
+				return nil, fmt.Errorf("scenario %s wave references unknown template %s", scenario.Name, u.Template)
+			}
+		}
+
+		waves = append(waves, scheduledWave{round: wave.Round, squadID: squadID, units: wave.Units})
+
+	}
+
+	return waves, nil
+}
+
+// deployWave creates the wave units inside the receiving squad and records
+// the arrival. Templates were validated when the wave was scheduled
+func deployWave(em *common.EntityManager, record *squads.BattleRecord, wave scheduledWave, round int) {
+
+	event := squads.ReinforcementEvent{Round: round, SquadID: wave.squadID}
+
+	for _, u := range wave.units {
+
+		template, _ := squads.UnitTemplateByName(u.Template)
+		squads.CreateUnitFromTemplate(em, template, wave.squadID, u.Row, u.Col)
+		event.Units = append(event.Units, u.Template)
+
+	}
+
+	record.RecordReinforcement(event)
+}
@@ -0,0 +1,97 @@
+package simulation
+
+import (
+	"game_main/common"
+	"game_main/squads"
+	"testing"
+)
+
+// Slow grinders, so the battle comfortably outlasts the wave schedule
+func registerWaveTemplates() {
+
+	squads.Units["WaveTank"] = squads.UnitTemplate{
+		Name:      "WaveTank",
+		Class:     "Knight",
+		Attr:      common.NewBaseAttributes(40, 0, 10, 0, 1, 0),
+		MinDamage: 2,
+		MaxDamage: 2,
+	}
+
+}
+
+// A defender wave at round 3 joins exactly then: the defenders volleys
+// double from that round on, and the record notes the arrival
+func TestReinforcementWaveArrivesAtItsRound(t *testing.T) {
+
+	registerWaveTemplates()
+
+	scenario := Scenario{
+		Name:     "WaveTest",
+		Attacker: []ScenarioUnit{{Template: "WaveTank", Row: 0, Col: 0}},
+		Defender: []ScenarioUnit{{Template: "WaveTank", Row: 0, Col: 0}},
+		Waves: []ReinforcementWave{
+			{Round: 3, Side: "defender", Units: []ScenarioUnit{{Template: "WaveTank", Row: 0, Col: 1}}},
+		},
+	}
+
+	sim := NewSimulator()
+	_, record, err := sim.runScenarioOnce(scenario)
+	if err != nil {
+		t.Fatalf("running the wave scenario failed: %v", err)
+	}
+
+	counters := map[int]int{}
+	for _, event := range record.Events {
+		if event.Phase == squads.PhaseCounterattack {
+			counters[event.Round]++
+		}
+	}
+
+	if counters[2] != 1 {
+		t.Errorf("round 2 should see 1 counterattack before the wave, got %d", counters[2])
+	}
+	if counters[3] != 2 {
+		t.Errorf("round 3 should see 2 counterattacks once the wave joined, got %d", counters[3])
+	}
+
+	if len(record.Reinforcements) != 1 {
+		t.Fatalf("expected 1 reinforcement entry in the record, got %d", len(record.Reinforcements))
+	}
+
+	arrival := record.Reinforcements[0]
+	if arrival.Round != 3 {
+		t.Errorf("the record should place the wave at round 3, got %d", arrival.Round)
+	}
+	if arrival.SquadID != record.DefenderSquadID {
+		t.Errorf("the wave should join the defender squad %d, got %d", record.DefenderSquadID, arrival.SquadID)
+	}
+	if len(arrival.Units) != 1 || arrival.Units[0] != "WaveTank" {
+		t.Errorf("the record should name the arriving units, got %v", arrival.Units)
+	}
+}
+
+// Bad wave definitions fail the scenario up front instead of mid-battle
+func TestWaveValidationRejectsBadDefinitions(t *testing.T) {
+
+	registerWaveTemplates()
+
+	base := Scenario{
+		Name:     "BadWave",
+		Attacker: []ScenarioUnit{{Template: "WaveTank", Row: 0, Col: 0}},
+		Defender: []ScenarioUnit{{Template: "WaveTank", Row: 0, Col: 0}},
+	}
+
+	sim := NewSimulator()
+
+	unknownSide := base
+	unknownSide.Waves = []ReinforcementWave{{Round: 2, Side: "flank", Units: []ScenarioUnit{{Template: "WaveTank"}}}}
+	if _, _, err := sim.runScenarioOnce(unknownSide); err == nil {
+		t.Error("a wave for an unknown side should fail")
+	}
+
+	unknownTemplate := base
+	unknownTemplate.Waves = []ReinforcementWave{{Round: 2, Side: "defender", Units: []ScenarioUnit{{Template: "NoSuchUnit"}}}}
+	if _, _, err := sim.runScenarioOnce(unknownTemplate); err == nil {
+		t.Error("a wave referencing an unknown template should fail")
+	}
+}
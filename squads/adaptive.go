@@ -0,0 +1,127 @@
+package squads
+
+import (
+	"fmt"
+	"game_main/common"
+	"io"
+)
+
+// Adaptive encounter scaling. A campaign tracks the players army strength as
+// a rolling average of squad threat scores, and encounter generation can
+// blend a share of that metric into the authored budget so a leveled army
+// keeps meeting resistance. The blend is clamped to an authored ceiling and
+// every decision can be logged, so a difficulty spike is always auditable
+
+// ArmyStrengthTracker smooths the army strength over recent observations,
+// so one lucky recruitment turn does not spike the next encounter
+type ArmyStrengthTracker struct {
+	//How much of a new observation replaces the smoothed value, 0..1.
+	//Higher reacts faster
+	Smoothing float64
+
+	value  float64
+	primed bool
+}
+
+func NewArmyStrengthTracker(smoothing float64) *ArmyStrengthTracker {
+
+	if smoothing <= 0 || smoothing > 1 {
+		smoothing = 0.3
+	}
+
+	return &ArmyStrengthTracker{Smoothing: smoothing}
+}
+
+// Observe sums the threat scores of the players squads and folds the total
+// into the rolling value
+func (t *ArmyStrengthTracker) Observe(em *common.EntityManager) float64 {
+
+	total := 0.0
+	for _, result := range em.World.Query(em.WorldTags["squads"]) {
+
+		squad := GetSquadData(result.Entity)
+		if squad.Faction == PlayerFaction {
+			total += float64(ThreatScore(em, squad.SquadID))
+		}
+
+	}
+
+	return t.Record(total)
+}
+
+// Record folds one strength reading into the rolling value. The first
+// reading primes the tracker directly
+func (t *ArmyStrengthTracker) Record(strength float64) float64 {
+
+	if !t.primed {
+		t.value = strength
+		t.primed = true
+		return t.value
+	}
+
+	t.value = t.value*(1-t.Smoothing) + strength*t.Smoothing
+	return t.value
+}
+
+// Strength is the current smoothed army strength
+func (t *ArmyStrengthTracker) Strength() float64 {
+	return t.value
+}
+
+// ScalingDecision is one budget choice with all of its inputs, so a designer
+// reading the campaign debug log can reconstruct why an encounter was sized
+// the way it was
+type ScalingDecision struct {
+	Adaptive     bool
+	BaseBudget   float64
+	ArmyStrength float64
+	BlendPercent int
+	Ceiling      float64
+	Chosen       float64
+}
+
+func (d ScalingDecision) String() string {
+
+	if !d.Adaptive {
+		return fmt.Sprintf("encounter budget %.1f (adaptive scaling off, base %.1f)", d.Chosen, d.BaseBudget)
+	}
+
+	return fmt.Sprintf("encounter budget %.1f (base %.1f + %d%% of army strength %.1f, ceiling %.1f)",
+		d.Chosen, d.BaseBudget, d.BlendPercent, d.ArmyStrength, d.Ceiling)
+}
+
+// ScalingLog receives one line per budget decision when set. The campaign
+// points it at its debug log; nil keeps scaling quiet
+var ScalingLog io.Writer
+
+// DecideEncounterBudget sizes an encounter. With adaptive scaling off the
+// budget is the pure authored depth budget through the difficulty profile;
+// on, a share of the players rolling army strength blends in, clamped to
+// the authored ceiling. Ceiling 0 means no ceiling
+func DecideEncounterBudget(base float64, ceiling float64, armyStrength float64, profile DifficultyProfile) ScalingDecision {
+
+	decision := ScalingDecision{
+		Adaptive:     profile.AdaptiveScaling && profile.AdaptiveBlendPercent > 0,
+		BaseBudget:   base,
+		ArmyStrength: armyStrength,
+		BlendPercent: profile.AdaptiveBlendPercent,
+		Ceiling:      ceiling,
+	}
+
+	decision.Chosen = EncounterBudget(base, profile)
+
+	if decision.Adaptive {
+
+		decision.Chosen += armyStrength * float64(profile.AdaptiveBlendPercent) / 100.0
+		if ceiling > 0 && decision.Chosen > ceiling {
+			decision.Chosen = ceiling
+		}
+
+	}
+
+	if ScalingLog != nil {
+		fmt.Fprintln(ScalingLog, decision.String())
+	}
+
+	return decision
+}
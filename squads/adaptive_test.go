@@ -0,0 +1,105 @@
+package squads
+
+import (
+	"game_main/common"
+	"strings"
+	"testing"
+)
+
+func adaptiveProfile(blendPercent int) DifficultyProfile {
+
+	profile := DifficultyProfileFor(DifficultyNormal)
+	profile.AdaptiveScaling = true
+	profile.AdaptiveBlendPercent = blendPercent
+	return profile
+}
+
+// A strong army raises the budget by exactly its blended share
+func TestAdaptiveBudgetBlendsArmyStrength(t *testing.T) {
+
+	decision := DecideEncounterBudget(100, 0, 80, adaptiveProfile(50))
+
+	if decision.Chosen != 140 {
+		t.Errorf("expected 100 + 50%% of 80 = 140, got %f", decision.Chosen)
+	}
+	if !decision.Adaptive {
+		t.Error("the decision should report itself as adaptive")
+	}
+}
+
+// The authored ceiling caps whatever the blend produces
+func TestAdaptiveBudgetRespectsTheCeiling(t *testing.T) {
+
+	decision := DecideEncounterBudget(100, 120, 200, adaptiveProfile(50))
+
+	if decision.Chosen != 120 {
+		t.Errorf("expected the ceiling of 120, got %f", decision.Chosen)
+	}
+}
+
+// Scaling off reproduces the pure depth budget, army strength ignored
+func TestDisabledScalingKeepsDepthBudgets(t *testing.T) {
+
+	profile := adaptiveProfile(50)
+	profile.AdaptiveScaling = false
+
+	decision := DecideEncounterBudget(100, 120, 500, profile)
+
+	if decision.Chosen != EncounterBudget(100, profile) {
+		t.Errorf("expected the pure depth budget %f, got %f", EncounterBudget(100, profile), decision.Chosen)
+	}
+	if decision.Adaptive {
+		t.Error("the decision should report scaling as off")
+	}
+}
+
+// The tracker primes on its first reading and smooths the later ones
+func TestArmyStrengthSmoothing(t *testing.T) {
+
+	tracker := NewArmyStrengthTracker(0.5)
+
+	if got := tracker.Record(100); got != 100 {
+		t.Errorf("the first reading should prime the tracker at 100, got %f", got)
+	}
+	if got := tracker.Record(0); got != 50 {
+		t.Errorf("expected the collapse to smooth to 50, got %f", got)
+	}
+}
+
+// Observe only counts the players squads
+func TestObserveSumsPlayerThreatOnly(t *testing.T) {
+
+	em := newTestManager()
+	registerTestTemplates()
+
+	player := CreateSquad(em, "Player Army", PlayerFaction, common.Position{X: 2, Y: 2}, DirDown)
+	CreateUnitFromTemplate(em, Units["TestKnight"], player.SquadID, 0, 0)
+	CreateUnitFromTemplate(em, Units["TestKnight"], player.SquadID, 0, 1)
+
+	enemy := CreateSquad(em, "Raiders", "Enemy", common.Position{X: 5, Y: 5}, DirUp)
+	CreateUnitFromTemplate(em, Units["TestKnight"], enemy.SquadID, 0, 0)
+
+	tracker := NewArmyStrengthTracker(1)
+
+	//Two knights averaging 4 damage each
+	if got := tracker.Observe(em); got != 8 {
+		t.Errorf("expected a player army strength of 8, got %f", got)
+	}
+}
+
+// Every decision writes one auditable line to the campaign debug log
+func TestScalingDecisionsAreLogged(t *testing.T) {
+
+	var log strings.Builder
+	ScalingLog = &log
+	defer func() { ScalingLog = nil }()
+
+	DecideEncounterBudget(100, 120, 80, adaptiveProfile(50))
+
+	line := log.String()
+	for _, want := range []string{"120.0", "100.0", "50%", "80.0"} {
+		if !strings.Contains(line, want) {
+			t.Errorf("the log line should mention %s, got: %s", want, line)
+		}
+	}
+}
@@ -0,0 +1,459 @@
+package squads
+
+import (
+	"fmt"
+	"io"
+	"sort"
+
+	"game_main/common"
+
+	"github.com/bytearena/ecs"
+)
+
+// Pluggable squad AI. A controller only proposes actions from read-only
+// views; the engine validates and executes every one of them, so a buggy or
+// hostile controller can reject its way to a wasted turn but never corrupt
+// combat state. Built-in profiles are controllers like any other, and the
+// registry lets difficulty profiles, mods, and the simulator pick them by
+// name
+
+type AIActionType int
+
+const (
+	AIMove AIActionType = iota
+	AIAttack
+	AIGuard
+	AIUseAbility
+	AIUseConsumable
+	AIEndActivation
+)
+
+func (t AIActionType) String() string {
+	switch t {
+	case AIMove:
+		return "Move"
+	case AIAttack:
+		return "Attack"
+	case AIGuard:
+		return "Guard"
+	case AIUseAbility:
+		return "UseAbility"
+	case AIUseConsumable:
+		return "UseConsumable"
+	default:
+		return "EndActivation"
+	}
+}
+
+// AIAction is one proposed step of an activation. Which fields matter
+// depends on the type; the rest stay zero
+type AIAction struct {
+	Type AIActionType
+
+	//Attack and UseAbility aim at a squad
+	TargetSquadID int
+
+	//Move walks to this map tile
+	Dest common.Position
+
+	//UseAbility casts through this unit
+	CasterID ecs.EntityID
+
+	//UseConsumable spends this item, aimed at a grid cell of the own squad
+	ConsumableID string
+	TargetRow    int
+	TargetCol    int
+}
+
+// AISquadView is the read-only squad summary a controller reasons over
+type AISquadView struct {
+	SquadID       int
+	Name          string
+	Faction       string
+	Pos           common.Position
+	LivingUnits   int
+	Threat        int
+	MovementSpeed int
+}
+
+// AIEnemyView is a visible enemy with the cheap combat preview attached
+type AIEnemyView struct {
+	AISquadView
+
+	Distance   int
+	Prediction CombatPrediction
+}
+
+// AIContext is everything a controller gets to see for one activation. All
+// of it is copied out of the world, so a controller can do nothing to the
+// battle by mutating it
+type AIContext struct {
+	Self AISquadView
+
+	//The squads the diplomacy matrix lets it engage, in squad ID order
+	Enemies []AIEnemyView
+
+	Objectives []ObjectiveData
+
+	AttackRange int
+
+	//False once the squad has spent its action this round
+	ActionAvailable bool
+
+	//The squads rally point, if one is set. A copy, like everything else
+	RallyPoint *common.Position
+}
+
+// BuildAIContext assembles the read-only views for one squads activation
+func BuildAIContext(em *common.EntityManager, squadID int, attackRange int) AIContext {
+
+	squad := GetSquadByID(em, squadID)
+	if squad == nil {
+		return AIContext{}
+	}
+
+	ctx := AIContext{
+		Self:            squadView(em, squad),
+		AttackRange:     attackRange,
+		ActionAvailable: !squad.ActionSpent,
+	}
+
+	if squad.RallyPoint != nil {
+		rally := *squad.RallyPoint
+		ctx.RallyPoint = &rally
+	}
+
+	for _, enemyID := range ValidTargetSquads(em, squadID) {
+
+		enemy := GetSquadByID(em, enemyID)
+		ctx.Enemies = append(ctx.Enemies, AIEnemyView{
+			AISquadView: squadView(em, enemy),
+			Distance:    GetSquadDistance(em, squadID, enemyID),
+			Prediction:  PredictCombatOutcome(em, squadID, enemyID),
+		})
+
+	}
+
+	for _, objective := range Objectives(em) {
+		ctx.Objectives = append(ctx.Objectives, *objective)
+	}
+
+	return ctx
+}
+
+func squadView(em *common.EntityManager, squad *SquadData) AISquadView {
+
+	living := 0
+	for _, u := range SquadUnits(em, squad.SquadID) {
+		if common.GetAttributes(u).CurrentHealth > 0 {
+			living++
+		}
+	}
+
+	return AISquadView{
+		SquadID:       squad.SquadID,
+		Name:          squad.Name,
+		Faction:       squad.Faction,
+		Pos:           squad.MapPos,
+		LivingUnits:   living,
+		Threat:        ThreatScore(em, squad.SquadID),
+		MovementSpeed: SquadMovementSpeed(em, squad.SquadID),
+	}
+}
+
+// SquadAIController decides what one squad does with its activation. The
+// returned actions run in order until EndActivation or the list ends;
+// anything illegal is rejected individually and the rest still runs
+type SquadAIController interface {
+	Name() string
+	DecideActivation(ctx AIContext) []AIAction
+}
+
+var aiControllers = map[string]SquadAIController{}
+
+// RegisterAIController adds the controller under its own name, replacing any
+// previous registration so mods can override the built-in profiles
+func RegisterAIController(controller SquadAIController) {
+	aiControllers[controller.Name()] = controller
+}
+
+func AIControllerByName(name string) (SquadAIController, bool) {
+
+	controller, ok := aiControllers[name]
+	return controller, ok
+}
+
+// AIControllerNames lists the registered controllers, sorted, for CLI error
+// messages and mod tooling
+func AIControllerNames() []string {
+
+	names := make([]string, 0, len(aiControllers))
+	for name := range aiControllers {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+	return names
+}
+
+// The built-in profiles, expressed through the same interface as everything
+// else. They pick one attack from the context views and end the activation
+type builtinAIController struct {
+	name   string
+	config AIConfig
+}
+
+func (c builtinAIController) Name() string {
+	return c.name
+}
+
+func (c builtinAIController) DecideActivation(ctx AIContext) []AIAction {
+
+	target := 0
+
+	if c.config.PreferFinishingBlows {
+
+		bestHealth := 0
+		for _, enemy := range ctx.Enemies {
+
+			if enemy.Distance > ctx.AttackRange || !enemy.Prediction.Lethal {
+				continue
+			}
+			if target == 0 || enemy.Prediction.DefenderHealth > bestHealth {
+				target = enemy.SquadID
+				bestHealth = enemy.Prediction.DefenderHealth
+			}
+
+		}
+
+	}
+
+	if target == 0 {
+
+		bestThreat := -1
+		for _, enemy := range ctx.Enemies {
+
+			if enemy.Distance > ctx.AttackRange {
+				continue
+			}
+			if enemy.Threat > bestThreat {
+				target = enemy.SquadID
+				bestThreat = enemy.Threat
+			}
+
+		}
+
+	}
+
+	actions := []AIAction{}
+
+	if dest, ok := c.moveDestination(ctx); ok {
+		actions = append(actions, AIAction{Type: AIMove, Dest: dest})
+	}
+
+	if target != 0 {
+		actions = append(actions, AIAction{Type: AIAttack, TargetSquadID: target})
+	}
+
+	return append(actions, AIAction{Type: AIEndActivation})
+}
+
+// Where the activation walks, if anywhere. A rally point takes priority: the
+// squad marches there and then holds it, so a chokepoint defense actually
+// reaches the chokepoint. Without one the squad advances at the nearest
+// enemy until somebody is in attack range
+func (c builtinAIController) moveDestination(ctx AIContext) (common.Position, bool) {
+
+	if ctx.RallyPoint != nil {
+
+		if ctx.Self.Pos == *ctx.RallyPoint {
+			return common.Position{}, false
+		}
+
+		return stepToward(ctx.Self.Pos, *ctx.RallyPoint, ctx.Self.MovementSpeed)
+	}
+
+	var nearest *AIEnemyView
+	for i := range ctx.Enemies {
+
+		enemy := &ctx.Enemies[i]
+		if enemy.Distance <= ctx.AttackRange {
+			return common.Position{}, false
+		}
+		if nearest == nil || enemy.Distance < nearest.Distance {
+			nearest = enemy
+		}
+
+	}
+
+	if nearest == nil {
+		return common.Position{}, false
+	}
+
+	return stepToward(ctx.Self.Pos, nearest.Pos, ctx.Self.MovementSpeed)
+}
+
+// stepToward walks from one position toward another, at most speed tiles
+// under the squad distance metric. Chebyshev lets each axis close
+// independently, so clamping them one by one stays within the budget
+func stepToward(from common.Position, to common.Position, speed int) (common.Position, bool) {
+
+	dest := common.Position{
+		X: from.X + clampStep(to.X-from.X, speed),
+		Y: from.Y + clampStep(to.Y-from.Y, speed),
+	}
+
+	return dest, dest != from
+}
+
+func clampStep(delta int, speed int) int {
+
+	if delta > speed {
+		return speed
+	}
+	if delta < -speed {
+		return -speed
+	}
+
+	return delta
+}
+
+func init() {
+	RegisterAIController(builtinAIController{name: "default", config: DefaultAIConfig})
+	RegisterAIController(builtinAIController{name: "cautious", config: AIConfig{}})
+}
+
+// RejectedAIAction is an action the engine refused, with the concrete reason
+type RejectedAIAction struct {
+	Action AIAction
+	Reason string
+}
+
+// ActivationResult is what one activation actually did: the actions that
+// ran, the ones the engine refused, and the attack events produced
+type ActivationResult struct {
+	Executed []AIAction
+	Rejected []RejectedAIAction
+	Events   []AttackEvent
+}
+
+// AILog receives one line per rejected action when set, like ScalingLog.
+// Nil keeps rejections silent outside the activation result
+var AILog io.Writer
+
+// ExecuteActivation runs one controller-driven activation for the squad.
+// Every proposed action is validated against the same rules the player
+// obeys; illegal ones are rejected and logged, and a controller that
+// proposes nothing usable simply ends its activation having done nothing
+func ExecuteActivation(em *common.EntityManager, squadID int, controller SquadAIController, attackRange int) ActivationResult {
+
+	result := ActivationResult{}
+
+	squad := GetSquadByID(em, squadID)
+	if squad == nil {
+		return result
+	}
+
+	moved := false
+	for _, action := range controller.DecideActivation(BuildAIContext(em, squadID, attackRange)) {
+
+		if action.Type == AIEndActivation {
+			result.Executed = append(result.Executed, action)
+			break
+		}
+
+		if reason := rejectionReason(em, squad, action, moved, attackRange); reason != "" {
+			rejectAIAction(&result, squadID, action, reason)
+			continue
+		}
+
+		switch action.Type {
+
+		case AIMove:
+			MoveSquadTo(em, nil, squadID, action.Dest)
+			moved = true
+
+		case AIAttack:
+			target := GetSquadByID(em, action.TargetSquadID)
+			events := ExecuteSquadAttack(em, squadID, action.TargetSquadID, overwatchFireDirection(squad.MapPos, target.MapPos))
+			result.Events = append(result.Events, events...)
+			squad.ActionSpent = true
+
+		case AIGuard:
+			//Holding formation: the action is spent without anyone swinging,
+			//which under bracing rules keeps every shield up
+			squad.ActionSpent = true
+
+		case AIUseAbility:
+			if _, err := UseSupportAbility(em, action.CasterID, action.TargetSquadID); err != nil {
+				rejectAIAction(&result, squadID, action, err.Error())
+				continue
+			}
+			squad.ActionSpent = true
+
+		case AIUseConsumable:
+			target := ConsumableTarget{SquadID: squadID, Row: action.TargetRow, Col: action.TargetCol}
+			if _, err := UseConsumable(squadID, action.ConsumableID, target, em); err != nil {
+				rejectAIAction(&result, squadID, action, err.Error())
+				continue
+			}
+			squad.ActionSpent = true
+
+		}
+
+		result.Executed = append(result.Executed, action)
+
+	}
+
+	return result
+}
+
+func rejectAIAction(result *ActivationResult, squadID int, action AIAction, reason string) {
+
+	result.Rejected = append(result.Rejected, RejectedAIAction{Action: action, Reason: reason})
+
+	if AILog != nil {
+		fmt.Fprintf(AILog, "squad %d: rejected %s: %s\n", squadID, action.Type, reason)
+	}
+
+}
+
+// The checks that can run before touching anything. Execution-time failures
+// (an ability that fizzles) reject at the call site instead
+func rejectionReason(em *common.EntityManager, squad *SquadData, action AIAction, moved bool, attackRange int) string {
+
+	//Everything but movement is the squads one action per round
+	if action.Type != AIMove && squad.ActionSpent {
+		return "the squad already spent its action this round"
+	}
+
+	switch action.Type {
+
+	case AIMove:
+
+		if moved {
+			return "the squad already moved this activation"
+		}
+
+		speed := SquadMovementSpeed(em, squad.SquadID)
+		if distance := squad.MapPos.SquadDistance(&action.Dest); distance > speed {
+			return fmt.Sprintf("destination (%d, %d) is %d tiles away, movement is %d", action.Dest.X, action.Dest.Y, distance, speed)
+		}
+
+	case AIAttack:
+
+		if code, reason := CheckAttackEligibility(em, squad.SquadID, action.TargetSquadID, attackRange); code != EligibilityOK {
+			return reason
+		}
+
+	case AIUseAbility:
+
+		caster := FindUnitByID(em, action.CasterID)
+		if caster == nil || GetUnitData(caster).SquadID != squad.SquadID {
+			return fmt.Sprintf("caster %d is not a unit of squad %d", action.CasterID, squad.SquadID)
+		}
+
+	}
+
+	return ""
+}
@@ -0,0 +1,197 @@
+package squads
+
+import (
+	"game_main/common"
+	"testing"
+)
+
+// A controller that replays a fixed action list, which is all a malicious or
+// buggy mod controller amounts to from the engines point of view
+type scriptedAIController struct {
+	name    string
+	actions []AIAction
+}
+
+func (c scriptedAIController) Name() string {
+	return c.name
+}
+
+func (c scriptedAIController) DecideActivation(ctx AIContext) []AIAction {
+	return c.actions
+}
+
+// A deliberately malicious controller: attack an ally, attack out of range,
+// teleport across the map, spend an item it does not have, cast through a
+// unit it does not own. Every action must be rejected with a reason and the
+// world must come out untouched
+func TestEngineRejectsIllegalAIActions(t *testing.T) {
+
+	em := newTestManager()
+	registerTestTemplates()
+
+	player := CreateSquad(em, "Vanguard", "Player", common.Position{X: 5, Y: 5}, DirDown)
+	CreateUnitFromTemplate(em, Units["TestKnight"], player.SquadID, 0, 0)
+
+	ally := CreateSquad(em, "Friends", "Player", common.Position{X: 4, Y: 5}, DirDown)
+	allyUnit := CreateUnitFromTemplate(em, Units["TestKnight"], ally.SquadID, 0, 0)
+
+	far := CreateSquad(em, "Far", "Enemy", common.Position{X: 5, Y: 9}, DirUp)
+	farUnit := CreateUnitFromTemplate(em, Units["TestKnight"], far.SquadID, 0, 0)
+
+	malicious := scriptedAIController{name: "malicious", actions: []AIAction{
+		{Type: AIAttack, TargetSquadID: ally.SquadID},
+		{Type: AIAttack, TargetSquadID: far.SquadID},
+		{Type: AIMove, Dest: common.Position{X: 20, Y: 20}},
+		{Type: AIUseConsumable, ConsumableID: "phantom potion"},
+		{Type: AIUseAbility, CasterID: 99999, TargetSquadID: player.SquadID},
+		{Type: AIEndActivation},
+	}}
+
+	result := ExecuteActivation(em, player.SquadID, malicious, 1)
+
+	if len(result.Rejected) != 5 {
+		t.Fatalf("all five illegal actions should be rejected, got %d", len(result.Rejected))
+	}
+	for _, rejected := range result.Rejected {
+		if rejected.Reason == "" {
+			t.Errorf("rejected %s without a reason", rejected.Action.Type)
+		}
+	}
+
+	if len(result.Executed) != 1 || result.Executed[0].Type != AIEndActivation {
+		t.Errorf("only EndActivation should have run, got %v", result.Executed)
+	}
+	if len(result.Events) != 0 {
+		t.Errorf("no attack should have resolved, got %d events", len(result.Events))
+	}
+
+	if hp := common.GetAttributes(allyUnit).CurrentHealth; hp != 20 {
+		t.Errorf("the ally should be untouched, has %d HP", hp)
+	}
+	if hp := common.GetAttributes(farUnit).CurrentHealth; hp != 20 {
+		t.Errorf("the out-of-range enemy should be untouched, has %d HP", hp)
+	}
+	if player.MapPos != (common.Position{X: 5, Y: 5}) {
+		t.Errorf("the squad should not have moved, sits at %v", player.MapPos)
+	}
+	if player.ActionSpent {
+		t.Error("a fully rejected activation should not spend the squads action")
+	}
+
+}
+
+// The built-in profiles come out of the registry and fight through the same
+// validated path as everything else
+func TestBuiltinControllersAreRegistered(t *testing.T) {
+
+	for _, name := range []string{"default", "cautious"} {
+		if _, ok := AIControllerByName(name); !ok {
+			t.Errorf("the %s profile should be registered", name)
+		}
+	}
+	if _, ok := AIControllerByName("does-not-exist"); ok {
+		t.Error("an unregistered name should not resolve")
+	}
+
+	em := newTestManager()
+	registerTestTemplates()
+
+	player := CreateSquad(em, "Vanguard", "Player", common.Position{X: 5, Y: 5}, DirDown)
+	CreateUnitFromTemplate(em, Units["TestKnight"], player.SquadID, 0, 0)
+
+	enemy := CreateSquad(em, "Raiders", "Enemy", common.Position{X: 5, Y: 6}, DirUp)
+	victim := CreateUnitFromTemplate(em, Units["TestRogue"], enemy.SquadID, 0, 0)
+
+	controller, _ := AIControllerByName("default")
+	result := ExecuteActivation(em, player.SquadID, controller, 1)
+
+	if len(result.Rejected) != 0 {
+		t.Fatalf("the built-in profile should only propose legal actions, got rejections %v", result.Rejected)
+	}
+	if len(result.Events) == 0 || common.GetAttributes(victim).CurrentHealth >= 10 {
+		t.Error("the default profile should have attacked the enemy in range")
+	}
+	if !player.ActionSpent {
+		t.Error("the attack should spend the squads action")
+	}
+
+}
+
+// A squad with a rally point marches there step by step and then holds it,
+// instead of chasing the enemy across the map
+func TestRallyPointComesBeforeEngaging(t *testing.T) {
+
+	em := newTestManager()
+	registerTestTemplates()
+
+	player := CreateSquad(em, "Garrison", "Player", common.Position{X: 2, Y: 2}, DirDown)
+	CreateUnitFromTemplate(em, Units["TestKnight"], player.SquadID, 0, 0)
+	player.RallyPoint = &common.Position{X: 2, Y: 5}
+
+	enemy := CreateSquad(em, "Raiders", "Enemy", common.Position{X: 8, Y: 2}, DirUp)
+	CreateUnitFromTemplate(em, Units["TestKnight"], enemy.SquadID, 0, 0)
+
+	controller, _ := AIControllerByName("default")
+
+	result := ExecuteActivation(em, player.SquadID, controller, 1)
+	if len(result.Executed) != 2 || result.Executed[0].Type != AIMove {
+		t.Fatalf("the squad should march for the rally point, got %v", result.Executed)
+	}
+	if player.MapPos != (common.Position{X: 2, Y: 3}) {
+		t.Fatalf("one activation should close one tile toward the rally point, sits at %v", player.MapPos)
+	}
+	if len(result.Events) != 0 {
+		t.Errorf("nothing is in range, no attack should land, got %d events", len(result.Events))
+	}
+
+	for range 2 {
+		ExecuteActivation(em, player.SquadID, controller, 1)
+	}
+	if player.MapPos != *player.RallyPoint {
+		t.Fatalf("three activations should reach the rally point, sits at %v", player.MapPos)
+	}
+
+	result = ExecuteActivation(em, player.SquadID, controller, 1)
+	if len(result.Executed) != 1 || result.Executed[0].Type != AIEndActivation {
+		t.Errorf("a squad on its rally point should hold instead of advancing, got %v", result.Executed)
+	}
+
+}
+
+// Without a rally point the squad advances directly at the nearest enemy and
+// attacks once it is in range
+func TestWithoutRallyPointAdvancesAtTheEnemy(t *testing.T) {
+
+	em := newTestManager()
+	registerTestTemplates()
+
+	player := CreateSquad(em, "Vanguard", "Player", common.Position{X: 2, Y: 2}, DirDown)
+	CreateUnitFromTemplate(em, Units["TestKnight"], player.SquadID, 0, 0)
+
+	enemy := CreateSquad(em, "Raiders", "Enemy", common.Position{X: 5, Y: 2}, DirUp)
+	victim := CreateUnitFromTemplate(em, Units["TestKnight"], enemy.SquadID, 0, 0)
+
+	controller, _ := AIControllerByName("default")
+
+	result := ExecuteActivation(em, player.SquadID, controller, 1)
+	if player.MapPos != (common.Position{X: 3, Y: 2}) {
+		t.Fatalf("the squad should advance at the enemy, sits at %v", player.MapPos)
+	}
+	if len(result.Events) != 0 {
+		t.Errorf("the enemy is still out of range, got %d events", len(result.Events))
+	}
+
+	result = ExecuteActivation(em, player.SquadID, controller, 1)
+	if player.MapPos != (common.Position{X: 4, Y: 2}) {
+		t.Fatalf("the second activation should close to attack range, sits at %v", player.MapPos)
+	}
+
+	result = ExecuteActivation(em, player.SquadID, controller, 1)
+	if len(result.Events) == 0 || common.GetAttributes(victim).CurrentHealth >= 20 {
+		t.Error("once in range the squad should stand and attack")
+	}
+	if player.MapPos != (common.Position{X: 4, Y: 2}) {
+		t.Errorf("an in-range squad should not keep walking, sits at %v", player.MapPos)
+	}
+
+}
@@ -0,0 +1,107 @@
+package squads
+
+import (
+	"fmt"
+
+	"github.com/bytearena/ecs"
+
+	"game_main/common"
+)
+
+// Squad banners make similarly named squads tell apart on the map and in the
+// logs. Colors default per faction and a squad can override them. The actual
+// tinting lives in the graphics package, this is just the data
+
+// BannerColor is plain RGB so it serializes cleanly into save files and
+// battle records
+type BannerColor struct {
+	R uint8 `json:"r"`
+	G uint8 `json:"g"`
+	B uint8 `json:"b"`
+}
+
+// ANSISwatch renders the color as a small colored block for terminal output.
+// When ANSI is off it degrades to a plain marker so logs stay readable
+func (c BannerColor) ANSISwatch(ansi bool) string {
+
+	if !ansi {
+		return "[#]"
+	}
+
+	return fmt.Sprintf("\x1b[38;2;%d;%d;%dm██\x1b[0m", c.R, c.G, c.B)
+}
+
+var SquadAppearanceComponent *ecs.Component
+
+type SquadAppearance struct {
+	Primary    BannerColor `json:"primary"`
+	Secondary  BannerColor `json:"secondary"`
+	BannerIcon string      `json:"bannerIcon"`
+}
+
+// The built-in banner icon set the squad editor picks from
+var BannerIcons = []string{"sword", "shield", "wolf", "tower", "star"}
+
+func IsValidBannerIcon(icon string) bool {
+
+	for _, i := range BannerIcons {
+		if i == icon {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Factions the game knows about get distinct defaults, everything else falls
+// back to gray so an unstyled squad is still visible
+var factionDefaults = map[string]SquadAppearance{
+	"Player": {Primary: BannerColor{R: 60, G: 120, B: 220}, Secondary: BannerColor{R: 220, G: 220, B: 240}, BannerIcon: "shield"},
+	"Enemy":  {Primary: BannerColor{R: 200, G: 50, B: 50}, Secondary: BannerColor{R: 40, G: 20, B: 20}, BannerIcon: "sword"},
+}
+
+func DefaultAppearanceForFaction(faction string) SquadAppearance {
+
+	if appearance, ok := factionDefaults[faction]; ok {
+		return appearance
+	}
+
+	return SquadAppearance{
+		Primary:    BannerColor{R: 128, G: 128, B: 128},
+		Secondary:  BannerColor{R: 64, G: 64, B: 64},
+		BannerIcon: "star",
+	}
+}
+
+// GetSquadAppearance returns the squads own appearance if one was set, the
+// faction default otherwise
+func GetSquadAppearance(em *common.EntityManager, squadID int) SquadAppearance {
+
+	squad := GetSquadEntity(em, squadID)
+	if squad == nil {
+		return DefaultAppearanceForFaction("")
+	}
+
+	if override := common.GetComponentType[*SquadAppearance](squad, SquadAppearanceComponent); override != nil {
+		return *override
+	}
+
+	return DefaultAppearanceForFaction(GetSquadData(squad).Faction)
+}
+
+// SetSquadAppearance attaches a per-squad override. Invalid icons are
+// rejected so the renderer never sees a key outside the built-in set
+func SetSquadAppearance(em *common.EntityManager, squadID int, appearance SquadAppearance) error {
+
+	if !IsValidBannerIcon(appearance.BannerIcon) {
+		return fmt.Errorf("unknown banner icon %s", appearance.BannerIcon)
+	}
+
+	squad := GetSquadEntity(em, squadID)
+	if squad == nil {
+		return fmt.Errorf("no squad with ID %d", squadID)
+	}
+
+	squad.AddComponent(SquadAppearanceComponent, &appearance)
+	return nil
+}
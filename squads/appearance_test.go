@@ -0,0 +1,73 @@
+package squads
+
+import (
+	"encoding/json"
+	"game_main/common"
+	"testing"
+)
+
+// A squad without an override wears its faction colors, one with an override
+// wears its own, and the override survives a JSON round trip
+func TestAppearanceDefaultsAndOverrides(t *testing.T) {
+
+	em := newTestManager()
+
+	squad := CreateSquad(em, "First Lance", "Player", common.Position{X: 0, Y: 0}, DirUp)
+
+	if GetSquadAppearance(em, squad.SquadID) != DefaultAppearanceForFaction("Player") {
+		t.Error("a squad without an override should wear its faction colors")
+	}
+
+	custom := SquadAppearance{
+		Primary:    BannerColor{R: 10, G: 200, B: 30},
+		Secondary:  BannerColor{R: 1, G: 2, B: 3},
+		BannerIcon: "wolf",
+	}
+	if err := SetSquadAppearance(em, squad.SquadID, custom); err != nil {
+		t.Fatalf("setting a valid appearance failed: %v", err)
+	}
+
+	if GetSquadAppearance(em, squad.SquadID) != custom {
+		t.Error("the per-squad override should win over the faction default")
+	}
+
+	if err := SetSquadAppearance(em, squad.SquadID, SquadAppearance{BannerIcon: "doodle"}); err == nil {
+		t.Error("an icon outside the built-in set should be rejected")
+	}
+}
+
+func TestAppearanceSerializationRoundTrip(t *testing.T) {
+
+	original := SquadAppearance{
+		Primary:    BannerColor{R: 200, G: 50, B: 50},
+		Secondary:  BannerColor{R: 40, G: 20, B: 20},
+		BannerIcon: "sword",
+	}
+
+	contents, err := json.Marshal(original)
+	if err != nil {
+		t.Fatalf("marshal failed: %v", err)
+	}
+
+	var restored SquadAppearance
+	if err := json.Unmarshal(contents, &restored); err != nil {
+		t.Fatalf("unmarshal failed: %v", err)
+	}
+
+	if restored != original {
+		t.Errorf("appearance did not round trip: %+v vs %+v", restored, original)
+	}
+}
+
+func TestANSISwatchDegradesToPlainText(t *testing.T) {
+
+	color := BannerColor{R: 1, G: 2, B: 3}
+
+	if color.ANSISwatch(false) != "[#]" {
+		t.Error("expected a plain marker when ANSI is off")
+	}
+
+	if color.ANSISwatch(true) == "[#]" {
+		t.Error("expected an escape sequence when ANSI is on")
+	}
+}
@@ -0,0 +1,107 @@
+package squads
+
+import (
+	"sort"
+
+	"game_main/common"
+
+	"github.com/bytearena/ecs"
+)
+
+// Squad artifacts are relics that buff squads rather than single units.
+// Unit gear lives in the gear package; artifacts live here because their
+// reach is defined in squad terms - one squad, or every squad of a faction,
+// like a Rallying Horn whose aura lifts the whole army
+
+type ArtifactReach int
+
+const (
+	//The artifact only affects the squad carrying it
+	ArtifactSquad ArtifactReach = iota
+
+	//The artifact affects every squad of its owners faction
+	ArtifactFaction
+)
+
+var ArtifactComponent *ecs.Component
+
+type ArtifactData struct {
+	Name  string
+	Reach ArtifactReach
+
+	//The squad carrying the artifact
+	SquadID int
+}
+
+func GetArtifactData(e *ecs.Entity) *ArtifactData {
+	return common.GetComponentType[*ArtifactData](e, ArtifactComponent)
+}
+
+// CreateArtifact makes the artifact entity, carried by the given squad
+func CreateArtifact(em *common.EntityManager, name string, reach ArtifactReach, squadID int) *ecs.Entity {
+
+	artifact := em.World.NewEntity()
+	artifact.AddComponent(ArtifactComponent, &ArtifactData{
+		Name:    name,
+		Reach:   reach,
+		SquadID: squadID,
+	})
+
+	return artifact
+}
+
+func artifactByID(em *common.EntityManager, artifactID ecs.EntityID) *ecs.Entity {
+
+	for _, a := range em.World.Query(em.WorldTags["artifacts"]) {
+
+		if a.Entity.GetID() == artifactID {
+			return a.Entity
+		}
+
+	}
+
+	return nil
+}
+
+// GetAffectedSquads returns the squad entities the artifact currently
+// reaches, so the UI can highlight them. A squad artifact reaches only its
+// carrier; a faction artifact reaches every squad of the given faction.
+// Sorted by squad ID so highlights render in a stable order
+func GetAffectedSquads(em *common.EntityManager, artifactID ecs.EntityID, faction string) []ecs.EntityID {
+
+	artifact := artifactByID(em, artifactID)
+	if artifact == nil {
+		return nil
+	}
+
+	data := GetArtifactData(artifact)
+	affected := []*ecs.Entity{}
+
+	for _, s := range em.World.Query(em.WorldTags["squads"]) {
+
+		squad := GetSquadData(s.Entity)
+
+		switch data.Reach {
+		case ArtifactFaction:
+			if squad.Faction == faction {
+				affected = append(affected, s.Entity)
+			}
+		default:
+			if squad.SquadID == data.SquadID {
+				affected = append(affected, s.Entity)
+			}
+		}
+
+	}
+
+	sort.Slice(affected, func(i, j int) bool {
+		return GetSquadData(affected[i]).SquadID < GetSquadData(affected[j]).SquadID
+	})
+
+	ids := make([]ecs.EntityID, 0, len(affected))
+	for _, s := range affected {
+		ids = append(ids, s.GetID())
+	}
+
+	return ids
+}
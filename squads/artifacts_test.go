@@ -0,0 +1,65 @@
+package squads
+
+import (
+	"game_main/common"
+	"testing"
+
+	"github.com/bytearena/ecs"
+)
+
+// Three player squads, one enemy squad, so reach has something to tell apart
+func artifactFixture() (*common.EntityManager, []*SquadData) {
+
+	em := newTestManager()
+	registerTestTemplates()
+
+	first := CreateSquad(em, "Vanguard", "Player", common.Position{X: 1, Y: 1}, DirDown)
+	second := CreateSquad(em, "Rearguard", "Player", common.Position{X: 2, Y: 1}, DirDown)
+	third := CreateSquad(em, "Reserve", "Player", common.Position{X: 3, Y: 1}, DirDown)
+	CreateSquad(em, "Raiders", "Enemy", common.Position{X: 9, Y: 9}, DirUp)
+
+	return em, []*SquadData{first, second, third}
+}
+
+// A faction-wide artifact reaches every squad of the faction and none of the
+// enemys
+func TestFactionArtifactReachesTheWholeFaction(t *testing.T) {
+
+	em, playerSquads := artifactFixture()
+
+	horn := CreateArtifact(em, "Rallying Horn", ArtifactFaction, playerSquads[0].SquadID)
+
+	affected := GetAffectedSquads(em, horn.GetID(), "Player")
+	if len(affected) != 3 {
+		t.Fatalf("the horn should reach all 3 player squads, got %d", len(affected))
+	}
+
+	for i, id := range affected {
+		if GetSquadData(em.World.GetEntityByID(id).Entity).SquadID != playerSquads[i].SquadID {
+			t.Errorf("affected squad %d should be %s in squad ID order", i, playerSquads[i].Name)
+		}
+	}
+
+}
+
+// A single-squad artifact only reaches its carrier
+func TestSquadArtifactReachesOnlyItsCarrier(t *testing.T) {
+
+	em, playerSquads := artifactFixture()
+
+	banner := CreateArtifact(em, "Old Banner", ArtifactSquad, playerSquads[1].SquadID)
+
+	affected := GetAffectedSquads(em, banner.GetID(), "Player")
+	if len(affected) != 1 {
+		t.Fatalf("the banner should reach exactly its carrier, got %d squads", len(affected))
+	}
+
+	if GetSquadData(em.World.GetEntityByID(affected[0]).Entity).SquadID != playerSquads[1].SquadID {
+		t.Error("the banner should reach the squad carrying it")
+	}
+
+	if got := GetAffectedSquads(em, ecs.EntityID(99999), "Player"); got != nil {
+		t.Errorf("an unknown artifact should reach nothing, got %v", got)
+	}
+
+}
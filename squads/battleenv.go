@@ -0,0 +1,48 @@
+package squads
+
+// The slice of the environmental cycle that combat cares about. The
+// strategic layer (or a scenario) sets ActiveEnvironment before a battle and
+// clears it after; the zero value means fair weather and no modifiers.
+// Package-level like the Fatigue and Enrage knobs so the damage pipeline
+// can read it without threading state through every call
+
+type BattleEnvironment struct {
+	Phase   string `json:"phase,omitempty"`
+	Weather string `json:"weather,omitempty"`
+
+	//Flat damage taken off every attack, the combat face of rain
+	AttackPenalty int `json:"attackPenalty,omitempty"`
+
+	//Flat bonus for units whose template is flagged nocturnal, only
+	//meaningful when the phase is Night
+	NocturnalBonus int `json:"nocturnalBonus,omitempty"`
+}
+
+var ActiveEnvironment BattleEnvironment
+
+// EnvironmentFor maps conditions to combat numbers in one place so the
+// strategic layer and scenario files cannot drift apart
+func EnvironmentFor(phase string, weather string) BattleEnvironment {
+
+	env := BattleEnvironment{Phase: phase, Weather: weather}
+
+	if weather == "Rain" {
+		env.AttackPenalty = 1
+	}
+	if phase == "Night" {
+		env.NocturnalBonus = 2
+	}
+
+	return env
+}
+
+// environmentDamageDelta is the environments contribution to one attack
+func environmentDamageDelta(attacker *UnitTemplate) int {
+
+	delta := -ActiveEnvironment.AttackPenalty
+	if attacker.Nocturnal && ActiveEnvironment.Phase == "Night" {
+		delta += ActiveEnvironment.NocturnalBonus
+	}
+
+	return delta
+}
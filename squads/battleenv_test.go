@@ -0,0 +1,74 @@
+package squads
+
+import (
+	"game_main/common"
+	"testing"
+)
+
+// One knight-on-knight attack under controlled conditions
+func environmentAttackDamage(t *testing.T, env BattleEnvironment, attackerTemplate string) int {
+
+	t.Helper()
+
+	em := newTestManager()
+	registerTestTemplates()
+
+	originalRoll := dodgeRoll
+	dodgeRoll = func() int { return 100 }
+	defer func() { dodgeRoll = originalRoll }()
+
+	ActiveEnvironment = env
+	defer func() { ActiveEnvironment = BattleEnvironment{} }()
+
+	att := CreateSquad(em, "Attackers", "Player", common.Position{X: 5, Y: 3}, DirDown)
+	attacker := CreateUnitFromTemplate(em, Units[attackerTemplate], att.SquadID, 0, 0)
+
+	def := CreateSquad(em, "Defenders", "Enemy", common.Position{X: 5, Y: 4}, DirUp)
+	defender := CreateUnitFromTemplate(em, Units["TestKnight"], def.SquadID, 0, 0)
+
+	return calculateUnitDamageByID(em, attacker.GetID(), defender.GetID(), DirDown).Damage
+}
+
+// Rain takes its flat penalty off the attack, and the record keeps the
+// conditions for segmentation
+func TestRainPenaltyLandsInAttackEvents(t *testing.T) {
+
+	fair := environmentAttackDamage(t, BattleEnvironment{}, "TestKnight")
+	rain := EnvironmentFor("Day", "Rain")
+	wet := environmentAttackDamage(t, rain, "TestKnight")
+
+	if wet != fair-rain.AttackPenalty {
+		t.Errorf("expected rain to cost %d damage: fair %d, wet %d", rain.AttackPenalty, fair, wet)
+	}
+
+	ActiveEnvironment = rain
+	record := NewBattleRecord(1, 2)
+	ActiveEnvironment = BattleEnvironment{}
+
+	if record.Environment.Weather != "Rain" {
+		t.Error("the battle record should keep the conditions it was fought under")
+	}
+}
+
+// Nocturnal templates hit harder at night and normal ones don't
+func TestNocturnalBonusOnlyAppliesAtNight(t *testing.T) {
+
+	owl := Units["TestRogue"]
+	owl.Name = "TestOwl"
+	owl.Nocturnal = true
+	RegisterUnitTemplate(owl)
+
+	night := EnvironmentFor("Night", "Clear")
+
+	day := environmentAttackDamage(t, BattleEnvironment{}, "TestOwl")
+	dark := environmentAttackDamage(t, night, "TestOwl")
+	if dark != day+night.NocturnalBonus {
+		t.Errorf("expected the nocturnal bonus %d at night: day %d, night %d", night.NocturnalBonus, day, dark)
+	}
+
+	knightDay := environmentAttackDamage(t, BattleEnvironment{}, "TestKnight")
+	knightNight := environmentAttackDamage(t, night, "TestKnight")
+	if knightNight != knightDay {
+		t.Errorf("a diurnal unit should fight the same at night: %d vs %d", knightNight, knightDay)
+	}
+}
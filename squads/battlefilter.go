@@ -0,0 +1,151 @@
+package squads
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Filter expressions for scanning battle log directories. An expression is a
+// comma-separated list of clauses that must all hold, evaluated against the
+// partially decoded BattleHeader so non-matching files are skipped without a
+// full unmarshal:
+//
+//	victor=Raiders, rounds>=3, rounds<=10, template=GoldenKnight, outcome=wipe
+
+type filterClause struct {
+	field string
+	op    string
+	text  string
+	num   int
+}
+
+type BattleFilter struct {
+	clauses []filterClause
+}
+
+// ParseBattleFilter parses a filter expression. The empty expression is a
+// filter that matches everything
+func ParseBattleFilter(expr string) (BattleFilter, error) {
+
+	filter := BattleFilter{}
+
+	for _, part := range strings.Split(expr, ",") {
+
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		clause, err := parseFilterClause(part)
+		if err != nil {
+			return BattleFilter{}, err
+		}
+
+		filter.clauses = append(filter.clauses, clause)
+
+	}
+
+	return filter, nil
+}
+
+func parseFilterClause(part string) (filterClause, error) {
+
+	//Two-character operators first so "rounds>=3" does not split at "="
+	op := ""
+	for _, candidate := range []string{">=", "<=", "="} {
+		if strings.Contains(part, candidate) {
+			op = candidate
+			break
+		}
+	}
+	if op == "" {
+		return filterClause{}, fmt.Errorf("filter clause %q has no operator, expected =, >= or <=", part)
+	}
+
+	pieces := strings.SplitN(part, op, 2)
+	clause := filterClause{
+		field: strings.TrimSpace(pieces[0]),
+		op:    op,
+		text:  strings.TrimSpace(pieces[1]),
+	}
+
+	if clause.text == "" {
+		return filterClause{}, fmt.Errorf("filter clause %q has no value", part)
+	}
+
+	switch clause.field {
+
+	case "rounds":
+		num, err := strconv.Atoi(clause.text)
+		if err != nil {
+			return filterClause{}, fmt.Errorf("filter clause %q needs a number, got %q", part, clause.text)
+		}
+		clause.num = num
+
+	case "victor", "template":
+		if clause.op != "=" {
+			return filterClause{}, fmt.Errorf("filter field %s only supports =", clause.field)
+		}
+
+	case "outcome":
+		if clause.op != "=" {
+			return filterClause{}, fmt.Errorf("filter field %s only supports =", clause.field)
+		}
+		if clause.text != "draw" && clause.text != "win" && clause.text != "wipe" {
+			return filterClause{}, fmt.Errorf("unknown outcome %q, expected draw, win or wipe", clause.text)
+		}
+
+	default:
+		return filterClause{}, fmt.Errorf("unknown filter field %q, expected victor, rounds, template or outcome", clause.field)
+
+	}
+
+	return clause, nil
+}
+
+// Matches is true when every clause holds for the header
+func (f BattleFilter) Matches(header *BattleHeader) bool {
+
+	for _, clause := range f.clauses {
+
+		switch clause.field {
+
+		case "victor":
+			if header.VictorName() != clause.text {
+				return false
+			}
+
+		case "template":
+			if !header.HasTemplate(clause.text) {
+				return false
+			}
+
+		case "outcome":
+			if header.Outcome() != clause.text {
+				return false
+			}
+
+		case "rounds":
+
+			switch clause.op {
+			case "=":
+				if header.Rounds != clause.num {
+					return false
+				}
+			case ">=":
+				if header.Rounds < clause.num {
+					return false
+				}
+			case "<=":
+				if header.Rounds > clause.num {
+					return false
+				}
+			}
+
+		}
+
+	}
+
+	return true
+}
@@ -0,0 +1,76 @@
+package squads
+
+import (
+	"testing"
+)
+
+func filterFixtureHeader() *BattleHeader {
+
+	return &BattleHeader{
+		AttackerSquadID: 1,
+		DefenderSquadID: 2,
+		WinnerSquadID:   1,
+		Rounds:          7,
+		AttackerName:    "Raiders",
+		DefenderName:    "Garrison",
+		AttackerUnits:   2,
+		DefenderUnits:   1,
+		DefenderLost:    1,
+		Templates:       []string{"TestKnight", "TestRogue"},
+		TotalDamage:     8,
+	}
+}
+
+func TestBattleFilterMatching(t *testing.T) {
+
+	header := filterFixtureHeader()
+
+	cases := []struct {
+		expr    string
+		matches bool
+	}{
+		{"", true},
+		{"victor=Raiders", true},
+		{"victor=Garrison", false},
+		{"rounds>=3", true},
+		{"rounds<=3", false},
+		{"rounds=7", true},
+		{"template=TestRogue", true},
+		{"template=TestArcher", false},
+		{"outcome=wipe", true},
+		{"outcome=draw", false},
+		{"victor=Raiders, rounds>=3, outcome=wipe", true},
+		{"victor=Raiders, rounds<=3", false},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.expr, func(t *testing.T) {
+
+			filter, err := ParseBattleFilter(tc.expr)
+			if err != nil {
+				t.Fatalf("expected %q to parse, got %v", tc.expr, err)
+			}
+
+			if filter.Matches(header) != tc.matches {
+				t.Errorf("expected Matches(%q) = %v", tc.expr, tc.matches)
+			}
+
+		})
+	}
+}
+
+func TestBattleFilterRejectsBadExpressions(t *testing.T) {
+
+	for _, expr := range []string{
+		"hp=3",
+		"rounds=many",
+		"victor>=Raiders",
+		"outcome=glorious",
+		"rounds",
+		"victor=",
+	} {
+		if _, err := ParseBattleFilter(expr); err == nil {
+			t.Errorf("expected %q to be rejected", expr)
+		}
+	}
+}
@@ -0,0 +1,188 @@
+package squads
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+)
+
+// BattleHeader is the cheap, partially decoded view of a battle log that the
+// directory-scanning tools filter on. ReadBattleHeader token-scans one file
+// with json.Decoder and only materializes the small fields: the events array
+// is reduced to a damage total without ever allocating the event structs, so
+// scanning a directory of thousands of logs stays fast
+
+type BattleHeader struct {
+	Path string
+
+	AttackerSquadID int
+	DefenderSquadID int
+	WinnerSquadID   int
+	Rounds          int
+
+	AttackerName string
+	DefenderName string
+
+	//Unit counts per side and how many each side lost, for outcome
+	//classification without the full record
+	AttackerUnits int
+	DefenderUnits int
+	AttackerLost  int
+	DefenderLost  int
+
+	//Every distinct unit template that fought, both sides, sorted
+	Templates []string
+
+	//Sum of the damage dealt across all events
+	TotalDamage int
+}
+
+// VictorName is the name of the winning squad, or "" for a draw
+func (h *BattleHeader) VictorName() string {
+
+	switch h.WinnerSquadID {
+	case h.AttackerSquadID:
+		return h.AttackerName
+	case h.DefenderSquadID:
+		return h.DefenderName
+	}
+
+	return ""
+}
+
+// Outcome classifies the battle as "draw", "wipe" (the loser lost every
+// unit), or "win"
+func (h *BattleHeader) Outcome() string {
+
+	if h.WinnerSquadID == 0 ||
+		(h.WinnerSquadID != h.AttackerSquadID && h.WinnerSquadID != h.DefenderSquadID) {
+		return "draw"
+	}
+
+	loserUnits, loserLost := h.AttackerUnits, h.AttackerLost
+	if h.WinnerSquadID == h.AttackerSquadID {
+		loserUnits, loserLost = h.DefenderUnits, h.DefenderLost
+	}
+
+	if loserUnits > 0 && loserLost >= loserUnits {
+		return "wipe"
+	}
+
+	return "win"
+}
+
+func (h *BattleHeader) HasTemplate(name string) bool {
+
+	for _, t := range h.Templates {
+		if t == name {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ReadBattleHeader partially decodes one battle log. Unknown fields are
+// skipped, the events array is summed into TotalDamage without building the
+// events, and a truncated or mangled file comes back as an error
+func ReadBattleHeader(path string) (BattleHeader, error) {
+
+	header := BattleHeader{Path: path}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return header, err
+	}
+	defer file.Close()
+
+	dec := json.NewDecoder(file)
+
+	tok, err := dec.Token()
+	if err != nil {
+		return header, fmt.Errorf("battle record %s did not parse: %w", path, err)
+	}
+	if delim, ok := tok.(json.Delim); !ok || delim != '{' {
+		return header, fmt.Errorf("battle record %s is not a JSON object", path)
+	}
+
+	var attacker, defender SquadSnapshot
+	lost := map[int][]string{}
+
+	for dec.More() {
+
+		keyTok, err := dec.Token()
+		if err != nil {
+			return header, fmt.Errorf("battle record %s did not parse: %w", path, err)
+		}
+		key, ok := keyTok.(string)
+		if !ok {
+			return header, fmt.Errorf("battle record %s is not a JSON object", path)
+		}
+
+		switch key {
+
+		case "attackerSquadID":
+			err = dec.Decode(&header.AttackerSquadID)
+		case "defenderSquadID":
+			err = dec.Decode(&header.DefenderSquadID)
+		case "winnerSquadID":
+			err = dec.Decode(&header.WinnerSquadID)
+		case "rounds":
+			err = dec.Decode(&header.Rounds)
+
+		case "attacker":
+			err = dec.Decode(&attacker)
+		case "defender":
+			err = dec.Decode(&defender)
+		case "unitsLost":
+			err = dec.Decode(&lost)
+
+		case "events":
+			//Only the damage numbers, never the full event structs
+			var damages []struct {
+				Damage int `json:"damage"`
+			}
+			if err = dec.Decode(&damages); err == nil {
+				for _, d := range damages {
+					header.TotalDamage += d.Damage
+				}
+			}
+
+		default:
+			var skipped json.RawMessage
+			err = dec.Decode(&skipped)
+
+		}
+
+		if err != nil {
+			return header, fmt.Errorf("battle record %s did not parse: %w", path, err)
+		}
+
+	}
+
+	//The closing brace. A file cut off mid-write fails here
+	if _, err := dec.Token(); err != nil {
+		return header, fmt.Errorf("battle record %s did not parse: %w", path, err)
+	}
+
+	header.AttackerName = attacker.Name
+	header.DefenderName = defender.Name
+	header.AttackerUnits = len(attacker.Units)
+	header.DefenderUnits = len(defender.Units)
+	header.AttackerLost = len(lost[header.AttackerSquadID])
+	header.DefenderLost = len(lost[header.DefenderSquadID])
+
+	seen := map[string]bool{}
+	for _, snapshot := range []SquadSnapshot{attacker, defender} {
+		for _, u := range snapshot.Units {
+			if u.TemplateName != "" && !seen[u.TemplateName] {
+				seen[u.TemplateName] = true
+				header.Templates = append(header.Templates, u.TemplateName)
+			}
+		}
+	}
+	sort.Strings(header.Templates)
+
+	return header, nil
+}
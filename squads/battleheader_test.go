@@ -0,0 +1,89 @@
+package squads
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func headerFixtureRecord() *BattleRecord {
+
+	return &BattleRecord{
+		AttackerSquadID: 1,
+		DefenderSquadID: 2,
+		WinnerSquadID:   1,
+		Rounds:          7,
+		Attacker: SquadSnapshot{
+			SquadID: 1,
+			Name:    "Raiders",
+			Units: []UnitSnapshot{
+				{TemplateName: "TestKnight"},
+				{TemplateName: "TestRogue"},
+			},
+		},
+		Defender: SquadSnapshot{
+			SquadID: 2,
+			Name:    "Garrison",
+			Units:   []UnitSnapshot{{TemplateName: "TestKnight"}},
+		},
+		Events: []AttackEvent{
+			{AttackerID: 10, DefenderID: 20, Damage: 5},
+			{AttackerID: 11, DefenderID: 20, Damage: 3},
+			{AttackerID: 20, DefenderID: 10, Dodged: true},
+		},
+		UnitsLost: map[int][]string{2: {"TestKnight"}},
+	}
+}
+
+// The partial decode pulls out exactly the fields the filters need
+func TestReadBattleHeaderDecodesTheHeaderFields(t *testing.T) {
+
+	dir := t.TempDir()
+	path, err := SaveBattleRecord(dir, headerFixtureRecord())
+	if err != nil {
+		t.Fatalf("saving the fixture failed: %v", err)
+	}
+
+	header, err := ReadBattleHeader(path)
+	if err != nil {
+		t.Fatalf("reading the header failed: %v", err)
+	}
+
+	if header.AttackerName != "Raiders" || header.DefenderName != "Garrison" {
+		t.Errorf("expected Raiders vs Garrison, got %s vs %s", header.AttackerName, header.DefenderName)
+	}
+	if header.Rounds != 7 {
+		t.Errorf("expected 7 rounds, got %d", header.Rounds)
+	}
+	if header.TotalDamage != 8 {
+		t.Errorf("expected 8 total damage, got %d", header.TotalDamage)
+	}
+	if header.VictorName() != "Raiders" {
+		t.Errorf("expected Raiders as the victor, got %q", header.VictorName())
+	}
+	if header.Outcome() != "wipe" {
+		t.Errorf("expected a wipe, the garrison lost its only unit, got %q", header.Outcome())
+	}
+	if !header.HasTemplate("TestRogue") || header.HasTemplate("TestArcher") {
+		t.Errorf("expected the templates of both sides, got %v", header.Templates)
+	}
+}
+
+// A file cut off mid-write must come back as an error, not a half header
+func TestReadBattleHeaderRefusesTruncatedFiles(t *testing.T) {
+
+	contents, err := json.MarshalIndent(headerFixtureRecord(), "", "  ")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	path := filepath.Join(t.TempDir(), "battle_trunc.json")
+	if err := os.WriteFile(path, contents[:len(contents)/2], 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := ReadBattleHeader(path); err == nil {
+		t.Error("a truncated battle log must be refused")
+	}
+}
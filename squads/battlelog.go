@@ -0,0 +1,158 @@
+package squads
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/bytearena/ecs"
+)
+
+// Battle records are persisted as one JSON file per battle in a log directory,
+// by default combat_logs/. The dataset tooling and the regression suite read
+// them back with LoadBattleRecord and FindAllBattles
+
+const DefaultLogDir = "combat_logs"
+
+// BattleLogConfig caps how much of the event list a saved record carries.
+// Long battles write megabytes of routine attack events; mass simulation
+// runs care about the shape of the fight, not every swing. Kills and crits
+// always survive the cap, and the record notes how many events were dropped
+type BattleLogConfig struct {
+	//Maximum attack events written per record. 0 writes everything
+	MaxEvents int
+}
+
+var BattleLog = BattleLogConfig{}
+
+// TrimBattleRecord returns a copy of the record whose event list fits the
+// cap: the first maxEvents events stay, and beyond those only kills and
+// crits survive. The original record is untouched, and the copys
+// TruncatedEvents counts what was dropped so readers can tell a short
+// battle from a trimmed one
+func TrimBattleRecord(record *BattleRecord, maxEvents int) *BattleRecord {
+
+	if maxEvents <= 0 || len(record.Events) <= maxEvents {
+		return record
+	}
+
+	kills := killingEvents(record)
+
+	trimmed := *record
+	trimmed.Events = []AttackEvent{}
+	for i, e := range record.Events {
+
+		if i < maxEvents || e.Crit || kills[i] {
+			trimmed.Events = append(trimmed.Events, e)
+		}
+
+	}
+
+	trimmed.TruncatedEvents = len(record.Events) - len(trimmed.Events)
+	return &trimmed
+}
+
+// killingEvents replays the events against the starting health pools and
+// flags the index of every event that felled a unit
+func killingEvents(record *BattleRecord) map[int]bool {
+
+	health := make(map[ecs.EntityID]int)
+	for _, side := range []SquadSnapshot{record.Attacker, record.Defender} {
+		for _, u := range side.Units {
+			health[u.EntityID] = u.MaxHealth
+		}
+	}
+
+	kills := make(map[int]bool)
+	for i, e := range record.Events {
+
+		hp, known := health[e.DefenderID]
+		if !known || hp <= 0 || e.Damage == 0 {
+			continue
+		}
+
+		hp -= e.Damage
+		health[e.DefenderID] = hp
+
+		if hp <= 0 {
+			kills[i] = true
+		}
+
+	}
+
+	return kills
+}
+
+// SaveBattleRecord writes a record into the log directory, creating the
+// directory if needed. Two battles finishing in the same second get distinct
+// suffixed names, so rapid generator runs never overwrite each other.
+// Returns the path it was written to
+func SaveBattleRecord(dir string, record *BattleRecord) (string, error) {
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", err
+	}
+
+	contents, err := json.MarshalIndent(TrimBattleRecord(record, BattleLog.MaxEvents), "", "  ")
+	if err != nil {
+		return "", err
+	}
+
+	name := fmt.Sprintf("battle_%d_vs_%d_%d", record.AttackerSquadID, record.DefenderSquadID, time.Now().Unix())
+
+	//O_EXCL claims the name atomically; on a clash try the next suffix
+	for n := 0; ; n++ {
+
+		path := filepath.Join(dir, name+".json")
+		if n > 0 {
+			path = filepath.Join(dir, fmt.Sprintf("%s_%d.json", name, n))
+		}
+
+		file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+		if os.IsExist(err) {
+			continue
+		}
+		if err != nil {
+			return "", err
+		}
+
+		if _, err := file.Write(contents); err != nil {
+			file.Close()
+			return "", err
+		}
+
+		return path, file.Close()
+	}
+}
+
+func LoadBattleRecord(path string) (*BattleRecord, error) {
+
+	contents, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	record := &BattleRecord{}
+	if err := json.Unmarshal(contents, record); err != nil {
+		return nil, fmt.Errorf("battle record %s did not parse: %w", path, err)
+	}
+
+	return record, nil
+}
+
+// FindAllBattles returns the paths of every battle record in the log
+// directory, sorted so runs are deterministic. A missing directory is just an
+// empty result, not an error
+func FindAllBattles(dir string) ([]string, error) {
+
+	paths, err := filepath.Glob(filepath.Join(dir, "battle_*.json"))
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Strings(paths)
+	return paths, nil
+}
@@ -0,0 +1,135 @@
+package squads
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// Several battles saved within the same second must land in distinct files
+func TestSaveBattleRecordNamesAreCollisionSafe(t *testing.T) {
+
+	dir := t.TempDir()
+	record := &BattleRecord{AttackerSquadID: 1, DefenderSquadID: 2, UnitsLost: map[int][]string{}}
+
+	seen := map[string]bool{}
+	for i := 0; i < 5; i++ {
+
+		path, err := SaveBattleRecord(dir, record)
+		if err != nil {
+			t.Fatalf("save %d failed: %v", i, err)
+		}
+
+		if seen[path] {
+			t.Fatalf("save %d reused the path %s", i, path)
+		}
+		seen[path] = true
+
+	}
+
+	//Every file must still match the pattern FindAllBattles looks for
+	paths, err := FindAllBattles(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(paths) != 5 {
+		t.Errorf("expected FindAllBattles to see all 5 saves, got %d: %v", len(paths), paths)
+	}
+
+	for _, path := range paths {
+		if _, err := LoadBattleRecord(filepath.Join(dir, filepath.Base(path))); err != nil {
+			t.Errorf("saved file %s did not load back: %v", path, err)
+		}
+	}
+}
+
+// A long grind of routine events: one knight chips a tanky defender down,
+// with a crit in the middle and the kill at the very end
+func truncationFixture() *BattleRecord {
+
+	record := NewBattleRecord(1, 2)
+	record.WinnerSquadID = 1
+	record.Rounds = 50
+
+	record.Attacker = SquadSnapshot{
+		SquadID: 1,
+		Units:   []UnitSnapshot{{EntityID: 10, TemplateName: "Knight", MaxHealth: 20}},
+	}
+	record.Defender = SquadSnapshot{
+		SquadID: 2,
+		Units:   []UnitSnapshot{{EntityID: 20, TemplateName: "Tank", MaxHealth: 100}},
+	}
+
+	for i := 0; i < 50; i++ {
+		record.RecordEvent(AttackEvent{Round: i + 1, AttackerID: 10, DefenderID: 20, Damage: 2, Crit: i == 25})
+	}
+
+	return record
+}
+
+// A battle over the cap saves smaller, keeps every kill and crit, and
+// carries the truncation marker
+func TestEventCapShrinksSavedBattles(t *testing.T) {
+
+	restore := BattleLog
+	defer func() { BattleLog = restore }()
+
+	dir := t.TempDir()
+	record := truncationFixture()
+
+	fullPath, err := SaveBattleRecord(dir, record)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	BattleLog.MaxEvents = 10
+	cappedPath, err := SaveBattleRecord(dir, record)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	full, _ := os.Stat(fullPath)
+	capped, _ := os.Stat(cappedPath)
+	if capped.Size() >= full.Size() {
+		t.Errorf("the capped file should be smaller, got %d against %d bytes", capped.Size(), full.Size())
+	}
+
+	loaded, err := LoadBattleRecord(cappedPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	//10 routine events, the crit at index 25, and the killing blow at 49
+	if len(loaded.Events) != 12 {
+		t.Fatalf("the cap should keep 10 events plus the crit and the kill, got %d", len(loaded.Events))
+	}
+	if loaded.TruncatedEvents != 38 {
+		t.Errorf("the marker should count the dropped events, got %d", loaded.TruncatedEvents)
+	}
+
+	last := loaded.Events[len(loaded.Events)-1]
+	if last.Round != 50 {
+		t.Errorf("the killing blow should survive the cap, got round %d", last.Round)
+	}
+	if !loaded.Events[10].Crit {
+		t.Errorf("crits should survive the cap")
+	}
+
+	//The record in memory is untouched
+	if len(record.Events) != 50 || record.TruncatedEvents != 0 {
+		t.Error("saving must not mutate the record itself")
+	}
+
+}
+
+// Records under the cap save exactly as before
+func TestEventCapLeavesShortBattlesAlone(t *testing.T) {
+
+	record := truncationFixture()
+
+	trimmed := TrimBattleRecord(record, 50)
+	if trimmed != record {
+		t.Error("a record at the cap should pass through untrimmed")
+	}
+
+}
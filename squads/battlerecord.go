@@ -0,0 +1,166 @@
+package squads
+
+import (
+	"game_main/common"
+
+	"github.com/bytearena/ecs"
+)
+
+// BattleRecord is everything that happened in one squad battle. The simulator,
+// the battle logs, and the post battle summary all build on it
+
+type BattleRecord struct {
+	AttackerSquadID int `json:"attackerSquadID"`
+	DefenderSquadID int `json:"defenderSquadID"`
+	WinnerSquadID   int `json:"winnerSquadID"`
+	Rounds          int `json:"rounds"`
+
+	//Snapshots of both squads as they entered the battle, so the record can
+	//be analyzed offline without the ECS world it came from
+	Attacker SquadSnapshot `json:"attacker"`
+	Defender SquadSnapshot `json:"defender"`
+
+	Events []AttackEvent `json:"events"`
+
+	//How many routine attack events the log cap dropped on save. 0 means the
+	//event list is complete
+	TruncatedEvents int `json:"truncatedEvents,omitempty"`
+
+	//Consumables spent during the battle, in the order they were used
+	Consumables []ConsumableEvent `json:"consumables,omitempty"`
+
+	//Support buffs applied during the battle, in the order they were cast
+	Buffs []BuffEvent `json:"buffs,omitempty"`
+
+	//Squad movement during the battle, in the order it happened. PhaseLog
+	//folds these into the per-round phase view
+	Movements []MovementEvent `json:"movements,omitempty"`
+
+	//Reinforcement waves that joined mid-battle, in arrival order
+	Reinforcements []ReinforcementEvent `json:"reinforcements,omitempty"`
+
+	//Names of the units each squad lost, by squad ID
+	UnitsLost map[int][]string `json:"unitsLost"`
+
+	//Final state of any objective tiles, and the faction that won on
+	//objectives if the battle ended that way rather than by annihilation
+	Objectives      []ObjectiveState `json:"objectives,omitempty"`
+	ObjectiveVictor string           `json:"objectiveVictor,omitempty"`
+
+	//The conditions the battle was fought under, so balance analysis can
+	//segment by weather and time of day
+	Environment BattleEnvironment `json:"environment,omitempty"`
+}
+
+// UnitSnapshot is the state of one unit when the battle started
+type UnitSnapshot struct {
+	//Entity ID in the world the battle ran in, so offline analysis can
+	//attribute attack events to a side. Never resolved after a reload
+	EntityID ecs.EntityID `json:"entityID"`
+
+	TemplateName string `json:"templateName"`
+	Class        string `json:"class"`
+	Row          int    `json:"row"`
+	Col          int    `json:"col"`
+	MaxHealth    int    `json:"maxHealth"`
+	Protection   int    `json:"protection"`
+	MinDamage    int    `json:"minDamage"`
+	MaxDamage    int    `json:"maxDamage"`
+
+	//Below the wound threshold when the snapshot was taken
+	Wounded bool `json:"wounded,omitempty"`
+}
+
+type SquadSnapshot struct {
+	SquadID int             `json:"squadID"`
+	Name    string          `json:"name"`
+	MapPos  common.Position `json:"mapPos"`
+
+	//Banner colors and icon, so log visualizers can color their output
+	Appearance SquadAppearance `json:"appearance"`
+
+	Units []UnitSnapshot `json:"units"`
+}
+
+// SnapshotSquad captures a squads composition for the battle record
+func SnapshotSquad(em *common.EntityManager, squadID int) SquadSnapshot {
+
+	snapshot := SquadSnapshot{SquadID: squadID}
+
+	squad := GetSquadByID(em, squadID)
+	if squad != nil {
+		snapshot.Name = squad.Name
+		snapshot.MapPos = squad.MapPos
+		snapshot.Appearance = GetSquadAppearance(em, squadID)
+	}
+
+	for _, u := range SquadUnits(em, squadID) {
+
+		data := GetUnitData(u)
+		template := UnitTemplateFor(u)
+
+		snapshot.Units = append(snapshot.Units, UnitSnapshot{
+			EntityID:     u.GetID(),
+			TemplateName: data.TemplateName,
+			Class:        template.Class,
+			Row:          data.Row,
+			Col:          data.Col,
+			MaxHealth:    common.GetAttributes(u).MaxHealth,
+			Protection:   common.GetAttributes(u).TotalProtection,
+			MinDamage:    template.MinDamage,
+			MaxDamage:    template.MaxDamage,
+			Wounded:      IsWounded(u),
+		})
+
+	}
+
+	return snapshot
+}
+
+func NewBattleRecord(attackerSquadID int, defenderSquadID int) *BattleRecord {
+
+	return &BattleRecord{
+		AttackerSquadID: attackerSquadID,
+		DefenderSquadID: defenderSquadID,
+		UnitsLost:       make(map[int][]string),
+		Environment:     ActiveEnvironment,
+	}
+}
+
+func (r *BattleRecord) RecordEvent(event AttackEvent) {
+	r.Events = append(r.Events, event)
+}
+
+func (r *BattleRecord) RecordConsumable(event ConsumableEvent) {
+	r.Consumables = append(r.Consumables, event)
+}
+
+func (r *BattleRecord) RecordBuff(event BuffEvent) {
+	r.Buffs = append(r.Buffs, event)
+}
+
+// ReinforcementEvent notes a wave of fresh units joining a squad mid-battle
+type ReinforcementEvent struct {
+	Round   int      `json:"round"`
+	SquadID int      `json:"squadID"`
+	Units   []string `json:"units"`
+}
+
+func (r *BattleRecord) RecordReinforcement(event ReinforcementEvent) {
+	r.Reinforcements = append(r.Reinforcements, event)
+}
+
+func (r *BattleRecord) RecordUnitLost(squadID int, unitName string) {
+	r.UnitsLost[squadID] = append(r.UnitsLost[squadID], unitName)
+}
+
+// Total damage each entity dealt over the battle, for summaries and analyzers
+func (r *BattleRecord) DamageByAttacker() map[uint32]int {
+
+	totals := make(map[uint32]int)
+	for _, e := range r.Events {
+		totals[uint32(e.AttackerID)] += e.Damage
+	}
+
+	return totals
+}
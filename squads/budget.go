@@ -0,0 +1,183 @@
+package squads
+
+import (
+	"fmt"
+	"game_main/common"
+	"game_main/randgen"
+)
+
+// The combat budget gives "balanced" a number: every unit has a cost derived
+// from its template, a squads budget is the sum over its living units, and
+// the balanced generator builds matchups whose budgets agree within a
+// tolerance. The battle-logging generator uses this so its datasets aren't
+// full of foregone conclusions
+
+// UnitCost prices a template: bulk, protection, weighted damage output, and
+// the situational extras at a discount. Depends only on the template, so the
+// same squad always prices the same. A template with an explicit Cost skips
+// the formula entirely
+func UnitCost(template UnitTemplate) float64 {
+
+	if template.Cost > 0 {
+		return template.Cost
+	}
+
+	cost := float64(template.Attr.MaxHealth)
+	cost += 2 * float64(template.Attr.BaseProtection)
+	cost += 1.5 * float64(template.MinDamage+template.MaxDamage)
+	cost += float64(template.CoverValue)
+	cost += 0.5 * float64(template.BackstabBonus)
+	cost += 20 * float64(template.Attr.BaseDodgeChance)
+
+	return cost
+}
+
+// SquadCostBudget sums the cost of every living unit in the squad
+func SquadCostBudget(squadID int, manager *common.EntityManager) float64 {
+
+	budget := 0.0
+	for _, u := range SquadUnits(manager, squadID) {
+
+		if common.GetAttributes(u).CurrentHealth <= 0 {
+			continue
+		}
+
+		budget += UnitCost(UnitTemplateFor(u))
+
+	}
+
+	return budget
+}
+
+// GenerateBalancedSquads builds two squads from the template pool, each
+// filled up towards targetBudget, and errors if it cannot get their budgets
+// within tolerance of each other. The picks are random, so repeated calls
+// produce varied compositions at the same price point
+func GenerateBalancedSquads(em *common.EntityManager, pool []string, targetBudget float64, tolerance float64) (*SquadData, *SquadData, error) {
+
+	if len(pool) == 0 {
+		return nil, nil, fmt.Errorf("balanced generator needs a non-empty template pool")
+	}
+	for _, name := range pool {
+		if _, ok := GetUnitTemplate(name); !ok {
+			return nil, nil, fmt.Errorf("balanced generator references unknown template %s", name)
+		}
+	}
+
+	a := CreateSquad(em, "Balanced A", "Attacker", common.Position{X: 5, Y: 3}, DirDown)
+	b := CreateSquad(em, "Balanced B", "Defender", common.Position{X: 5, Y: 5}, DirUp)
+
+	fillToBudget(em, a.SquadID, pool, targetBudget)
+	fillToBudget(em, b.SquadID, pool, targetBudget)
+
+	//Top the lighter squad off with whatever still fits into the gap
+	for i := 0; i < GridWidth*GridHeight; i++ {
+
+		diff := SquadCostBudget(a.SquadID, em) - SquadCostBudget(b.SquadID, em)
+		if diff < 0 {
+			diff = -diff
+		}
+		if diff <= tolerance {
+			return a, b, nil
+		}
+
+		lighter := a
+		if SquadCostBudget(b.SquadID, em) < SquadCostBudget(a.SquadID, em) {
+			lighter = b
+		}
+
+		if !addCheapestFitting(em, lighter.SquadID, pool, diff) {
+			break
+		}
+
+	}
+
+	diff := SquadCostBudget(a.SquadID, em) - SquadCostBudget(b.SquadID, em)
+	if diff < 0 {
+		diff = -diff
+	}
+	if diff > tolerance {
+		return nil, nil, fmt.Errorf("balanced generator could not close the budget gap: off by %.1f with tolerance %.1f", diff, tolerance)
+	}
+
+	return a, b, nil
+}
+
+// Fill a squad with random affordable picks until the budget or the grid
+// runs out
+func fillToBudget(em *common.EntityManager, squadID int, pool []string, targetBudget float64) {
+
+	gridW, gridH := SquadGridSize(em, squadID)
+	for len(SquadUnits(em, squadID)) < gridW*gridH {
+
+		remaining := targetBudget - SquadCostBudget(squadID, em)
+
+		affordable := []string{}
+		for _, name := range pool {
+
+			template, _ := GetUnitTemplate(name)
+			if UnitCost(template) <= remaining {
+				affordable = append(affordable, name)
+			}
+
+		}
+
+		if len(affordable) == 0 {
+			return
+		}
+
+		pick := affordable[randgen.GetRandomBetween(0, len(affordable)-1)]
+		template, _ := GetUnitTemplate(pick)
+		placeInNextFreeCell(em, squadID, template)
+
+	}
+
+}
+
+func addCheapestFitting(em *common.EntityManager, squadID int, pool []string, gap float64) bool {
+
+	gridW, gridH := SquadGridSize(em, squadID)
+	if len(SquadUnits(em, squadID)) >= gridW*gridH {
+		return false
+	}
+
+	best := ""
+	bestCost := 0.0
+	for _, name := range pool {
+
+		template, _ := GetUnitTemplate(name)
+		cost := UnitCost(template)
+		if cost > gap {
+			continue
+		}
+		if best == "" || cost > bestCost {
+			best = name
+			bestCost = cost
+		}
+
+	}
+
+	if best == "" {
+		return false
+	}
+
+	template, _ := GetUnitTemplate(best)
+	placeInNextFreeCell(em, squadID, template)
+	return true
+}
+
+func placeInNextFreeCell(em *common.EntityManager, squadID int, template UnitTemplate) {
+
+	gridW, gridH := SquadGridSize(em, squadID)
+	for row := 0; row < gridH; row++ {
+		for col := 0; col < gridW; col++ {
+
+			if UnitOccupyingCell(em, squadID, row, col) == nil {
+				CreateUnitFromTemplate(em, template, squadID, row, col)
+				return
+			}
+
+		}
+	}
+
+}
@@ -0,0 +1,151 @@
+package squads
+
+import (
+	"encoding/json"
+	"game_main/common"
+	"game_main/entitytemplates"
+	"testing"
+)
+
+func registerBudgetTemplates() []string {
+
+	Units["CheapSpear"] = UnitTemplate{
+		Name:      "CheapSpear",
+		Attr:      common.NewBaseAttributes(8, 0, 8, 0, 1, 0),
+		MinDamage: 2,
+		MaxDamage: 2,
+	}
+	Units["HeavyKnight"] = UnitTemplate{
+		Name:       "HeavyKnight",
+		Attr:       common.NewBaseAttributes(24, 0, 12, 3, 1, 0),
+		MinDamage:  5,
+		MaxDamage:  7,
+		CoverValue: 2,
+	}
+
+	return []string{"CheapSpear", "HeavyKnight"}
+}
+
+// The same squad always prices the same, and dead units stop counting
+func TestSquadCostBudgetIsDeterministic(t *testing.T) {
+
+	em := newTestManager()
+	registerBudgetTemplates()
+
+	squad := CreateSquad(em, "Priced", "Player", common.Position{X: 1, Y: 1}, DirDown)
+	CreateUnitFromTemplate(em, Units["HeavyKnight"], squad.SquadID, 0, 0)
+	spear := CreateUnitFromTemplate(em, Units["CheapSpear"], squad.SquadID, 0, 1)
+
+	first := SquadCostBudget(squad.SquadID, em)
+	second := SquadCostBudget(squad.SquadID, em)
+
+	if first != second {
+		t.Errorf("the cost function must be deterministic, got %f then %f", first, second)
+	}
+
+	expected := UnitCost(Units["HeavyKnight"]) + UnitCost(Units["CheapSpear"])
+	if first != expected {
+		t.Errorf("the budget should be the sum of the unit costs, expected %f got %f", expected, first)
+	}
+
+	common.GetAttributes(spear).CurrentHealth = 0
+	if SquadCostBudget(squad.SquadID, em) != UnitCost(Units["HeavyKnight"]) {
+		t.Error("dead units should not count towards the budget")
+	}
+}
+
+// The generator gets both squads within the configured tolerance
+func TestBalancedGeneratorStaysWithinTolerance(t *testing.T) {
+
+	em := newTestManager()
+	pool := registerBudgetTemplates()
+
+	tolerance := UnitCost(Units["CheapSpear"])
+
+	a, b, err := GenerateBalancedSquads(em, pool, 150, tolerance)
+	if err != nil {
+		t.Fatalf("the generator failed: %v", err)
+	}
+
+	budgetA := SquadCostBudget(a.SquadID, em)
+	budgetB := SquadCostBudget(b.SquadID, em)
+
+	diff := budgetA - budgetB
+	if diff < 0 {
+		diff = -diff
+	}
+	if diff > tolerance {
+		t.Errorf("budgets %f and %f differ by more than the tolerance %f", budgetA, budgetB, diff)
+	}
+
+	if len(SquadUnits(em, a.SquadID)) == 0 || len(SquadUnits(em, b.SquadID)) == 0 {
+		t.Error("both generated squads should have units")
+	}
+}
+
+func TestBalancedGeneratorRejectsBadPools(t *testing.T) {
+
+	em := newTestManager()
+	registerBudgetTemplates()
+
+	if _, _, err := GenerateBalancedSquads(em, nil, 100, 5); err == nil {
+		t.Error("an empty pool should be rejected")
+	}
+	if _, _, err := GenerateBalancedSquads(em, []string{"NoSuchUnit"}, 100, 5); err == nil {
+		t.Error("an unknown template should be rejected")
+	}
+}
+
+// An explicit template cost beats the formula, no matter the stats
+func TestExplicitCostOverridesTheFormula(t *testing.T) {
+
+	registerBudgetTemplates()
+
+	priced := Units["HeavyKnight"]
+	priced.Name = "PricedKnight"
+	priced.Cost = 12.5
+	Units["PricedKnight"] = priced
+
+	if cost := UnitCost(Units["PricedKnight"]); cost != 12.5 {
+		t.Errorf("expected the explicit cost of 12.5, got %f", cost)
+	}
+
+	derived := UnitCost(Units["HeavyKnight"])
+	if derived == 12.5 {
+		t.Fatal("the fixture needs a derived cost different from the override")
+	}
+
+	//Identical stats, no override: the formula still applies
+	free := Units["PricedKnight"]
+	free.Cost = 0
+	if cost := UnitCost(free); cost != derived {
+		t.Errorf("expected the derived cost %f without an override, got %f", derived, cost)
+	}
+}
+
+// An explicit cost written in monsterdata.json survives the canonical load
+// path: unmarshal -> NewJSONMonster -> CreateUnitTemplates. The test above
+// only sets Cost programmatically, so it cannot catch the constructor
+// dropping the field
+func TestJSONCostSurvivesTheLoadPath(t *testing.T) {
+
+	raw := []byte(`{
+		"name": "PricedFromJSON",
+		"attributes": {"MaxHealth": 10, "BaseArmorClass": 8, "BaseMovementSpeed": 1},
+		"meleeWeapon": {"minDamage": 2, "maxDamage": 2},
+		"cost": 42.5
+	}`)
+
+	var monster entitytemplates.JSONMonster
+	if err := json.Unmarshal(raw, &monster); err != nil {
+		t.Fatalf("unmarshalling the template failed: %v", err)
+	}
+
+	//NewJSONMonster is what ReadMonsterData stores into MonsterTemplates, so
+	//the round trip has to pass through it
+	CreateUnitTemplates([]entitytemplates.JSONMonster{entitytemplates.NewJSONMonster(monster)})
+
+	if cost := UnitCost(Units["PricedFromJSON"]); cost != 42.5 {
+		t.Errorf("the JSON cost should survive the load path, got %f", cost)
+	}
+}
@@ -0,0 +1,94 @@
+package squads
+
+import (
+	"game_main/common"
+	"testing"
+)
+
+// Executing a squad command fires the squads subscription exactly once and
+// never the roster one, so a squad list refreshes once and the roster panel
+// not at all
+func TestSquadCommandFiresOneSquadRefresh(t *testing.T) {
+
+	em := newTestManager()
+	registerTestTemplates()
+
+	caster := CreateSquad(em, "Banners", "Player", common.Position{X: 5, Y: 5}, DirDown)
+	unit := CreateUnitFromTemplate(em, Units["TestKnight"], caster.SquadID, 0, 0)
+	AssignSupportAbility(unit, SupportAbilityData{
+		AbilityID: "Rally",
+		Effect:    BuffMorale,
+		Amount:    2,
+		Range:     3,
+	})
+
+	ally := CreateSquad(em, "Line", "Player", common.Position{X: 5, Y: 6}, DirDown)
+	CreateUnitFromTemplate(em, Units["TestKnight"], ally.SquadID, 0, 0)
+
+	squadsSub := em.Changes().Subscribe(common.SquadsChanged)
+	rosterSub := em.Changes().Subscribe(common.RosterChanged)
+
+	//Drain the notifications the setup itself produced
+	squadsSub.Fired()
+	rosterSub.Fired()
+
+	if _, err := UseSupportAbility(em, unit.GetID(), ally.SquadID); err != nil {
+		t.Fatalf("the squad command failed: %v", err)
+	}
+
+	if !squadsSub.Fired() {
+		t.Error("a squad command should fire the squads subscription")
+	}
+	if squadsSub.Fired() {
+		t.Error("one command should cost exactly one refresh")
+	}
+	if rosterSub.Fired() {
+		t.Error("a squad command should not touch the roster subscription")
+	}
+}
+
+// Any number of mutations between two polls still costs one rebuild, and
+// the generation counter accounts for each of them
+func TestChangeBatchingAndGeneration(t *testing.T) {
+
+	em := newTestManager()
+	registerTestTemplates()
+
+	sub := em.Changes().Subscribe(common.SquadsChanged)
+	before := em.Changes().Generation()
+
+	squad := CreateSquad(em, "Recruits", "Player", common.Position{X: 1, Y: 1}, DirDown)
+	CreateUnitFromTemplate(em, Units["TestKnight"], squad.SquadID, 0, 0)
+	CreateUnitFromTemplate(em, Units["TestKnight"], squad.SquadID, 0, 1)
+	CreateUnitFromTemplate(em, Units["TestKnight"], squad.SquadID, 0, 2)
+
+	if got := em.Changes().Generation() - before; got != 4 {
+		t.Errorf("expected 4 notified mutations, got %d", got)
+	}
+
+	if !sub.Fired() {
+		t.Fatal("the batch should fire the subscription")
+	}
+	if sub.Fired() {
+		t.Error("a batch of mutations should cost a single rebuild")
+	}
+}
+
+// Destroying a squad refreshes both the roster and the squad panels
+func TestDestructionFiresTheRoster(t *testing.T) {
+
+	em := newTestManager()
+	registerTestTemplates()
+
+	squad := CreateSquad(em, "Doomed", "Player", common.Position{X: 1, Y: 1}, DirDown)
+	CreateUnitFromTemplate(em, Units["TestKnight"], squad.SquadID, 0, 0)
+
+	rosterSub := em.Changes().Subscribe(common.RosterChanged)
+	rosterSub.Fired()
+
+	ProcessSquadDestruction(em, nil, squad.SquadID)
+
+	if !rosterSub.Fired() {
+		t.Error("destroying a squad should fire the roster subscription")
+	}
+}
@@ -0,0 +1,35 @@
+package squads
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"sort"
+)
+
+// CombatChecksum condenses a battle record into one hash so CI can assert
+// that seeded scenarios still resolve exactly the same way. Only
+// deterministic outcome fields go in: squad IDs, rounds, the attack events,
+// and the losses. Presentation fields like duration hints stay out so a
+// pacing tweak does not read as balance drift
+func CombatChecksum(record *BattleRecord) string {
+
+	h := sha256.New()
+
+	fmt.Fprintf(h, "%d/%d/%d/%d\n", record.AttackerSquadID, record.DefenderSquadID, record.WinnerSquadID, record.Rounds)
+
+	for _, e := range record.Events {
+		fmt.Fprintf(h, "%d>%d:%d:%d:%v:%v:%d\n", e.AttackerID, e.DefenderID, e.Damage, e.PotentialDamage, e.Dodged, e.Backstab, e.Round)
+	}
+
+	squadIDs := make([]int, 0, len(record.UnitsLost))
+	for squadID := range record.UnitsLost {
+		squadIDs = append(squadIDs, squadID)
+	}
+	sort.Ints(squadIDs)
+
+	for _, squadID := range squadIDs {
+		fmt.Fprintf(h, "%d:%v\n", squadID, record.UnitsLost[squadID])
+	}
+
+	return fmt.Sprintf("%x", h.Sum(nil))
+}
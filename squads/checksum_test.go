@@ -0,0 +1,58 @@
+package squads
+
+import (
+	"testing"
+)
+
+func checksumRecord() *BattleRecord {
+
+	record := NewBattleRecord(1, 2)
+	record.WinnerSquadID = 1
+	record.Rounds = 3
+	record.RecordEvent(AttackEvent{AttackerID: 10, DefenderID: 20, Damage: 4, Round: 1})
+	record.RecordEvent(AttackEvent{AttackerID: 20, DefenderID: 10, Dodged: true, PotentialDamage: 5, Round: 1})
+	record.RecordUnitLost(2, "TestRogue")
+
+	return record
+}
+
+func TestIdenticalRecordsHashTheSame(t *testing.T) {
+
+	if CombatChecksum(checksumRecord()) != CombatChecksum(checksumRecord()) {
+		t.Error("two identical records must produce the same checksum")
+	}
+}
+
+func TestChecksumReactsToDamageAndOutcome(t *testing.T) {
+
+	base := CombatChecksum(checksumRecord())
+
+	damaged := checksumRecord()
+	damaged.Events[0].Damage = 5
+	if CombatChecksum(damaged) == base {
+		t.Error("a damage change must change the checksum")
+	}
+
+	flipped := checksumRecord()
+	flipped.WinnerSquadID = 2
+	if CombatChecksum(flipped) == base {
+		t.Error("an outcome change must change the checksum")
+	}
+
+	losses := checksumRecord()
+	losses.UnitsLost[1] = []string{"TestKnight"}
+	if CombatChecksum(losses) == base {
+		t.Error("a loss change must change the checksum")
+	}
+}
+
+// Duration hints are presentation, not balance, so they must not move the hash
+func TestChecksumIgnoresPacing(t *testing.T) {
+
+	paced := checksumRecord()
+	paced.Events[0].DurationHint = 99
+
+	if CombatChecksum(paced) != CombatChecksum(checksumRecord()) {
+		t.Error("pacing fields must not affect the checksum")
+	}
+}
@@ -0,0 +1,240 @@
+package squads
+
+import (
+	"fmt"
+	"game_main/common"
+
+	"github.com/bytearena/ecs"
+)
+
+// Squads can carry a small bag of consumables usable once per item during a
+// battle: potions, bombs and banners. Unlike permanent equipment the item is
+// gone after use. Every use is logged as a ConsumableEvent so battle records
+// and the post battle summary can show what was spent
+
+type ConsumableEffect int
+
+const (
+	//Restore HP to the squads lowest-HP living unit, capped at max health
+	ConsumableHeal ConsumableEffect = iota
+	//Magic damage to a pattern of enemy grid cells, ignores protection
+	ConsumableDamage
+	//Raise squad morale and clear the shaken state
+	ConsumableRally
+)
+
+type ConsumableDefinition struct {
+	Name   string
+	Effect ConsumableEffect
+
+	//HP healed, damage dealt, or morale gained depending on the effect
+	Amount int
+
+	//Grid cell offsets from the target cell a damage effect covers
+	Pattern [][2]int
+}
+
+// The built-in consumables. Data-driven definitions can be layered on later,
+// the registry is just a map
+var ConsumableDefs = map[string]ConsumableDefinition{
+	"HealingPotion": {Name: "HealingPotion", Effect: ConsumableHeal, Amount: 10},
+	"FireBomb":      {Name: "FireBomb", Effect: ConsumableDamage, Amount: 6, Pattern: [][2]int{{0, 0}, {0, 1}, {1, 0}}},
+	"RallyBanner":   {Name: "RallyBanner", Effect: ConsumableRally, Amount: 2},
+}
+
+var ConsumableBagComponent *ecs.Component
+
+const DefaultBagCapacity = 3
+
+// ConsumableBag sits on the squad entity and persists with the savegame
+type ConsumableBag struct {
+	Capacity int      `json:"capacity"`
+	Items    []string `json:"items"`
+}
+
+func GetConsumableBag(em *common.EntityManager, squadID int) *ConsumableBag {
+
+	squad := GetSquadEntity(em, squadID)
+	if squad == nil {
+		return nil
+	}
+
+	if bag := common.GetComponentType[*ConsumableBag](squad, ConsumableBagComponent); bag != nil {
+		return bag
+	}
+
+	bag := &ConsumableBag{Capacity: DefaultBagCapacity}
+	squad.AddComponent(ConsumableBagComponent, bag)
+	return bag
+}
+
+func AddConsumable(em *common.EntityManager, squadID int, name string) error {
+
+	if _, ok := ConsumableDefs[name]; !ok {
+		return fmt.Errorf("unknown consumable %s", name)
+	}
+
+	bag := GetConsumableBag(em, squadID)
+	if bag == nil {
+		return fmt.Errorf("no squad with ID %d", squadID)
+	}
+
+	if len(bag.Items) >= bag.Capacity {
+		return fmt.Errorf("the consumable bag is full (%d items)", bag.Capacity)
+	}
+
+	bag.Items = append(bag.Items, name)
+	em.NotifyChanged(common.InventoryChanged)
+	return nil
+}
+
+// ConsumableTarget names the enemy squad and anchor cell a targeted
+// consumable applies to. Self-targeted effects ignore it
+type ConsumableTarget struct {
+	SquadID int
+	Row     int
+	Col     int
+}
+
+// ConsumableEvent is the log entry one use produces
+type ConsumableEvent struct {
+	Squad      string `json:"squad"`
+	Consumable string `json:"consumable"`
+
+	HealedUnit   string `json:"healedUnit,omitempty"`
+	AmountHealed int    `json:"amountHealed,omitempty"`
+
+	//Damage effects route through the normal attack events
+	Events []AttackEvent `json:"events,omitempty"`
+}
+
+// UseConsumable validates the battle context, applies the items effect,
+// removes it from the bag and returns the event for the battle record.
+// Consumables only work during a battle
+func UseConsumable(squadID int, consumableID string, target ConsumableTarget, manager *common.EntityManager) (ConsumableEvent, error) {
+
+	event := ConsumableEvent{Consumable: consumableID}
+
+	squad := GetSquadByID(manager, squadID)
+	if squad == nil {
+		return event, fmt.Errorf("no squad with ID %d", squadID)
+	}
+	event.Squad = squad.Name
+
+	if squad.CurrentRound == 0 {
+		return event, fmt.Errorf("consumables can only be used during a battle")
+	}
+
+	bag := GetConsumableBag(manager, squadID)
+	slot := -1
+	for i, item := range bag.Items {
+		if item == consumableID {
+			slot = i
+			break
+		}
+	}
+	if slot < 0 {
+		return event, fmt.Errorf("squad %s is not carrying a %s", squad.Name, consumableID)
+	}
+
+	def := ConsumableDefs[consumableID]
+
+	switch def.Effect {
+	case ConsumableHeal:
+		applyHealEffect(manager, squadID, def, &event)
+	case ConsumableDamage:
+		applyDamageEffect(manager, def, target, &event)
+	case ConsumableRally:
+		squad.Morale += def.Amount
+		squad.Shaken = false
+	}
+
+	bag.Items = append(bag.Items[:slot], bag.Items[slot+1:]...)
+	manager.NotifyChanged(common.InventoryChanged)
+	manager.NotifyChanged(common.SquadsChanged)
+	return event, nil
+}
+
+// Heals the lowest-HP living unit, capped at its max health
+func applyHealEffect(em *common.EntityManager, squadID int, def ConsumableDefinition, event *ConsumableEvent) {
+
+	var lowest *ecs.Entity
+	for _, u := range SquadUnits(em, squadID) {
+
+		attr := common.GetAttributes(u)
+		if attr.CurrentHealth <= 0 {
+			continue
+		}
+
+		if lowest == nil || attr.CurrentHealth < common.GetAttributes(lowest).CurrentHealth {
+			lowest = u
+		}
+
+	}
+
+	if lowest == nil {
+		return
+	}
+
+	attr := common.GetAttributes(lowest)
+	healed := def.Amount
+	if attr.CurrentHealth+healed > attr.MaxHealth {
+		healed = attr.MaxHealth - attr.CurrentHealth
+	}
+	attr.CurrentHealth += healed
+	RefreshWoundedState(lowest)
+
+	event.HealedUnit = common.GetComponentType[*common.Name](lowest, common.NameComponent).NameStr
+	event.AmountHealed = healed
+}
+
+// Magic damage against each covered cell. It bypasses protection, so the
+// events carry the flat amount
+func applyDamageEffect(em *common.EntityManager, def ConsumableDefinition, target ConsumableTarget, event *ConsumableEvent) {
+
+	for _, offset := range def.Pattern {
+
+		unit := UnitAtGridPos(em, target.SquadID, target.Row+offset[0], target.Col+offset[1])
+		if unit == nil || common.GetAttributes(unit).CurrentHealth <= 0 {
+			continue
+		}
+
+		common.GetAttributes(unit).CurrentHealth -= def.Amount
+		event.Events = append(event.Events, AttackEvent{
+			DefenderID: unit.GetID(),
+			Damage:     def.Amount,
+		})
+
+	}
+
+}
+
+// The heuristic the squad AI uses: drink a potion once anyone drops below 30%
+func AIShouldUsePotion(em *common.EntityManager, squadID int) bool {
+
+	bag := GetConsumableBag(em, squadID)
+	if bag == nil {
+		return false
+	}
+
+	hasPotion := false
+	for _, item := range bag.Items {
+		if ConsumableDefs[item].Effect == ConsumableHeal {
+			hasPotion = true
+		}
+	}
+	if !hasPotion {
+		return false
+	}
+
+	for _, u := range SquadUnits(em, squadID) {
+
+		attr := common.GetAttributes(u)
+		if attr.CurrentHealth > 0 && attr.CurrentHealth*10 < attr.MaxHealth*3 {
+			return true
+		}
+
+	}
+
+	return false
+}
@@ -0,0 +1,133 @@
+package squads
+
+import (
+	"game_main/common"
+	"testing"
+)
+
+func newConsumableFixture(t *testing.T) (*common.EntityManager, *SquadData, *SquadData) {
+
+	t.Helper()
+
+	em := newTestManager()
+	registerTestTemplates()
+
+	att := CreateSquad(em, "Raiders", "Player", common.Position{X: 5, Y: 3}, DirDown)
+	CreateUnitFromTemplate(em, Units["TestKnight"], att.SquadID, 0, 0)
+
+	def := CreateSquad(em, "Garrison", "Enemy", common.Position{X: 5, Y: 5}, DirUp)
+	CreateUnitFromTemplate(em, Units["TestKnight"], def.SquadID, 0, 0)
+	CreateUnitFromTemplate(em, Units["TestKnight"], def.SquadID, 0, 1)
+	CreateUnitFromTemplate(em, Units["TestRogue"], def.SquadID, 1, 0)
+
+	//Both squads are mid-battle
+	AdvanceSquadRound(att)
+	AdvanceSquadRound(def)
+
+	return em, att, def
+}
+
+func TestPotionHealsAndIsConsumed(t *testing.T) {
+
+	em, att, _ := newConsumableFixture(t)
+
+	if err := AddConsumable(em, att.SquadID, "HealingPotion"); err != nil {
+		t.Fatalf("adding a potion failed: %v", err)
+	}
+
+	wounded := SquadUnits(em, att.SquadID)[0]
+	attr := common.GetAttributes(wounded)
+	attr.CurrentHealth = attr.MaxHealth - 4
+
+	event, err := UseConsumable(att.SquadID, "HealingPotion", ConsumableTarget{}, em)
+	if err != nil {
+		t.Fatalf("using the potion failed: %v", err)
+	}
+
+	//The potion heals 10 but the unit is only 4 below max, so the cap applies
+	if event.AmountHealed != 4 {
+		t.Errorf("expected the heal to cap at 4, got %d", event.AmountHealed)
+	}
+	if attr.CurrentHealth != attr.MaxHealth {
+		t.Errorf("expected full health after the potion, got %d/%d", attr.CurrentHealth, attr.MaxHealth)
+	}
+
+	if len(GetConsumableBag(em, att.SquadID).Items) != 0 {
+		t.Error("the potion should be gone from the bag after use")
+	}
+
+	if _, err := UseConsumable(att.SquadID, "HealingPotion", ConsumableTarget{}, em); err == nil {
+		t.Error("using an already consumed item should fail")
+	}
+}
+
+func TestFireBombHitsTheChosenCells(t *testing.T) {
+
+	em, att, def := newConsumableFixture(t)
+
+	if err := AddConsumable(em, att.SquadID, "FireBomb"); err != nil {
+		t.Fatalf("adding a bomb failed: %v", err)
+	}
+
+	target := ConsumableTarget{SquadID: def.SquadID, Row: 0, Col: 0}
+	event, err := UseConsumable(att.SquadID, "FireBomb", target, em)
+	if err != nil {
+		t.Fatalf("using the bomb failed: %v", err)
+	}
+
+	//The bomb pattern covers (0,0), (0,1) and (1,0), all three are occupied
+	if len(event.Events) != 3 {
+		t.Fatalf("expected 3 damage events, got %d", len(event.Events))
+	}
+
+	for _, e := range event.Events {
+		if e.Damage != ConsumableDefs["FireBomb"].Amount {
+			t.Errorf("magic damage should bypass protection, got %d", e.Damage)
+		}
+	}
+
+	hit := UnitAtGridPos(em, def.SquadID, 0, 0)
+	attr := common.GetAttributes(hit)
+	if attr.CurrentHealth != attr.MaxHealth-ConsumableDefs["FireBomb"].Amount {
+		t.Errorf("expected the front knight to carry bomb damage, has %d/%d", attr.CurrentHealth, attr.MaxHealth)
+	}
+}
+
+func TestConsumablesRejectedOutsideCombat(t *testing.T) {
+
+	em := newTestManager()
+	registerTestTemplates()
+
+	squad := CreateSquad(em, "Idlers", "Player", common.Position{X: 0, Y: 0}, DirUp)
+	CreateUnitFromTemplate(em, Units["TestKnight"], squad.SquadID, 0, 0)
+
+	if err := AddConsumable(em, squad.SquadID, "HealingPotion"); err != nil {
+		t.Fatalf("adding a potion failed: %v", err)
+	}
+
+	if _, err := UseConsumable(squad.SquadID, "HealingPotion", ConsumableTarget{}, em); err == nil {
+		t.Error("using a consumable outside a battle should be rejected")
+	}
+
+	if len(GetConsumableBag(em, squad.SquadID).Items) != 1 {
+		t.Error("a rejected use must not consume the item")
+	}
+}
+
+func TestBagCapacityIsEnforced(t *testing.T) {
+
+	em := newTestManager()
+	registerTestTemplates()
+
+	squad := CreateSquad(em, "Packrats", "Player", common.Position{X: 0, Y: 0}, DirUp)
+
+	for i := 0; i < DefaultBagCapacity; i++ {
+		if err := AddConsumable(em, squad.SquadID, "HealingPotion"); err != nil {
+			t.Fatalf("filling the bag failed: %v", err)
+		}
+	}
+
+	if err := AddConsumable(em, squad.SquadID, "FireBomb"); err == nil {
+		t.Error("adding past the bag capacity should fail")
+	}
+}
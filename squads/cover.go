@@ -0,0 +1,43 @@
+package squads
+
+import (
+	"game_main/common"
+
+	"github.com/bytearena/ecs"
+)
+
+// Bracing cover: optionally, a unit only shields the cells behind it while it
+// has not spent its turn attacking. The idea is that holding a shield wall is
+// an action of its own - a unit that swung its weapon this round has dropped
+// its guard until the next one
+
+// CoverConfig holds the optional cover rules. Off by default, so cover keeps
+// its historical only-the-living-matter behavior
+type CoverConfig struct {
+	//A unit that has attacked this round provides no cover until the round
+	//advances
+	RequiresNotActed bool
+}
+
+var Cover = CoverConfig{}
+
+// markUnitActed stamps the unit with its squads current round. Called
+// whenever a unit resolves an attack, counters included
+func markUnitActed(em *common.EntityManager, unit *ecs.Entity) {
+
+	data := GetUnitData(unit)
+	if squad := GetSquadByID(em, data.SquadID); squad != nil {
+		data.ActedRound = squad.CurrentRound + 1
+	}
+
+}
+
+// UnitActedThisRound reports whether the unit has attacked in its squads
+// current round. A fresh round clears it implicitly, no per-round sweep
+func UnitActedThisRound(em *common.EntityManager, unit *ecs.Entity) bool {
+
+	data := GetUnitData(unit)
+	squad := GetSquadByID(em, data.SquadID)
+
+	return squad != nil && data.ActedRound == squad.CurrentRound+1
+}
@@ -0,0 +1,77 @@
+package squads
+
+import (
+	"game_main/common"
+	"testing"
+)
+
+// A shieldman in front of a knight, and an enemy to swing at. The knight
+// behind the shieldman gets cover 3 against front attacks
+func braceFixture() (*common.EntityManager, *SquadData, *SquadData) {
+
+	em := newTestManager()
+	registerTestTemplates()
+
+	att := CreateSquad(em, "Attackers", "Player", common.Position{X: 5, Y: 3}, DirDown)
+	CreateUnitFromTemplate(em, Units["TestKnight"], att.SquadID, 0, 0)
+
+	def := CreateSquad(em, "Defenders", "Enemy", common.Position{X: 5, Y: 5}, DirUp)
+	CreateUnitFromTemplate(em, Units["TestKnight"], def.SquadID, 0, 1)
+	CreateUnitFromTemplate(em, Units["TestKnight"], def.SquadID, 1, 1)
+
+	return em, att, def
+}
+
+// Under bracing rules a shieldman that spent its round attacking shields
+// nothing, and a fresh round restores its cover
+func TestActedProvidersGiveNoCoverWhenBracing(t *testing.T) {
+
+	em, att, def := braceFixture()
+
+	restore := Cover
+	Cover.RequiresNotActed = true
+	defer func() { Cover = restore }()
+
+	attacker := UnitAtGridPos(em, att.SquadID, 0, 0)
+	shieldman := UnitAtGridPos(em, def.SquadID, 0, 1)
+	protected := UnitAtGridPos(em, def.SquadID, 1, 1)
+
+	braced := ResolveUnitAttack(em, attacker.GetID(), protected.GetID(), DirDown)
+	if braced.CoverReduction != 3 {
+		t.Errorf("a shieldman that has not acted should cover for 3, got %d", braced.CoverReduction)
+	}
+
+	ResolveUnitAttack(em, shieldman.GetID(), attacker.GetID(), DirUp)
+
+	dropped := ResolveUnitAttack(em, attacker.GetID(), protected.GetID(), DirDown)
+	if dropped.CoverReduction != 0 {
+		t.Errorf("a shieldman that attacked this round should cover nothing, got %d", dropped.CoverReduction)
+	}
+
+	//The next round the shieldman braces again
+	AdvanceSquadRound(def)
+
+	recovered := ResolveUnitAttack(em, attacker.GetID(), protected.GetID(), DirDown)
+	if recovered.CoverReduction != 3 {
+		t.Errorf("a new round should restore the cover, got %d", recovered.CoverReduction)
+	}
+
+}
+
+// Off, acting changes nothing: the historical HP-only rule
+func TestActedProvidersStillCoverByDefault(t *testing.T) {
+
+	em, att, def := braceFixture()
+
+	attacker := UnitAtGridPos(em, att.SquadID, 0, 0)
+	shieldman := UnitAtGridPos(em, def.SquadID, 0, 1)
+	protected := UnitAtGridPos(em, def.SquadID, 1, 1)
+
+	ResolveUnitAttack(em, shieldman.GetID(), attacker.GetID(), DirUp)
+
+	event := ResolveUnitAttack(em, attacker.GetID(), protected.GetID(), DirDown)
+	if event.CoverReduction != 3 {
+		t.Errorf("without bracing rules an acted shieldman still covers for 3, got %d", event.CoverReduction)
+	}
+
+}
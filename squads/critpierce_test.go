@@ -0,0 +1,99 @@
+package squads
+
+import (
+	"game_main/common"
+	"testing"
+)
+
+// A focused fixed-damage attacker against a heavily armored target, so the
+// crit and pierce arithmetic has no randomness in it
+func critPierceFixture(t *testing.T) func() AttackEvent {
+
+	t.Helper()
+
+	em := newTestManager()
+
+	attr := common.NewBaseAttributes(20, 0, 10, 0, 1, 0)
+	attr.Focus = 1
+	RegisterUnitTemplate(UnitTemplate{
+		Name:      "PierceTester",
+		Class:     "Rogue",
+		Attr:      attr,
+		MinDamage: 6,
+		MaxDamage: 6,
+	})
+
+	RegisterUnitTemplate(UnitTemplate{
+		Name:      "PierceTank",
+		Class:     "Knight",
+		Attr:      common.NewBaseAttributes(1000, 0, 10, 4, 1, 0),
+		MinDamage: 1,
+		MaxDamage: 1,
+	})
+
+	att := CreateSquad(em, "Daggers", "Player", common.Position{X: 5, Y: 5}, DirDown)
+	attacker := CreateUnitFromTemplate(em, Units["PierceTester"], att.SquadID, 0, 0)
+
+	def := CreateSquad(em, "Tanks", "Enemy", common.Position{X: 5, Y: 6}, DirUp)
+	defender := CreateUnitFromTemplate(em, Units["PierceTank"], def.SquadID, 0, 0)
+
+	return func() AttackEvent {
+		return calculateUnitDamageByID(em, attacker.GetID(), defender.GetID(), DirDown)
+	}
+}
+
+// With penetration on, a crit against heavy armor clears 1.5x a normal hits
+// post-protection damage, and the pierced amount lands on the event
+func TestCritPenetrationBeatsHeavyArmor(t *testing.T) {
+
+	originalCrit := critRoll
+	defer func() { critRoll = originalCrit }()
+
+	restore := Crits
+	Crits.ArmorPierce = 0.5
+	defer func() { Crits = restore }()
+
+	swing := critPierceFixture(t)
+
+	critRoll = func() int { return 100 }
+	normal := swing()
+	if normal.Damage != 2 {
+		t.Fatalf("the normal hit should land 6 minus 4 protection, got %d", normal.Damage)
+	}
+
+	critRoll = func() int { return 1 }
+	crit := swing()
+
+	if !crit.Crit || crit.CritPierce != 2 {
+		t.Fatalf("the crit should punch through half the 4 protection, got pierce %d", crit.CritPierce)
+	}
+
+	//12 crit damage minus the 2 protection left after the pierce
+	if crit.Damage != 10 {
+		t.Errorf("the crit should land 10, got %d", crit.Damage)
+	}
+
+	if float64(crit.Damage) <= 1.5*float64(normal.Damage) {
+		t.Errorf("the pierced crit should clear 1.5x the normal hit, got %d against %d", crit.Damage, normal.Damage)
+	}
+
+}
+
+// Off, crits pay full protection and record no pierce
+func TestCritPenetrationDefaultsOff(t *testing.T) {
+
+	originalCrit := critRoll
+	critRoll = func() int { return 1 }
+	defer func() { critRoll = originalCrit }()
+
+	swing := critPierceFixture(t)
+	crit := swing()
+
+	if crit.CritPierce != 0 {
+		t.Errorf("no pierce should be recorded by default, got %d", crit.CritPierce)
+	}
+	if crit.Damage != 8 {
+		t.Errorf("a default crit should land 12 minus the full 4 protection, got %d", crit.Damage)
+	}
+
+}
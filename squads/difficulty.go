@@ -0,0 +1,186 @@
+package squads
+
+import (
+	"fmt"
+	"game_main/common"
+
+	"github.com/bytearena/ecs"
+)
+
+// Campaign difficulty. A profile is picked at new-game time, travels with the
+// savegame, and adjusts four knobs: AI unit stats at spawn, the squad AI
+// config, the encounter budget, and how fast player squads recover after a
+// battle. Scaled units are marked so balance logs can separate difficulty
+// from template changes. The simulator never applies any of this unless a
+// caller hands it a profile explicitly, so balance data stays at Normal
+
+// The faction the player controls. Everything else counts as AI for
+// difficulty scaling
+const PlayerFaction = "Player"
+
+type DifficultyLevel int
+
+const (
+	DifficultyEasy DifficultyLevel = iota
+	DifficultyNormal
+	DifficultyHard
+
+	//Custom keeps whatever percentages the player dialed in
+	DifficultyCustom
+)
+
+func (d DifficultyLevel) String() string {
+	switch d {
+	case DifficultyEasy:
+		return "Easy"
+	case DifficultyNormal:
+		return "Normal"
+	case DifficultyHard:
+		return "Hard"
+	default:
+		return "Custom"
+	}
+}
+
+type DifficultyProfile struct {
+	Level DifficultyLevel `json:"level"`
+
+	//Percentage applied to AI unit health and protection at spawn, 100 is
+	//unchanged. Damage comes off the shared templates so it stays as-is
+	AIStatPercent int `json:"aiStatPercent"`
+
+	//Whether the AI hunts for finishing blows or just trades with the
+	//biggest threat
+	AIPreferFinishingBlows bool `json:"aiPreferFinishingBlows"`
+
+	//Percentage applied to the encounter generators point budget
+	EncounterBudgetPercent int `json:"encounterBudgetPercent"`
+
+	//Percentage of missing health player units get back after a battle
+	RecoveryPercent int `json:"recoveryPercent"`
+
+	//Adaptive encounter scaling: blend this percentage of the players
+	//rolling army strength into encounter budgets. The master switch exists
+	//for players who hate rubber-banding; every preset ships with it off
+	AdaptiveScaling      bool `json:"adaptiveScaling,omitempty"`
+	AdaptiveBlendPercent int  `json:"adaptiveBlendPercent,omitempty"`
+}
+
+// DifficultyProfileFor is the preset table. Custom starts from Normal and
+// gets edited in the settings UI
+func DifficultyProfileFor(level DifficultyLevel) DifficultyProfile {
+
+	switch level {
+	case DifficultyEasy:
+		return DifficultyProfile{
+			Level:                  DifficultyEasy,
+			AIStatPercent:          80,
+			AIPreferFinishingBlows: false,
+			EncounterBudgetPercent: 80,
+			RecoveryPercent:        100,
+		}
+	case DifficultyHard:
+		return DifficultyProfile{
+			Level:                  DifficultyHard,
+			AIStatPercent:          125,
+			AIPreferFinishingBlows: true,
+			EncounterBudgetPercent: 120,
+			RecoveryPercent:        25,
+		}
+	default:
+		return DifficultyProfile{
+			Level:                  DifficultyNormal,
+			AIStatPercent:          100,
+			AIPreferFinishingBlows: true,
+			EncounterBudgetPercent: 100,
+			RecoveryPercent:        50,
+		}
+	}
+}
+
+// Describe is what the settings UI shows, one knob per line, so the player
+// sees exactly what a level changes
+func (p DifficultyProfile) Describe() string {
+
+	return fmt.Sprintf("%s\nAI unit stats: %d%%\nEncounter size: %d%%\nPost-battle recovery: %d%%\nAI finishes wounded squads: %v\n",
+		p.Level, p.AIStatPercent, p.EncounterBudgetPercent, p.RecoveryPercent, p.AIPreferFinishingBlows)
+}
+
+// AIConfigFor maps the profile onto the battle runner AI
+func AIConfigFor(profile DifficultyProfile) AIConfig {
+	return AIConfig{PreferFinishingBlows: profile.AIPreferFinishingBlows}
+}
+
+// EncounterBudget scales the threat generators point budget
+func EncounterBudget(base float64, profile DifficultyProfile) float64 {
+	return base * float64(profile.EncounterBudgetPercent) / 100.0
+}
+
+// SpawnEncounterUnit creates a unit and applies the difficulty scaling when
+// its squad is not player-controlled. Encounter spawning goes through here;
+// the simulator and the tests that care about raw templates keep using
+// CreateUnitFromTemplate directly
+func SpawnEncounterUnit(em *common.EntityManager, template UnitTemplate, squadID int, row int, col int, profile DifficultyProfile) *ecs.Entity {
+
+	unit := CreateUnitFromTemplate(em, template, squadID, row, col)
+
+	squad := GetSquadByID(em, squadID)
+	if squad != nil && squad.Faction != PlayerFaction {
+		applyDifficultyScaling(unit, profile)
+	}
+
+	return unit
+}
+
+// The scaling bakes into the units attributes at spawn and marks the unit,
+// so a balance log reading the numbers can tell difficulty apart from a
+// template change
+func applyDifficultyScaling(unit *ecs.Entity, profile DifficultyProfile) {
+
+	if profile.AIStatPercent == 100 {
+		return
+	}
+
+	attr := common.GetAttributes(unit)
+	attr.MaxHealth = attr.MaxHealth * profile.AIStatPercent / 100
+	attr.CurrentHealth = attr.CurrentHealth * profile.AIStatPercent / 100
+	attr.BaseProtection = attr.BaseProtection * profile.AIStatPercent / 100
+
+	GetUnitData(unit).DifficultyScaled = true
+
+}
+
+// RecoverAfterBattle heals a squads survivors by the profiles share of their
+// missing health. The post battle flow calls it for player squads
+func RecoverAfterBattle(em *common.EntityManager, squadID int, profile DifficultyProfile) {
+
+	for _, u := range SquadUnits(em, squadID) {
+
+		attr := common.GetAttributes(u)
+		if attr.CurrentHealth <= 0 {
+			continue
+		}
+
+		attr.CurrentHealth += (attr.MaxHealth - attr.CurrentHealth) * profile.RecoveryPercent / 100
+		if attr.CurrentHealth > attr.MaxHealth {
+			attr.CurrentHealth = attr.MaxHealth
+		}
+
+	}
+
+}
+
+// SavedCampaign is the savegame envelope: the difficulty rides along with
+// the squads so a Hard save loads back as Hard
+type SavedCampaign struct {
+	Difficulty DifficultyProfile `json:"difficulty"`
+	Squads     []SavedSquad      `json:"squads"`
+}
+
+func SerializeCampaign(em *common.EntityManager, profile DifficultyProfile) SavedCampaign {
+	return SavedCampaign{Difficulty: profile, Squads: SerializeSquads(em)}
+}
+
+func DeserializeCampaign(em *common.EntityManager, saved SavedCampaign) (DifficultyProfile, error) {
+	return saved.Difficulty, DeserializeSquads(em, saved.Squads)
+}
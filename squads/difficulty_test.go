@@ -0,0 +1,111 @@
+package squads
+
+import (
+	"encoding/json"
+	"game_main/common"
+	"strings"
+	"testing"
+)
+
+// The same encounter spawned at Easy and Hard: AI units move by the expected
+// delta, player units stay exactly on template
+func TestDifficultyScalesOnlyTheAIUnits(t *testing.T) {
+
+	em := newTestManager()
+	registerTestTemplates()
+
+	for _, level := range []DifficultyLevel{DifficultyEasy, DifficultyHard} {
+
+		profile := DifficultyProfileFor(level)
+
+		playerSquad := CreateSquad(em, "Player "+level.String(), "Player", common.Position{X: 1, Y: 1}, DirDown)
+		playerUnit := SpawnEncounterUnit(em, Units["TestKnight"], playerSquad.SquadID, 0, 0, profile)
+
+		enemySquad := CreateSquad(em, "Enemy "+level.String(), "Enemy", common.Position{X: 1, Y: 3}, DirUp)
+		enemyUnit := SpawnEncounterUnit(em, Units["TestKnight"], enemySquad.SquadID, 0, 0, profile)
+
+		base := Units["TestKnight"].Attr.MaxHealth
+		want := base * profile.AIStatPercent / 100
+
+		if got := common.GetAttributes(playerUnit).MaxHealth; got != base {
+			t.Errorf("%s: player unit should keep template health %d, got %d", level, base, got)
+		}
+		if GetUnitData(playerUnit).DifficultyScaled {
+			t.Errorf("%s: player unit must not be marked as scaled", level)
+		}
+
+		if got := common.GetAttributes(enemyUnit).MaxHealth; got != want {
+			t.Errorf("%s: expected AI unit health %d, got %d", level, want, got)
+		}
+		if !GetUnitData(enemyUnit).DifficultyScaled {
+			t.Errorf("%s: AI unit should be marked as scaled", level)
+		}
+
+	}
+}
+
+// A Hard save must come back as Hard, squads and all
+func TestHardSaveRoundTripsTheProfile(t *testing.T) {
+
+	em := newTestManager()
+	registerTestTemplates()
+
+	squad := CreateSquad(em, "Campaigners", "Player", common.Position{X: 1, Y: 1}, DirDown)
+	CreateUnitFromTemplate(em, Units["TestKnight"], squad.SquadID, 0, 0)
+
+	saved := SerializeCampaign(em, DifficultyProfileFor(DifficultyHard))
+
+	data, err := json.Marshal(saved)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	loaded := SavedCampaign{}
+	if err := json.Unmarshal(data, &loaded); err != nil {
+		t.Fatal(err)
+	}
+
+	fresh := newTestManager()
+	profile, err := DeserializeCampaign(fresh, loaded)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if profile != DifficultyProfileFor(DifficultyHard) {
+		t.Errorf("expected the Hard profile back, got %+v", profile)
+	}
+	if GetSquadByID(fresh, squad.SquadID) == nil {
+		t.Error("expected the squads to load along with the profile")
+	}
+}
+
+// The settings text names every knob
+func TestDescribeListsEveryKnob(t *testing.T) {
+
+	text := DifficultyProfileFor(DifficultyEasy).Describe()
+
+	for _, want := range []string{"Easy", "AI unit stats: 80%", "Encounter size: 80%", "Post-battle recovery: 100%"} {
+		if !strings.Contains(text, want) {
+			t.Errorf("expected the description to contain %q, got:\n%s", want, text)
+		}
+	}
+}
+
+// Recovery heals the survivors by the profiles share of missing health and
+// never past max
+func TestRecoveryRateFollowsTheProfile(t *testing.T) {
+
+	em := newTestManager()
+	registerTestTemplates()
+
+	squad := CreateSquad(em, "Weary", "Player", common.Position{X: 1, Y: 1}, DirDown)
+	unit := CreateUnitFromTemplate(em, Units["TestKnight"], squad.SquadID, 0, 0)
+	common.GetAttributes(unit).CurrentHealth = 10
+
+	RecoverAfterBattle(em, squad.SquadID, DifficultyProfileFor(DifficultyNormal))
+
+	//Normal recovers half of the 10 missing health
+	if got := common.GetAttributes(unit).CurrentHealth; got != 15 {
+		t.Errorf("expected 15 health after Normal recovery, got %d", got)
+	}
+}
@@ -0,0 +1,263 @@
+package squads
+
+import (
+	"encoding/json"
+	"fmt"
+	"game_main/common"
+	"os"
+	"sort"
+	"strings"
+)
+
+// The diplomacy matrix between factions. Combat historically treated every
+// other faction as an enemy; the matrix makes that explicit so the strategic
+// layer can declare ceasefires, alliances, and neutral third parties like a
+// merchant faction. Package-level like ActiveEnvironment: eligibility, the
+// AI, and the free-for-all battle loop all read the same matrix, and the
+// hostile default reproduces the old two-sided behavior when nothing ever
+// sets a stance
+
+type Stance int
+
+const (
+	StanceHostile Stance = iota
+	StanceNeutral
+	StanceAllied
+)
+
+func (s Stance) String() string {
+	switch s {
+	case StanceNeutral:
+		return "Neutral"
+	case StanceAllied:
+		return "Allied"
+	default:
+		return "Hostile"
+	}
+}
+
+type Diplomacy struct {
+	//Keyed by the sorted faction pair, so the stance is symmetric by
+	//construction
+	stances map[string]Stance
+}
+
+func NewDiplomacy() *Diplomacy {
+	return &Diplomacy{stances: make(map[string]Stance)}
+}
+
+var ActiveDiplomacy = NewDiplomacy()
+
+func stanceKey(a string, b string) string {
+
+	if a > b {
+		a, b = b, a
+	}
+
+	return a + "|" + b
+}
+
+// StanceBetween is the current stance between two factions. A faction is
+// always allied with itself, and unrelated factions default to hostile
+func (d *Diplomacy) StanceBetween(a string, b string) Stance {
+
+	if a == b {
+		return StanceAllied
+	}
+
+	return d.stances[stanceKey(a, b)]
+}
+
+// SetStance declares a new stance between two factions. Setting a pair back
+// to hostile removes the entry, so the saved matrix only carries departures
+// from the default. A factions stance with itself cannot change
+func (d *Diplomacy) SetStance(a string, b string, stance Stance) {
+
+	if a == b {
+		return
+	}
+
+	if stance == StanceHostile {
+		delete(d.stances, stanceKey(a, b))
+		return
+	}
+
+	d.stances[stanceKey(a, b)] = stance
+}
+
+// Hostile is the question combat actually asks: may these factions fight
+func (d *Diplomacy) Hostile(a string, b string) bool {
+	return d.StanceBetween(a, b) == StanceHostile
+}
+
+// ValidTargetSquads is every living squad the attacker is allowed to engage
+// under the current matrix, in squad ID order so callers iterate
+// deterministically
+func ValidTargetSquads(em *common.EntityManager, attackerSquadID int) []int {
+
+	attacker := GetSquadByID(em, attackerSquadID)
+	if attacker == nil {
+		return nil
+	}
+
+	targets := []int{}
+	for _, s := range em.World.Query(em.WorldTags["squads"]) {
+
+		data := GetSquadData(s.Entity)
+		if data.SquadID == attackerSquadID || !ActiveDiplomacy.Hostile(attacker.Faction, data.Faction) {
+			continue
+		}
+
+		if squadHasLivingUnits(em, data.SquadID) {
+			targets = append(targets, data.SquadID)
+		}
+
+	}
+
+	sort.Ints(targets)
+	return targets
+}
+
+// HostilePairsRemain reports whether any two living squads still belong to
+// mutually hostile factions. When it goes false the battle is over, whatever
+// mix of survivors that leaves standing
+func HostilePairsRemain(em *common.EntityManager) bool {
+
+	factions := []string{}
+	seen := make(map[string]bool)
+	for _, s := range em.World.Query(em.WorldTags["squads"]) {
+
+		data := GetSquadData(s.Entity)
+		if !seen[data.Faction] && squadHasLivingUnits(em, data.SquadID) {
+			seen[data.Faction] = true
+			factions = append(factions, data.Faction)
+		}
+
+	}
+
+	for i := 0; i < len(factions); i++ {
+		for j := i + 1; j < len(factions); j++ {
+
+			if ActiveDiplomacy.Hostile(factions[i], factions[j]) {
+				return true
+			}
+
+		}
+	}
+
+	return false
+}
+
+// RunFreeForAll resolves a battle among any number of factions: each round
+// the factions act in turn order and every living squad attacks the AI-chosen
+// target among the squads the matrix lets it engage. The battle ends when no
+// hostile pair of living squads remains - mutual annihilation down to one
+// side in an all-out war, or the moment the last shared enemy falls when two
+// factions fight as allies. Returns the factions with living squads left
+func RunFreeForAll(em *common.EntityManager, factions []string, maxRounds int) []string {
+
+	order := NewCombatTurnOrder(em, factions, 0)
+
+	for round := 0; round < maxRounds && HostilePairsRemain(em); round++ {
+
+		for _, faction := range factions {
+			for _, squadID := range order.GetTurnOrder(faction) {
+
+				targetID := ChooseTargetSquad(em, squadID, ValidTargetSquads(em, squadID), DefaultAIConfig)
+				if targetID == 0 {
+					continue
+				}
+
+				attacker := GetSquadByID(em, squadID)
+				target := GetSquadByID(em, targetID)
+				ExecuteSquadAttack(em, squadID, targetID, overwatchFireDirection(attacker.MapPos, target.MapPos))
+
+			}
+		}
+
+		for _, faction := range factions {
+			for _, squadID := range order.GetTurnOrder(faction) {
+				AdvanceSquadRound(GetSquadByID(em, squadID))
+			}
+		}
+
+	}
+
+	survivors := []string{}
+	for _, faction := range factions {
+		if len(order.GetTurnOrder(faction)) > 0 {
+			survivors = append(survivors, faction)
+		}
+	}
+
+	return survivors
+}
+
+// The saved form flattens the map so the savegame diffs cleanly. Only
+// non-hostile pairs are stored; everything else is the default
+type savedStance struct {
+	FactionA string `json:"factionA"`
+	FactionB string `json:"factionB"`
+	Stance   string `json:"stance"`
+}
+
+type savedDiplomacy struct {
+	Stances []savedStance `json:"stances"`
+}
+
+func SaveDiplomacy(path string, d *Diplomacy) error {
+
+	saved := savedDiplomacy{Stances: []savedStance{}}
+	for key, stance := range d.stances {
+
+		factions := strings.SplitN(key, "|", 2)
+		saved.Stances = append(saved.Stances, savedStance{FactionA: factions[0], FactionB: factions[1], Stance: stance.String()})
+
+	}
+
+	sort.Slice(saved.Stances, func(i, j int) bool {
+		if saved.Stances[i].FactionA != saved.Stances[j].FactionA {
+			return saved.Stances[i].FactionA < saved.Stances[j].FactionA
+		}
+		return saved.Stances[i].FactionB < saved.Stances[j].FactionB
+	})
+
+	contents, err := json.MarshalIndent(saved, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(path, append(contents, '\n'), 0644)
+}
+
+func LoadDiplomacy(path string) (*Diplomacy, error) {
+
+	contents, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var saved savedDiplomacy
+	if err := json.Unmarshal(contents, &saved); err != nil {
+		return nil, fmt.Errorf("diplomacy file %s did not parse: %w", path, err)
+	}
+
+	diplomacy := NewDiplomacy()
+	for _, entry := range saved.Stances {
+
+		switch entry.Stance {
+		case "Neutral":
+			diplomacy.SetStance(entry.FactionA, entry.FactionB, StanceNeutral)
+		case "Allied":
+			diplomacy.SetStance(entry.FactionA, entry.FactionB, StanceAllied)
+		case "Hostile":
+			//The default, nothing to store
+		default:
+			return nil, fmt.Errorf("diplomacy file %s has unknown stance %q between %s and %s",
+				path, entry.Stance, entry.FactionA, entry.FactionB)
+		}
+
+	}
+
+	return diplomacy, nil
+}
@@ -0,0 +1,168 @@
+package squads
+
+import (
+	"game_main/common"
+	"game_main/randgen"
+	"path/filepath"
+	"testing"
+)
+
+// A fresh matrix for the test, restored afterwards so the hostile default
+// stays in place for everything else
+func freshDiplomacy(t *testing.T) *Diplomacy {
+
+	t.Helper()
+
+	restore := ActiveDiplomacy
+	ActiveDiplomacy = NewDiplomacy()
+	t.Cleanup(func() { ActiveDiplomacy = restore })
+
+	return ActiveDiplomacy
+}
+
+// Every stance against the same target: hostile factions can fight, neutral
+// and allied ones get refused with their own reasons
+func TestEligibilityFollowsStance(t *testing.T) {
+
+	em := newTestManager()
+	registerTestTemplates()
+	diplomacy := freshDiplomacy(t)
+
+	att := CreateSquad(em, "Attackers", "Player", common.Position{X: 5, Y: 5}, DirDown)
+	CreateUnitFromTemplate(em, Units["TestKnight"], att.SquadID, 0, 0)
+
+	caravan := CreateSquad(em, "Caravan", "Merchants", common.Position{X: 5, Y: 6}, DirUp)
+	CreateUnitFromTemplate(em, Units["TestKnight"], caravan.SquadID, 0, 0)
+
+	//Unset pairs default to hostile, the historical behavior
+	if code, reason := CheckAttackEligibility(em, att.SquadID, caravan.SquadID, 1); code != EligibilityOK {
+		t.Fatalf("hostile factions should be attackable, got code %d (%s)", code, reason)
+	}
+
+	diplomacy.SetStance("Player", "Merchants", StanceNeutral)
+	if code, _ := CheckAttackEligibility(em, att.SquadID, caravan.SquadID, 1); code != EligibilityNeutral {
+		t.Errorf("a neutral faction should refuse with EligibilityNeutral, got code %d", code)
+	}
+
+	diplomacy.SetStance("Player", "Merchants", StanceAllied)
+	if code, _ := CheckAttackEligibility(em, att.SquadID, caravan.SquadID, 1); code != EligibilityAllied {
+		t.Errorf("an allied faction should refuse with EligibilityAllied, got code %d", code)
+	}
+
+	//Declaring war again reopens the target
+	diplomacy.SetStance("Player", "Merchants", StanceHostile)
+	if code, _ := CheckAttackEligibility(em, att.SquadID, caravan.SquadID, 1); code != EligibilityOK {
+		t.Errorf("declaring war should make the faction attackable again, got code %d", code)
+	}
+
+}
+
+// A mid-game stance change reshapes what the AI is willing to shoot at
+func TestStanceChangeUpdatesAITargets(t *testing.T) {
+
+	em := newTestManager()
+	registerTestTemplates()
+	diplomacy := freshDiplomacy(t)
+
+	att := CreateSquad(em, "Attackers", "Player", common.Position{X: 5, Y: 5}, DirDown)
+	CreateUnitFromTemplate(em, Units["TestKnight"], att.SquadID, 0, 0)
+
+	caravan := CreateSquad(em, "Caravan", "Merchants", common.Position{X: 5, Y: 6}, DirUp)
+	CreateUnitFromTemplate(em, Units["TestKnight"], caravan.SquadID, 0, 0)
+
+	raiders := CreateSquad(em, "Raiders", "Bandits", common.Position{X: 6, Y: 5}, DirUp)
+	CreateUnitFromTemplate(em, Units["TestRogue"], raiders.SquadID, 0, 0)
+
+	candidates := []int{caravan.SquadID, raiders.SquadID}
+
+	if got := ChooseTargetSquad(em, att.SquadID, candidates, DefaultAIConfig); got == 0 {
+		t.Fatal("with everyone hostile the AI should pick a target")
+	}
+
+	//A ceasefire with the merchants leaves only the bandits on the list
+	diplomacy.SetStance("Player", "Merchants", StanceNeutral)
+	if got := ChooseTargetSquad(em, att.SquadID, candidates, DefaultAIConfig); got != raiders.SquadID {
+		t.Errorf("with the merchants neutral the AI should pick the raiders, got squad %d", got)
+	}
+
+	diplomacy.SetStance("Player", "Bandits", StanceAllied)
+	if got := ChooseTargetSquad(em, att.SquadID, candidates, DefaultAIConfig); got != 0 {
+		t.Errorf("with nobody hostile the AI should hold fire, got squad %d", got)
+	}
+
+}
+
+// Three factions, two of them allied against the third. The battle must end
+// the moment the shared enemy falls, leaving both allies standing
+func TestThreeFactionBattleTerminates(t *testing.T) {
+
+	em := newTestManager()
+	registerTestTemplates()
+	diplomacy := freshDiplomacy(t)
+
+	randgen.Seed(99)
+	defer randgen.ClearSeed()
+
+	player := CreateSquad(em, "Vanguard", "Player", common.Position{X: 5, Y: 5}, DirDown)
+	CreateUnitFromTemplate(em, Units["TestKnight"], player.SquadID, 0, 0)
+
+	allies := CreateSquad(em, "Auxiliaries", "Mercenaries", common.Position{X: 4, Y: 5}, DirDown)
+	CreateUnitFromTemplate(em, Units["TestKnight"], allies.SquadID, 0, 0)
+
+	raiders := CreateSquad(em, "Raiders", "Bandits", common.Position{X: 5, Y: 7}, DirUp)
+	CreateUnitFromTemplate(em, Units["TestRogue"], raiders.SquadID, 0, 0)
+
+	diplomacy.SetStance("Player", "Mercenaries", StanceAllied)
+
+	if !HostilePairsRemain(em) {
+		t.Fatal("two factions are still at war with the bandits")
+	}
+
+	survivors := RunFreeForAll(em, []string{"Player", "Mercenaries", "Bandits"}, 50)
+
+	if HostilePairsRemain(em) {
+		t.Error("the battle ended with hostile pairs still standing")
+	}
+	if len(survivors) != 2 {
+		t.Fatalf("expected both allies to survive the bandits, got %v", survivors)
+	}
+	if squadHasLivingUnits(em, raiders.SquadID) {
+		t.Error("the bandits should have been wiped out")
+	}
+
+}
+
+// The matrix round-trips through its save file, and only departures from the
+// hostile default are stored
+func TestDiplomacyRoundTripsThroughSave(t *testing.T) {
+
+	diplomacy := NewDiplomacy()
+	diplomacy.SetStance("Player", "Merchants", StanceNeutral)
+	diplomacy.SetStance("Player", "Mercenaries", StanceAllied)
+	diplomacy.SetStance("Player", "Bandits", StanceAllied)
+	diplomacy.SetStance("Player", "Bandits", StanceHostile) //war again, drops out
+
+	path := filepath.Join(t.TempDir(), "diplomacy.json")
+	if err := SaveDiplomacy(path, diplomacy); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded, err := LoadDiplomacy(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got := loaded.StanceBetween("Merchants", "Player"); got != StanceNeutral {
+		t.Errorf("expected the merchant ceasefire to survive the save, got %v", got)
+	}
+	if got := loaded.StanceBetween("Player", "Mercenaries"); got != StanceAllied {
+		t.Errorf("expected the mercenary alliance to survive the save, got %v", got)
+	}
+	if got := loaded.StanceBetween("Player", "Bandits"); got != StanceHostile {
+		t.Errorf("expected the bandits to stay hostile, got %v", got)
+	}
+	if len(loaded.stances) != 2 {
+		t.Errorf("only the two non-hostile pairs should be stored, got %d entries", len(loaded.stances))
+	}
+
+}
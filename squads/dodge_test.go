@@ -0,0 +1,107 @@
+package squads
+
+import (
+	"game_main/common"
+	"testing"
+)
+
+// Replaces the dodge roll with a scripted sequence and returns a restore func
+func scriptDodgeRolls(rolls []int) func() {
+
+	original := dodgeRoll
+	i := 0
+	dodgeRoll = func() int {
+		roll := rolls[i%len(rolls)]
+		i++
+		return roll
+	}
+
+	return func() { dodgeRoll = original }
+}
+
+func TestDodgedAttackRecordsPotentialDamage(t *testing.T) {
+
+	defer scriptDodgeRolls([]int{1})() //always under any nonzero dodge chance
+
+	em := newTestManager()
+	registerTestTemplates()
+
+	Units["Dancer"] = UnitTemplate{
+		Name:      "Dancer",
+		Attr:      common.NewBaseAttributes(10, 0, 10, 2, 1, 0.4),
+		MinDamage: 1,
+		MaxDamage: 1,
+	}
+
+	defSquad := CreateSquad(em, "Defenders", "Enemy", common.Position{X: 5, Y: 5}, DirUp)
+	dancer := CreateUnitFromTemplate(em, Units["Dancer"], defSquad.SquadID, 0, 0)
+
+	attSquad := CreateSquad(em, "Attackers", "Player", common.Position{X: 5, Y: 3}, DirDown)
+	rogue := CreateUnitFromTemplate(em, Units["TestRogue"], attSquad.SquadID, 0, 0)
+
+	before := common.GetAttributes(dancer).CurrentHealth
+	event := ResolveUnitAttack(em, rogue.GetID(), dancer.GetID(), DirDown)
+
+	if !event.Dodged {
+		t.Fatal("expected the scripted roll to dodge")
+	}
+	if event.Damage != 0 {
+		t.Errorf("a dodged attack must deal nothing, got %d", event.Damage)
+	}
+	//5 base - 2 protection, what the hit would have dealt
+	if event.PotentialDamage != 3 {
+		t.Errorf("expected 3 potential damage on the dodge event, got %d", event.PotentialDamage)
+	}
+	if common.GetAttributes(dancer).CurrentHealth != before {
+		t.Error("a dodged attack must not damage the defender")
+	}
+	if GetUnitEffects(dancer).BleedStacks != 0 {
+		t.Error("a dodged attack must not stack bleed")
+	}
+}
+
+// Ten scripted rolls against a 40% dodge unit: exactly the four rolls at or
+// below 40 dodge, and the avoided damage total matches the sum of their
+// potential damage
+func TestAvoidedDamageMatchesScriptedRolls(t *testing.T) {
+
+	rolls := []int{10, 90, 30, 50, 40, 99, 41, 1, 70, 35}
+	defer scriptDodgeRolls(rolls)()
+
+	em := newTestManager()
+	registerTestTemplates()
+
+	Units["Dancer"] = UnitTemplate{
+		Name:      "Dancer",
+		Attr:      common.NewBaseAttributes(1000, 0, 10, 2, 1, 0.4),
+		MinDamage: 1,
+		MaxDamage: 1,
+	}
+
+	defSquad := CreateSquad(em, "Defenders", "Enemy", common.Position{X: 5, Y: 5}, DirUp)
+	dancer := CreateUnitFromTemplate(em, Units["Dancer"], defSquad.SquadID, 0, 0)
+
+	attSquad := CreateSquad(em, "Attackers", "Player", common.Position{X: 5, Y: 3}, DirDown)
+	rogue := CreateUnitFromTemplate(em, Units["TestRogue"], attSquad.SquadID, 0, 0)
+
+	avoided := 0
+	dodges := 0
+	for range rolls {
+
+		event := ResolveUnitAttack(em, rogue.GetID(), dancer.GetID(), DirDown)
+		if event.Dodged {
+			dodges++
+			avoided += event.PotentialDamage
+		}
+
+	}
+
+	if dodges != 5 {
+		t.Fatalf("rolls of 10, 30, 40, 1 and 35 should dodge at 40%%, got %d dodges", dodges)
+	}
+
+	//Every hit would have dealt 5 - 2 = 3 damage
+	if avoided != 5*3 {
+		t.Errorf("expected 15 total damage avoided, got %d", avoided)
+	}
+}
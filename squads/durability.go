@@ -0,0 +1,170 @@
+package squads
+
+import (
+	"fmt"
+	"game_main/common"
+
+	"github.com/bytearena/ecs"
+)
+
+// Armor durability: sustained combat wears a units armor down until its
+// protection degrades, creating attrition pressure beyond lost health. Each
+// unit has a durability pool derived from its base protection; every hit
+// taken chips it, and past the wear thresholds the protection contribution
+// drops by 25% and then 50% until repaired. Off by default so balance data
+// stays comparable - the campaign turns it on, the simulator can leave it off
+
+type DurabilityConfig struct {
+	Enabled bool
+
+	//Durability chipped off per hit taken
+	WearPerHit int
+
+	//Pool size per point of base protection
+	PoolPerProtection int
+}
+
+var Durability = DurabilityConfig{WearPerHit: 1, PoolPerProtection: 10}
+
+// The units full durability pool. Units without armor have nothing to wear
+func armorPool(attr *common.Attributes) int {
+	return attr.BaseProtection * Durability.PoolPerProtection
+}
+
+// ArmorPenaltyPercent is how much of the units protection its worn armor no
+// longer provides: 0, 25 below half durability, 50 below a quarter
+func ArmorPenaltyPercent(unit *ecs.Entity) int {
+
+	if !Durability.Enabled {
+		return 0
+	}
+
+	pool := armorPool(common.GetAttributes(unit))
+	if pool == 0 {
+		return 0
+	}
+
+	remaining := pool - GetUnitEffects(unit).ArmorWear
+
+	switch {
+	case remaining*4 <= pool:
+		return 50
+	case remaining*2 <= pool:
+		return 25
+	}
+
+	return 0
+}
+
+// effectiveProtection is the defenders protection after armor wear
+func effectiveProtection(unit *ecs.Entity, attr *common.Attributes) int {
+
+	protection := attr.TotalProtection
+	if penalty := ArmorPenaltyPercent(unit); penalty > 0 {
+		//Round the loss up so the penalty bites even on low protection values
+		protection -= (protection*penalty + 99) / 100
+	}
+
+	return protection
+}
+
+// WearArmor chips the units armor after a hit, capped at the pool
+func WearArmor(unit *ecs.Entity) {
+
+	if !Durability.Enabled {
+		return
+	}
+
+	pool := armorPool(common.GetAttributes(unit))
+	if pool == 0 {
+		return
+	}
+
+	effects := GetUnitEffects(unit)
+	effects.ArmorWear += Durability.WearPerHit
+	if effects.ArmorWear > pool {
+		effects.ArmorWear = pool
+	}
+
+}
+
+// RepairArmor restores the given amount of durability, or everything when
+// the amount is zero or negative. Settlements repair fully, an engineers
+// field repair passes a partial amount
+func RepairArmor(unit *ecs.Entity, amount int) {
+
+	effects := GetUnitEffects(unit)
+
+	if amount <= 0 {
+		effects.ArmorWear = 0
+		return
+	}
+
+	effects.ArmorWear -= amount
+	if effects.ArmorWear < 0 {
+		effects.ArmorWear = 0
+	}
+
+}
+
+// RepairSquadArmor fully repairs every living unit in the squad
+func RepairSquadArmor(em *common.EntityManager, squadID int) {
+
+	for _, u := range SquadUnits(em, squadID) {
+		RepairArmor(u, 0)
+	}
+
+}
+
+// DamagedArmorCount is how many living units in the squad fight with a
+// degraded armor penalty, for the post battle summary and squad tooltips
+func DamagedArmorCount(em *common.EntityManager, squadID int) int {
+
+	count := 0
+	for _, u := range SquadUnits(em, squadID) {
+
+		if common.GetAttributes(u).CurrentHealth <= 0 {
+			continue
+		}
+		if ArmorPenaltyPercent(u) > 0 {
+			count++
+		}
+
+	}
+
+	return count
+}
+
+// ArmorConditionText is the tooltip line for a units armor state, empty
+// while the armor still holds
+func ArmorConditionText(unit *ecs.Entity) string {
+
+	switch ArmorPenaltyPercent(unit) {
+	case 25:
+		return "armor worn (-25% protection)"
+	case 50:
+		return "armor battered (-50% protection)"
+	}
+
+	return ""
+}
+
+// The summary line for lasting effects, empty when nothing is damaged
+func damagedArmorEffect(em *common.EntityManager, squadID int) string {
+
+	count := DamagedArmorCount(em, squadID)
+	if count == 0 {
+		return ""
+	}
+
+	squad := GetSquadByID(em, squadID)
+	if squad == nil {
+		return ""
+	}
+
+	if count == 1 {
+		return fmt.Sprintf("%s: 1 unit has damaged armor", squad.Name)
+	}
+
+	return fmt.Sprintf("%s: %d units have damaged armor", squad.Name, count)
+}
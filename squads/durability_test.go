@@ -0,0 +1,121 @@
+package squads
+
+import (
+	"game_main/common"
+	"testing"
+)
+
+type durabilityFixture struct {
+	em         *common.EntityManager
+	defSquadID int
+	effects    *UnitEffects
+	swing      func() AttackEvent
+}
+
+func newDurabilityFixture(t *testing.T) durabilityFixture {
+
+	t.Helper()
+
+	em := newTestManager()
+	registerTestTemplates()
+
+	att := CreateSquad(em, "Attackers", "Player", common.Position{X: 5, Y: 5}, DirDown)
+	attacker := CreateUnitFromTemplate(em, Units["TestKnight"], att.SquadID, 0, 0)
+
+	def := CreateSquad(em, "Defenders", "Enemy", common.Position{X: 5, Y: 6}, DirUp)
+	defender := CreateUnitFromTemplate(em, Units["TestKnight"], def.SquadID, 0, 0)
+
+	return durabilityFixture{
+		em:         em,
+		defSquadID: def.SquadID,
+		effects:    GetUnitEffects(defender),
+		swing: func() AttackEvent {
+			return calculateUnitDamageByID(em, attacker.GetID(), defender.GetID(), DirDown)
+		},
+	}
+}
+
+// Knight on knight is 4 damage minus 2 protection. Ten hits of wear cross
+// the half-durability threshold, the 25% penalty shaves a point of
+// protection, and the next battles attacks land for 3
+func TestWornArmorProtectsLess(t *testing.T) {
+
+	Durability.Enabled = true
+	defer func() { Durability.Enabled = false }()
+
+	f := newDurabilityFixture(t)
+
+	if damage := f.swing().Damage; damage != 2 {
+		t.Fatalf("expected 2 damage against fresh armor, got %d", damage)
+	}
+
+	//Pool is BaseProtection 2 x 10 = 20; ten hits reach the halfway mark
+	for i := 0; i < 10; i++ {
+		f.effects.ArmorWear += Durability.WearPerHit
+	}
+
+	if damage := f.swing().Damage; damage != 3 {
+		t.Errorf("expected 3 damage through worn armor, got %d", damage)
+	}
+}
+
+// Repairing restores the protection, fully at a settlement or partially in
+// the field
+func TestRepairRestoresProtection(t *testing.T) {
+
+	Durability.Enabled = true
+	defer func() { Durability.Enabled = false }()
+
+	f := newDurabilityFixture(t)
+
+	f.effects.ArmorWear = 12
+	if damage := f.swing().Damage; damage != 3 {
+		t.Fatalf("expected 3 damage through worn armor, got %d", damage)
+	}
+
+	//A partial field repair off the threshold is enough
+	RepairArmor(SquadUnits(f.em, f.defSquadID)[0], 4)
+	if f.effects.ArmorWear != 8 {
+		t.Errorf("expected 8 wear after a partial repair, got %d", f.effects.ArmorWear)
+	}
+	if damage := f.swing().Damage; damage != 2 {
+		t.Errorf("expected 2 damage after the partial repair, got %d", damage)
+	}
+
+	f.effects.ArmorWear = 12
+	RepairSquadArmor(f.em, f.defSquadID)
+	if f.effects.ArmorWear != 0 {
+		t.Errorf("expected a full settlement repair to clear the wear, got %d", f.effects.ArmorWear)
+	}
+}
+
+// With the flag off, wear has no effect on the numbers at all
+func TestDisabledDurabilityKeepsPreFeatureDamage(t *testing.T) {
+
+	f := newDurabilityFixture(t)
+
+	f.effects.ArmorWear = 18
+	if damage := f.swing().Damage; damage != 2 {
+		t.Errorf("expected the pre-feature 2 damage with durability disabled, got %d", damage)
+	}
+}
+
+// Wear rides through a save and load
+func TestArmorWearSurvivesSerialization(t *testing.T) {
+
+	f := newDurabilityFixture(t)
+	f.effects.ArmorWear = 7
+
+	saved := SerializeSquads(f.em)
+
+	fresh := newTestManager()
+	if err := DeserializeSquads(fresh, saved); err != nil {
+		t.Fatalf("loading the save failed: %v", err)
+	}
+
+	for _, u := range SquadUnits(fresh, f.defSquadID) {
+		if wear := GetUnitEffects(u).ArmorWear; wear != 7 {
+			t.Errorf("expected the defenders wear of 7 to survive the save, got %d", wear)
+		}
+	}
+}
@@ -0,0 +1,123 @@
+package squads
+
+import (
+	"game_main/common"
+
+	"github.com/bytearena/ecs"
+)
+
+// Combat effects that persist between attacks. Bleed stacks up with every hit
+// a unit takes and deals its damage at round start, which rewards multi-hit
+// attackers over single big swings
+
+type BleedConfig struct {
+	DamagePerStack int
+	MaxStacks      int
+}
+
+var Bleed = BleedConfig{DamagePerStack: 1, MaxStacks: 5}
+
+// Enrage: every ally death hands the survivors a stacking damage bonus for
+// the rest of the battle. DamagePerDeath is the flat bonus per stack and the
+// stacks cap out so a massacre does not snowball forever
+type EnrageConfig struct {
+	DamagePerDeath int
+	MaxStacks      int
+}
+
+var Enrage = EnrageConfig{DamagePerDeath: 2, MaxStacks: 3}
+
+var UnitEffectsComponent *ecs.Component
+
+type UnitEffects struct {
+	BleedStacks  int `json:"bleedStacks"`
+	EnrageStacks int `json:"enrageStacks"`
+
+	//Accumulated armor durability damage. Unlike the battle-scoped stacks
+	//above this persists across battles until repaired
+	ArmorWear int `json:"armorWear,omitempty"`
+
+	//Below the wound threshold and fighting at a penalty. Maintained by
+	//RefreshWoundedState, read through IsWounded
+	Wounded bool `json:"wounded,omitempty"`
+}
+
+// GetUnitEffects returns the units effect state, attaching an empty one on
+// first use so callers never nil check
+func GetUnitEffects(unit *ecs.Entity) *UnitEffects {
+
+	if effects := common.GetComponentType[*UnitEffects](unit, UnitEffectsComponent); effects != nil {
+		return effects
+	}
+
+	effects := &UnitEffects{}
+	unit.AddComponent(UnitEffectsComponent, effects)
+	return effects
+}
+
+// AddBleedStack is called once per hit taken. Stacks cap out at the
+// configured max
+func AddBleedStack(unit *ecs.Entity) {
+
+	effects := GetUnitEffects(unit)
+	if effects.BleedStacks < Bleed.MaxStacks {
+		effects.BleedStacks++
+	}
+
+}
+
+// EnrageSquad grants every living unit in the squad one enrage stack, up to
+// the cap. The kill path calls it once per ally death
+func EnrageSquad(em *common.EntityManager, squadID int) {
+
+	for _, u := range SquadUnits(em, squadID) {
+
+		if common.GetAttributes(u).CurrentHealth <= 0 {
+			continue
+		}
+
+		effects := GetUnitEffects(u)
+		if effects.EnrageStacks < Enrage.MaxStacks {
+			effects.EnrageStacks++
+		}
+
+	}
+
+}
+
+// EnrageBonus is the flat extra damage a units enrage stacks add
+func EnrageBonus(unit *ecs.Entity) int {
+	return GetUnitEffects(unit).EnrageStacks * Enrage.DamagePerDeath
+}
+
+// TickEffects runs the round-start effect damage for one squad and returns
+// the damage events so the battle record can log them. Each bleed stack deals
+// its configured damage
+func TickEffects(em *common.EntityManager, squadID int) []AttackEvent {
+
+	//Buff durations and support cooldowns share the round-start lifetime tick
+	tickSupportLifetimes(em, squadID)
+
+	events := []AttackEvent{}
+	for _, u := range SquadUnits(em, squadID) {
+
+		attr := common.GetAttributes(u)
+		if attr.CurrentHealth <= 0 {
+			continue
+		}
+
+		effects := GetUnitEffects(u)
+		if effects.BleedStacks == 0 {
+			continue
+		}
+
+		damage := effects.BleedStacks * Bleed.DamagePerStack
+		attr.CurrentHealth -= damage
+		RefreshWoundedState(u)
+
+		events = append(events, AttackEvent{DefenderID: u.GetID(), Damage: damage})
+
+	}
+
+	return events
+}
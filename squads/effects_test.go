@@ -0,0 +1,121 @@
+package squads
+
+import (
+	"game_main/common"
+	"testing"
+)
+
+func TestHitsStackBleed(t *testing.T) {
+
+	em := newTestManager()
+	registerTestTemplates()
+
+	defSquad := CreateSquad(em, "Defenders", "Enemy", common.Position{X: 5, Y: 5}, DirUp)
+	target := CreateUnitFromTemplate(em, Units["TestKnight"], defSquad.SquadID, 0, 0)
+
+	attSquad := CreateSquad(em, "Attackers", "Player", common.Position{X: 5, Y: 3}, DirDown)
+	attacker := CreateUnitFromTemplate(em, Units["TestRogue"], attSquad.SquadID, 0, 0)
+
+	for i := 0; i < 3; i++ {
+		ResolveUnitAttack(em, attacker.GetID(), target.GetID(), DirDown)
+	}
+
+	if GetUnitEffects(target).BleedStacks != 3 {
+		t.Errorf("expected 3 bleed stacks after 3 hits, got %d", GetUnitEffects(target).BleedStacks)
+	}
+}
+
+func TestTickDealsSummedBleedDamage(t *testing.T) {
+
+	em := newTestManager()
+	registerTestTemplates()
+
+	squad := CreateSquad(em, "Bleeders", "Enemy", common.Position{X: 5, Y: 5}, DirUp)
+	unit := CreateUnitFromTemplate(em, Units["TestKnight"], squad.SquadID, 0, 0)
+
+	GetUnitEffects(unit).BleedStacks = 3
+	before := common.GetAttributes(unit).CurrentHealth
+
+	events := TickEffects(em, squad.SquadID)
+
+	expected := 3 * Bleed.DamagePerStack
+	if len(events) != 1 || events[0].Damage != expected {
+		t.Fatalf("expected one event with %d damage, got %v", expected, events)
+	}
+	if common.GetAttributes(unit).CurrentHealth != before-expected {
+		t.Errorf("expected the tick to apply %d damage", expected)
+	}
+}
+
+func TestBleedStacksAreCapped(t *testing.T) {
+
+	em := newTestManager()
+	registerTestTemplates()
+
+	squad := CreateSquad(em, "Pincushions", "Enemy", common.Position{X: 5, Y: 5}, DirUp)
+	unit := CreateUnitFromTemplate(em, Units["TestKnight"], squad.SquadID, 0, 0)
+
+	for i := 0; i < Bleed.MaxStacks+4; i++ {
+		AddBleedStack(unit)
+	}
+
+	if GetUnitEffects(unit).BleedStacks != Bleed.MaxStacks {
+		t.Errorf("expected stacks to cap at %d, got %d", Bleed.MaxStacks, GetUnitEffects(unit).BleedStacks)
+	}
+}
+
+// Two ally deaths should leave the survivors hitting harder than at battle
+// start, one stack per death
+func TestAllyDeathsEnrageTheSurvivors(t *testing.T) {
+
+	em := newTestManager()
+	registerTestTemplates()
+
+	originalRoll := dodgeRoll
+	dodgeRoll = func() int { return 100 }
+	defer func() { dodgeRoll = originalRoll }()
+
+	defSquad := CreateSquad(em, "Mourners", "Enemy", common.Position{X: 5, Y: 5}, DirUp)
+	survivor := CreateUnitFromTemplate(em, Units["TestKnight"], defSquad.SquadID, 1, 0)
+	first := CreateUnitFromTemplate(em, Units["TestKnight"], defSquad.SquadID, 0, 0)
+	second := CreateUnitFromTemplate(em, Units["TestKnight"], defSquad.SquadID, 0, 1)
+	common.GetAttributes(first).CurrentHealth = 1
+	common.GetAttributes(second).CurrentHealth = 1
+
+	attSquad := CreateSquad(em, "Attackers", "Player", common.Position{X: 5, Y: 3}, DirDown)
+	attacker := CreateUnitFromTemplate(em, Units["TestRogue"], attSquad.SquadID, 0, 0)
+
+	target := CreateUnitFromTemplate(em, Units["TestRogue"], attSquad.SquadID, 0, 1)
+	baseline := calculateUnitDamageByID(em, survivor.GetID(), target.GetID(), DirUp).Damage
+
+	ResolveUnitAttack(em, attacker.GetID(), first.GetID(), DirDown)
+	ResolveUnitAttack(em, attacker.GetID(), second.GetID(), DirDown)
+
+	if got := GetUnitEffects(survivor).EnrageStacks; got != 2 {
+		t.Fatalf("expected 2 enrage stacks after 2 ally deaths, got %d", got)
+	}
+
+	enraged := calculateUnitDamageByID(em, survivor.GetID(), target.GetID(), DirUp).Damage
+	if enraged != baseline+2*Enrage.DamagePerDeath {
+		t.Errorf("expected %d damage while enraged, got %d (baseline %d)",
+			baseline+2*Enrage.DamagePerDeath, enraged, baseline)
+	}
+}
+
+// The stacks stop at the cap no matter how many allies fall
+func TestEnrageRespectsTheStackingCap(t *testing.T) {
+
+	em := newTestManager()
+	registerTestTemplates()
+
+	squad := CreateSquad(em, "Berserks", "Enemy", common.Position{X: 5, Y: 5}, DirUp)
+	unit := CreateUnitFromTemplate(em, Units["TestKnight"], squad.SquadID, 0, 0)
+
+	for i := 0; i < Enrage.MaxStacks+4; i++ {
+		EnrageSquad(em, squad.SquadID)
+	}
+
+	if got := GetUnitEffects(unit).EnrageStacks; got != Enrage.MaxStacks {
+		t.Errorf("expected the stacks to cap at %d, got %d", Enrage.MaxStacks, got)
+	}
+}
@@ -0,0 +1,85 @@
+package squads
+
+import (
+	"fmt"
+	"game_main/common"
+	"sort"
+)
+
+// Attack eligibility with a reason. The targeting GUI, the AI, and
+// auto-resolve all gate on the same check, so "why can't I attack that"
+// always has one answer
+
+type EligibilityCode int
+
+const (
+	EligibilityOK EligibilityCode = iota
+
+	//The target squad does not exist or has nothing left alive
+	EligibilityNoTarget
+
+	//The target belongs to the attackers own faction
+	EligibilityAllied
+
+	//The target is further away than the attack range
+	EligibilityOutOfRange
+
+	//No unit in the attacking squad can participate this round
+	EligibilityNoAttackers
+
+	//The target belongs to a faction the attackers faction is not at war with
+	EligibilityNeutral
+)
+
+// CheckAttackEligibility decides whether the attacker can attack the target
+// right now. The string is a concrete reason for the GUI to show, empty when
+// the attack is allowed
+func CheckAttackEligibility(em *common.EntityManager, attackerSquadID int, targetSquadID int, attackRange int) (EligibilityCode, string) {
+
+	attacker := GetSquadByID(em, attackerSquadID)
+	target := GetSquadByID(em, targetSquadID)
+
+	if target == nil || !squadHasLivingUnits(em, targetSquadID) {
+		return EligibilityNoTarget, "no squad left to attack there"
+	}
+
+	if attacker != nil {
+
+		switch ActiveDiplomacy.StanceBetween(attacker.Faction, target.Faction) {
+		case StanceAllied:
+			return EligibilityAllied, fmt.Sprintf("%s is an allied squad", target.Name)
+		case StanceNeutral:
+			return EligibilityNeutral, fmt.Sprintf("%s belongs to a neutral faction", target.Name)
+		}
+
+	}
+
+	if distance := GetSquadDistance(em, attackerSquadID, targetSquadID); distance > attackRange {
+		return EligibilityOutOfRange, fmt.Sprintf("out of range: %s is %d tiles away, range is %d", target.Name, distance, attackRange)
+	}
+
+	if len(PreviewSquadAttack(em, attackerSquadID)) == 0 {
+		return EligibilityNoAttackers, "no unit can attack this round"
+	}
+
+	return EligibilityOK, ""
+}
+
+// TargetCycleOrder is the order Tab walks the candidates in: closest first,
+// squad ID breaking ties, so the cycle is stable frame to frame
+func TargetCycleOrder(em *common.EntityManager, attackerSquadID int, candidateIDs []int) []int {
+
+	ordered := append([]int{}, candidateIDs...)
+	sort.SliceStable(ordered, func(i, j int) bool {
+
+		di := GetSquadDistance(em, attackerSquadID, ordered[i])
+		dj := GetSquadDistance(em, attackerSquadID, ordered[j])
+		if di != dj {
+			return di < dj
+		}
+		return ordered[i] < ordered[j]
+
+	})
+
+	return ordered
+}
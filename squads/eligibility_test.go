@@ -0,0 +1,109 @@
+package squads
+
+import (
+	"game_main/common"
+	"strings"
+	"testing"
+)
+
+// One failure case per eligibility code, plus the happy path
+func TestEligibilityReasons(t *testing.T) {
+
+	em := newTestManager()
+	registerTestTemplates()
+
+	att := CreateSquad(em, "Attackers", "Player", common.Position{X: 5, Y: 5}, DirDown)
+	CreateUnitFromTemplate(em, Units["TestKnight"], att.SquadID, 0, 0)
+
+	near := CreateSquad(em, "Near", "Enemy", common.Position{X: 5, Y: 6}, DirUp)
+	CreateUnitFromTemplate(em, Units["TestKnight"], near.SquadID, 0, 0)
+
+	far := CreateSquad(em, "Far", "Enemy", common.Position{X: 5, Y: 9}, DirUp)
+	CreateUnitFromTemplate(em, Units["TestKnight"], far.SquadID, 0, 0)
+
+	ally := CreateSquad(em, "Friends", "Player", common.Position{X: 4, Y: 5}, DirDown)
+	CreateUnitFromTemplate(em, Units["TestKnight"], ally.SquadID, 0, 0)
+
+	wiped := CreateSquad(em, "Ghosts", "Enemy", common.Position{X: 6, Y: 5}, DirUp)
+	ghost := CreateUnitFromTemplate(em, Units["TestKnight"], wiped.SquadID, 0, 0)
+	common.GetAttributes(ghost).CurrentHealth = 0
+
+	cases := []struct {
+		name     string
+		targetID int
+		code     EligibilityCode
+		reason   string
+	}{
+		{"valid", near.SquadID, EligibilityOK, ""},
+		{"out of range", far.SquadID, EligibilityOutOfRange, "out of range"},
+		{"allied", ally.SquadID, EligibilityAllied, "allied"},
+		{"wiped out", wiped.SquadID, EligibilityNoTarget, "no squad left"},
+		{"missing", 999, EligibilityNoTarget, "no squad left"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+
+			code, reason := CheckAttackEligibility(em, att.SquadID, tc.targetID, 1)
+			if code != tc.code {
+				t.Errorf("expected code %d, got %d (%s)", tc.code, code, reason)
+			}
+			if !strings.Contains(reason, tc.reason) {
+				t.Errorf("expected the reason to mention %q, got %q", tc.reason, reason)
+			}
+
+		})
+	}
+}
+
+// A squad with nobody left standing cannot start an attack either
+func TestEligibilityNeedsLivingAttackers(t *testing.T) {
+
+	em := newTestManager()
+	registerTestTemplates()
+
+	att := CreateSquad(em, "Spent", "Player", common.Position{X: 5, Y: 5}, DirDown)
+	down := CreateUnitFromTemplate(em, Units["TestKnight"], att.SquadID, 0, 0)
+	common.GetAttributes(down).CurrentHealth = 0
+
+	def := CreateSquad(em, "Enemy", "Enemy", common.Position{X: 5, Y: 6}, DirUp)
+	CreateUnitFromTemplate(em, Units["TestKnight"], def.SquadID, 0, 0)
+
+	code, reason := CheckAttackEligibility(em, att.SquadID, def.SquadID, 1)
+	if code != EligibilityNoAttackers {
+		t.Errorf("expected EligibilityNoAttackers, got %d (%s)", code, reason)
+	}
+}
+
+// Tab order is closest first with squad ID breaking ties, and it does not
+// reshuffle between calls
+func TestTargetCycleOrderIsStableAndDistanceSorted(t *testing.T) {
+
+	em := newTestManager()
+	registerTestTemplates()
+
+	att := CreateSquad(em, "Attackers", "Player", common.Position{X: 5, Y: 5}, DirDown)
+	CreateUnitFromTemplate(em, Units["TestKnight"], att.SquadID, 0, 0)
+
+	far := CreateSquad(em, "Far", "Enemy", common.Position{X: 5, Y: 9}, DirUp)
+	close1 := CreateSquad(em, "Close A", "Enemy", common.Position{X: 5, Y: 6}, DirUp)
+	close2 := CreateSquad(em, "Close B", "Enemy", common.Position{X: 6, Y: 6}, DirUp)
+
+	candidates := []int{far.SquadID, close2.SquadID, close1.SquadID}
+
+	first := TargetCycleOrder(em, att.SquadID, candidates)
+	want := []int{close1.SquadID, close2.SquadID, far.SquadID}
+
+	for i := range want {
+		if first[i] != want[i] {
+			t.Fatalf("expected order %v, got %v", want, first)
+		}
+	}
+
+	second := TargetCycleOrder(em, att.SquadID, candidates)
+	for i := range first {
+		if first[i] != second[i] {
+			t.Error("the cycle order must be stable between calls")
+		}
+	}
+}
@@ -0,0 +1,92 @@
+package squads
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/bytearena/ecs"
+)
+
+// ExplainOutcome turns a battle record into a short narrative for the battle
+// log and post-battle flavor text: who won, the hardest blow, first blood,
+// and the round the fight was actually decided. Everything is derived from
+// the records events and snapshots, so it works on reloaded logs too
+func ExplainOutcome(record *BattleRecord) string {
+
+	winner, loser := record.Attacker, record.Defender
+	if record.WinnerSquadID == record.Defender.SquadID {
+		winner, loser = record.Defender, record.Attacker
+	}
+
+	if record.WinnerSquadID == 0 {
+		return fmt.Sprintf("Neither %s nor %s could finish the job after %d rounds.", record.Attacker.Name, record.Defender.Name, record.Rounds)
+	}
+
+	templates := make(map[ecs.EntityID]string)
+	sides := make(map[ecs.EntityID]string)
+	for _, side := range []SquadSnapshot{record.Attacker, record.Defender} {
+		for _, u := range side.Units {
+			templates[u.EntityID] = u.TemplateName
+			sides[u.EntityID] = side.Name
+		}
+	}
+
+	sentences := []string{fmt.Sprintf("%s defeated %s in %d rounds.", winner.Name, loser.Name, record.Rounds)}
+
+	var biggest AttackEvent
+	for _, e := range record.Events {
+		if e.Damage > biggest.Damage {
+			biggest = e
+		}
+	}
+	if biggest.Damage > 0 {
+		sentences = append(sentences, fmt.Sprintf("The hardest blow came in round %d, when %s's %s hit %s's %s for %d.",
+			biggest.Round, sides[biggest.AttackerID], templates[biggest.AttackerID],
+			sides[biggest.DefenderID], templates[biggest.DefenderID], biggest.Damage))
+	}
+
+	if kill, ok := firstKill(record); ok {
+		sentences = append(sentences, fmt.Sprintf("First blood went to %s in round %d, cutting down %s's %s.",
+			sides[kill.AttackerID], kill.Round, sides[kill.DefenderID], templates[kill.DefenderID]))
+	}
+
+	defeat := AnalyzeSquadDefeat(record, loser.Name)
+	switch {
+	case defeat.Wiped && defeat.LossRound > 0:
+		sentences = append(sentences, fmt.Sprintf("The fight was decided in round %d, when the last of %s fell.", defeat.LossRound, loser.Name))
+	case record.ObjectiveVictor != "":
+		sentences = append(sentences, fmt.Sprintf("%s took the field on objectives.", record.ObjectiveVictor))
+	}
+
+	return strings.Join(sentences, " ")
+}
+
+// firstKill replays the events against the starting health pools and returns
+// the event that scored the battles first death
+func firstKill(record *BattleRecord) (AttackEvent, bool) {
+
+	health := make(map[ecs.EntityID]int)
+	for _, side := range []SquadSnapshot{record.Attacker, record.Defender} {
+		for _, u := range side.Units {
+			health[u.EntityID] = u.MaxHealth
+		}
+	}
+
+	for _, e := range record.Events {
+
+		hp, known := health[e.DefenderID]
+		if !known || hp <= 0 || e.Damage == 0 {
+			continue
+		}
+
+		hp -= e.Damage
+		health[e.DefenderID] = hp
+
+		if hp <= 0 {
+			return e, true
+		}
+
+	}
+
+	return AttackEvent{}, false
+}
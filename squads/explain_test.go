@@ -0,0 +1,71 @@
+package squads
+
+import (
+	"strings"
+	"testing"
+)
+
+// A hand-built record: Alpha's knight lands the big hit in round 2 and
+// finishes Bravo's last archer in round 3
+func explainFixture() *BattleRecord {
+
+	record := NewBattleRecord(1, 2)
+	record.WinnerSquadID = 1
+	record.Rounds = 3
+
+	record.Attacker = SquadSnapshot{
+		SquadID: 1,
+		Name:    "Alpha",
+		Units: []UnitSnapshot{
+			{EntityID: 10, TemplateName: "Knight", MaxHealth: 20},
+		},
+	}
+	record.Defender = SquadSnapshot{
+		SquadID: 2,
+		Name:    "Bravo",
+		Units: []UnitSnapshot{
+			{EntityID: 20, TemplateName: "Archer", MaxHealth: 12},
+			{EntityID: 21, TemplateName: "Archer", MaxHealth: 12},
+		},
+	}
+
+	record.RecordEvent(AttackEvent{Round: 1, AttackerID: 10, DefenderID: 20, Damage: 6})
+	record.RecordEvent(AttackEvent{Round: 2, AttackerID: 10, DefenderID: 20, Damage: 9})
+	record.RecordEvent(AttackEvent{Round: 3, AttackerID: 10, DefenderID: 21, Damage: 8})
+	record.RecordEvent(AttackEvent{Round: 3, AttackerID: 10, DefenderID: 21, Damage: 7})
+
+	record.RecordUnitLost(2, "Archer")
+	record.RecordUnitLost(2, "Archer")
+
+	return record
+}
+
+func TestExplainOutcomeNamesTheDecisiveRound(t *testing.T) {
+
+	summary := ExplainOutcome(explainFixture())
+
+	for _, want := range []string{
+		"Alpha defeated Bravo in 3 rounds.",
+		"hardest blow came in round 2",
+		"for 9",
+		"First blood went to Alpha in round 2",
+		"decided in round 3",
+	} {
+		if !strings.Contains(summary, want) {
+			t.Errorf("the summary should mention %q, got:\n%s", want, summary)
+		}
+	}
+
+}
+
+func TestExplainOutcomeStalemate(t *testing.T) {
+
+	record := explainFixture()
+	record.WinnerSquadID = 0
+
+	summary := ExplainOutcome(record)
+	if !strings.Contains(summary, "Neither Alpha nor Bravo") {
+		t.Errorf("a drawn battle should read as a stalemate, got:\n%s", summary)
+	}
+
+}
@@ -0,0 +1,45 @@
+package squads
+
+// Optional combat fatigue. Once a battle drags past the round threshold the
+// units start losing a small percentage of their damage every further round,
+// modeling exhaustion and preventing infinite stalemates between tanky squads
+
+type FatigueConfig struct {
+	Enabled        bool
+	RoundThreshold int
+
+	//Fraction of damage lost per round past the threshold, e.g. 0.02 for 2%
+	PenaltyPerRound float64
+}
+
+// Fatigue never reduces damage below this fraction of the original
+const minFatigueMultiplier = 0.25
+
+var Fatigue = FatigueConfig{
+	Enabled:         false,
+	RoundThreshold:  10,
+	PenaltyPerRound: 0.02,
+}
+
+// AdvanceSquadRound bumps the squads round counter and hands the squad a
+// fresh action. The battle runner calls this once per squad per combat round
+func AdvanceSquadRound(squad *SquadData) {
+	squad.CurrentRound++
+	squad.ActionSpent = false
+}
+
+// fatigueMultiplier is what the damage gets scaled by for a squad at the given
+// round. Returns 1.0 when fatigue is off or the battle is still young
+func fatigueMultiplier(round int) float64 {
+
+	if !Fatigue.Enabled || round <= Fatigue.RoundThreshold {
+		return 1.0
+	}
+
+	multiplier := 1.0 - float64(round-Fatigue.RoundThreshold)*Fatigue.PenaltyPerRound
+	if multiplier < minFatigueMultiplier {
+		multiplier = minFatigueMultiplier
+	}
+
+	return multiplier
+}
@@ -0,0 +1,82 @@
+package squads
+
+import (
+	"game_main/common"
+	"testing"
+
+	"github.com/bytearena/ecs"
+)
+
+func fatigueFixture(em *common.EntityManager) (attacker *SquadData, attackerUnit, defenderUnit ecs.EntityID) {
+
+	//Deterministic damage so only the fatigue multiplier can change the result
+	Units["FatigueBrute"] = UnitTemplate{
+		Name:      "FatigueBrute",
+		Class:     "Brute",
+		Attr:      common.NewBaseAttributes(100, 0, 10, 0, 1, 0),
+		MinDamage: 10,
+		MaxDamage: 10,
+	}
+
+	attSquad := CreateSquad(em, "Attackers", "Player", common.Position{X: 1, Y: 1}, DirDown)
+	defSquad := CreateSquad(em, "Defenders", "Enemy", common.Position{X: 1, Y: 3}, DirUp)
+
+	att := CreateUnitFromTemplate(em, Units["FatigueBrute"], attSquad.SquadID, 0, 0)
+	def := CreateUnitFromTemplate(em, Units["FatigueBrute"], defSquad.SquadID, 0, 0)
+
+	return attSquad, att.GetID(), def.GetID()
+}
+
+func TestFatigueReducesDamageInLongBattles(t *testing.T) {
+
+	em := newTestManager()
+	registerTestTemplates()
+
+	attacker, att, def := fatigueFixture(em)
+
+	Fatigue = FatigueConfig{Enabled: true, RoundThreshold: 5, PenaltyPerRound: 0.02}
+	defer func() { Fatigue = FatigueConfig{Enabled: false, RoundThreshold: 10, PenaltyPerRound: 0.02} }()
+
+	attacker.CurrentRound = 1
+	early := calculateUnitDamageByID(em, att, def, DirDown)
+
+	attacker.CurrentRound = 20
+	late := calculateUnitDamageByID(em, att, def, DirDown)
+
+	if late.Damage >= early.Damage {
+		t.Errorf("expected round 20 damage (%d) to be lower than round 1 damage (%d)", late.Damage, early.Damage)
+	}
+}
+
+func TestFatigueDisabledLeavesDamageConstant(t *testing.T) {
+
+	em := newTestManager()
+	registerTestTemplates()
+
+	attacker, att, def := fatigueFixture(em)
+
+	attacker.CurrentRound = 1
+	early := calculateUnitDamageByID(em, att, def, DirDown)
+
+	attacker.CurrentRound = 20
+	late := calculateUnitDamageByID(em, att, def, DirDown)
+
+	if late.Damage != early.Damage {
+		t.Errorf("expected constant damage with fatigue off, got %d then %d", early.Damage, late.Damage)
+	}
+}
+
+func TestAdvanceSquadRound(t *testing.T) {
+
+	em := newTestManager()
+	registerTestTemplates()
+
+	attacker, _, _ := fatigueFixture(em)
+
+	AdvanceSquadRound(attacker)
+	AdvanceSquadRound(attacker)
+
+	if attacker.CurrentRound != 2 {
+		t.Errorf("expected round counter 2, got %d", attacker.CurrentRound)
+	}
+}
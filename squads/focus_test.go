@@ -0,0 +1,134 @@
+package squads
+
+import (
+	"game_main/common"
+	"game_main/randgen"
+	"testing"
+)
+
+func focusFixture(t *testing.T, focus int) func() AttackEvent {
+
+	t.Helper()
+
+	em := newTestManager()
+
+	attr := common.NewBaseAttributes(20, 0, 10, 0, 1, 0)
+	attr.Focus = focus
+	RegisterUnitTemplate(UnitTemplate{
+		Name:      "FocusTester",
+		Class:     "Archer",
+		Attr:      attr,
+		MinDamage: 2,
+		MaxDamage: 8,
+	})
+
+	target := common.NewBaseAttributes(1000, 0, 10, 0, 1, 0)
+	RegisterUnitTemplate(UnitTemplate{
+		Name:      "FocusTarget",
+		Class:     "Knight",
+		Attr:      target,
+		MinDamage: 1,
+		MaxDamage: 1,
+	})
+
+	att := CreateSquad(em, "Shooters", "Player", common.Position{X: 5, Y: 5}, DirDown)
+	attacker := CreateUnitFromTemplate(em, Units["FocusTester"], att.SquadID, 0, 0)
+
+	def := CreateSquad(em, "Dummies", "Enemy", common.Position{X: 5, Y: 6}, DirUp)
+	defender := CreateUnitFromTemplate(em, Units["FocusTarget"], def.SquadID, 0, 0)
+
+	return func() AttackEvent {
+		return calculateUnitDamageByID(em, attacker.GetID(), defender.GetID(), DirDown)
+	}
+}
+
+// With crits scripted away, a focused units damage rolls land in a narrower
+// band than an unfocused units
+func TestFocusNarrowsTheDamageSpread(t *testing.T) {
+
+	originalCrit := critRoll
+	critRoll = func() int { return 100 }
+	defer func() { critRoll = originalCrit }()
+
+	randgen.Seed(31)
+	defer randgen.ClearSeed()
+
+	spreadFor := func(focus int) int {
+
+		swing := focusFixture(t, focus)
+
+		low, high := 0, 0
+		for i := 0; i < 300; i++ {
+
+			damage := swing().PotentialDamage
+			if i == 0 || damage < low {
+				low = damage
+			}
+			if damage > high {
+				high = damage
+			}
+
+		}
+
+		return high - low
+	}
+
+	steady := spreadFor(4)
+	wild := spreadFor(0)
+
+	if steady >= wild {
+		t.Errorf("expected the focused spread (%d) to be narrower than the unfocused spread (%d)", steady, wild)
+	}
+}
+
+// Each point of Focus is a percent of crit chance, so a high-Focus unit
+// crits more often than a low-Focus one over the same seeded sequence
+func TestFocusRaisesTheCritRate(t *testing.T) {
+
+	randgen.Seed(47)
+	defer randgen.ClearSeed()
+
+	critsFor := func(focus int) int {
+
+		swing := focusFixture(t, focus)
+
+		crits := 0
+		for i := 0; i < 400; i++ {
+			if swing().Crit {
+				crits++
+			}
+		}
+
+		return crits
+	}
+
+	sharpshooter := critsFor(10)
+	novice := critsFor(1)
+
+	if sharpshooter <= novice {
+		t.Errorf("expected more crits at Focus 10 (%d) than at Focus 1 (%d)", sharpshooter, novice)
+	}
+	if novice == 0 {
+		t.Error("even Focus 1 should crit at least once in 400 swings")
+	}
+}
+
+// A critical hit doubles the weapon roll and marks the event
+func TestCritDoublesTheWeaponRoll(t *testing.T) {
+
+	originalCrit := critRoll
+	critRoll = func() int { return 1 }
+	defer func() { critRoll = originalCrit }()
+
+	swing := focusFixture(t, 6)
+
+	event := swing()
+	if !event.Crit {
+		t.Fatal("a scripted roll of 1 against Focus 6 must crit")
+	}
+
+	//Focus 6 raises the floor to 8 of 8, so the doubled roll is exactly 16
+	if event.PotentialDamage != 16 {
+		t.Errorf("expected a doubled max roll of 16, got %d", event.PotentialDamage)
+	}
+}
@@ -0,0 +1,129 @@
+package squads
+
+import (
+	"game_main/common"
+	"testing"
+)
+
+// An L-shaped serpent: the anchor, the cell below it, and the cell diagonally
+// below-right. Three cells a rectangle cannot express
+func registerSerpentTemplate() {
+
+	Units["TestSerpent"] = UnitTemplate{
+		Name:        "TestSerpent",
+		Class:       "Knight",
+		Attr:        common.NewBaseAttributes(30, 0, 10, 0, 1, 0),
+		MinDamage:   3,
+		MaxDamage:   3,
+		CoverValue:  2,
+		CellOffsets: [][2]int{{0, 0}, {1, 0}, {1, 1}},
+	}
+
+}
+
+// The serpent places at every anchor that keeps the whole L on the grid and
+// nowhere else
+func TestLShapedUnitPlacesAtEveryLegalAnchor(t *testing.T) {
+
+	em := newTestManager()
+	registerTestTemplates()
+	registerSerpentTemplate()
+
+	for row := 0; row < GridHeight; row++ {
+		for col := 0; col < GridWidth; col++ {
+
+			squad := CreateSquad(em, "Placement", "Player", common.Position{X: 1, Y: 1}, DirDown)
+			_, err := AddUnitToSquad(em, Units["TestSerpent"], squad.SquadID, row, col)
+
+			legal := row+1 < GridHeight && col+1 < GridWidth
+			if legal && err != nil {
+				t.Errorf("anchor (%d, %d) keeps the L on the grid and should place, got %v", row, col, err)
+			}
+			if !legal && err == nil {
+				t.Errorf("anchor (%d, %d) hangs the L off the grid and should be refused", row, col)
+			}
+
+		}
+	}
+
+}
+
+// Collision is per footprint cell: the diagonal cell of the L blocks a
+// placement there even though no unit anchors on it, while the cells the L
+// skips stay free
+func TestFootprintCollisionIsPerCell(t *testing.T) {
+
+	em := newTestManager()
+	registerTestTemplates()
+	registerSerpentTemplate()
+
+	squad := CreateSquad(em, "Collision", "Player", common.Position{X: 1, Y: 1}, DirDown)
+	if _, err := AddUnitToSquad(em, Units["TestSerpent"], squad.SquadID, 0, 0); err != nil {
+		t.Fatalf("placing the serpent failed: %v", err)
+	}
+
+	if _, err := AddUnitToSquad(em, Units["TestKnight"], squad.SquadID, 1, 1); err == nil {
+		t.Error("the serpents tail cell should block the knight")
+	}
+	if _, err := AddUnitToSquad(em, Units["TestKnight"], squad.SquadID, 0, 1); err != nil {
+		t.Errorf("the cell the L skips should be free, got %v", err)
+	}
+
+}
+
+// An attack shape finds the serpent through any of its cells and hits it
+// exactly once, at the struck cell
+func TestAttackShapesSeeTheFootprint(t *testing.T) {
+
+	em := newTestManager()
+	registerTestTemplates()
+	registerSerpentTemplate()
+
+	def := CreateSquad(em, "Defenders", "Enemy", common.Position{X: 5, Y: 5}, DirUp)
+	CreateUnitFromTemplate(em, Units["TestSerpent"], def.SquadID, 0, 0)
+
+	row := SelectTargets(em, def.SquadID, TargetRowData{Mode: TargetRow, Index: 1})
+	if len(row.TargetIDs) != 1 {
+		t.Fatalf("the row attack should hit the serpent exactly once, got %d targets", len(row.TargetIDs))
+	}
+	if cell := row.HitCells[row.TargetIDs[0]]; cell != [2]int{1, 0} {
+		t.Errorf("the row attack should strike the first covered cell, got %v", cell)
+	}
+
+	column := SelectTargets(em, def.SquadID, TargetRowData{Mode: TargetColumn, Index: 1})
+	if len(column.TargetIDs) != 1 {
+		t.Fatalf("the column attack should find the diagonal cell, got %d targets", len(column.TargetIDs))
+	}
+	if cell := column.HitCells[column.TargetIDs[0]]; cell != [2]int{1, 1} {
+		t.Errorf("the column attack should strike the tail cell, got %v", cell)
+	}
+
+}
+
+// Cover follows the footprint column by column: the tail cell shields the
+// unit behind it, the column the L never touches gets nothing
+func TestFootprintCoverIsPerColumn(t *testing.T) {
+
+	em := newTestManager()
+	registerTestTemplates()
+	registerSerpentTemplate()
+
+	att := CreateSquad(em, "Attackers", "Player", common.Position{X: 5, Y: 3}, DirDown)
+	attacker := CreateUnitFromTemplate(em, Units["TestKnight"], att.SquadID, 0, 0)
+
+	def := CreateSquad(em, "Defenders", "Enemy", common.Position{X: 5, Y: 5}, DirUp)
+	CreateUnitFromTemplate(em, Units["TestSerpent"], def.SquadID, 0, 0)
+	behindTail := CreateUnitFromTemplate(em, Units["TestKnight"], def.SquadID, 2, 1)
+	exposed := CreateUnitFromTemplate(em, Units["TestKnight"], def.SquadID, 2, 2)
+
+	covered := ResolveUnitAttack(em, attacker.GetID(), behindTail.GetID(), DirDown)
+	if covered.CoverReduction != 2 {
+		t.Errorf("the serpents tail should cover the knight behind it for 2, got %d", covered.CoverReduction)
+	}
+
+	open := ResolveUnitAttack(em, attacker.GetID(), exposed.GetID(), DirDown)
+	if open.CoverReduction != 0 {
+		t.Errorf("the column the L never touches should get no cover, got %d", open.CoverReduction)
+	}
+
+}
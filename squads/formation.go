@@ -0,0 +1,52 @@
+package squads
+
+import (
+	"game_main/common"
+)
+
+// Formation sanity checks. None of these block combat, they just tell the
+// battle runner that a layout is probably unintended so it can log a warning
+
+// IsFormationCombatViable inspects a squads living units and returns whether
+// the layout looks intentional along with the warnings for anything odd
+func IsFormationCombatViable(squadID int, manager *common.EntityManager) (bool, []string) {
+
+	warnings := []string{}
+
+	rows := make(map[int]int)
+	cols := make(map[int]int)
+	living := 0
+
+	for _, u := range SquadUnits(manager, squadID) {
+
+		if common.GetAttributes(u).CurrentHealth <= 0 {
+			continue
+		}
+
+		data := GetUnitData(u)
+		rows[data.Row]++
+		cols[data.Col]++
+		living++
+
+	}
+
+	if living == 0 {
+		warnings = append(warnings, "squad has no living units")
+		return false, warnings
+	}
+
+	if rows[0] == 0 {
+		warnings = append(warnings, "no front line: every unit is behind the front row")
+	}
+
+	if len(cols) == 1 && living > 1 {
+		warnings = append(warnings, "single column: one column attack can hit the whole squad")
+	}
+
+	_, gridH := SquadGridSize(manager, squadID)
+	if rows[gridH-1] == living && living > 1 {
+		warnings = append(warnings, "entire squad is crammed in the back row")
+	}
+
+	return len(warnings) == 0, warnings
+}
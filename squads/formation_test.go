@@ -0,0 +1,50 @@
+package squads
+
+import (
+	"game_main/common"
+	"strings"
+	"testing"
+)
+
+func TestAllBackRowFormationWarns(t *testing.T) {
+
+	em := newTestManager()
+	registerTestTemplates()
+
+	squad := CreateSquad(em, "Turtles", "Player", common.Position{X: 0, Y: 0}, DirUp)
+	CreateUnitFromTemplate(em, Units["TestKnight"], squad.SquadID, 2, 0)
+	CreateUnitFromTemplate(em, Units["TestRogue"], squad.SquadID, 2, 1)
+
+	viable, warnings := IsFormationCombatViable(squad.SquadID, em)
+
+	if viable {
+		t.Error("an all-back-row formation should not be considered viable")
+	}
+
+	found := false
+	for _, w := range warnings {
+		if strings.Contains(w, "front line") {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("expected a no front line warning, got %v", warnings)
+	}
+}
+
+func TestNormalFormationHasNoWarnings(t *testing.T) {
+
+	em := newTestManager()
+	registerTestTemplates()
+
+	squad := CreateSquad(em, "Regulars", "Player", common.Position{X: 0, Y: 0}, DirUp)
+	CreateUnitFromTemplate(em, Units["TestKnight"], squad.SquadID, 0, 0)
+	CreateUnitFromTemplate(em, Units["TestKnight"], squad.SquadID, 0, 1)
+	CreateUnitFromTemplate(em, Units["TestRogue"], squad.SquadID, 1, 0)
+
+	viable, warnings := IsFormationCombatViable(squad.SquadID, em)
+
+	if !viable || len(warnings) != 0 {
+		t.Errorf("expected a normal formation to pass cleanly, got %v", warnings)
+	}
+}
@@ -0,0 +1,97 @@
+package squads
+
+import (
+	"game_main/common"
+	"testing"
+)
+
+// A full 3x3 melee line against a boss alone on a 1x1 grid. Every attackers
+// column wraps onto the bosses single column instead of reading off the
+// smaller grid
+func TestMeleeSquadTargetsSingleCellBoss(t *testing.T) {
+
+	em := newTestManager()
+	registerTestTemplates()
+
+	Units["TestBoss"] = UnitTemplate{
+		Name:      "TestBoss",
+		Class:     "Knight",
+		Attr:      common.NewBaseAttributes(60, 0, 12, 0, 1, 0),
+		MinDamage: 6,
+		MaxDamage: 6,
+	}
+
+	att := CreateSquad(em, "Attackers", "Player", common.Position{X: 5, Y: 3}, DirDown)
+	for col := 0; col < GridWidth; col++ {
+		CreateUnitFromTemplate(em, Units["TestKnight"], att.SquadID, 0, col)
+	}
+
+	lair := CreateSquadWithGrid(em, "Lair", "Enemy", common.Position{X: 5, Y: 5}, DirUp, 1, 1)
+	boss, err := AddUnitToSquad(em, Units["TestBoss"], lair.SquadID, 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	//Nothing else fits on a 1x1 grid
+	if _, err := AddUnitToSquad(em, Units["TestKnight"], lair.SquadID, 0, 1); err == nil {
+		t.Error("cell (0, 1) does not exist on a 1x1 grid, the placement should fail")
+	}
+
+	//A column 2 attacker wraps onto the bosses only column
+	if target := ColumnTarget(em, lair.SquadID, 2); target != boss {
+		t.Error("the column 2 attack should wrap onto the boss")
+	}
+
+	before := common.GetAttributes(boss).CurrentHealth
+	events := ExecuteSquadAttack(em, att.SquadID, lair.SquadID, DirDown)
+
+	if len(events) != 3 {
+		t.Fatalf("all three knights should swing at the boss, got %d events", len(events))
+	}
+	for _, event := range events {
+		if event.DefenderID != boss.GetID() {
+			t.Errorf("event hit unit %d, the boss is the only possible target", event.DefenderID)
+		}
+	}
+	if common.GetAttributes(boss).CurrentHealth >= before {
+		t.Error("the boss should have taken damage")
+	}
+
+}
+
+// A lone ranged attacker on a 1x1 grid firing at rows that only exist on the
+// defenders larger 5x5 grid
+func TestSmallGridAttackerTargetsLargeGridRows(t *testing.T) {
+
+	em := newTestManager()
+	registerTestTemplates()
+
+	att := CreateSquadWithGrid(em, "Sniper", "Player", common.Position{X: 5, Y: 3}, DirDown, 1, 1)
+	archer := CreateUnitFromTemplate(em, Units["TestRogue"], att.SquadID, 0, 0)
+
+	horde := CreateSquadWithGrid(em, "Horde", "Enemy", common.Position{X: 5, Y: 5}, DirUp, 5, 5)
+	rearguard, err := AddUnitToSquad(em, Units["TestKnight"], horde.SquadID, 4, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := AddUnitToSquad(em, Units["TestKnight"], horde.SquadID, 5, 0); err == nil {
+		t.Error("row 5 does not exist even on a 5x5 grid, the placement should fail")
+	}
+
+	info := SelectTargets(em, horde.SquadID, TargetRowData{Mode: TargetRow, Index: 4})
+	if len(info.TargetIDs) != 1 || info.TargetIDs[0] != rearguard.GetID() {
+		t.Fatalf("the row 4 attack should find the rearguard, got %v", info.TargetIDs)
+	}
+
+	events, _ := ResolveRowAttack(em, archer.GetID(), horde.SquadID, TargetRowData{Mode: TargetRow, Index: 4}, DirDown)
+	if len(events) != 1 || events[0].Damage <= 0 {
+		t.Errorf("the attack on row 4 should land, got %v", events)
+	}
+
+	//The default grid still ends at row 2
+	standard := CreateSquad(em, "Standard", "Enemy", common.Position{X: 6, Y: 5}, DirUp)
+	if _, err := AddUnitToSquad(em, Units["TestKnight"], standard.SquadID, 4, 0); err == nil {
+		t.Error("row 4 does not exist on the default 3x3 grid, the placement should fail")
+	}
+
+}
@@ -0,0 +1,128 @@
+package squads
+
+import (
+	"fmt"
+	"game_main/common"
+	"game_main/randgen"
+
+	"github.com/bytearena/ecs"
+)
+
+// A guardian steps in front of melee attacks aimed at the ally it protects.
+// The substitution happens as a targeting post-process, so damage calculation
+// sees the guardian as the defender and rolls its dodge and protection, not
+// the charges. The redirect is recorded on the attack event so both units
+// stats stay attributable
+
+var GuardianComponent *ecs.Component
+
+type GuardianData struct {
+	//The unit this guardian takes hits for
+	ProtectedUnitID ecs.EntityID
+
+	//Chance per attack that the guardian actually steps in, 0 to 1.
+	//AssignGuardian sets 1 for the always-intercept bodyguard
+	InterceptChance float32
+}
+
+func GetGuardianData(e *ecs.Entity) *GuardianData {
+	return common.GetComponentType[*GuardianData](e, GuardianComponent)
+}
+
+// AssignGuardian makes a unit intercept every melee attack aimed at an ally.
+// One protege per guard: assigning again replaces the previous link
+func AssignGuardian(guardian *ecs.Entity, protected *ecs.Entity) {
+	guardian.AddComponent(GuardianComponent, &GuardianData{ProtectedUnitID: protected.GetID(), InterceptChance: 1})
+}
+
+// A guardian can only intercept while it lives and stands next to its charge
+// in the grid
+func guardianCanIntercept(em *common.EntityManager, guardian *ecs.Entity, protected *ecs.Entity) bool {
+
+	if common.GetAttributes(guardian).CurrentHealth <= 0 {
+		return false
+	}
+
+	gData := GetUnitData(guardian)
+	pData := GetUnitData(protected)
+
+	if gData.SquadID != pData.SquadID {
+		return false
+	}
+
+	rowDist := abs(gData.Row - pData.Row)
+	colDist := abs(gData.Col - pData.Col)
+
+	return rowDist <= 1 && colDist <= 1
+}
+
+// InterceptWithGuardian is the targeting post-process. If the target has a
+// living, adjacent guardian the guardian is substituted, otherwise the target
+// comes back unchanged
+func InterceptWithGuardian(em *common.EntityManager, targetID ecs.EntityID) ecs.EntityID {
+
+	target := FindUnitByID(em, targetID)
+	if target == nil {
+		return targetID
+	}
+
+	for _, u := range SquadUnits(em, GetUnitData(target).SquadID) {
+
+		data := GetGuardianData(u)
+		if data == nil || data.ProtectedUnitID != targetID {
+			continue
+		}
+
+		if !guardianCanIntercept(em, u, target) {
+			continue
+		}
+
+		//A partial intercept chance gets its own roll per attack
+		if data.InterceptChance < 1 && float32(interceptRoll()) > data.InterceptChance*100 {
+			continue
+		}
+
+		return u.GetID()
+
+	}
+
+	return targetID
+}
+
+// Swappable so tests can script the rolls, same shape as dodgeRoll
+var interceptRoll = func() int {
+	return randgen.GetDiceRoll(100)
+}
+
+// GuardianLinks describes every guard link in a squad as "guard cell ->
+// protected cell". The squad editor draws these as arrows between grid cells
+func GuardianLinks(em *common.EntityManager, squadID int) []string {
+
+	links := []string{}
+	for _, u := range SquadUnits(em, squadID) {
+
+		data := GetGuardianData(u)
+		if data == nil {
+			continue
+		}
+
+		protected := FindUnitByID(em, data.ProtectedUnitID)
+		if protected == nil {
+			continue
+		}
+
+		gData := GetUnitData(u)
+		pData := GetUnitData(protected)
+		links = append(links, fmt.Sprintf("(%d,%d) -> (%d,%d)", gData.Row, gData.Col, pData.Row, pData.Col))
+
+	}
+
+	return links
+}
+
+func abs(v int) int {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
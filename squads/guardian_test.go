@@ -0,0 +1,160 @@
+package squads
+
+import (
+	"game_main/common"
+	"testing"
+)
+
+// A guardian standing next to its charge takes the melee hit in its place
+func TestGuardianInterceptsMeleeAttack(t *testing.T) {
+
+	em := newTestManager()
+	registerTestTemplates()
+
+	defSquad := CreateSquad(em, "Defenders", "Enemy", common.Position{X: 5, Y: 5}, DirUp)
+	charge := CreateUnitFromTemplate(em, Units["TestRogue"], defSquad.SquadID, 0, 0)
+	guardian := CreateUnitFromTemplate(em, Units["TestKnight"], defSquad.SquadID, 0, 1)
+	AssignGuardian(guardian, charge)
+
+	attSquad := CreateSquad(em, "Attackers", "Player", common.Position{X: 5, Y: 3}, DirDown)
+	attacker := CreateUnitFromTemplate(em, Units["TestRogue"], attSquad.SquadID, 0, 0)
+
+	chargeHP := common.GetAttributes(charge).CurrentHealth
+
+	attack := TargetRowData{Mode: TargetSingle, Index: 0, Melee: true}
+	events, info := ResolveRowAttack(em, attacker.GetID(), defSquad.SquadID, attack, DirDown)
+
+	if len(info.TargetIDs) != 1 || info.TargetIDs[0] != guardian.GetID() {
+		t.Fatalf("expected the guardian to be substituted as the target, got %v", info.TargetIDs)
+	}
+
+	if common.GetAttributes(charge).CurrentHealth != chargeHP {
+		t.Error("the protected unit should take no damage while the guardian lives")
+	}
+
+	if len(events) != 1 || events[0].DefenderID != guardian.GetID() {
+		t.Error("the attack event should record the guardian as the defender")
+	}
+}
+
+// Once the guardian dies the charge is targetable again
+func TestDeadGuardianStopsIntercepting(t *testing.T) {
+
+	em := newTestManager()
+	registerTestTemplates()
+
+	defSquad := CreateSquad(em, "Defenders", "Enemy", common.Position{X: 5, Y: 5}, DirUp)
+	charge := CreateUnitFromTemplate(em, Units["TestRogue"], defSquad.SquadID, 0, 0)
+	guardian := CreateUnitFromTemplate(em, Units["TestKnight"], defSquad.SquadID, 0, 1)
+	AssignGuardian(guardian, charge)
+
+	common.GetAttributes(guardian).CurrentHealth = 0
+
+	if InterceptWithGuardian(em, charge.GetID()) != charge.GetID() {
+		t.Error("a dead guardian should not intercept, targeting must revert to the charge")
+	}
+}
+
+// An intercepted attack is logged as a redirect naming the covered unit
+func TestInterceptIsLoggedAsRedirect(t *testing.T) {
+
+	em := newTestManager()
+	registerTestTemplates()
+
+	defSquad := CreateSquad(em, "Defenders", "Enemy", common.Position{X: 5, Y: 5}, DirUp)
+	charge := CreateUnitFromTemplate(em, Units["TestRogue"], defSquad.SquadID, 0, 0)
+	guardian := CreateUnitFromTemplate(em, Units["TestKnight"], defSquad.SquadID, 0, 1)
+	AssignGuardian(guardian, charge)
+
+	attSquad := CreateSquad(em, "Attackers", "Player", common.Position{X: 5, Y: 3}, DirDown)
+	attacker := CreateUnitFromTemplate(em, Units["TestRogue"], attSquad.SquadID, 0, 0)
+
+	attack := TargetRowData{Mode: TargetSingle, Index: 0, Melee: true}
+	events, _ := ResolveRowAttack(em, attacker.GetID(), defSquad.SquadID, attack, DirDown)
+
+	if len(events) != 1 {
+		t.Fatalf("expected one event, got %d", len(events))
+	}
+
+	if !events[0].Redirected || events[0].RedirectedFrom != charge.GetID() {
+		t.Errorf("the event should record the redirect away from the charge, got %+v", events[0])
+	}
+}
+
+// Killing the guard mid-engagement lets subsequent attacks through to the
+// unit it was covering
+func TestKillingTheGuardLetsAttacksThrough(t *testing.T) {
+
+	em := newTestManager()
+	registerTestTemplates()
+
+	defSquad := CreateSquad(em, "Defenders", "Enemy", common.Position{X: 5, Y: 5}, DirUp)
+	charge := CreateUnitFromTemplate(em, Units["TestRogue"], defSquad.SquadID, 0, 0)
+	guardian := CreateUnitFromTemplate(em, Units["TestKnight"], defSquad.SquadID, 0, 1)
+	AssignGuardian(guardian, charge)
+
+	attSquad := CreateSquad(em, "Attackers", "Player", common.Position{X: 5, Y: 3}, DirDown)
+	attacker := CreateUnitFromTemplate(em, Units["TestRogue"], attSquad.SquadID, 0, 0)
+
+	attack := TargetRowData{Mode: TargetSingle, Index: 0, Melee: true}
+
+	events, _ := ResolveRowAttack(em, attacker.GetID(), defSquad.SquadID, attack, DirDown)
+	if events[0].DefenderID != guardian.GetID() {
+		t.Fatal("the first attack should land on the guard")
+	}
+
+	common.GetAttributes(guardian).CurrentHealth = 0
+
+	events, _ = ResolveRowAttack(em, attacker.GetID(), defSquad.SquadID, attack, DirDown)
+	if len(events) != 1 || events[0].DefenderID != charge.GetID() {
+		t.Errorf("with the guard dead the attack should hit the charge, got %+v", events)
+	}
+	if events[0].Redirected {
+		t.Error("an attack that got through must not be marked redirected")
+	}
+}
+
+// A partial intercept chance only steps in when its roll passes
+func TestInterceptChanceRolls(t *testing.T) {
+
+	em := newTestManager()
+	registerTestTemplates()
+
+	defSquad := CreateSquad(em, "Defenders", "Enemy", common.Position{X: 5, Y: 5}, DirUp)
+	charge := CreateUnitFromTemplate(em, Units["TestRogue"], defSquad.SquadID, 0, 0)
+	guardian := CreateUnitFromTemplate(em, Units["TestKnight"], defSquad.SquadID, 0, 1)
+	AssignGuardian(guardian, charge)
+	GetGuardianData(guardian).InterceptChance = 0.5
+
+	original := interceptRoll
+	defer func() { interceptRoll = original }()
+
+	interceptRoll = func() int { return 90 } //over 50, the guard stays put
+	if InterceptWithGuardian(em, charge.GetID()) != charge.GetID() {
+		t.Error("a failed intercept roll should leave the charge as the target")
+	}
+
+	interceptRoll = func() int { return 10 } //under 50, the guard steps in
+	if InterceptWithGuardian(em, charge.GetID()) != guardian.GetID() {
+		t.Error("a passed intercept roll should substitute the guard")
+	}
+}
+
+// Ranged attacks skip the guardian post-process entirely
+func TestGuardianIgnoresNonMeleeAttacks(t *testing.T) {
+
+	em := newTestManager()
+	registerTestTemplates()
+
+	defSquad := CreateSquad(em, "Defenders", "Enemy", common.Position{X: 5, Y: 5}, DirUp)
+	charge := CreateUnitFromTemplate(em, Units["TestRogue"], defSquad.SquadID, 0, 0)
+	guardian := CreateUnitFromTemplate(em, Units["TestKnight"], defSquad.SquadID, 0, 1)
+	AssignGuardian(guardian, charge)
+
+	attack := TargetRowData{Mode: TargetSingle, Index: 0}
+	info := SelectTargets(em, defSquad.SquadID, attack)
+
+	if len(info.TargetIDs) != 1 || info.TargetIDs[0] != charge.GetID() {
+		t.Errorf("a non-melee attack should still hit the charge, got %v", info.TargetIDs)
+	}
+}
@@ -0,0 +1,248 @@
+package squads
+
+import (
+	"encoding/json"
+	"fmt"
+	"game_main/common"
+	"os"
+)
+
+// The campaign journal: an append-only stream of what happened over a long
+// game - battles resolved, squads raised and destroyed - so a player can
+// scroll back through the campaign instead of reconstructing it from memory.
+// The stream is capped and evicts oldest-first, and it rides along in the
+// savegame. Event types this build does not know (mods) keep their raw
+// payload and render generically instead of being dropped
+
+// The event types this build writes itself. Anything else is passed through
+const (
+	JournalBattle         = "battle"
+	JournalSquadCreated   = "squadCreated"
+	JournalSquadDestroyed = "squadDestroyed"
+	JournalDiplomacy      = "diplomacy"
+)
+
+const DefaultJournalCap = 500
+
+type JournalEvent struct {
+	//Monotonic sequence number, stable across eviction and reload
+	Seq int `json:"seq"`
+
+	Turn int    `json:"turn"`
+	Type string `json:"type"`
+
+	Factions []string `json:"factions,omitempty"`
+	SquadIDs []int    `json:"squadIDs,omitempty"`
+
+	Summary string `json:"summary,omitempty"`
+
+	//Where it happened, for centering the map on the event
+	Pos *common.Position `json:"pos,omitempty"`
+
+	//Verbatim payload of event types this build does not understand
+	Payload json.RawMessage `json:"payload,omitempty"`
+}
+
+// DisplayString renders the event for the timeline. Unknown types render
+// generically rather than erroring, so modded journals stay readable
+func (e JournalEvent) DisplayString() string {
+
+	if e.Summary != "" {
+		return fmt.Sprintf("turn %d: %s", e.Turn, e.Summary)
+	}
+
+	return fmt.Sprintf("turn %d: %s event (no details in this build)", e.Turn, e.Type)
+}
+
+type Journal struct {
+	//Maximum events kept. Recording past it evicts the oldest
+	Cap int
+
+	events  []JournalEvent
+	nextSeq int
+}
+
+func NewJournal(cap int) *Journal {
+
+	if cap <= 0 {
+		cap = DefaultJournalCap
+	}
+
+	return &Journal{Cap: cap}
+}
+
+// Record appends the event, stamping its sequence number and evicting the
+// oldest entries once the cap is exceeded
+func (j *Journal) Record(event JournalEvent) {
+
+	j.nextSeq++
+	event.Seq = j.nextSeq
+	j.events = append(j.events, event)
+
+	if excess := len(j.events) - j.Cap; excess > 0 {
+		j.events = append([]JournalEvent{}, j.events[excess:]...)
+	}
+
+}
+
+func (j *Journal) Events() []JournalEvent {
+	return j.events
+}
+
+func (j *Journal) Len() int {
+	return len(j.events)
+}
+
+// RecordBattle notes a resolved battle from its record
+func (j *Journal) RecordBattle(turn int, record *BattleRecord) {
+
+	winner, loser := record.Attacker.Name, record.Defender.Name
+	pos := record.Attacker.MapPos
+	if record.WinnerSquadID == record.DefenderSquadID {
+		winner, loser = loser, winner
+		pos = record.Defender.MapPos
+	}
+
+	j.Record(JournalEvent{
+		Turn:     turn,
+		Type:     JournalBattle,
+		SquadIDs: []int{record.AttackerSquadID, record.DefenderSquadID},
+		Summary:  fmt.Sprintf("%s defeated %s in %d rounds", winner, loser, record.Rounds),
+		Pos:      &pos,
+	})
+
+}
+
+func (j *Journal) RecordSquadCreated(turn int, squad *SquadData) {
+
+	pos := squad.MapPos
+	j.Record(JournalEvent{
+		Turn:     turn,
+		Type:     JournalSquadCreated,
+		Factions: []string{squad.Faction},
+		SquadIDs: []int{squad.SquadID},
+		Summary:  fmt.Sprintf("%s raised the squad %s", squad.Faction, squad.Name),
+		Pos:      &pos,
+	})
+
+}
+
+func (j *Journal) RecordSquadDestroyed(turn int, squad *SquadData) {
+
+	pos := squad.MapPos
+	j.Record(JournalEvent{
+		Turn:     turn,
+		Type:     JournalSquadDestroyed,
+		Factions: []string{squad.Faction},
+		SquadIDs: []int{squad.SquadID},
+		Summary:  fmt.Sprintf("%s lost the squad %s", squad.Faction, squad.Name),
+		Pos:      &pos,
+	})
+
+}
+
+// RecordStanceChange notes a diplomatic shift between two factions
+func (j *Journal) RecordStanceChange(turn int, factionA string, factionB string, stance Stance) {
+
+	j.Record(JournalEvent{
+		Turn:     turn,
+		Type:     JournalDiplomacy,
+		Factions: []string{factionA, factionB},
+		Summary:  fmt.Sprintf("%s and %s are now %s", factionA, factionB, stance),
+	})
+
+}
+
+// JournalFilter narrows the timeline. Zero values mean "everything"
+type JournalFilter struct {
+	Type    string
+	Faction string
+	SquadID int
+}
+
+func (f JournalFilter) matches(event JournalEvent) bool {
+
+	if f.Type != "" && event.Type != f.Type {
+		return false
+	}
+
+	if f.Faction != "" {
+
+		found := false
+		for _, faction := range event.Factions {
+			if faction == f.Faction {
+				found = true
+			}
+		}
+		if !found {
+			return false
+		}
+
+	}
+
+	if f.SquadID != 0 {
+
+		found := false
+		for _, id := range event.SquadIDs {
+			if id == f.SquadID {
+				found = true
+			}
+		}
+		if !found {
+			return false
+		}
+
+	}
+
+	return true
+}
+
+// Filtered is the timeline view: the events the filter keeps, oldest first
+func (j *Journal) Filtered(filter JournalFilter) []JournalEvent {
+
+	kept := []JournalEvent{}
+	for _, event := range j.events {
+		if filter.matches(event) {
+			kept = append(kept, event)
+		}
+	}
+
+	return kept
+}
+
+// The saved form carries the sequence counter so reloaded journals keep
+// numbering where they left off
+type savedJournal struct {
+	Cap     int            `json:"cap"`
+	NextSeq int            `json:"nextSeq"`
+	Events  []JournalEvent `json:"events"`
+}
+
+func SaveJournal(path string, j *Journal) error {
+
+	contents, err := json.MarshalIndent(savedJournal{Cap: j.Cap, NextSeq: j.nextSeq, Events: j.events}, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(path, append(contents, '\n'), 0644)
+}
+
+func LoadJournal(path string) (*Journal, error) {
+
+	contents, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var saved savedJournal
+	if err := json.Unmarshal(contents, &saved); err != nil {
+		return nil, fmt.Errorf("journal file %s did not parse: %w", path, err)
+	}
+
+	journal := NewJournal(saved.Cap)
+	journal.nextSeq = saved.NextSeq
+	journal.events = saved.Events
+
+	return journal, nil
+}
@@ -0,0 +1,120 @@
+package squads
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"testing"
+
+	"game_main/common"
+)
+
+// A short scripted campaign: two squads raised, a battle, a destruction. The
+// journal should hold the events in order with the right types
+func TestJournalCapturesAScriptedSequence(t *testing.T) {
+
+	em := newTestManager()
+	registerTestTemplates()
+
+	att := CreateSquad(em, "Attackers", "Player", common.Position{X: 1, Y: 1}, DirDown)
+	def := CreateSquad(em, "Defenders", "Enemy", common.Position{X: 1, Y: 3}, DirUp)
+
+	record := NewBattleRecord(att.SquadID, def.SquadID)
+	record.Attacker = SnapshotSquad(em, att.SquadID)
+	record.Defender = SnapshotSquad(em, def.SquadID)
+	record.WinnerSquadID = att.SquadID
+	record.Rounds = 4
+
+	journal := NewJournal(0)
+	journal.RecordSquadCreated(1, att)
+	journal.RecordSquadCreated(1, def)
+	journal.RecordBattle(3, record)
+	journal.RecordSquadDestroyed(3, def)
+
+	events := journal.Events()
+	if len(events) != 4 {
+		t.Fatalf("the journal should hold 4 events, got %d", len(events))
+	}
+
+	wantTypes := []string{JournalSquadCreated, JournalSquadCreated, JournalBattle, JournalSquadDestroyed}
+	for i, want := range wantTypes {
+		if events[i].Type != want {
+			t.Errorf("event %d should be %s, got %s", i, want, events[i].Type)
+		}
+	}
+
+	if events[2].Summary != "Attackers defeated Defenders in 4 rounds" {
+		t.Errorf("the battle summary reads wrong: %q", events[2].Summary)
+	}
+
+	if got := journal.Filtered(JournalFilter{Faction: "Enemy"}); len(got) != 2 {
+		t.Errorf("filtering on the enemy faction should keep its creation and loss, got %d events", len(got))
+	}
+
+}
+
+// Past the cap the oldest events go first, and sequence numbers keep counting
+func TestJournalEvictsOldestAtTheCap(t *testing.T) {
+
+	journal := NewJournal(3)
+
+	for turn := 1; turn <= 5; turn++ {
+		journal.Record(JournalEvent{Turn: turn, Type: JournalBattle})
+	}
+
+	if journal.Len() != 3 {
+		t.Fatalf("the cap should hold the journal at 3 events, got %d", journal.Len())
+	}
+
+	events := journal.Events()
+	if events[0].Turn != 3 || events[2].Turn != 5 {
+		t.Errorf("the two oldest events should be gone, kept turns %d through %d", events[0].Turn, events[2].Turn)
+	}
+
+	if events[2].Seq != 5 {
+		t.Errorf("sequence numbers should survive eviction, got %d for the newest event", events[2].Seq)
+	}
+
+}
+
+// The journal survives a save and load, and an event type from a mod keeps
+// its raw payload and still renders
+func TestJournalPersistenceKeepsUnknownEvents(t *testing.T) {
+
+	journal := NewJournal(10)
+	journal.Record(JournalEvent{Turn: 2, Type: JournalBattle, Summary: "skirmish at the ford"})
+	journal.Record(JournalEvent{
+		Turn:    3,
+		Type:    "moddedEclipse",
+		Payload: json.RawMessage(`{"darkness":7}`),
+	})
+
+	path := filepath.Join(t.TempDir(), "journal.json")
+	if err := SaveJournal(path, journal); err != nil {
+		t.Fatalf("saving the journal failed: %v", err)
+	}
+
+	loaded, err := LoadJournal(path)
+	if err != nil {
+		t.Fatalf("loading the journal failed: %v", err)
+	}
+
+	if loaded.Len() != 2 {
+		t.Fatalf("the loaded journal should hold 2 events, got %d", loaded.Len())
+	}
+
+	modded := loaded.Events()[1]
+	var payload map[string]int
+	if err := json.Unmarshal(modded.Payload, &payload); err != nil || payload["darkness"] != 7 {
+		t.Errorf("the unknown events payload should survive the round trip, got %s", modded.Payload)
+	}
+	if modded.DisplayString() != "turn 3: moddedEclipse event (no details in this build)" {
+		t.Errorf("unknown events should render generically, got %q", modded.DisplayString())
+	}
+
+	//New events after a load keep numbering where the save left off
+	loaded.Record(JournalEvent{Turn: 4, Type: JournalBattle})
+	if got := loaded.Events()[2].Seq; got != 3 {
+		t.Errorf("numbering should continue from the save, got seq %d", got)
+	}
+
+}
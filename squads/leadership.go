@@ -0,0 +1,211 @@
+package squads
+
+import (
+	"fmt"
+
+	"game_main/common"
+
+	"github.com/bytearena/ecs"
+)
+
+// Squad leaders raise their squads unit capacity by their Leadership score.
+// When a leader dies mid-battle the squads succession policy decides what
+// happens: promote the best candidate on the spot, or fight on leaderless
+// until the player sorts it out after the battle. Abilities carried by the
+// fallen leader die with the unit like any other gear - succession only
+// moves the leadership itself
+
+type SquadLeadershipPolicy int
+
+const (
+	//No succession: the squad goes leaderless when its leader dies
+	LeadershipNone SquadLeadershipPolicy = iota
+
+	//The living unit with the highest Leadership takes over immediately
+	AutoPromoteHighestLeadership
+
+	//The squad fights on leaderless and the player picks a successor on the
+	//post-battle screen
+	PlayerChoosesAfterBattle
+)
+
+// What a squad can hold with no leader alive
+const BaseSquadCapacity = 6
+
+var LeaderComponent *ecs.Component
+
+// LeaderData marks the leading unit. The leadership score itself lives on
+// UnitData so every unit is a potential successor
+type LeaderData struct{}
+
+// MakeLeader puts the unit in charge of its squad and clears the leaderless
+// flag. Any previous leader of the squad steps down
+func MakeLeader(em *common.EntityManager, unit *ecs.Entity) {
+
+	data := GetUnitData(unit)
+
+	if current := SquadLeader(em, data.SquadID); current != nil && current != unit {
+		current.RemoveComponent(LeaderComponent)
+	}
+
+	unit.AddComponent(LeaderComponent, &LeaderData{})
+
+	if squad := GetSquadByID(em, data.SquadID); squad != nil {
+		squad.Leaderless = false
+	}
+
+}
+
+// SquadLeader returns the squads living leader, or nil
+func SquadLeader(em *common.EntityManager, squadID int) *ecs.Entity {
+
+	for _, u := range SquadUnits(em, squadID) {
+
+		if u.HasComponent(LeaderComponent) && common.GetAttributes(u).CurrentHealth > 0 {
+			return u
+		}
+
+	}
+
+	return nil
+}
+
+// SquadCapacity is how many units the squad can hold: the base plus the
+// living leaders Leadership. A leaderless squad drops back to the base
+func SquadCapacity(em *common.EntityManager, squadID int) int {
+
+	capacity := BaseSquadCapacity
+	if leader := SquadLeader(em, squadID); leader != nil {
+		capacity += GetUnitData(leader).Leadership
+	}
+
+	return capacity
+}
+
+// ResolveLeaderDeath runs the squads succession policy. The death path calls
+// it for every unit that drops; non-leaders return immediately
+func ResolveLeaderDeath(em *common.EntityManager, fallen *ecs.Entity) {
+
+	if !fallen.HasComponent(LeaderComponent) {
+		return
+	}
+
+	data := GetUnitData(fallen)
+	squad := GetSquadByID(em, data.SquadID)
+	if squad == nil {
+		return
+	}
+
+	fallen.RemoveComponent(LeaderComponent)
+
+	if squad.LeadershipPolicy == AutoPromoteHighestLeadership {
+
+		if successor := bestSuccessor(em, data.SquadID); successor != nil {
+
+			MakeLeader(em, successor)
+			squad.SuccessionNote = fmt.Sprintf("%s took command of %s", GetUnitData(successor).TemplateName, squad.Name)
+			return
+
+		}
+
+	}
+
+	squad.Leaderless = true
+	squad.SuccessionNote = fmt.Sprintf("%s fights on leaderless", squad.Name)
+}
+
+// The living unit with the highest Leadership, front-and-left first on ties
+// so the pick is deterministic
+func bestSuccessor(em *common.EntityManager, squadID int) *ecs.Entity {
+
+	var best *ecs.Entity
+	for _, u := range SquadUnits(em, squadID) {
+
+		if common.GetAttributes(u).CurrentHealth <= 0 {
+			continue
+		}
+
+		if best == nil || betterSuccessor(GetUnitData(u), GetUnitData(best)) {
+			best = u
+		}
+
+	}
+
+	return best
+}
+
+func betterSuccessor(candidate *UnitData, current *UnitData) bool {
+
+	if candidate.Leadership != current.Leadership {
+		return candidate.Leadership > current.Leadership
+	}
+
+	if candidate.Row != current.Row {
+		return candidate.Row < current.Row
+	}
+
+	return candidate.Col < current.Col
+}
+
+// OverCapacityError is the typed refusal from AddUnitToSquad, so callers can
+// tell "squad is full" apart from other failures and render it properly
+type OverCapacityError struct {
+	SquadID  int
+	Capacity int
+	Units    int
+
+	//True when the refusal is the leaderless grace rule rather than a full
+	//squad: existing units stay, but nothing joins until a leader is set
+	Leaderless bool
+}
+
+func (e *OverCapacityError) Error() string {
+
+	if e.Leaderless {
+		return fmt.Sprintf("squad %d is leaderless, no units can join until a leader is chosen", e.SquadID)
+	}
+
+	return fmt.Sprintf("squad %d is at its capacity of %d units", e.SquadID, e.Capacity)
+}
+
+// AddUnitToSquad is CreateUnitFromTemplate behind the capacity rules: a full
+// squad refuses, and a leaderless squad refuses everything until resolved
+func AddUnitToSquad(em *common.EntityManager, template UnitTemplate, squadID int, row int, col int) (*ecs.Entity, error) {
+
+	squad := GetSquadByID(em, squadID)
+	if squad == nil {
+		return nil, fmt.Errorf("no squad with ID %d", squadID)
+	}
+
+	units := len(SquadUnits(em, squadID))
+	capacity := SquadCapacity(em, squadID)
+
+	if squad.Leaderless {
+		return nil, &OverCapacityError{SquadID: squadID, Capacity: capacity, Units: units, Leaderless: true}
+	}
+
+	if units >= capacity {
+		return nil, &OverCapacityError{SquadID: squadID, Capacity: capacity, Units: units}
+	}
+
+	//Every cell of the footprint must lie on the grid and be free. The
+	//placeholder carries the templates footprint so the check sees the real
+	//shape, not just the anchor
+	gridW, gridH := squad.GridSize()
+	placed := UnitData{Row: row, Col: col, CellOffsets: template.CellOffsets}
+	for _, cell := range placed.OccupiedCells() {
+
+		if cell[0] < 0 || cell[0] >= gridH || cell[1] < 0 || cell[1] >= gridW {
+			return nil, fmt.Errorf("unit %s does not fit at row %d, col %d: cell (%d, %d) is off the grid",
+				template.Name, row, col, cell[0], cell[1])
+		}
+
+		if other := UnitOccupyingCell(em, squadID, cell[0], cell[1]); other != nil && common.GetAttributes(other).CurrentHealth > 0 {
+			return nil, fmt.Errorf("cell (%d, %d) of squad %d is already occupied by %s",
+				cell[0], cell[1], squadID, GetUnitData(other).TemplateName)
+		}
+
+	}
+
+	return CreateUnitFromTemplate(em, template, squadID, row, col), nil
+}
@@ -0,0 +1,118 @@
+package squads
+
+import (
+	"errors"
+	"game_main/common"
+	"testing"
+
+	"github.com/bytearena/ecs"
+)
+
+// An attacker squad and a defender squad whose captain leads with Leadership
+// 3, a sergeant waiting at 2, and a ranker at 0
+func leadershipFixture(policy SquadLeadershipPolicy) (*common.EntityManager, ecs.EntityID, *SquadData, *ecs.Entity) {
+
+	em := newTestManager()
+	registerTestTemplates()
+
+	att := CreateSquad(em, "Attackers", "Player", common.Position{X: 5, Y: 3}, DirDown)
+	attacker := CreateUnitFromTemplate(em, Units["TestKnight"], att.SquadID, 0, 0)
+
+	def := CreateSquad(em, "Defenders", "Enemy", common.Position{X: 5, Y: 5}, DirUp)
+	def.LeadershipPolicy = policy
+
+	captain := CreateUnitFromTemplate(em, Units["TestKnight"], def.SquadID, 0, 0)
+	GetUnitData(captain).Leadership = 3
+	MakeLeader(em, captain)
+
+	sergeant := CreateUnitFromTemplate(em, Units["TestKnight"], def.SquadID, 1, 0)
+	GetUnitData(sergeant).Leadership = 2
+
+	CreateUnitFromTemplate(em, Units["TestKnight"], def.SquadID, 2, 0)
+
+	//One hit fells the captain
+	common.GetAttributes(captain).CurrentHealth = 1
+
+	return em, attacker.GetID(), def, captain
+}
+
+// Killing the leader under auto-promotion hands command to the highest
+// Leadership survivor and adjusts capacity in the same attack resolution
+func TestAutoPromotionTransfersLeadership(t *testing.T) {
+
+	em, attackerID, def, captain := leadershipFixture(AutoPromoteHighestLeadership)
+
+	if got := SquadCapacity(em, def.SquadID); got != BaseSquadCapacity+3 {
+		t.Fatalf("the captain should add their 3 Leadership to capacity, got %d", got)
+	}
+
+	ResolveUnitAttack(em, attackerID, captain.GetID(), DirDown)
+
+	leader := SquadLeader(em, def.SquadID)
+	if leader == nil || GetUnitData(leader).Leadership != 2 {
+		t.Fatal("the sergeant should take command the moment the captain falls")
+	}
+
+	if captain.HasComponent(LeaderComponent) {
+		t.Error("the fallen captain should no longer hold leadership")
+	}
+
+	if got := SquadCapacity(em, def.SquadID); got != BaseSquadCapacity+2 {
+		t.Errorf("capacity should recompute from the successor, got %d", got)
+	}
+
+	if def.Leaderless || def.SuccessionNote != "TestKnight took command of Defenders" {
+		t.Errorf("the succession should be noted for the summary, got %q", def.SuccessionNote)
+	}
+
+}
+
+// Without auto-promotion the squad goes leaderless: capacity drops to the
+// base, survivors stay put, and nothing joins until a leader is chosen
+func TestLeaderlessSquadsRefuseNewUnits(t *testing.T) {
+
+	em, attackerID, def, captain := leadershipFixture(PlayerChoosesAfterBattle)
+
+	ResolveUnitAttack(em, attackerID, captain.GetID(), DirDown)
+
+	if !def.Leaderless {
+		t.Fatal("the squad should be flagged leaderless")
+	}
+	if got := SquadCapacity(em, def.SquadID); got != BaseSquadCapacity {
+		t.Errorf("a leaderless squad should drop to the base capacity, got %d", got)
+	}
+
+	_, err := AddUnitToSquad(em, Units["TestKnight"], def.SquadID, 2, 2)
+
+	var overCap *OverCapacityError
+	if !errors.As(err, &overCap) || !overCap.Leaderless {
+		t.Fatalf("joining a leaderless squad should fail with the typed grace-rule error, got %v", err)
+	}
+
+	//Choosing a new leader lifts the block
+	MakeLeader(em, SquadUnits(em, def.SquadID)[1])
+	if _, err := AddUnitToSquad(em, Units["TestKnight"], def.SquadID, 2, 2); err != nil {
+		t.Errorf("a led squad under capacity should accept the unit, got %v", err)
+	}
+
+}
+
+// A squad at its capacity refuses more units even with a leader
+func TestFullSquadsRefuseNewUnits(t *testing.T) {
+
+	em := newTestManager()
+	registerTestTemplates()
+
+	squad := CreateSquad(em, "Packed", "Player", common.Position{X: 1, Y: 1}, DirDown)
+	for i := 0; i < BaseSquadCapacity; i++ {
+		CreateUnitFromTemplate(em, Units["TestKnight"], squad.SquadID, i/GridWidth, i%GridWidth)
+	}
+
+	_, err := AddUnitToSquad(em, Units["TestKnight"], squad.SquadID, 2, 0)
+
+	var overCap *OverCapacityError
+	if !errors.As(err, &overCap) || overCap.Leaderless || overCap.Capacity != BaseSquadCapacity {
+		t.Fatalf("a full squad should fail with the typed capacity error, got %v", err)
+	}
+
+}
@@ -0,0 +1,118 @@
+package squads
+
+import (
+	"game_main/common"
+
+	"github.com/bytearena/ecs"
+)
+
+// Squad units gain XP from battles and level up between them. Growth is
+// deterministic so designers can reason about progression, and PreviewGrowth
+// uses the exact same scaling as the real level-up
+
+var UnitLevelComponent *ecs.Component
+
+type UnitLevelData struct {
+	Level int `json:"level"`
+	XP    int `json:"xp"`
+}
+
+func GetUnitLevelData(e *ecs.Entity) *UnitLevelData {
+	return common.GetComponentType[*UnitLevelData](e, UnitLevelComponent)
+}
+
+// XPForLevel is how much XP it takes to go from level to level+1
+func XPForLevel(level int) int {
+	return 100 * level
+}
+
+func AddUnitXP(em *common.EntityManager, unitID ecs.EntityID, xp int) {
+
+	unit := FindUnitByID(em, unitID)
+	if unit == nil {
+		return
+	}
+
+	if data := GetUnitLevelData(unit); data != nil {
+		data.XP += xp
+		em.NotifyChanged(common.SquadsChanged)
+	}
+
+}
+
+// CheckLevelUp consumes banked XP and levels the unit up as many times as it
+// can afford, applying the growth scaling each level. Returns levels gained
+func CheckLevelUp(em *common.EntityManager, unitID ecs.EntityID) int {
+
+	unit := FindUnitByID(em, unitID)
+	if unit == nil {
+		return 0
+	}
+
+	data := GetUnitLevelData(unit)
+	if data == nil {
+		return 0
+	}
+
+	attr := common.GetAttributes(unit)
+
+	gained := 0
+	for data.XP >= XPForLevel(data.Level) {
+
+		data.XP -= XPForLevel(data.Level)
+		data.Level++
+		growAttributes(attr, data.Level)
+		gained++
+
+	}
+
+	if gained > 0 {
+		RefreshWoundedState(unit)
+	}
+
+	return gained
+}
+
+// One level worth of growth. Health scales with the units bulk so a knight
+// gains more than a rogue, protection only ticks up on even levels
+func growAttributes(attr *common.Attributes, newLevel int) {
+
+	healthGain := attr.MaxHealth / 10
+	if healthGain < 1 {
+		healthGain = 1
+	}
+
+	attr.MaxHealth += healthGain
+	attr.CurrentHealth += healthGain
+	attr.AttackBonus++
+
+	if newLevel%2 == 0 {
+		attr.BaseProtection++
+		attr.TotalProtection++
+	}
+
+}
+
+// PreviewGrowth returns what the units attributes would become after the
+// given number of level-ups without applying anything, so designers can plan
+// progression. Zero levels just returns the current attributes
+func PreviewGrowth(unitID ecs.EntityID, levels int, manager *common.EntityManager) common.Attributes {
+
+	unit := FindUnitByID(manager, unitID)
+	if unit == nil {
+		return common.Attributes{}
+	}
+
+	preview := *common.GetAttributes(unit)
+
+	level := 1
+	if data := GetUnitLevelData(unit); data != nil {
+		level = data.Level
+	}
+
+	for i := 1; i <= levels; i++ {
+		growAttributes(&preview, level+i)
+	}
+
+	return preview
+}
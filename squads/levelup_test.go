@@ -0,0 +1,65 @@
+package squads
+
+import (
+	"game_main/common"
+	"testing"
+)
+
+func TestPreviewGrowthRaisesStats(t *testing.T) {
+
+	em := newTestManager()
+	registerTestTemplates()
+
+	squad := CreateSquad(em, "Levelers", "Player", common.Position{X: 0, Y: 0}, DirUp)
+	unit := CreateUnitFromTemplate(em, Units["TestKnight"], squad.SquadID, 0, 0)
+
+	current := *common.GetAttributes(unit)
+	preview := PreviewGrowth(unit.GetID(), 3, em)
+
+	if preview.MaxHealth <= current.MaxHealth {
+		t.Errorf("expected more health after 3 levels, got %d vs %d", preview.MaxHealth, current.MaxHealth)
+	}
+	if preview.AttackBonus != current.AttackBonus+3 {
+		t.Errorf("expected one attack bonus per level, got %d vs %d", preview.AttackBonus, current.AttackBonus)
+	}
+
+	//The preview must not touch the unit itself
+	if *common.GetAttributes(unit) != current {
+		t.Error("previewing growth changed the units real attributes")
+	}
+}
+
+func TestPreviewGrowthZeroLevelsIsIdentity(t *testing.T) {
+
+	em := newTestManager()
+	registerTestTemplates()
+
+	squad := CreateSquad(em, "Levelers", "Player", common.Position{X: 0, Y: 0}, DirUp)
+	unit := CreateUnitFromTemplate(em, Units["TestKnight"], squad.SquadID, 0, 0)
+
+	if PreviewGrowth(unit.GetID(), 0, em) != *common.GetAttributes(unit) {
+		t.Error("previewing 0 levels should return the current attributes unchanged")
+	}
+}
+
+// The preview and the real level-up must use the same scaling
+func TestPreviewMatchesCheckLevelUp(t *testing.T) {
+
+	em := newTestManager()
+	registerTestTemplates()
+
+	squad := CreateSquad(em, "Levelers", "Player", common.Position{X: 0, Y: 0}, DirUp)
+	unit := CreateUnitFromTemplate(em, Units["TestKnight"], squad.SquadID, 0, 0)
+
+	preview := PreviewGrowth(unit.GetID(), 2, em)
+
+	//Enough XP for level 1->2 and 2->3
+	AddUnitXP(em, unit.GetID(), XPForLevel(1)+XPForLevel(2))
+	if gained := CheckLevelUp(em, unit.GetID()); gained != 2 {
+		t.Fatalf("expected 2 level-ups, got %d", gained)
+	}
+
+	if *common.GetAttributes(unit) != preview {
+		t.Errorf("the applied level-ups diverged from the preview: %+v vs %+v", *common.GetAttributes(unit), preview)
+	}
+}
@@ -0,0 +1,211 @@
+package squads
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// A battle generator killed mid-write leaves zero-byte and truncated JSON in
+// the log directory. Instead of failing the whole run on the first bad file,
+// LoadBattleRecords classifies every failure, keeps going, and hands back a
+// LoadReport so the tooling can say exactly how much of the dataset it lost
+
+type LoadFailureCategory string
+
+const (
+	FailureEmpty     LoadFailureCategory = "empty"
+	FailureTruncated LoadFailureCategory = "truncated"
+	FailureSchema    LoadFailureCategory = "schema"
+	FailureInvalid   LoadFailureCategory = "invalid"
+)
+
+type LoadFailure struct {
+	Path     string
+	Category LoadFailureCategory
+	Err      error
+}
+
+type LoadReport struct {
+	Loaded   int
+	Failures []LoadFailure
+}
+
+func (r *LoadReport) Skipped() int {
+	return len(r.Failures)
+}
+
+// Fraction of the dataset that was dropped, 0 to 1
+func (r *LoadReport) SkippedFraction() float64 {
+
+	total := r.Loaded + r.Skipped()
+	if total == 0 {
+		return 0
+	}
+
+	return float64(r.Skipped()) / float64(total)
+}
+
+func (r *LoadReport) CountByCategory() map[LoadFailureCategory]int {
+
+	counts := make(map[LoadFailureCategory]int)
+	for _, f := range r.Failures {
+		counts[f.Category]++
+	}
+
+	return counts
+}
+
+// ExceedsThreshold is the strict-mode check: true when the skipped fraction
+// is over the given threshold
+func (r *LoadReport) ExceedsThreshold(threshold float64) bool {
+	return r.Skipped() > 0 && r.SkippedFraction() > threshold
+}
+
+// Summary is the human-readable report printed after loading: counts per
+// category, the first few offending filenames, and how much of the dataset
+// got skipped
+func (r *LoadReport) Summary() string {
+
+	if r.Skipped() == 0 {
+		return fmt.Sprintf("loaded %d battle records, no failures", r.Loaded)
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "loaded %d battle records, skipped %d (%.0f%% of the dataset)\n",
+		r.Loaded, r.Skipped(), r.SkippedFraction()*100)
+
+	for _, category := range []LoadFailureCategory{FailureEmpty, FailureTruncated, FailureSchema, FailureInvalid} {
+
+		names := []string{}
+		for _, f := range r.Failures {
+			if f.Category == category {
+				names = append(names, filepath.Base(f.Path))
+			}
+		}
+
+		if len(names) == 0 {
+			continue
+		}
+
+		shown := names
+		if len(shown) > 3 {
+			shown = shown[:3]
+		}
+
+		fmt.Fprintf(&sb, "  %s: %d (%s", category, len(names), strings.Join(shown, ", "))
+		if len(names) > len(shown) {
+			fmt.Fprintf(&sb, ", ...")
+		}
+		fmt.Fprintf(&sb, ")\n")
+
+	}
+
+	return strings.TrimRight(sb.String(), "\n")
+}
+
+// ValidateBattleRecord is the semantic pass over a record that parsed fine.
+// It checks what the schema can express: no negative damage, dodged attacks
+// that still dealt damage, a negative round count, or a winner that is
+// neither of the two squads
+func ValidateBattleRecord(record *BattleRecord) error {
+
+	if record.Rounds < 0 {
+		return fmt.Errorf("negative round count %d", record.Rounds)
+	}
+
+	if record.WinnerSquadID != 0 &&
+		record.WinnerSquadID != record.AttackerSquadID &&
+		record.WinnerSquadID != record.DefenderSquadID {
+		return fmt.Errorf("winner %d is neither squad in the battle", record.WinnerSquadID)
+	}
+
+	for i, e := range record.Events {
+
+		if e.Damage < 0 {
+			return fmt.Errorf("event %d has negative damage %d", i, e.Damage)
+		}
+
+		if e.Dodged && e.Damage != 0 {
+			return fmt.Errorf("event %d was dodged but dealt %d damage", i, e.Damage)
+		}
+
+	}
+
+	return nil
+}
+
+// LoadBattleRecords loads every battle record in dir, classifying failures
+// instead of stopping on them. When quarantineDir is non-empty the bad files
+// are moved there so repeated runs stay clean
+func LoadBattleRecords(dir string, quarantineDir string) ([]*BattleRecord, LoadReport, error) {
+
+	paths, err := FindAllBattles(dir)
+	if err != nil {
+		return nil, LoadReport{}, err
+	}
+
+	records := []*BattleRecord{}
+	report := LoadReport{}
+
+	for _, path := range paths {
+
+		record, failure := loadAndClassify(path)
+		if failure == nil {
+			records = append(records, record)
+			report.Loaded++
+			continue
+		}
+
+		report.Failures = append(report.Failures, *failure)
+
+		if quarantineDir != "" {
+
+			if err := os.MkdirAll(quarantineDir, 0755); err != nil {
+				return records, report, err
+			}
+			if err := os.Rename(path, filepath.Join(quarantineDir, filepath.Base(path))); err != nil {
+				return records, report, err
+			}
+
+		}
+
+	}
+
+	return records, report, nil
+}
+
+func loadAndClassify(path string) (*BattleRecord, *LoadFailure) {
+
+	contents, err := os.ReadFile(path)
+	if err != nil {
+		return nil, &LoadFailure{Path: path, Category: FailureTruncated, Err: err}
+	}
+
+	if len(contents) == 0 {
+		return nil, &LoadFailure{Path: path, Category: FailureEmpty, Err: errors.New("zero-byte file")}
+	}
+
+	record := &BattleRecord{}
+	if err := json.Unmarshal(contents, record); err != nil {
+
+		//A type error means the JSON was whole but the shape was wrong.
+		//Anything else is a cut-off or mangled file
+		var typeErr *json.UnmarshalTypeError
+		if errors.As(err, &typeErr) {
+			return nil, &LoadFailure{Path: path, Category: FailureSchema, Err: err}
+		}
+
+		return nil, &LoadFailure{Path: path, Category: FailureTruncated, Err: err}
+
+	}
+
+	if err := ValidateBattleRecord(record); err != nil {
+		return nil, &LoadFailure{Path: path, Category: FailureInvalid, Err: err}
+	}
+
+	return record, nil
+}
@@ -0,0 +1,125 @@
+package squads
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// One good, one empty, one truncated, and one semantically invalid file.
+// Loading classifies each failure and keeps the good record
+func corruptedLogDir(t *testing.T) string {
+
+	t.Helper()
+	dir := t.TempDir()
+
+	good := `{"attackerSquadID": 1, "defenderSquadID": 2, "winnerSquadID": 1, "rounds": 3, "unitsLost": {}}`
+	invalid := `{"attackerSquadID": 1, "defenderSquadID": 2, "winnerSquadID": 1, "rounds": 3,
+		"events": [{"attackerID": 1, "defenderID": 2, "damage": -5}], "unitsLost": {}}`
+
+	files := map[string]string{
+		"battle_1_vs_2_100.json": good,
+		"battle_1_vs_2_200.json": "",
+		"battle_1_vs_2_300.json": `{"attackerSquadID": 1, "defen`,
+		"battle_1_vs_2_400.json": invalid,
+	}
+
+	for name, contents := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(contents), 0644); err != nil {
+			t.Fatalf("could not build the log directory: %v", err)
+		}
+	}
+
+	return dir
+}
+
+func TestLoadReportClassifiesFailures(t *testing.T) {
+
+	dir := corruptedLogDir(t)
+
+	records, report, err := LoadBattleRecords(dir, "")
+	if err != nil {
+		t.Fatalf("loading should survive bad files, got %v", err)
+	}
+
+	if len(records) != 1 || report.Loaded != 1 {
+		t.Fatalf("expected the one good record to load, got %d", len(records))
+	}
+
+	counts := report.CountByCategory()
+	for _, category := range []LoadFailureCategory{FailureEmpty, FailureTruncated, FailureInvalid} {
+		if counts[category] != 1 {
+			t.Errorf("expected one %s failure, got %d", category, counts[category])
+		}
+	}
+
+	summary := report.Summary()
+	if !strings.Contains(summary, "skipped 3 (75% of the dataset)") {
+		t.Errorf("the summary should state how much of the dataset was dropped, got %q", summary)
+	}
+}
+
+// Strict mode trips when the skipped fraction is over the threshold
+func TestStrictThreshold(t *testing.T) {
+
+	dir := corruptedLogDir(t)
+
+	_, report, err := LoadBattleRecords(dir, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !report.ExceedsThreshold(0.1) {
+		t.Error("75% skipped should trip a 10% threshold")
+	}
+	if report.ExceedsThreshold(0.9) {
+		t.Error("75% skipped should pass a 90% threshold")
+	}
+}
+
+// Quarantine moves the bad files aside so the next run only sees good ones
+func TestQuarantineKeepsRepeatRunsClean(t *testing.T) {
+
+	dir := corruptedLogDir(t)
+	quarantine := filepath.Join(dir, "quarantine")
+
+	if _, _, err := LoadBattleRecords(dir, quarantine); err != nil {
+		t.Fatal(err)
+	}
+
+	moved, _ := filepath.Glob(filepath.Join(quarantine, "battle_*.json"))
+	if len(moved) != 3 {
+		t.Errorf("expected 3 files in quarantine, got %d", len(moved))
+	}
+
+	_, report, err := LoadBattleRecords(dir, quarantine)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Loaded != 1 || report.Skipped() != 0 {
+		t.Errorf("the second run should see only the good file, got %+v", report)
+	}
+}
+
+// The semantic pass rejects records that parsed but cannot have happened
+func TestValidateBattleRecord(t *testing.T) {
+
+	good := &BattleRecord{AttackerSquadID: 1, DefenderSquadID: 2, WinnerSquadID: 1, Rounds: 2}
+	if err := ValidateBattleRecord(good); err != nil {
+		t.Errorf("a sane record should validate, got %v", err)
+	}
+
+	badWinner := &BattleRecord{AttackerSquadID: 1, DefenderSquadID: 2, WinnerSquadID: 7}
+	if err := ValidateBattleRecord(badWinner); err == nil {
+		t.Error("a winner that fought in neither squad should fail validation")
+	}
+
+	dodgedWithDamage := &BattleRecord{
+		AttackerSquadID: 1, DefenderSquadID: 2,
+		Events: []AttackEvent{{Dodged: true, Damage: 4}},
+	}
+	if err := ValidateBattleRecord(dodgedWithDamage); err == nil {
+		t.Error("a dodged attack that dealt damage should fail validation")
+	}
+}
@@ -0,0 +1,135 @@
+package squads
+
+import "github.com/bytearena/ecs"
+
+// Combat momentum: the cumulative net damage differential round by round.
+// It shows which side was winning at every point of a long battle, which the
+// final outcome and the raw event list both hide. Derived from the events,
+// so old battle logs get it for free
+
+// MomentumPoint is one rounds entry in the momentum curve
+type MomentumPoint struct {
+	Round int
+
+	//Damage each side dealt this round
+	AttackerDamage int
+	DefenderDamage int
+
+	//Cumulative net damage through this round. Positive means the attacker
+	//is ahead, negative the defender
+	Momentum int
+}
+
+// MomentumCurve computes the per-round momentum of the battle. Events whose
+// units joined after the opening snapshots (reinforcements) attribute by
+// whose side they hit instead
+func (r *BattleRecord) MomentumCurve() []MomentumPoint {
+
+	attackerUnits := make(map[ecs.EntityID]bool)
+	defenderUnits := make(map[ecs.EntityID]bool)
+	for _, u := range r.Attacker.Units {
+		attackerUnits[u.EntityID] = true
+	}
+	for _, u := range r.Defender.Units {
+		defenderUnits[u.EntityID] = true
+	}
+
+	perRound := make(map[int]*MomentumPoint)
+	lastRound := 0
+
+	for _, e := range r.Events {
+
+		attackerDealt, known := sideOfAttack(e, attackerUnits, defenderUnits)
+		if !known {
+			continue
+		}
+
+		point := perRound[e.Round]
+		if point == nil {
+			point = &MomentumPoint{Round: e.Round}
+			perRound[e.Round] = point
+		}
+		if e.Round > lastRound {
+			lastRound = e.Round
+		}
+
+		if attackerDealt {
+			point.AttackerDamage += e.Damage
+		} else {
+			point.DefenderDamage += e.Damage
+		}
+
+	}
+
+	//Every round gets a point, including quiet ones, so the curve lines up
+	//with the round axis
+	curve := []MomentumPoint{}
+	momentum := 0
+	for round := 1; round <= lastRound; round++ {
+
+		point := MomentumPoint{Round: round}
+		if p := perRound[round]; p != nil {
+			point = *p
+		}
+
+		momentum += point.AttackerDamage - point.DefenderDamage
+		point.Momentum = momentum
+		curve = append(curve, point)
+
+	}
+
+	return curve
+}
+
+// BuildDamageOverTime flattens the battle into one cumulative damage series
+// per squad, keyed by squad name, ready for plotting: index i is the total
+// damage the squad had dealt through round i+1. Both series span every round
+// of the battle, so the final value is the squads total damage output
+func BuildDamageOverTime(record *BattleRecord) map[string][]int {
+
+	curve := record.MomentumCurve()
+
+	rounds := record.Rounds
+	if rounds < len(curve) {
+		rounds = len(curve)
+	}
+
+	attacker := make([]int, 0, rounds)
+	defender := make([]int, 0, rounds)
+
+	attackerTotal, defenderTotal := 0, 0
+	for round := 1; round <= rounds; round++ {
+
+		if round <= len(curve) {
+			attackerTotal += curve[round-1].AttackerDamage
+			defenderTotal += curve[round-1].DefenderDamage
+		}
+
+		attacker = append(attacker, attackerTotal)
+		defender = append(defender, defenderTotal)
+
+	}
+
+	return map[string][]int{
+		record.Attacker.Name: attacker,
+		record.Defender.Name: defender,
+	}
+}
+
+// Whether the attacking side of the battle dealt this event. The attacker
+// unit decides when the snapshots know it; otherwise the defender unit does
+func sideOfAttack(e AttackEvent, attackerUnits map[ecs.EntityID]bool, defenderUnits map[ecs.EntityID]bool) (bool, bool) {
+
+	switch {
+	case attackerUnits[e.AttackerID]:
+		return true, true
+	case defenderUnits[e.AttackerID]:
+		return false, true
+	case defenderUnits[e.DefenderID]:
+		return true, true
+	case attackerUnits[e.DefenderID]:
+		return false, true
+	}
+
+	return false, false
+}
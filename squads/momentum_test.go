@@ -0,0 +1,121 @@
+package squads
+
+import "testing"
+
+// A record where the attacker dominates the opening rounds and then
+// collapses: unit 1 fights for the attacker, unit 2 for the defender
+func momentumFixtureRecord() *BattleRecord {
+
+	record := NewBattleRecord(10, 20)
+	record.Attacker.Units = []UnitSnapshot{{EntityID: 1}}
+	record.Defender.Units = []UnitSnapshot{{EntityID: 2}}
+
+	events := []AttackEvent{
+		{Round: 1, AttackerID: 1, DefenderID: 2, Damage: 8},
+		{Round: 1, AttackerID: 2, DefenderID: 1, Damage: 2},
+		{Round: 2, AttackerID: 1, DefenderID: 2, Damage: 6},
+		{Round: 2, AttackerID: 2, DefenderID: 1, Damage: 3},
+		{Round: 3, AttackerID: 2, DefenderID: 1, Damage: 12},
+		{Round: 4, AttackerID: 2, DefenderID: 1, Damage: 9},
+	}
+	for _, e := range events {
+		record.RecordEvent(e)
+	}
+
+	return record
+}
+
+// Early dominance then collapse shows as momentum crossing from positive to
+// negative across the rounds
+func TestMomentumShiftsSign(t *testing.T) {
+
+	curve := momentumFixtureRecord().MomentumCurve()
+	if len(curve) != 4 {
+		t.Fatalf("expected one point per round, got %d", len(curve))
+	}
+
+	want := []int{6, 9, -3, -12}
+	for i, point := range curve {
+
+		if point.Round != i+1 {
+			t.Errorf("point %d should be round %d, got %d", i, i+1, point.Round)
+		}
+		if point.Momentum != want[i] {
+			t.Errorf("round %d momentum should be %d, got %d", point.Round, want[i], point.Momentum)
+		}
+
+	}
+
+	if curve[1].AttackerDamage != 6 || curve[1].DefenderDamage != 3 {
+		t.Errorf("round 2 should split 6 attacker / 3 defender, got %d/%d",
+			curve[1].AttackerDamage, curve[1].DefenderDamage)
+	}
+}
+
+// A quiet round still gets a point, holding the running momentum, so the
+// curve lines up with the round axis
+func TestMomentumCoversQuietRounds(t *testing.T) {
+
+	record := momentumFixtureRecord()
+	record.RecordEvent(AttackEvent{Round: 6, AttackerID: 1, DefenderID: 2, Damage: 5})
+
+	curve := record.MomentumCurve()
+	if len(curve) != 6 {
+		t.Fatalf("expected 6 points through the last active round, got %d", len(curve))
+	}
+
+	if curve[4].Momentum != -12 || curve[4].AttackerDamage != 0 {
+		t.Errorf("the quiet round 5 should carry the momentum unchanged, got %+v", curve[4])
+	}
+	if curve[5].Momentum != -7 {
+		t.Errorf("round 6 should move the momentum to -7, got %d", curve[5].Momentum)
+	}
+}
+
+// Each squads damage-over-time series covers every round and ends at the
+// squads total damage dealt
+func TestDamageOverTimeSeries(t *testing.T) {
+
+	record := momentumFixtureRecord()
+	record.Attacker.Name = "Raiders"
+	record.Defender.Name = "Garrison"
+	record.Rounds = 5 //the battle ran one quiet round past the last hit
+
+	series := BuildDamageOverTime(record)
+
+	raiders, garrison := series["Raiders"], series["Garrison"]
+	if len(raiders) != 5 || len(garrison) != 5 {
+		t.Fatalf("both series should span all 5 rounds, got %d and %d", len(raiders), len(garrison))
+	}
+
+	//The attacker dealt 8+6=14 in total, the defender 2+3+12+9=26
+	if raiders[4] != 14 {
+		t.Errorf("the raiders series should end at their total of 14, got %d", raiders[4])
+	}
+	if garrison[4] != 26 {
+		t.Errorf("the garrison series should end at their total of 26, got %d", garrison[4])
+	}
+
+	wantRaiders := []int{8, 14, 14, 14, 14}
+	for i, got := range raiders {
+		if got != wantRaiders[i] {
+			t.Errorf("raiders round %d should read %d cumulative, got %d", i+1, wantRaiders[i], got)
+		}
+	}
+
+}
+
+// Units missing from the opening snapshots (reinforcements) attribute by the
+// side they hit
+func TestMomentumAttributesReinforcements(t *testing.T) {
+
+	record := momentumFixtureRecord()
+
+	//Unit 99 joined mid-battle and hits the defenders unit 2
+	record.RecordEvent(AttackEvent{Round: 5, AttackerID: 99, DefenderID: 2, Damage: 4})
+
+	curve := record.MomentumCurve()
+	if curve[4].AttackerDamage != 4 {
+		t.Errorf("the reinforcement hit should count for the attacker, got %+v", curve[4])
+	}
+}
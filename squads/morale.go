@@ -0,0 +1,51 @@
+package squads
+
+import "game_main/common"
+
+// Morale recovery between battles. Rally abilities and drinks raise morale
+// mid-fight; this is the slower campaign-side mend, so a squad that got
+// shaken two battles ago is not demoralized forever
+
+const MoraleCap = 100
+
+// MoraleConfig holds the campaign-side morale rules
+type MoraleConfig struct {
+	//How much morale a squad naturally regains after each battle in a
+	//campaign loop. Zero means no natural recovery
+	RecoveryPerBattle int
+}
+
+var Morale = MoraleConfig{RecoveryPerBattle: 10}
+
+// RecoverMorale raises the squads morale by amount, clamped to the cap, and
+// clears the shaken state once morale is back at full. Returns how much was
+// actually regained
+func RecoverMorale(em *common.EntityManager, squadID int, amount int) int {
+
+	squad := GetSquadByID(em, squadID)
+	if squad == nil || amount <= 0 {
+		return 0
+	}
+
+	before := squad.Morale
+	squad.Morale += amount
+	if squad.Morale > MoraleCap {
+		squad.Morale = MoraleCap
+	}
+
+	if squad.Morale >= MoraleCap {
+		squad.Shaken = false
+	}
+
+	return squad.Morale - before
+}
+
+// RecoverMoraleBetweenBattles applies the configured natural recovery to
+// every squad. A campaign loop calls it after each battle resolves
+func RecoverMoraleBetweenBattles(em *common.EntityManager) {
+
+	for _, s := range em.World.Query(em.WorldTags["squads"]) {
+		RecoverMorale(em, GetSquadData(s.Entity).SquadID, Morale.RecoveryPerBattle)
+	}
+
+}
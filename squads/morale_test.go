@@ -0,0 +1,81 @@
+package squads
+
+import (
+	"game_main/common"
+	"testing"
+)
+
+// A battered squad regains the configured amount, never past the cap
+func TestMoraleRecoversUpToTheCap(t *testing.T) {
+
+	em := newTestManager()
+	registerTestTemplates()
+
+	squad := CreateSquad(em, "Weary", "Player", common.Position{X: 1, Y: 1}, DirDown)
+	squad.Morale = 40
+
+	if got := RecoverMorale(em, squad.SquadID, 25); got != 25 {
+		t.Errorf("the squad should regain the full 25, got %d", got)
+	}
+	if squad.Morale != 65 {
+		t.Errorf("morale should land at 65, got %d", squad.Morale)
+	}
+
+	if got := RecoverMorale(em, squad.SquadID, 50); got != 35 {
+		t.Errorf("recovery should clamp at the cap and only regain 35, got %d", got)
+	}
+	if squad.Morale != MoraleCap {
+		t.Errorf("morale should sit at the cap, got %d", squad.Morale)
+	}
+
+}
+
+// A full-morale squad stays at 100, and reaching the cap clears shaken
+func TestFullMoraleStaysPut(t *testing.T) {
+
+	em := newTestManager()
+	registerTestTemplates()
+
+	squad := CreateSquad(em, "Steady", "Player", common.Position{X: 1, Y: 1}, DirDown)
+	squad.Morale = MoraleCap
+
+	if got := RecoverMorale(em, squad.SquadID, 10); got != 0 {
+		t.Errorf("a squad already at the cap should regain nothing, got %d", got)
+	}
+	if squad.Morale != MoraleCap {
+		t.Errorf("morale should stay at 100, got %d", squad.Morale)
+	}
+
+	shaken := CreateSquad(em, "Rattled", "Player", common.Position{X: 2, Y: 1}, DirDown)
+	shaken.Morale = 95
+	shaken.Shaken = true
+
+	RecoverMorale(em, shaken.SquadID, Morale.RecoveryPerBattle)
+	if shaken.Morale != MoraleCap || shaken.Shaken {
+		t.Errorf("topping out should clear shaken, got morale %d shaken %v", shaken.Morale, shaken.Shaken)
+	}
+
+}
+
+// The campaign-loop helper touches every squad with the configured amount
+func TestNaturalRecoveryBetweenBattles(t *testing.T) {
+
+	em := newTestManager()
+	registerTestTemplates()
+
+	first := CreateSquad(em, "First", "Player", common.Position{X: 1, Y: 1}, DirDown)
+	second := CreateSquad(em, "Second", "Enemy", common.Position{X: 5, Y: 5}, DirUp)
+	first.Morale = 40
+	second.Morale = 70
+
+	restore := Morale
+	Morale.RecoveryPerBattle = 15
+	defer func() { Morale = restore }()
+
+	RecoverMoraleBetweenBattles(em)
+
+	if first.Morale != 55 || second.Morale != 85 {
+		t.Errorf("both squads should regain 15, got %d and %d", first.Morale, second.Morale)
+	}
+
+}
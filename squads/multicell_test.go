@@ -0,0 +1,92 @@
+package squads
+
+import (
+	"game_main/common"
+	"testing"
+)
+
+// A 2x2 shelled beast behind a shieldman: the beast anchors at (1,1) and
+// fills rows 1-2 of columns 1-2, the shieldman stands at (0,1)
+func multiCellFixture() (*common.EntityManager, *SquadData, *SquadData) {
+
+	em := newTestManager()
+	registerTestTemplates()
+
+	Units["TestBeast"] = UnitTemplate{
+		Name:       "TestBeast",
+		Class:      "Knight",
+		Attr:       common.NewBaseAttributes(40, 0, 10, 0, 1, 0),
+		MinDamage:  4,
+		MaxDamage:  4,
+		CoverValue: 2,
+	}
+
+	att := CreateSquad(em, "Attackers", "Player", common.Position{X: 5, Y: 3}, DirDown)
+	CreateUnitFromTemplate(em, Units["TestKnight"], att.SquadID, 0, 0)
+
+	def := CreateSquad(em, "Defenders", "Enemy", common.Position{X: 5, Y: 5}, DirUp)
+	CreateUnitFromTemplate(em, Units["TestKnight"], def.SquadID, 0, 1)
+
+	beast := CreateUnitFromTemplate(em, Units["TestBeast"], def.SquadID, 1, 1)
+	GetUnitData(beast).Width = 2
+	GetUnitData(beast).Height = 2
+
+	return em, att, def
+}
+
+// The footprint, not the anchor, decides what an attack shape covers: a rear
+// row attack finds the beast even though it anchors a row further forward,
+// and hits it exactly once
+func TestAttackShapesMatchTheFootprint(t *testing.T) {
+
+	em, _, def := multiCellFixture()
+
+	info := SelectTargets(em, def.SquadID, TargetRowData{Mode: TargetRow, Index: 2})
+	if len(info.TargetIDs) != 1 {
+		t.Fatalf("the rear row attack should hit the beast exactly once, got %d targets", len(info.TargetIDs))
+	}
+
+	if cell := info.HitCells[info.TargetIDs[0]]; cell != [2]int{2, 1} {
+		t.Errorf("the struck cell should be the rear one, got %v", cell)
+	}
+}
+
+// With hit-cell modifiers on, the rear cell of the beast sits behind both
+// the shieldman and the beasts own front half, so it gets more cover than
+// the front cell
+func TestRearCellGetsMoreCoverThanTheFrontCell(t *testing.T) {
+
+	em, att, def := multiCellFixture()
+
+	restore := Targeting
+	Targeting.ModifiersUseHitCell = true
+	defer func() { Targeting = restore }()
+
+	attacker := PreviewSquadAttack(em, att.SquadID)[0]
+
+	front, _ := ResolveRowAttack(em, attacker, def.SquadID, TargetRowData{Mode: TargetRow, Index: 1}, DirDown)
+	rear, _ := ResolveRowAttack(em, attacker, def.SquadID, TargetRowData{Mode: TargetRow, Index: 2}, DirDown)
+
+	if front[0].CoverReduction != 3 {
+		t.Errorf("the front cell should only get the shieldmans cover 3, got %d", front[0].CoverReduction)
+	}
+	if rear[0].CoverReduction != 5 {
+		t.Errorf("the rear cell should add the beasts own front half, got cover %d", rear[0].CoverReduction)
+	}
+}
+
+// Off, both cells evaluate at the anchor, the historical behavior
+func TestAnchorModifiersAreTheDefault(t *testing.T) {
+
+	em, att, def := multiCellFixture()
+
+	attacker := PreviewSquadAttack(em, att.SquadID)[0]
+
+	front, _ := ResolveRowAttack(em, attacker, def.SquadID, TargetRowData{Mode: TargetRow, Index: 1}, DirDown)
+	rear, _ := ResolveRowAttack(em, attacker, def.SquadID, TargetRowData{Mode: TargetRow, Index: 2}, DirDown)
+
+	if front[0].CoverReduction != 3 || rear[0].CoverReduction != 3 {
+		t.Errorf("anchor mode should give both cells the anchors cover 3, got %d and %d",
+			front[0].CoverReduction, rear[0].CoverReduction)
+	}
+}
@@ -0,0 +1,244 @@
+package squads
+
+import (
+	"fmt"
+	"game_main/common"
+	"sort"
+
+	"github.com/bytearena/ecs"
+)
+
+// Map objectives turn battles into more than annihilation: designated tiles
+// (a supply cache, a ritual circle, a bridge) that a squad captures by
+// ending its turn on them. Control has to be held for a number of
+// consecutive rounds to count, and an enemy squad adjacent to the tile
+// contests it, pausing the counter until the area is cleared
+
+var ObjectiveComponent *ecs.Component
+
+type ObjectiveData struct {
+	Name string
+	Pos  common.Position
+
+	//Consecutive uncontested rounds of control needed before this
+	//objective counts towards victory
+	HoldRequired int
+
+	//Faction currently controlling the tile, empty while unclaimed
+	Controller string
+
+	//Consecutive uncontested rounds the controller has held it
+	HoldRounds int
+
+	//True while an enemy squad stands next to the tile
+	Contested bool
+}
+
+// CreateObjective places an objective tile on the map
+func CreateObjective(em *common.EntityManager, name string, pos common.Position, holdRequired int) *ObjectiveData {
+
+	if holdRequired < 1 {
+		holdRequired = 1
+	}
+
+	data := &ObjectiveData{Name: name, Pos: pos, HoldRequired: holdRequired}
+	em.World.NewEntity().
+		AddComponent(ObjectiveComponent, data).
+		AddComponent(common.PositionComponent, &common.Position{X: pos.X, Y: pos.Y})
+
+	return data
+}
+
+// Objectives lists the maps objectives, sorted by name so HUD lines and
+// record snapshots stay deterministic
+func Objectives(em *common.EntityManager) []*ObjectiveData {
+
+	objectives := []*ObjectiveData{}
+	for _, result := range em.World.Query(em.WorldTags["objectives"]) {
+		objectives = append(objectives, common.GetComponentType[*ObjectiveData](result.Entity, ObjectiveComponent))
+	}
+
+	sort.Slice(objectives, func(i, j int) bool {
+		return objectives[i].Name < objectives[j].Name
+	})
+
+	return objectives
+}
+
+// ResolveObjectives runs the capture and contest rules for one round. The
+// turn manager calls it after every faction has acted: a squad standing on
+// the tile captures it, an adjacent enemy contests it, and an uncontested
+// held round advances the counter
+func ResolveObjectives(em *common.EntityManager) {
+
+	for _, objective := range Objectives(em) {
+
+		occupier := factionOnTile(em, objective.Pos)
+
+		if occupier != "" && occupier != objective.Controller {
+			objective.Controller = occupier
+			objective.HoldRounds = 0
+		}
+
+		if objective.Controller == "" {
+			objective.Contested = false
+			continue
+		}
+
+		objective.Contested = enemyAdjacent(em, objective.Pos, objective.Controller)
+		if !objective.Contested {
+			objective.HoldRounds++
+		}
+
+	}
+
+}
+
+// ObjectiveVictor is the faction that holds more than half of the
+// objectives, each for its required rounds. Empty while nobody does
+func ObjectiveVictor(em *common.EntityManager) string {
+
+	objectives := Objectives(em)
+	if len(objectives) == 0 {
+		return ""
+	}
+
+	held := map[string]int{}
+	for _, objective := range objectives {
+		if objective.Controller != "" && objective.HoldRounds >= objective.HoldRequired {
+			held[objective.Controller]++
+		}
+	}
+
+	for faction, count := range held {
+		if count*2 > len(objectives) {
+			return faction
+		}
+	}
+
+	return ""
+}
+
+// ObjectiveStatusLines is the HUD view: one line per objective with its
+// controller and the hold counter
+func ObjectiveStatusLines(em *common.EntityManager) []string {
+
+	lines := []string{}
+	for _, objective := range Objectives(em) {
+
+		switch {
+		case objective.Controller == "":
+			lines = append(lines, fmt.Sprintf("%s: unclaimed", objective.Name))
+		case objective.Contested:
+			lines = append(lines, fmt.Sprintf("%s: held by %s, contested (%d/%d rounds)",
+				objective.Name, objective.Controller, objective.HoldRounds, objective.HoldRequired))
+		default:
+			lines = append(lines, fmt.Sprintf("%s: held by %s (%d/%d rounds)",
+				objective.Name, objective.Controller, objective.HoldRounds, objective.HoldRequired))
+		}
+
+	}
+
+	return lines
+}
+
+// ObjectiveMoveTarget is the movement AIs objective weighting: head for the
+// nearest objective the squads faction does not safely hold, and fall back
+// to defending the nearest held one. False when the map has no objectives
+func ObjectiveMoveTarget(em *common.EntityManager, squadID int) (common.Position, bool) {
+
+	squad := GetSquadByID(em, squadID)
+	if squad == nil {
+		return common.Position{}, false
+	}
+
+	var best *ObjectiveData
+	bestDistance := 0
+
+	//Objectives someone else holds, or nobody does, come first
+	for _, pass := range []bool{true, false} {
+		for _, objective := range Objectives(em) {
+
+			wanted := objective.Controller != squad.Faction
+			if wanted != pass {
+				continue
+			}
+
+			distance := squad.MapPos.SquadDistance(&objective.Pos)
+			if best == nil || distance < bestDistance {
+				best = objective
+				bestDistance = distance
+			}
+
+		}
+
+		if best != nil {
+			break
+		}
+	}
+
+	if best == nil {
+		return common.Position{}, false
+	}
+
+	return best.Pos, true
+}
+
+func factionOnTile(em *common.EntityManager, pos common.Position) string {
+
+	for _, result := range em.World.Query(em.WorldTags["squads"]) {
+
+		squad := GetSquadData(result.Entity)
+		if squad.MapPos == pos && squadHasLivingUnits(em, squad.SquadID) {
+			return squad.Faction
+		}
+
+	}
+
+	return ""
+}
+
+func enemyAdjacent(em *common.EntityManager, pos common.Position, faction string) bool {
+
+	for _, result := range em.World.Query(em.WorldTags["squads"]) {
+
+		squad := GetSquadData(result.Entity)
+		if squad.Faction == faction || !squadHasLivingUnits(em, squad.SquadID) {
+			continue
+		}
+
+		if squad.MapPos != pos && squad.MapPos.SquadDistance(&pos) <= 1 {
+			return true
+		}
+
+	}
+
+	return false
+}
+
+// ObjectiveState is the snapshot of one objective for the battle record
+type ObjectiveState struct {
+	Name       string `json:"name"`
+	Controller string `json:"controller,omitempty"`
+	HoldRounds int    `json:"holdRounds"`
+	Required   int    `json:"required"`
+	Contested  bool   `json:"contested,omitempty"`
+}
+
+// SnapshotObjectives captures the objectives for the battle record, so the
+// balance tooling can pull objective scenarios apart from annihilations
+func SnapshotObjectives(em *common.EntityManager) []ObjectiveState {
+
+	states := []ObjectiveState{}
+	for _, objective := range Objectives(em) {
+		states = append(states, ObjectiveState{
+			Name:       objective.Name,
+			Controller: objective.Controller,
+			HoldRounds: objective.HoldRounds,
+			Required:   objective.HoldRequired,
+			Contested:  objective.Contested,
+		})
+	}
+
+	return states
+}
@@ -0,0 +1,169 @@
+package squads
+
+import (
+	"game_main/common"
+	"strings"
+	"testing"
+)
+
+// A player squad on the tile and an enemy squad far away, around a single
+// objective that takes two held rounds
+func objectiveFixture() (*common.EntityManager, *ObjectiveData, *SquadData, *SquadData) {
+
+	em := newTestManager()
+	registerTestTemplates()
+
+	objective := CreateObjective(em, "Supply Cache", common.Position{X: 5, Y: 5}, 2)
+
+	holder := CreateSquad(em, "Holders", "Player", common.Position{X: 5, Y: 5}, DirDown)
+	CreateUnitFromTemplate(em, Units["TestKnight"], holder.SquadID, 0, 0)
+
+	enemy := CreateSquad(em, "Raiders", "Enemy", common.Position{X: 10, Y: 10}, DirUp)
+	CreateUnitFromTemplate(em, Units["TestKnight"], enemy.SquadID, 0, 0)
+
+	return em, objective, holder, enemy
+}
+
+// Ending rounds on the tile captures it, and holding it for the required
+// rounds produces the objective victor
+func TestCaptureAndHoldWinsOnObjectives(t *testing.T) {
+
+	em, objective, _, _ := objectiveFixture()
+
+	ResolveObjectives(em)
+	if objective.Controller != "Player" {
+		t.Fatalf("the squad on the tile should capture it, controller is %q", objective.Controller)
+	}
+	if objective.HoldRounds != 1 {
+		t.Fatalf("the capture round should count as the first held round, got %d", objective.HoldRounds)
+	}
+	if ObjectiveVictor(em) != "" {
+		t.Fatal("one held round of two should not decide the battle yet")
+	}
+
+	ResolveObjectives(em)
+	if ObjectiveVictor(em) != "Player" {
+		t.Errorf("two held rounds should win on objectives, victor is %q", ObjectiveVictor(em))
+	}
+}
+
+// An enemy squad next to the tile contests it: the counter freezes until the
+// enemy leaves, then resumes where it was
+func TestAdjacentEnemyContestsAndPausesTheCounter(t *testing.T) {
+
+	em, objective, _, enemy := objectiveFixture()
+
+	ResolveObjectives(em)
+
+	enemy.MapPos = common.Position{X: 5, Y: 6}
+	ResolveObjectives(em)
+	ResolveObjectives(em)
+
+	if !objective.Contested {
+		t.Fatal("an adjacent enemy should contest the objective")
+	}
+	if objective.HoldRounds != 1 {
+		t.Errorf("a contested objective should not accrue held rounds, got %d", objective.HoldRounds)
+	}
+	if ObjectiveVictor(em) != "" {
+		t.Fatal("a contested hold should not decide the battle")
+	}
+
+	enemy.MapPos = common.Position{X: 10, Y: 10}
+	ResolveObjectives(em)
+
+	if objective.Contested {
+		t.Error("the contest should clear once the enemy leaves")
+	}
+	if objective.HoldRounds != 2 {
+		t.Errorf("the counter should resume where it paused, got %d", objective.HoldRounds)
+	}
+}
+
+// Stepping onto an enemy-held tile flips control and resets the counter
+func TestCaptureResetsTheHoldCounter(t *testing.T) {
+
+	em, objective, holder, enemy := objectiveFixture()
+
+	ResolveObjectives(em)
+
+	holder.MapPos = common.Position{X: 1, Y: 1}
+	enemy.MapPos = objective.Pos
+	ResolveObjectives(em)
+
+	if objective.Controller != "Enemy" {
+		t.Fatalf("the tile should change hands, controller is %q", objective.Controller)
+	}
+	if objective.HoldRounds != 1 {
+		t.Errorf("a fresh capture should restart the counter, got %d", objective.HoldRounds)
+	}
+}
+
+// Victory needs more than half of the objectives held to term: holding one
+// of three is not enough, two of three is
+func TestObjectiveVictoryNeedsTheMajority(t *testing.T) {
+
+	em, _, holder, _ := objectiveFixture()
+
+	second := CreateObjective(em, "Bridge", common.Position{X: 2, Y: 2}, 2)
+	CreateObjective(em, "Ritual Circle", common.Position{X: 8, Y: 2}, 2)
+
+	ResolveObjectives(em)
+	ResolveObjectives(em)
+
+	if ObjectiveVictor(em) != "" {
+		t.Fatal("one of three objectives held should not win")
+	}
+
+	holder.MapPos = second.Pos
+	ResolveObjectives(em)
+	ResolveObjectives(em)
+
+	if ObjectiveVictor(em) != "Player" {
+		t.Errorf("two of three objectives held to term should win, victor is %q", ObjectiveVictor(em))
+	}
+}
+
+func TestObjectiveStatusLines(t *testing.T) {
+
+	em, _, _, enemy := objectiveFixture()
+	CreateObjective(em, "Bridge", common.Position{X: 2, Y: 2}, 2)
+
+	enemy.MapPos = common.Position{X: 5, Y: 6}
+	ResolveObjectives(em)
+
+	lines := ObjectiveStatusLines(em)
+	if len(lines) != 2 {
+		t.Fatalf("expected one line per objective, got %v", lines)
+	}
+
+	if lines[0] != "Bridge: unclaimed" {
+		t.Errorf("an unclaimed objective should say so, got %q", lines[0])
+	}
+	if !strings.Contains(lines[1], "held by Player") || !strings.Contains(lines[1], "contested") {
+		t.Errorf("a contested hold should show the controller and the contest, got %q", lines[1])
+	}
+}
+
+// The movement AI heads for objectives its faction does not hold, and falls
+// back to defending a held one
+func TestObjectiveMoveTargetPrefersUnheldTiles(t *testing.T) {
+
+	em, objective, holder, enemy := objectiveFixture()
+
+	far := CreateObjective(em, "Watchtower", common.Position{X: 1, Y: 9}, 2)
+
+	ResolveObjectives(em)
+
+	target, ok := ObjectiveMoveTarget(em, enemy.SquadID)
+	if !ok || target != objective.Pos {
+		t.Errorf("the enemy should push for the player-held tile, got %v %v", target, ok)
+	}
+
+	//The player holds everything in reach: the unclaimed far tile comes
+	//first for them, since it is not safely theirs yet
+	target, ok = ObjectiveMoveTarget(em, holder.SquadID)
+	if !ok || target != far.Pos {
+		t.Errorf("the holder should claim the open tile next, got %v %v", target, ok)
+	}
+}
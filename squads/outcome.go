@@ -0,0 +1,80 @@
+package squads
+
+import (
+	"game_main/common"
+)
+
+// A single classification of how a squad engagement went. The event slice
+// from ExecuteSquadAttack carries every detail, but downstream tools and the
+// AI usually just want to branch on "did that accomplish anything", so this
+// boils the round down to one value
+
+type EngagementOutcome int
+
+const (
+	//No damage landed at all
+	OutcomeIneffective EngagementOutcome = iota
+
+	//Damage landed but nothing died
+	OutcomeDamaging
+
+	//At least one defender died
+	OutcomeLethal
+
+	//The defending squad has nothing left alive
+	OutcomeWipe
+)
+
+func (o EngagementOutcome) String() string {
+	switch o {
+	case OutcomeIneffective:
+		return "Ineffective"
+	case OutcomeDamaging:
+		return "Damaging"
+	case OutcomeLethal:
+		return "Lethal"
+	default:
+		return "Wipe"
+	}
+}
+
+// ClassifyEngagement derives the outcome of one squads round of attacks
+// against the defender. Call it right after ExecuteSquadAttack, while the
+// defenders health still reflects the damage the events dealt
+func ClassifyEngagement(em *common.EntityManager, defenderSquadID int, events []AttackEvent) EngagementOutcome {
+
+	totalDamage := 0
+	for _, event := range events {
+		totalDamage += event.Damage
+	}
+
+	if totalDamage == 0 {
+		return OutcomeIneffective
+	}
+
+	living := 0
+	killed := false
+	for _, u := range SquadUnits(em, defenderSquadID) {
+
+		if common.GetAttributes(u).CurrentHealth > 0 {
+			living++
+			continue
+		}
+
+		for _, event := range events {
+			if event.DefenderID == u.GetID() && event.Damage > 0 {
+				killed = true
+			}
+		}
+
+	}
+
+	if living == 0 {
+		return OutcomeWipe
+	}
+	if killed {
+		return OutcomeLethal
+	}
+
+	return OutcomeDamaging
+}
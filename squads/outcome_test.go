@@ -0,0 +1,91 @@
+package squads
+
+import (
+	"game_main/common"
+	"testing"
+)
+
+// Two squads a tile apart, with the defender row holding however many knights
+// the test wants to leave standing
+func outcomeFixture(t *testing.T, em *common.EntityManager, defenders int) (att *SquadData, def *SquadData) {
+
+	t.Helper()
+
+	att = CreateSquad(em, "Attackers", "Player", common.Position{X: 5, Y: 3}, DirDown)
+	CreateUnitFromTemplate(em, Units["TestKnight"], att.SquadID, 0, 0)
+
+	def = CreateSquad(em, "Defenders", "Enemy", common.Position{X: 5, Y: 4}, DirUp)
+	for col := 0; col < defenders; col++ {
+		CreateUnitFromTemplate(em, Units["TestKnight"], def.SquadID, 0, col)
+	}
+
+	return att, def
+}
+
+// Killing everything left in the squad is a wipe
+func TestWipeOutcome(t *testing.T) {
+
+	em := newTestManager()
+	registerTestTemplates()
+
+	att, def := outcomeFixture(t, em, 1)
+
+	target := UnitAtGridPos(em, def.SquadID, 0, 0)
+	common.GetAttributes(target).CurrentHealth = 1
+
+	originalRoll := dodgeRoll
+	dodgeRoll = func() int { return 100 }
+	defer func() { dodgeRoll = originalRoll }()
+
+	events := ExecuteSquadAttack(em, att.SquadID, def.SquadID, DirDown)
+
+	if got := ClassifyEngagement(em, def.SquadID, events); got != OutcomeWipe {
+		t.Errorf("expected Wipe, got %s", got)
+	}
+}
+
+// An engagement that lands nothing is ineffective
+func TestIneffectiveOutcome(t *testing.T) {
+
+	em := newTestManager()
+	registerTestTemplates()
+
+	_, def := outcomeFixture(t, em, 2)
+
+	if got := ClassifyEngagement(em, def.SquadID, []AttackEvent{}); got != OutcomeIneffective {
+		t.Errorf("an empty round should be Ineffective, got %s", got)
+	}
+
+	dodge := []AttackEvent{{Dodged: true, PotentialDamage: 5}}
+	if got := ClassifyEngagement(em, def.SquadID, dodge); got != OutcomeIneffective {
+		t.Errorf("an all-dodged round should be Ineffective, got %s", got)
+	}
+}
+
+// Killing part of the squad is lethal, damage without a kill is just damaging
+func TestLethalAndDamagingOutcomes(t *testing.T) {
+
+	em := newTestManager()
+	registerTestTemplates()
+
+	att, def := outcomeFixture(t, em, 2)
+
+	target := UnitAtGridPos(em, def.SquadID, 0, 0)
+	common.GetAttributes(target).CurrentHealth = 1
+
+	originalRoll := dodgeRoll
+	dodgeRoll = func() int { return 100 }
+	defer func() { dodgeRoll = originalRoll }()
+
+	events := ExecuteSquadAttack(em, att.SquadID, def.SquadID, DirDown)
+
+	if got := ClassifyEngagement(em, def.SquadID, events); got != OutcomeLethal {
+		t.Errorf("a partial kill should be Lethal, got %s", got)
+	}
+
+	events = ExecuteSquadAttack(em, att.SquadID, def.SquadID, DirDown)
+
+	if got := ClassifyEngagement(em, def.SquadID, events); got != OutcomeDamaging {
+		t.Errorf("damage without a kill should be Damaging, got %s", got)
+	}
+}
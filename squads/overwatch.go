@@ -0,0 +1,189 @@
+package squads
+
+import (
+	"fmt"
+	"game_main/common"
+
+	"github.com/bytearena/ecs"
+)
+
+// Overwatch stance. A squad spends its attack action to set up a firing
+// line: the first enemy squad that moves within the watch range eats one
+// interrupt volley mid-move, fired at a small hit penalty because the
+// target is moving. Triggering consumes the stance; a squad that acts
+// again before anything walks in clears it with ClearOverwatch
+
+type OverwatchConfig struct {
+	//Extra dodge the moving squad gets against interrupt fire - the hit
+	//penalty for shooting at a moving target
+	HitPenalty float32
+}
+
+var Overwatch = OverwatchConfig{HitPenalty: 0.15}
+
+var OverwatchComponent *ecs.Component
+
+// OverwatchData lives on the watching squads entity
+type OverwatchData struct {
+	//How far out the firing line reaches, in map tiles
+	WatchRange int
+}
+
+func GetOverwatchData(em *common.EntityManager, squadID int) *OverwatchData {
+
+	if squad := GetSquadEntity(em, squadID); squad != nil {
+		return common.GetComponentType[*OverwatchData](squad, OverwatchComponent)
+	}
+
+	return nil
+}
+
+// EnterOverwatch puts a squad on overwatch. The squad gives up its attack
+// to do so, which is the callers business; this only sets the stance
+func EnterOverwatch(em *common.EntityManager, squadID int, watchRange int) error {
+
+	squad := GetSquadEntity(em, squadID)
+	if squad == nil {
+		return fmt.Errorf("no squad with ID %d", squadID)
+	}
+
+	if !squadHasLivingUnits(em, squadID) {
+		return fmt.Errorf("%s has no units left to watch with", GetSquadData(squad).Name)
+	}
+	if watchRange < 1 {
+		return fmt.Errorf("watch range must be at least 1 tile")
+	}
+
+	squad.AddComponent(OverwatchComponent, &OverwatchData{WatchRange: watchRange})
+	return nil
+}
+
+func OnOverwatch(em *common.EntityManager, squadID int) bool {
+	return GetOverwatchData(em, squadID) != nil
+}
+
+// ClearOverwatch drops the stance, as happens when the squad activates
+// again without anything having triggered it
+func ClearOverwatch(em *common.EntityManager, squadID int) {
+
+	if squad := GetSquadEntity(em, squadID); squad != nil && GetOverwatchData(em, squadID) != nil {
+		squad.RemoveComponent(OverwatchComponent)
+	}
+
+}
+
+// OverwatchersCovering lists the enemy squads whose overwatch reaches pos,
+// for the moving squad given. Movement previews use this to mark the tiles
+// a path would draw fire on
+func OverwatchersCovering(em *common.EntityManager, movingSquadID int, pos common.Position) []int {
+
+	mover := GetSquadByID(em, movingSquadID)
+	if mover == nil {
+		return nil
+	}
+
+	watchers := []int{}
+	for _, result := range em.World.Query(em.WorldTags["squads"]) {
+
+		squad := GetSquadData(result.Entity)
+
+		if squad.SquadID == movingSquadID || squad.Faction == mover.Faction {
+			continue
+		}
+		if !squadHasLivingUnits(em, squad.SquadID) {
+			continue
+		}
+
+		data := GetOverwatchData(em, squad.SquadID)
+		if data == nil {
+			continue
+		}
+
+		if squad.MapPos.SquadDistance(&pos) <= data.WatchRange {
+			watchers = append(watchers, squad.SquadID)
+		}
+
+	}
+
+	return watchers
+}
+
+// MoveSquadUnderOverwatch walks a squad along a path tile by tile and
+// resolves any interrupt fire along the way. Movement continues after an
+// interrupt unless the squad was destroyed by it, in which case it stops
+// where it died. Returns the interrupt events, flagged as overwatch fire
+func MoveSquadUnderOverwatch(em *common.EntityManager, squadID int, path []common.Position) []AttackEvent {
+
+	squad := GetSquadByID(em, squadID)
+	if squad == nil {
+		return nil
+	}
+
+	events := []AttackEvent{}
+	for _, tile := range path {
+
+		syncSquadPosition(em, squad, tile)
+
+		for _, watcherID := range OverwatchersCovering(em, squadID, tile) {
+			events = append(events, resolveOverwatchVolley(em, watcherID, squadID)...)
+		}
+
+		if !squadHasLivingUnits(em, squadID) {
+			return events
+		}
+
+	}
+
+	return events
+}
+
+// One interrupt volley from the watcher against the moving squad. The
+// stance is consumed up front so a volley can never trigger twice, the
+// movers units dodge the penalty easier for the duration, and the mover
+// does not answer - interrupt fire draws no counters
+func resolveOverwatchVolley(em *common.EntityManager, watcherSquadID int, movingSquadID int) []AttackEvent {
+
+	ClearOverwatch(em, watcherSquadID)
+
+	movers := SquadUnits(em, movingSquadID)
+	for _, u := range movers {
+		common.GetAttributes(u).TotalDodgeChance += Overwatch.HitPenalty
+	}
+	defer func() {
+		for _, u := range movers {
+			common.GetAttributes(u).TotalDodgeChance -= Overwatch.HitPenalty
+		}
+	}()
+
+	interleaved := Counterattack.Interleaved
+	Counterattack.Interleaved = false
+	defer func() { Counterattack.Interleaved = interleaved }()
+
+	dir := overwatchFireDirection(GetSquadByID(em, watcherSquadID).MapPos, GetSquadByID(em, movingSquadID).MapPos)
+
+	events := ExecuteSquadAttack(em, watcherSquadID, movingSquadID, dir)
+	for i := range events {
+		events[i].Overwatch = true
+	}
+
+	return events
+}
+
+// The map direction interrupt fire travels from the watcher to the mover
+func overwatchFireDirection(from common.Position, to common.Position) Direction {
+
+	dx := to.X - from.X
+	dy := to.Y - from.Y
+
+	if abs(dx) > abs(dy) {
+		if dx > 0 {
+			return DirRight
+		}
+		return DirLeft
+	}
+
+	if dy > 0 {
+		return DirDown
+	}
+	return DirUp
+}
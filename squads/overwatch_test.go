@@ -0,0 +1,111 @@
+package squads
+
+import (
+	"game_main/common"
+	"testing"
+)
+
+// A watcher squad of knights at (5,5) and an enemy mover squad starting
+// well outside the watch range
+func overwatchFixture(t *testing.T) (*common.EntityManager, int, int) {
+
+	t.Helper()
+
+	em := newTestManager()
+	registerTestTemplates()
+
+	watcher := CreateSquad(em, "Watchers", "Attacker", common.Position{X: 5, Y: 5}, DirUp)
+	CreateUnitFromTemplate(em, Units["TestKnight"], watcher.SquadID, 0, 0)
+	CreateUnitFromTemplate(em, Units["TestKnight"], watcher.SquadID, 0, 1)
+
+	mover := CreateSquad(em, "Movers", "Defender", common.Position{X: 5, Y: 10}, DirUp)
+	CreateUnitFromTemplate(em, Units["TestKnight"], mover.SquadID, 0, 0)
+
+	if err := EnterOverwatch(em, watcher.SquadID, 2); err != nil {
+		t.Fatalf("entering overwatch failed: %v", err)
+	}
+
+	return em, watcher.SquadID, mover.SquadID
+}
+
+// Walking through the firing line draws exactly one volley, even though
+// several path tiles are in range, and the volley fires at the penalized
+// hit threshold
+func TestOverwatchFiresOnceAtThePenalizedThreshold(t *testing.T) {
+
+	em, watcherID, moverID := overwatchFixture(t)
+
+	//The movers have no dodge of their own, so a roll of 10 only misses
+	//because of the 15% moving-target penalty
+	originalDodge := dodgeRoll
+	dodgeRoll = func() int { return 10 }
+	defer func() { dodgeRoll = originalDodge }()
+
+	path := []common.Position{{X: 5, Y: 8}, {X: 5, Y: 7}, {X: 5, Y: 6}}
+	events := MoveSquadUnderOverwatch(em, moverID, path)
+
+	if len(events) != 2 {
+		t.Fatalf("expected one volley of 2 interrupt attacks, got %d events", len(events))
+	}
+
+	for i, event := range events {
+		if !event.Overwatch {
+			t.Errorf("event %d should carry the overwatch flag", i)
+		}
+		if !event.Dodged {
+			t.Errorf("event %d should miss under the moving-target penalty", i)
+		}
+	}
+
+	if mover := GetSquadByID(em, moverID); mover.MapPos != (common.Position{X: 5, Y: 6}) {
+		t.Errorf("the mover should finish its path, ended at (%d,%d)", mover.MapPos.X, mover.MapPos.Y)
+	}
+
+	if OnOverwatch(em, watcherID) {
+		t.Error("triggering should consume the overwatch stance")
+	}
+}
+
+// Once the stance is consumed, walking through again draws nothing
+func TestOverwatchDoesNotFireTwice(t *testing.T) {
+
+	em, _, moverID := overwatchFixture(t)
+
+	path := []common.Position{{X: 5, Y: 7}}
+	if events := MoveSquadUnderOverwatch(em, moverID, path); len(events) == 0 {
+		t.Fatal("the first pass should have triggered the overwatch")
+	}
+
+	if events := MoveSquadUnderOverwatch(em, moverID, []common.Position{{X: 5, Y: 6}}); len(events) != 0 {
+		t.Errorf("the second pass should draw no fire, got %d events", len(events))
+	}
+}
+
+// A path that skirts outside the watch range never triggers, and the
+// stance stays armed
+func TestOverwatchIgnoresPathsOutsideRange(t *testing.T) {
+
+	em, watcherID, moverID := overwatchFixture(t)
+
+	path := []common.Position{{X: 5, Y: 9}, {X: 5, Y: 8}}
+	if events := MoveSquadUnderOverwatch(em, moverID, path); len(events) != 0 {
+		t.Errorf("a path outside the watch range should draw no fire, got %d events", len(events))
+	}
+
+	if !OnOverwatch(em, watcherID) {
+		t.Error("an untriggered overwatch should stay armed")
+	}
+}
+
+// The preview helper marks exactly the tiles inside the firing line
+func TestOverwatchersCoveringMatchesTheWatchRange(t *testing.T) {
+
+	em, watcherID, moverID := overwatchFixture(t)
+
+	if watchers := OverwatchersCovering(em, moverID, common.Position{X: 5, Y: 7}); len(watchers) != 1 || watchers[0] != watcherID {
+		t.Errorf("(5,7) is in range and should be covered, got %v", watchers)
+	}
+	if watchers := OverwatchersCovering(em, moverID, common.Position{X: 5, Y: 8}); len(watchers) != 0 {
+		t.Errorf("(5,8) is outside the watch range, got %v", watchers)
+	}
+}
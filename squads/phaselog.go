@@ -0,0 +1,83 @@
+package squads
+
+import (
+	"game_main/common"
+)
+
+// The phase log is the battle records per-round view: instead of one flat
+// event list, each round splits into labeled movement, effects, attack, and
+// counterattack groups, which is what visualizers want to print. The flat
+// Events list stays the source of truth; PhaseLog is derived from it
+
+// The phases a round resolves in, in resolution order
+const (
+	PhaseMovement      = "movement"
+	PhaseEffects       = "effects"
+	PhaseAttack        = "attack"
+	PhaseCounterattack = "counterattack"
+)
+
+// MovementEvent records a squad changing map position mid-battle, such as a
+// retreat or a repositioning step
+type MovementEvent struct {
+	Round   int             `json:"round"`
+	SquadID int             `json:"squadID"`
+	From    common.Position `json:"from"`
+	To      common.Position `json:"to"`
+}
+
+// RoundPhase is one labeled group of a rounds phase log. Exactly one of the
+// two slices is populated: movements for the movement phase, events otherwise
+type RoundPhase struct {
+	Phase     string          `json:"phase"`
+	Events    []AttackEvent   `json:"events,omitempty"`
+	Movements []MovementEvent `json:"movements,omitempty"`
+}
+
+func (r *BattleRecord) RecordMovement(event MovementEvent) {
+	r.Movements = append(r.Movements, event)
+}
+
+// PhaseLog groups one rounds happenings into labeled phases, in the order
+// the round resolved them: movement, then round-start effects, then the
+// attack volley, then the counterattack. Phases nothing happened in are
+// omitted. Events from records predating phase labels all land in the
+// attack group
+func (r *BattleRecord) PhaseLog(round int) []RoundPhase {
+
+	groups := []RoundPhase{}
+
+	movements := []MovementEvent{}
+	for _, m := range r.Movements {
+		if m.Round == round {
+			movements = append(movements, m)
+		}
+	}
+	if len(movements) > 0 {
+		groups = append(groups, RoundPhase{Phase: PhaseMovement, Movements: movements})
+	}
+
+	for _, phase := range []string{PhaseEffects, PhaseAttack, PhaseCounterattack} {
+
+		events := []AttackEvent{}
+		for _, e := range r.Events {
+
+			label := e.Phase
+			if label == "" {
+				label = PhaseAttack
+			}
+
+			if e.Round == round && label == phase {
+				events = append(events, e)
+			}
+
+		}
+
+		if len(events) > 0 {
+			groups = append(groups, RoundPhase{Phase: phase, Events: events})
+		}
+
+	}
+
+	return groups
+}
@@ -0,0 +1,59 @@
+package squads
+
+import (
+	"game_main/common"
+	"testing"
+)
+
+// A round with movement, an attack, and a counterattack groups into three
+// labeled phases, in the order the round resolved them
+func TestPhaseLogGroupsARoundInOrder(t *testing.T) {
+
+	record := NewBattleRecord(1, 2)
+	record.Rounds = 1
+
+	record.RecordMovement(MovementEvent{Round: 1, SquadID: 2, From: common.Position{X: 5, Y: 5}, To: common.Position{X: 5, Y: 6}})
+	record.RecordEvent(AttackEvent{Round: 1, Phase: PhaseAttack, AttackerID: 7, Damage: 4})
+	record.RecordEvent(AttackEvent{Round: 1, Phase: PhaseCounterattack, AttackerID: 9, Damage: 5})
+
+	groups := record.PhaseLog(1)
+	if len(groups) != 3 {
+		t.Fatalf("expected 3 phase groups, got %d", len(groups))
+	}
+
+	if groups[0].Phase != PhaseMovement || len(groups[0].Movements) != 1 {
+		t.Errorf("expected the movement phase first, got %s with %d movements", groups[0].Phase, len(groups[0].Movements))
+	}
+	if groups[1].Phase != PhaseAttack || len(groups[1].Events) != 1 || groups[1].Events[0].AttackerID != 7 {
+		t.Errorf("expected the attack phase second with the attackers swing, got %+v", groups[1])
+	}
+	if groups[2].Phase != PhaseCounterattack || len(groups[2].Events) != 1 || groups[2].Events[0].AttackerID != 9 {
+		t.Errorf("expected the counterattack phase last with the defenders answer, got %+v", groups[2])
+	}
+}
+
+// Phases nothing happened in stay out, and other rounds don't leak in
+func TestPhaseLogOmitsEmptyPhasesAndOtherRounds(t *testing.T) {
+
+	record := NewBattleRecord(1, 2)
+
+	record.RecordEvent(AttackEvent{Round: 1, Phase: PhaseEffects, Damage: 1})
+	record.RecordEvent(AttackEvent{Round: 2, Phase: PhaseAttack, Damage: 4})
+
+	groups := record.PhaseLog(1)
+	if len(groups) != 1 || groups[0].Phase != PhaseEffects {
+		t.Fatalf("expected only the effects phase for round 1, got %+v", groups)
+	}
+}
+
+// Old records have no phase labels; their events all read as the attack phase
+func TestPhaseLogTreatsUnlabeledEventsAsAttacks(t *testing.T) {
+
+	record := NewBattleRecord(1, 2)
+	record.RecordEvent(AttackEvent{Round: 1, Damage: 4})
+
+	groups := record.PhaseLog(1)
+	if len(groups) != 1 || groups[0].Phase != PhaseAttack {
+		t.Fatalf("expected the unlabeled event under the attack phase, got %+v", groups)
+	}
+}
@@ -0,0 +1,87 @@
+package squads
+
+import (
+	"fmt"
+	"sort"
+
+	"game_main/common"
+)
+
+// A squads map position lives in three places: SquadData.MapPos, the squad
+// entitys PositionComponent, and the stack occupancy index. With movement,
+// retreats, overwatch walks and destruction all mutating position, a writer
+// that updates only one copy leaves a desync that surfaces much later as a
+// wrong distance. MoveSquadTo is the single authoritative writer, and
+// ValidateWorldConsistency is the debug check that catches anything that
+// still bypasses it
+
+// MoveSquadTo relocates a squad and keeps every copy of its position in
+// step. Pass the stacks index when the world has one, so occupancy moves
+// with the squad; a nil index syncs just the data and the component
+func MoveSquadTo(em *common.EntityManager, stacks *SquadStacks, squadID int, dest common.Position) error {
+
+	if stacks != nil {
+		return stacks.MoveSquad(em, squadID, dest)
+	}
+
+	squad := GetSquadByID(em, squadID)
+	if squad == nil {
+		return fmt.Errorf("no squad with ID %d", squadID)
+	}
+
+	syncSquadPosition(em, squad, dest)
+	return nil
+}
+
+// syncSquadPosition writes the position everywhere except the occupancy
+// index, which the stacking code owns, and emits the SquadMoved event.
+// Every internal mover routes through here
+func syncSquadPosition(em *common.EntityManager, squad *SquadData, dest common.Position) {
+
+	squad.MapPos = dest
+
+	if entity := GetSquadEntity(em, squad.SquadID); entity != nil {
+		if pos := common.GetPosition(entity); pos != nil {
+			*pos = dest
+		}
+	}
+
+	em.NotifyChanged(common.SquadMoved)
+}
+
+// ValidateWorldConsistency cross-checks every squads position component and
+// MapPos, and its tile in the occupancy index when one is in play. Returns
+// one message per mismatch, named so the log points straight at the squad.
+// Run it behind DEBUG_MODE each turn and in tests; an empty result means the
+// world agrees with itself
+func ValidateWorldConsistency(em *common.EntityManager, stacks *SquadStacks) []string {
+
+	problems := []string{}
+
+	for _, s := range em.World.Query(em.WorldTags["squads"]) {
+
+		squad := GetSquadData(s.Entity)
+
+		if pos := common.GetPosition(s.Entity); pos != nil && *pos != squad.MapPos {
+			problems = append(problems, fmt.Sprintf("%s: position component says (%d,%d) but MapPos says (%d,%d)",
+				squad.Name, pos.X, pos.Y, squad.MapPos.X, squad.MapPos.Y))
+		}
+
+		if stacks == nil {
+			continue
+		}
+
+		tile, placed := stacks.tileOf(squad.SquadID)
+		switch {
+		case !placed:
+			problems = append(problems, fmt.Sprintf("%s: missing from the occupancy index", squad.Name))
+		case tile != squad.MapPos:
+			problems = append(problems, fmt.Sprintf("%s: occupancy index holds it at (%d,%d) but MapPos says (%d,%d)",
+				squad.Name, tile.X, tile.Y, squad.MapPos.X, squad.MapPos.Y))
+		}
+
+	}
+
+	sort.Strings(problems)
+	return problems
+}
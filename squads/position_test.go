@@ -0,0 +1,154 @@
+package squads
+
+import (
+	"game_main/common"
+	"game_main/randgen"
+	"testing"
+)
+
+func positionFixture(t *testing.T) (*common.EntityManager, *SquadStacks, *SquadData) {
+
+	t.Helper()
+
+	em := newTestManager()
+	registerTestTemplates()
+
+	stacks := NewSquadStacks(3)
+	squad := CreateSquad(em, "Walkers", "Player", common.Position{X: 2, Y: 2}, DirDown)
+	CreateUnitFromTemplate(em, Units["TestKnight"], squad.SquadID, 0, 0)
+
+	if err := stacks.Join(em, squad.SquadID, squad.MapPos); err != nil {
+		t.Fatalf("placing the squad failed: %v", err)
+	}
+
+	return em, stacks, squad
+}
+
+// The authoritative mover keeps MapPos, the position component, and the
+// occupancy index in step, and fires the SquadMoved event
+func TestMoveSquadToKeepsEveryCopyInStep(t *testing.T) {
+
+	em, stacks, squad := positionFixture(t)
+
+	moved := em.Changes().Subscribe(common.SquadMoved)
+	moved.Fired()
+
+	dest := common.Position{X: 7, Y: 4}
+	if err := MoveSquadTo(em, stacks, squad.SquadID, dest); err != nil {
+		t.Fatalf("the move failed: %v", err)
+	}
+
+	if squad.MapPos != dest {
+		t.Errorf("MapPos should follow the move, got %+v", squad.MapPos)
+	}
+	if pos := common.GetPosition(GetSquadEntity(em, squad.SquadID)); *pos != dest {
+		t.Errorf("the position component should follow the move, got %+v", *pos)
+	}
+	if tile, _ := stacks.tileOf(squad.SquadID); tile != dest {
+		t.Errorf("the occupancy index should follow the move, got %+v", tile)
+	}
+	if !moved.Fired() {
+		t.Error("the move should fire the SquadMoved event")
+	}
+
+	if problems := ValidateWorldConsistency(em, stacks); len(problems) != 0 {
+		t.Errorf("a clean move should validate, got %v", problems)
+	}
+
+}
+
+// Each copy of the position is corrupted in turn, and the validator must
+// name the squad every time
+func TestValidatorCatchesEveryDesync(t *testing.T) {
+
+	em, stacks, squad := positionFixture(t)
+
+	//A stale position component
+	*common.GetPosition(GetSquadEntity(em, squad.SquadID)) = common.Position{X: 9, Y: 9}
+	problems := ValidateWorldConsistency(em, stacks)
+	if len(problems) != 1 || !containsName(problems, "Walkers") {
+		t.Errorf("a stale component should be reported by name, got %v", problems)
+	}
+	*common.GetPosition(GetSquadEntity(em, squad.SquadID)) = squad.MapPos
+
+	//An occupancy entry on the wrong tile
+	stacks.Leave(squad.SquadID)
+	stacks.tiles[common.Position{X: 0, Y: 0}] = []int{squad.SquadID}
+	problems = ValidateWorldConsistency(em, stacks)
+	if len(problems) != 1 || !containsName(problems, "Walkers") {
+		t.Errorf("a drifted occupancy entry should be reported, got %v", problems)
+	}
+
+	//Gone from the index entirely
+	stacks.Leave(squad.SquadID)
+	problems = ValidateWorldConsistency(em, stacks)
+	if len(problems) != 1 || !containsName(problems, "Walkers") {
+		t.Errorf("a squad missing from the index should be reported, got %v", problems)
+	}
+
+}
+
+func containsName(problems []string, name string) bool {
+
+	for _, p := range problems {
+		if len(p) >= len(name) && p[:len(name)] == name {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Random moves, stack joins and destructions never leave the world
+// inconsistent as long as everything routes through the movers
+func TestRandomMovementKeepsTheWorldConsistent(t *testing.T) {
+
+	randgen.Seed(7)
+	defer randgen.ClearSeed()
+
+	em := newTestManager()
+	registerTestTemplates()
+	stacks := NewSquadStacks(3)
+
+	ids := []int{}
+	for i := 0; i < 6; i++ {
+
+		squad := CreateSquad(em, "Wanderers", "Player", common.Position{X: i, Y: 0}, DirDown)
+		CreateUnitFromTemplate(em, Units["TestKnight"], squad.SquadID, 0, 0)
+
+		if err := stacks.Join(em, squad.SquadID, squad.MapPos); err != nil {
+			t.Fatalf("placing squad %d failed: %v", squad.SquadID, err)
+		}
+		ids = append(ids, squad.SquadID)
+
+	}
+
+	for step := 0; step < 200 && len(ids) > 0; step++ {
+
+		pick := randgen.GetRandomBetween(0, len(ids)-1)
+		id := ids[pick]
+
+		if randgen.GetRandomBetween(0, 9) == 0 {
+
+			ProcessSquadDestruction(em, stacks, id)
+			ids = append(ids[:pick], ids[pick+1:]...)
+
+		} else {
+
+			dest := common.Position{
+				X: randgen.GetRandomBetween(0, 4),
+				Y: randgen.GetRandomBetween(0, 4),
+			}
+
+			//A full tile rejects the move and must change nothing
+			_ = MoveSquadTo(em, stacks, id, dest)
+
+		}
+
+		if problems := ValidateWorldConsistency(em, stacks); len(problems) != 0 {
+			t.Fatalf("step %d left the world inconsistent: %v", step, problems)
+		}
+
+	}
+
+}
@@ -0,0 +1,203 @@
+package squads
+
+import (
+	"fmt"
+	"game_main/common"
+	"game_main/gear"
+
+	"github.com/bytearena/ecs"
+)
+
+// PostBattleSummary is the consolidated picture of a finished battle that the
+// summary screen displays. It is plain data with JSON tags so auto-resolved
+// and manual battles can share it
+
+type UnitSummary struct {
+	Name        string `json:"name"`
+	HPRemaining int    `json:"hpRemaining"`
+	LeveledUp   bool   `json:"leveledUp,omitempty"`
+	Wounded     bool   `json:"wounded,omitempty"`
+}
+
+type SquadSummary struct {
+	SquadID      int           `json:"squadID"`
+	Name         string        `json:"name"`
+	UnitsLost    []string      `json:"unitsLost"`
+	Units        []UnitSummary `json:"units"`
+	XPAwarded    int           `json:"xpAwarded"`
+	MoraleChange int           `json:"moraleChange"`
+
+	//Set when leadership changed hands or was lost during the battle
+	Succession string `json:"succession,omitempty"`
+
+	//Consumables this squad spent during the battle
+	ConsumablesUsed []string `json:"consumablesUsed,omitempty"`
+}
+
+type LootDrop struct {
+	EntityID ecs.EntityID `json:"entityID"`
+	Name     string       `json:"name"`
+	Taken    bool         `json:"taken"`
+}
+
+type PostBattleSummary struct {
+	Squads         []SquadSummary `json:"squads"`
+	Loot           []LootDrop     `json:"loot"`
+	TotalXP        int            `json:"totalXP"`
+	LastingEffects []string       `json:"lastingEffects,omitempty"`
+
+	lootApplied bool
+}
+
+// BuildPostBattleSummary assembles the summary from the battle record and the
+// loot the orchestration decided to drop. Every drop starts out marked taken,
+// the screen lets the player toggle individual ones
+func BuildPostBattleSummary(em *common.EntityManager, record *BattleRecord, totalXP int, lootIDs []ecs.EntityID) *PostBattleSummary {
+
+	summary := &PostBattleSummary{TotalXP: totalXP}
+
+	for _, squadID := range []int{record.AttackerSquadID, record.DefenderSquadID} {
+
+		squad := GetSquadByID(em, squadID)
+		if squad == nil {
+			continue
+		}
+
+		squadSummary := SquadSummary{
+			SquadID:    squadID,
+			Name:       squad.Name,
+			UnitsLost:  record.UnitsLost[squadID],
+			Succession: squad.SuccessionNote,
+		}
+
+		for _, c := range record.Consumables {
+			if c.Squad == squad.Name {
+				squadSummary.ConsumablesUsed = append(squadSummary.ConsumablesUsed, c.Consumable)
+			}
+		}
+
+		for _, u := range SquadUnits(em, squadID) {
+
+			squadSummary.Units = append(squadSummary.Units, UnitSummary{
+				Name:        common.GetComponentType[*common.Name](u, common.NameComponent).NameStr,
+				HPRemaining: common.GetAttributes(u).CurrentHealth,
+				Wounded:     IsWounded(u),
+			})
+
+		}
+
+		//Winners split the XP, losers get nothing for now
+		if squadID == record.WinnerSquadID {
+			squadSummary.XPAwarded = totalXP
+		}
+
+		if effect := damagedArmorEffect(em, squadID); effect != "" {
+			summary.LastingEffects = append(summary.LastingEffects, effect)
+		}
+
+		summary.Squads = append(summary.Squads, squadSummary)
+
+	}
+
+	for _, id := range lootIDs {
+
+		name := ""
+		if res := em.World.GetEntityByID(id); res != nil {
+			if res.Entity.HasComponent(common.NameComponent) {
+				name = common.GetComponentType[*common.Name](res.Entity, common.NameComponent).NameStr
+			}
+		}
+
+		summary.Loot = append(summary.Loot, LootDrop{EntityID: id, Name: name, Taken: true})
+
+	}
+
+	return summary
+}
+
+func (s *PostBattleSummary) SetLootTaken(entityID ecs.EntityID, taken bool) {
+
+	for i := range s.Loot {
+		if s.Loot[i].EntityID == entityID {
+			s.Loot[i].Taken = taken
+		}
+	}
+
+}
+
+// ApplyLoot moves every accepted drop into the inventory. Dismissing the
+// summary screen calls this exactly once, a second call is a no-op so a
+// double dismiss can't duplicate items. Returns the entities the player left
+// behind so the caller can put them back on the map as lootables
+func (s *PostBattleSummary) ApplyLoot(em *common.EntityManager, inv *gear.Inventory) []*ecs.Entity {
+
+	left := make([]*ecs.Entity, 0)
+
+	if s.lootApplied {
+		return left
+	}
+	s.lootApplied = true
+
+	for _, drop := range s.Loot {
+
+		res := em.World.GetEntityByID(drop.EntityID)
+		if res == nil {
+			continue
+		}
+
+		if drop.Taken {
+			inv.InventoryContent = append(inv.InventoryContent, res.Entity)
+		} else {
+			left = append(left, res.Entity)
+		}
+
+	}
+
+	return left
+}
+
+// For the summary screens text panels
+func (s *PostBattleSummary) DisplayString() string {
+
+	res := ""
+	for _, squad := range s.Squads {
+
+		res += fmt.Sprintln(squad.Name)
+		res += fmt.Sprintln("  Units lost:", len(squad.UnitsLost))
+		for _, name := range squad.UnitsLost {
+			res += fmt.Sprintln("   ", name)
+		}
+		for _, u := range squad.Units {
+
+			if u.Wounded {
+				res += fmt.Sprintln("  ", u.Name, "HP", u.HPRemaining, "(wounded)")
+			} else {
+				res += fmt.Sprintln("  ", u.Name, "HP", u.HPRemaining)
+			}
+
+		}
+		if squad.XPAwarded > 0 {
+			res += fmt.Sprintln("  XP awarded:", squad.XPAwarded)
+		}
+		if squad.MoraleChange != 0 {
+			res += fmt.Sprintln("  Morale change:", squad.MoraleChange)
+		}
+		if squad.Succession != "" {
+			res += fmt.Sprintln("  Leadership:", squad.Succession)
+		}
+
+	}
+
+	if len(s.Loot) > 0 {
+		res += fmt.Sprintln("Loot:")
+		for _, drop := range s.Loot {
+			res += fmt.Sprintln("  ", drop.Name, "taken:", drop.Taken)
+		}
+	}
+
+	for _, effect := range s.LastingEffects {
+		res += fmt.Sprintln("Lasting effect:", effect)
+	}
+
+	return res
+}
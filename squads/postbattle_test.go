@@ -0,0 +1,102 @@
+package squads
+
+import (
+	"encoding/json"
+	"game_main/common"
+	"game_main/gear"
+	"testing"
+
+	"github.com/bytearena/ecs"
+)
+
+func fixtureBattle(em *common.EntityManager) (*BattleRecord, *SquadData, *SquadData) {
+
+	attackers := CreateSquad(em, "Attackers", "Player", common.Position{X: 1, Y: 1}, DirDown)
+	CreateUnitFromTemplate(em, Units["TestKnight"], attackers.SquadID, 0, 0)
+
+	defenders := CreateSquad(em, "Defenders", "Enemy", common.Position{X: 1, Y: 3}, DirUp)
+	CreateUnitFromTemplate(em, Units["TestRogue"], defenders.SquadID, 0, 0)
+
+	record := NewBattleRecord(attackers.SquadID, defenders.SquadID)
+	record.WinnerSquadID = attackers.SquadID
+	record.Rounds = 3
+	record.RecordUnitLost(defenders.SquadID, "TestRogue")
+
+	return record, attackers, defenders
+}
+
+// Accepting loot mutates the inventory exactly once even if applied twice
+func TestAcceptedLootAppliesExactlyOnce(t *testing.T) {
+
+	em := newTestManager()
+	registerTestTemplates()
+
+	record, _, _ := fixtureBattle(em)
+
+	loot := em.World.NewEntity().
+		AddComponent(common.NameComponent, &common.Name{NameStr: "Rusty Sword"}).
+		AddComponent(common.PositionComponent, &common.Position{X: 1, Y: 2})
+
+	summary := BuildPostBattleSummary(em, record, 10, []ecs.EntityID{loot.GetID()})
+
+	inv := &gear.Inventory{}
+
+	summary.ApplyLoot(em, inv)
+	summary.ApplyLoot(em, inv)
+
+	if len(inv.InventoryContent) != 1 {
+		t.Fatalf("expected the loot to be added exactly once, inventory has %d items", len(inv.InventoryContent))
+	}
+}
+
+// Declined loot is returned so the caller can leave it on the map
+func TestDeclinedLootIsLeftBehind(t *testing.T) {
+
+	em := newTestManager()
+	registerTestTemplates()
+
+	record, _, _ := fixtureBattle(em)
+
+	loot := em.World.NewEntity().
+		AddComponent(common.NameComponent, &common.Name{NameStr: "Cracked Shield"}).
+		AddComponent(common.PositionComponent, &common.Position{X: 1, Y: 2})
+
+	summary := BuildPostBattleSummary(em, record, 10, []ecs.EntityID{loot.GetID()})
+	summary.SetLootTaken(loot.GetID(), false)
+
+	inv := &gear.Inventory{}
+	left := summary.ApplyLoot(em, inv)
+
+	if len(inv.InventoryContent) != 0 {
+		t.Error("declined loot should not enter the inventory")
+	}
+	if len(left) != 1 || left[0] != loot {
+		t.Error("declined loot should be returned for map placement")
+	}
+}
+
+func TestSummaryIsSerializable(t *testing.T) {
+
+	em := newTestManager()
+	registerTestTemplates()
+
+	record, attackers, _ := fixtureBattle(em)
+	summary := BuildPostBattleSummary(em, record, 25, nil)
+
+	data, err := json.Marshal(summary)
+	if err != nil {
+		t.Fatalf("summary did not serialize: %v", err)
+	}
+
+	var roundTrip PostBattleSummary
+	if err := json.Unmarshal(data, &roundTrip); err != nil {
+		t.Fatalf("summary did not deserialize: %v", err)
+	}
+
+	if len(roundTrip.Squads) != 2 || roundTrip.Squads[0].SquadID != attackers.SquadID {
+		t.Error("squad panels did not survive the round trip")
+	}
+	if roundTrip.Squads[0].XPAwarded != 25 {
+		t.Errorf("expected the winner to be awarded 25 XP, got %d", roundTrip.Squads[0].XPAwarded)
+	}
+}
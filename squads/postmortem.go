@@ -0,0 +1,112 @@
+package squads
+
+import (
+	"sort"
+
+	"github.com/bytearena/ecs"
+)
+
+// The post-mortem analyzer answers "what killed my squad" from a battle
+// record alone: which round the squad fell, who did the damage, and which
+// units went down first. Everything is replayed from the event list against
+// the starting snapshots, so it works on reloaded logs too
+
+type DefeatReport struct {
+	SquadName string
+
+	//Name of the squad that won the battle, empty when the analyzed squad
+	//was not actually defeated
+	KilledBy string
+
+	//True when every unit in the squad died
+	Wiped bool
+
+	//The round the last unit fell
+	LossRound int
+
+	//Template names of the squads units in the order they died
+	DeathOrder []string
+
+	//Enemy templates ranked by total damage dealt against the squad
+	TopDamageDealers []DamageDealer
+}
+
+type DamageDealer struct {
+	Template string
+	Damage   int
+}
+
+// AnalyzeSquadDefeat replays the records events against the named squads
+// snapshot. Damage is accumulated per unit until it exceeds the units
+// starting health, which attributes each death to a round without needing
+// per-event health bookkeeping
+func AnalyzeSquadDefeat(record *BattleRecord, squadName string) DefeatReport {
+
+	report := DefeatReport{SquadName: squadName}
+
+	mine, enemy := record.Attacker, record.Defender
+	if record.Defender.Name == squadName {
+		mine, enemy = record.Defender, record.Attacker
+	}
+
+	if record.WinnerSquadID != mine.SquadID {
+		report.KilledBy = enemy.Name
+	}
+
+	health := make(map[ecs.EntityID]int)
+	templates := make(map[ecs.EntityID]string)
+	for _, u := range mine.Units {
+		health[u.EntityID] = u.MaxHealth
+		templates[u.EntityID] = u.TemplateName
+	}
+
+	attackerTemplates := make(map[ecs.EntityID]string)
+	for _, u := range enemy.Units {
+		attackerTemplates[u.EntityID] = u.TemplateName
+	}
+
+	damageBy := make(map[string]int)
+
+	for _, e := range record.Events {
+
+		hp, ours := health[e.DefenderID]
+		if !ours || e.Damage == 0 {
+			continue
+		}
+
+		//Effect ticks have no attacker to credit
+		if template, known := attackerTemplates[e.AttackerID]; known {
+			damageBy[template] += e.Damage
+		}
+
+		if hp <= 0 {
+			continue
+		}
+
+		hp -= e.Damage
+		health[e.DefenderID] = hp
+
+		if hp <= 0 {
+			report.DeathOrder = append(report.DeathOrder, templates[e.DefenderID])
+			report.LossRound = e.Round
+		}
+
+	}
+
+	report.Wiped = len(report.DeathOrder) == len(mine.Units) && len(mine.Units) > 0
+
+	for template, damage := range damageBy {
+		report.TopDamageDealers = append(report.TopDamageDealers, DamageDealer{Template: template, Damage: damage})
+	}
+
+	sort.Slice(report.TopDamageDealers, func(i, j int) bool {
+
+		if report.TopDamageDealers[i].Damage != report.TopDamageDealers[j].Damage {
+			return report.TopDamageDealers[i].Damage > report.TopDamageDealers[j].Damage
+		}
+		return report.TopDamageDealers[i].Template < report.TopDamageDealers[j].Template
+
+	})
+
+	return report
+}
@@ -0,0 +1,84 @@
+package squads
+
+import "testing"
+
+// A hand-built record where the defenders get wiped over two rounds
+func wipedDefenderRecord() *BattleRecord {
+
+	record := &BattleRecord{
+		AttackerSquadID: 1,
+		DefenderSquadID: 2,
+		WinnerSquadID:   1,
+		Rounds:          2,
+		Attacker: SquadSnapshot{
+			SquadID: 1,
+			Name:    "Raiders",
+			Units: []UnitSnapshot{
+				{EntityID: 10, TemplateName: "Brute", MaxHealth: 30},
+				{EntityID: 11, TemplateName: "Archer", MaxHealth: 10},
+			},
+		},
+		Defender: SquadSnapshot{
+			SquadID: 2,
+			Name:    "Defenders",
+			Units: []UnitSnapshot{
+				{EntityID: 20, TemplateName: "Knight", MaxHealth: 12},
+				{EntityID: 21, TemplateName: "Healer", MaxHealth: 8},
+			},
+		},
+	}
+
+	record.Events = []AttackEvent{
+		{AttackerID: 10, DefenderID: 21, Damage: 8, Round: 1},  //healer dies first
+		{AttackerID: 11, DefenderID: 20, Damage: 4, Round: 1},  //knight wounded
+		{AttackerID: 10, DefenderID: 20, Damage: 10, Round: 2}, //knight falls
+	}
+
+	return record
+}
+
+func TestDefeatReportNamesKillerAndLossRound(t *testing.T) {
+
+	report := AnalyzeSquadDefeat(wipedDefenderRecord(), "Defenders")
+
+	if !report.Wiped {
+		t.Fatal("losing every unit should mark the squad as wiped")
+	}
+	if report.KilledBy != "Raiders" {
+		t.Errorf("expected the report to name the killing squad, got %q", report.KilledBy)
+	}
+	if report.LossRound != 2 {
+		t.Errorf("the squad fell in round 2, got %d", report.LossRound)
+	}
+}
+
+func TestDefeatReportDeathOrderAndDamageDealers(t *testing.T) {
+
+	report := AnalyzeSquadDefeat(wipedDefenderRecord(), "Defenders")
+
+	if len(report.DeathOrder) != 2 || report.DeathOrder[0] != "Healer" || report.DeathOrder[1] != "Knight" {
+		t.Errorf("expected the healer to die before the knight, got %v", report.DeathOrder)
+	}
+
+	if len(report.TopDamageDealers) != 2 {
+		t.Fatalf("expected both enemy templates in the ranking, got %v", report.TopDamageDealers)
+	}
+
+	//The brute dealt 18 of the 22 damage
+	if report.TopDamageDealers[0].Template != "Brute" || report.TopDamageDealers[0].Damage != 18 {
+		t.Errorf("expected the brute on top with 18 damage, got %+v", report.TopDamageDealers[0])
+	}
+}
+
+// The winning squad gets no killer attributed
+func TestDefeatReportForTheWinner(t *testing.T) {
+
+	report := AnalyzeSquadDefeat(wipedDefenderRecord(), "Raiders")
+
+	if report.KilledBy != "" {
+		t.Errorf("the winner was not killed by anyone, got %q", report.KilledBy)
+	}
+	if report.Wiped {
+		t.Error("the winner should not read as wiped")
+	}
+}
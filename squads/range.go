@@ -0,0 +1,33 @@
+package squads
+
+import (
+	"game_main/common"
+)
+
+// Range gating for squad combat. Everything that asks "can these squads
+// fight" goes through GetSquadDistance, which delegates to the one shared
+// metric on Position, so the attack button and the attack itself always
+// agree
+
+// GetSquadDistance is the map distance between two squads. Returns -1 when
+// either squad does not exist
+func GetSquadDistance(em *common.EntityManager, squadID int, otherID int) int {
+
+	a := GetSquadByID(em, squadID)
+	b := GetSquadByID(em, otherID)
+
+	if a == nil || b == nil {
+		return -1
+	}
+
+	return a.MapPos.SquadDistance(&b.MapPos)
+}
+
+// CanSquadAttack is the range gate: true when the defender exists and is
+// within attackRange tiles. Melee is attackRange 1, which includes diagonal
+// neighbours
+func CanSquadAttack(em *common.EntityManager, attackerSquadID int, defenderSquadID int, attackRange int) bool {
+
+	distance := GetSquadDistance(em, attackerSquadID, defenderSquadID)
+	return distance >= 0 && distance <= attackRange
+}
@@ -0,0 +1,94 @@
+package squads
+
+import (
+	"game_main/common"
+	"testing"
+)
+
+// Diagonal, straight, and knight's-move offsets against the shared metric.
+// Melee is range 1, so diagonals are adjacent and knight's moves are not,
+// exactly as the 8-directional movement implies
+func TestSquadDistanceOffsets(t *testing.T) {
+
+	cases := []struct {
+		name     string
+		dx, dy   int
+		distance int
+		melee    bool
+	}{
+		{"same tile", 0, 0, 0, true},
+		{"straight", 0, 1, 1, true},
+		{"diagonal", 1, 1, 1, true},
+		{"two straight", 0, 2, 2, false},
+		{"knight's move", 1, 2, 2, false},
+		{"far diagonal", 3, 3, 3, false},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+
+			em := newTestManager()
+			registerTestTemplates()
+
+			att := CreateSquad(em, "Attackers", "Player", common.Position{X: 5, Y: 5}, DirDown)
+			def := CreateSquad(em, "Defenders", "Enemy", common.Position{X: 5 + tc.dx, Y: 5 + tc.dy}, DirUp)
+
+			if got := GetSquadDistance(em, att.SquadID, def.SquadID); got != tc.distance {
+				t.Errorf("offset (%d,%d): expected distance %d, got %d", tc.dx, tc.dy, tc.distance, got)
+			}
+
+			if got := CanSquadAttack(em, att.SquadID, def.SquadID, 1); got != tc.melee {
+				t.Errorf("offset (%d,%d): expected melee reachable %v, got %v", tc.dx, tc.dy, tc.melee, got)
+			}
+
+		})
+	}
+}
+
+// A missing squad can never be in range
+func TestSquadDistanceMissingSquad(t *testing.T) {
+
+	em := newTestManager()
+	registerTestTemplates()
+
+	att := CreateSquad(em, "Attackers", "Player", common.Position{X: 5, Y: 5}, DirDown)
+
+	if GetSquadDistance(em, att.SquadID, 999) != -1 {
+		t.Error("a missing squad should report distance -1")
+	}
+	if CanSquadAttack(em, att.SquadID, 999, 10) {
+		t.Error("a missing squad must not be attackable")
+	}
+}
+
+// The previewed participant set matches who actually attacked
+func TestPreviewMatchesExecution(t *testing.T) {
+
+	em := newTestManager()
+	registerTestTemplates()
+
+	att := CreateSquad(em, "Attackers", "Player", common.Position{X: 5, Y: 3}, DirDown)
+	for col := 0; col < GridWidth; col++ {
+		CreateUnitFromTemplate(em, Units["TestRogue"], att.SquadID, 0, col)
+	}
+	att.MaxAttacksPerRound = 2
+
+	//A defender tanky enough that it cannot be wiped mid-round
+	def := CreateSquad(em, "Defenders", "Enemy", common.Position{X: 5, Y: 4}, DirUp)
+	tank := CreateUnitFromTemplate(em, Units["TestKnight"], def.SquadID, 0, 0)
+	common.GetAttributes(tank).MaxHealth = 1000
+	common.GetAttributes(tank).CurrentHealth = 1000
+
+	preview := PreviewSquadAttack(em, att.SquadID)
+	events := ExecuteSquadAttack(em, att.SquadID, def.SquadID, DirDown)
+
+	if len(events) != len(preview) {
+		t.Fatalf("preview promised %d attacks, execution ran %d", len(preview), len(events))
+	}
+
+	for i := range events {
+		if events[i].AttackerID != preview[i] {
+			t.Errorf("attack %d: preview said unit %d, execution used %d", i, preview[i], events[i].AttackerID)
+		}
+	}
+}
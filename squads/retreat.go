@@ -0,0 +1,118 @@
+package squads
+
+import (
+	"fmt"
+	"game_main/common"
+
+	"github.com/bytearena/ecs"
+)
+
+// Retreat and pinning. A battered squad can fall back to a free tile once
+// its health drops below the retreat threshold - unless an adjacent enemy
+// squad has pinned it. The pin holds only while the pinner is alive and
+// adjacent, so killing or shaking off the pinner frees the squad
+
+type RetreatConfig struct {
+	//Fraction of total health below which a squad is allowed to retreat
+	HealthThreshold float64
+}
+
+var Retreat = RetreatConfig{HealthThreshold: 0.5}
+
+var PinComponent *ecs.Component
+
+// PinData lives on the pinned squads entity and names the squad holding it
+type PinData struct {
+	PinnerSquadID int
+}
+
+func GetPinData(em *common.EntityManager, squadID int) *PinData {
+
+	if squad := GetSquadEntity(em, squadID); squad != nil {
+		return common.GetComponentType[*PinData](squad, PinComponent)
+	}
+
+	return nil
+}
+
+// PinSquad lets an engaging squad pin an adjacent enemy in place
+func PinSquad(em *common.EntityManager, pinnerSquadID int, targetSquadID int) error {
+
+	pinner := GetSquadByID(em, pinnerSquadID)
+	target := GetSquadEntity(em, targetSquadID)
+	if pinner == nil || target == nil {
+		return fmt.Errorf("no squad with ID %d", targetSquadID)
+	}
+
+	if pinner.Faction == GetSquadData(target).Faction {
+		return fmt.Errorf("cannot pin an allied squad")
+	}
+	if GetSquadDistance(em, pinnerSquadID, targetSquadID) > 1 {
+		return fmt.Errorf("%s is not adjacent", GetSquadData(target).Name)
+	}
+
+	target.AddComponent(PinComponent, &PinData{PinnerSquadID: pinnerSquadID})
+	return nil
+}
+
+// PinnedBy is the squad currently holding this one in place, or 0. A stale
+// pin whose pinner died or moved away no longer counts
+func PinnedBy(em *common.EntityManager, squadID int) int {
+
+	pin := GetPinData(em, squadID)
+	if pin == nil {
+		return 0
+	}
+
+	if !squadHasLivingUnits(em, pin.PinnerSquadID) {
+		return 0
+	}
+	if GetSquadDistance(em, squadID, pin.PinnerSquadID) > 1 {
+		return 0
+	}
+
+	return pin.PinnerSquadID
+}
+
+// squadHealthFraction is current health over max across the living units
+func squadHealthFraction(em *common.EntityManager, squadID int) float64 {
+
+	current, max := 0, 0
+	for _, u := range SquadUnits(em, squadID) {
+
+		attr := common.GetAttributes(u)
+		if attr.CurrentHealth > 0 {
+			current += attr.CurrentHealth
+		}
+		max += attr.MaxHealth
+
+	}
+
+	if max == 0 {
+		return 0
+	}
+
+	return float64(current) / float64(max)
+}
+
+// AttemptRetreat pulls a battered squad back to the destination tile.
+// Fails when the squad is still healthy enough to fight, or when an
+// adjacent living enemy has it pinned
+func AttemptRetreat(em *common.EntityManager, squadID int, dest common.Position) error {
+
+	squad := GetSquadByID(em, squadID)
+	if squad == nil {
+		return fmt.Errorf("no squad with ID %d", squadID)
+	}
+
+	if squadHealthFraction(em, squadID) >= Retreat.HealthThreshold {
+		return fmt.Errorf("%s is not battered enough to retreat", squad.Name)
+	}
+
+	if pinner := PinnedBy(em, squadID); pinner != 0 {
+		return fmt.Errorf("%s is pinned by %s", squad.Name, GetSquadByID(em, pinner).Name)
+	}
+
+	syncSquadPosition(em, squad, dest)
+	return nil
+}
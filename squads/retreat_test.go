@@ -0,0 +1,92 @@
+package squads
+
+import (
+	"game_main/common"
+	"testing"
+)
+
+// Pinned and battered: the squad stays put while the pinner lives
+func TestPinnedSquadCannotRetreatWhilePinnerLives(t *testing.T) {
+
+	em := newTestManager()
+	registerTestTemplates()
+
+	battered := CreateSquad(em, "Battered", "Player", common.Position{X: 5, Y: 5}, DirDown)
+	unit := CreateUnitFromTemplate(em, Units["TestKnight"], battered.SquadID, 0, 0)
+	common.GetAttributes(unit).CurrentHealth = 4
+
+	pinner := CreateSquad(em, "Pinners", "Enemy", common.Position{X: 5, Y: 6}, DirUp)
+	enemy := CreateUnitFromTemplate(em, Units["TestKnight"], pinner.SquadID, 0, 0)
+
+	if err := PinSquad(em, pinner.SquadID, battered.SquadID); err != nil {
+		t.Fatalf("pinning an adjacent enemy should work, got %v", err)
+	}
+
+	if err := AttemptRetreat(em, battered.SquadID, common.Position{X: 5, Y: 2}); err == nil {
+		t.Fatal("a pinned squad must not be able to retreat while the pinner lives")
+	}
+
+	//Killing the pinner releases the pin without any cleanup call
+	common.GetAttributes(enemy).CurrentHealth = 0
+
+	if err := AttemptRetreat(em, battered.SquadID, common.Position{X: 5, Y: 2}); err != nil {
+		t.Fatalf("retreat should succeed once the pinner is dead, got %v", err)
+	}
+	if battered.MapPos.X != 5 || battered.MapPos.Y != 2 {
+		t.Errorf("expected the squad to fall back to (5,2), got %v", battered.MapPos)
+	}
+}
+
+// A healthy squad holds the line, and a pinner that moved away no longer pins
+func TestRetreatThresholdAndStalePins(t *testing.T) {
+
+	em := newTestManager()
+	registerTestTemplates()
+
+	squad := CreateSquad(em, "Steady", "Player", common.Position{X: 5, Y: 5}, DirDown)
+	unit := CreateUnitFromTemplate(em, Units["TestKnight"], squad.SquadID, 0, 0)
+
+	if err := AttemptRetreat(em, squad.SquadID, common.Position{X: 5, Y: 2}); err == nil {
+		t.Fatal("a squad at full strength must not retreat")
+	}
+
+	pinner := CreateSquad(em, "Pinners", "Enemy", common.Position{X: 5, Y: 6}, DirUp)
+	CreateUnitFromTemplate(em, Units["TestKnight"], pinner.SquadID, 0, 0)
+
+	if err := PinSquad(em, pinner.SquadID, squad.SquadID); err != nil {
+		t.Fatalf("pinning an adjacent enemy should work, got %v", err)
+	}
+
+	common.GetAttributes(unit).CurrentHealth = 4
+	pinner.MapPos = common.Position{X: 5, Y: 9}
+
+	if got := PinnedBy(em, squad.SquadID); got != 0 {
+		t.Errorf("a pinner that moved away should not count, got squad %d", got)
+	}
+	if err := AttemptRetreat(em, squad.SquadID, common.Position{X: 5, Y: 2}); err != nil {
+		t.Fatalf("retreat should succeed once the pinner is out of reach, got %v", err)
+	}
+}
+
+// Pins only land on adjacent enemies
+func TestPinRequiresAdjacentEnemy(t *testing.T) {
+
+	em := newTestManager()
+	registerTestTemplates()
+
+	pinner := CreateSquad(em, "Pinners", "Enemy", common.Position{X: 5, Y: 5}, DirDown)
+	CreateUnitFromTemplate(em, Units["TestKnight"], pinner.SquadID, 0, 0)
+
+	ally := CreateSquad(em, "Friends", "Enemy", common.Position{X: 5, Y: 6}, DirUp)
+	CreateUnitFromTemplate(em, Units["TestKnight"], ally.SquadID, 0, 0)
+
+	far := CreateSquad(em, "Far", "Player", common.Position{X: 5, Y: 9}, DirUp)
+	CreateUnitFromTemplate(em, Units["TestKnight"], far.SquadID, 0, 0)
+
+	if err := PinSquad(em, pinner.SquadID, ally.SquadID); err == nil {
+		t.Error("pinning an allied squad must fail")
+	}
+	if err := PinSquad(em, pinner.SquadID, far.SquadID); err == nil {
+		t.Error("pinning a squad out of reach must fail")
+	}
+}
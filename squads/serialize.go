@@ -0,0 +1,161 @@
+package squads
+
+import (
+	"game_main/common"
+
+	"github.com/bytearena/ecs"
+)
+
+// Squad serialization for savegames. Entity IDs are runtime-assigned, so the
+// saved form keeps each units old ID purely so cross-references (guardian
+// links) can be rewired through a common.IDRemapper on load. Loading never
+// trusts a stored entity ID directly
+
+type SavedUnit struct {
+	//The units entity ID in the world that wrote the save. Only used as a
+	//remapping key, never resolved directly
+	EntityID ecs.EntityID `json:"entityID"`
+
+	Unit  UnitData          `json:"unit"`
+	Attr  common.Attributes `json:"attr"`
+	Level UnitLevelData     `json:"level"`
+
+	//ProtectedUnitID inside is an old-world ID that gets remapped on load
+	Guardian *GuardianData `json:"guardian,omitempty"`
+
+	//Armor durability damage outlives battles, so it rides along in saves
+	ArmorWear int `json:"armorWear,omitempty"`
+}
+
+type SavedSquad struct {
+	Squad      SquadData        `json:"squad"`
+	Appearance *SquadAppearance `json:"appearance,omitempty"`
+	Bag        *ConsumableBag   `json:"bag,omitempty"`
+	Units      []SavedUnit      `json:"units"`
+}
+
+// SerializeSquads captures every squad and its units in saveable form
+func SerializeSquads(em *common.EntityManager) []SavedSquad {
+
+	saved := []SavedSquad{}
+	for _, s := range em.World.Query(em.WorldTags["squads"]) {
+
+		data := GetSquadData(s.Entity)
+		savedSquad := SavedSquad{Squad: *data}
+
+		if appearance := common.GetComponentType[*SquadAppearance](s.Entity, SquadAppearanceComponent); appearance != nil {
+			copied := *appearance
+			savedSquad.Appearance = &copied
+		}
+		if bag := common.GetComponentType[*ConsumableBag](s.Entity, ConsumableBagComponent); bag != nil {
+			copied := *bag
+			savedSquad.Bag = &copied
+		}
+
+		for _, u := range SquadUnits(em, data.SquadID) {
+
+			savedUnit := SavedUnit{
+				EntityID: u.GetID(),
+				Unit:     *GetUnitData(u),
+				Attr:     *common.GetAttributes(u),
+			}
+
+			if level := GetUnitLevelData(u); level != nil {
+				savedUnit.Level = *level
+			}
+			if guardian := GetGuardianData(u); guardian != nil {
+				copied := *guardian
+				savedUnit.Guardian = &copied
+			}
+			if effects := common.GetComponentType[*UnitEffects](u, UnitEffectsComponent); effects != nil {
+				savedUnit.ArmorWear = effects.ArmorWear
+			}
+
+			savedSquad.Units = append(savedSquad.Units, savedUnit)
+
+		}
+
+		saved = append(saved, savedSquad)
+
+	}
+
+	return saved
+}
+
+// DeserializeSquads rebuilds the squads in two phases: first every entity is
+// recreated and its old ID recorded, then the cross-references are rewired
+// through the remapper. A reference to an entity that was never recreated
+// fails the whole load
+func DeserializeSquads(em *common.EntityManager, saved []SavedSquad) error {
+
+	remapper := common.NewIDRemapper()
+	recreated := make(map[ecs.EntityID]*ecs.Entity)
+
+	for _, savedSquad := range saved {
+
+		data := savedSquad.Squad
+		squad := em.World.NewEntity().
+			AddComponent(SquadComponent, &data).
+			AddComponent(common.PositionComponent, &common.Position{X: data.MapPos.X, Y: data.MapPos.Y})
+
+		if savedSquad.Appearance != nil {
+			appearance := *savedSquad.Appearance
+			squad.AddComponent(SquadAppearanceComponent, &appearance)
+		}
+		if savedSquad.Bag != nil {
+			bag := *savedSquad.Bag
+			squad.AddComponent(ConsumableBagComponent, &bag)
+		}
+
+		//Keep the ID counter ahead of every loaded squad so new squads
+		//can't collide
+		if data.SquadID > squadIDCounter {
+			squadIDCounter = data.SquadID
+		}
+
+		for _, savedUnit := range savedSquad.Units {
+
+			unitData := savedUnit.Unit
+			attr := savedUnit.Attr
+			level := savedUnit.Level
+
+			unit := em.World.NewEntity().
+				AddComponent(UnitComponent, &unitData).
+				AddComponent(common.NameComponent, &common.Name{NameStr: unitData.TemplateName}).
+				AddComponent(common.AttributeComponent, &attr).
+				AddComponent(UnitLevelComponent, &level)
+
+			if savedUnit.ArmorWear > 0 {
+				unit.AddComponent(UnitEffectsComponent, &UnitEffects{ArmorWear: savedUnit.ArmorWear})
+			}
+
+			if err := remapper.Record(savedUnit.EntityID, unit.GetID()); err != nil {
+				return err
+			}
+			recreated[savedUnit.EntityID] = unit
+
+		}
+
+	}
+
+	//Second phase: rewire the guardian links through the remapper
+	for _, savedSquad := range saved {
+		for _, savedUnit := range savedSquad.Units {
+
+			if savedUnit.Guardian == nil {
+				continue
+			}
+
+			newProtectedID, err := remapper.Remap(savedUnit.Guardian.ProtectedUnitID)
+			if err != nil {
+				continue //VerifyComplete reports every broken reference at once
+			}
+
+			guardian := recreated[savedUnit.EntityID]
+			guardian.AddComponent(GuardianComponent, &GuardianData{ProtectedUnitID: newProtectedID})
+
+		}
+	}
+
+	return remapper.VerifyComplete()
+}
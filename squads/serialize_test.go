@@ -0,0 +1,133 @@
+package squads
+
+import (
+	"game_main/common"
+	"testing"
+)
+
+// Builds a world with cross-linked guardians and returns its saved form
+func buildCrossLinkedSave(t *testing.T) []SavedSquad {
+
+	t.Helper()
+
+	em := newTestManager()
+	registerTestTemplates()
+
+	first := CreateSquad(em, "First", "Player", common.Position{X: 1, Y: 1}, DirUp)
+	firstCharge := CreateUnitFromTemplate(em, Units["TestRogue"], first.SquadID, 0, 0)
+	firstGuardian := CreateUnitFromTemplate(em, Units["TestKnight"], first.SquadID, 0, 1)
+	AssignGuardian(firstGuardian, firstCharge)
+
+	second := CreateSquad(em, "Second", "Enemy", common.Position{X: 8, Y: 8}, DirDown)
+	secondCharge := CreateUnitFromTemplate(em, Units["TestRogue"], second.SquadID, 1, 1)
+	secondGuardian := CreateUnitFromTemplate(em, Units["TestKnight"], second.SquadID, 1, 2)
+	AssignGuardian(secondGuardian, secondCharge)
+
+	return SerializeSquads(em)
+}
+
+// Every guardian must point at the structurally correct unit after a reload,
+// regardless of what entity IDs the new world hands out
+func assertGuardianLinks(t *testing.T, em *common.EntityManager) {
+
+	t.Helper()
+
+	checked := 0
+	for _, s := range em.World.Query(em.WorldTags["squadunits"]) {
+
+		guardian := GetGuardianData(s.Entity)
+		if guardian == nil {
+			continue
+		}
+
+		protected := FindUnitByID(em, guardian.ProtectedUnitID)
+		if protected == nil {
+			t.Fatalf("guardian link points at entity %d which is not a unit", guardian.ProtectedUnitID)
+		}
+
+		gData := GetUnitData(s.Entity)
+		pData := GetUnitData(protected)
+
+		if pData.SquadID != gData.SquadID {
+			t.Errorf("guardian in squad %d protects a unit in squad %d", gData.SquadID, pData.SquadID)
+		}
+		if pData.TemplateName != "TestRogue" {
+			t.Errorf("guardian should protect the rogue, protects %s", pData.TemplateName)
+		}
+
+		checked++
+
+	}
+
+	if checked != 2 {
+		t.Errorf("expected 2 guardian links after reload, found %d", checked)
+	}
+}
+
+func TestReloadRewiresGuardianLinks(t *testing.T) {
+
+	saved := buildCrossLinkedSave(t)
+
+	//Forward order
+	forward := newTestManager()
+	if err := DeserializeSquads(forward, saved); err != nil {
+		t.Fatalf("forward load failed: %v", err)
+	}
+	assertGuardianLinks(t, forward)
+
+	//Reversed squad and unit order hands out entity IDs differently, the
+	//links must still land on the right units
+	reversed := make([]SavedSquad, 0, len(saved))
+	for i := len(saved) - 1; i >= 0; i-- {
+
+		squad := saved[i]
+		units := make([]SavedUnit, 0, len(squad.Units))
+		for j := len(squad.Units) - 1; j >= 0; j-- {
+			units = append(units, squad.Units[j])
+		}
+		squad.Units = units
+
+		reversed = append(reversed, squad)
+
+	}
+
+	backward := newTestManager()
+	if err := DeserializeSquads(backward, reversed); err != nil {
+		t.Fatalf("reversed load failed: %v", err)
+	}
+	assertGuardianLinks(t, backward)
+}
+
+// A save referencing a unit that no longer exists must fail loudly instead of
+// wiring the guardian to whatever entity got that ID
+func TestDanglingReferenceFailsTheLoad(t *testing.T) {
+
+	saved := buildCrossLinkedSave(t)
+
+	//Drop the first squads charge, leaving its guardian link dangling
+	saved[0].Units = saved[0].Units[1:]
+
+	em := newTestManager()
+	if err := DeserializeSquads(em, saved); err == nil {
+		t.Fatal("expected the dangling guardian reference to fail the load")
+	}
+}
+
+func TestRemapperRejectsUnknownAndDuplicateIDs(t *testing.T) {
+
+	remapper := common.NewIDRemapper()
+
+	if err := remapper.Record(1, 10); err != nil {
+		t.Fatalf("first record failed: %v", err)
+	}
+	if err := remapper.Record(1, 11); err == nil {
+		t.Error("recording the same old ID with a different new ID should fail")
+	}
+
+	if _, err := remapper.Remap(2); err == nil {
+		t.Error("remapping an unknown ID should be a hard error")
+	}
+	if err := remapper.VerifyComplete(); err == nil {
+		t.Error("VerifyComplete should report the unresolved reference")
+	}
+}
@@ -0,0 +1,45 @@
+package squads
+
+import (
+	"game_main/common"
+
+	"github.com/bytearena/ecs"
+)
+
+// Simultaneous resolution: both squads compute their round against the
+// pre-round state, so neither side benefits from the others casualties
+// mid-round. A unit that dies this round still deals the damage it had
+// coming. The battle loop snapshots health around the paired volleys;
+// off by default, the classic alternating rounds stay the baseline
+
+type SimultaneousConfig struct {
+	Enabled bool
+}
+
+var Simultaneous = SimultaneousConfig{}
+
+// SnapshotSquadHealth captures the current health of every unit in the
+// squad, keyed by entity ID
+func SnapshotSquadHealth(em *common.EntityManager, squadID int) map[ecs.EntityID]int {
+
+	snapshot := make(map[ecs.EntityID]int)
+	for _, u := range SquadUnits(em, squadID) {
+		snapshot[u.GetID()] = common.GetAttributes(u).CurrentHealth
+	}
+
+	return snapshot
+}
+
+// RestoreSquadHealth puts a snapshot back. Units missing from the snapshot
+// are left alone
+func RestoreSquadHealth(em *common.EntityManager, squadID int, snapshot map[ecs.EntityID]int) {
+
+	for _, u := range SquadUnits(em, squadID) {
+
+		if health, ok := snapshot[u.GetID()]; ok {
+			common.GetAttributes(u).CurrentHealth = health
+		}
+
+	}
+
+}
@@ -0,0 +1,138 @@
+package squads
+
+import (
+	"game_main/common"
+)
+
+// Battle runner AI for picking which enemy squad to attack. The default rule
+// scores squads by how dangerous they are, but with PreferFinishingBlows on
+// the AI first looks for a squad it can destroy this turn, since a dead squad
+// deals no damage back
+
+type AIConfig struct {
+	//Prefer targets that PredictCombatOutcome says die this turn over
+	//higher-value-but-survivable ones
+	PreferFinishingBlows bool
+}
+
+var DefaultAIConfig = AIConfig{PreferFinishingBlows: true}
+
+// CombatPrediction is a cheap estimate, not a simulation. Expected damage uses
+// average weapon rolls and ignores dodge, so it slightly overestimates
+type CombatPrediction struct {
+	ExpectedDamage int
+	DefenderHealth int
+	Lethal         bool
+}
+
+// PredictCombatOutcome estimates what one round of attacks from every living
+// unit of the attacker would do to the defender squad
+func PredictCombatOutcome(em *common.EntityManager, attackerSquadID int, defenderSquadID int) CombatPrediction {
+
+	prediction := CombatPrediction{}
+
+	for _, u := range SquadUnits(em, attackerSquadID) {
+
+		if common.GetAttributes(u).CurrentHealth <= 0 {
+			continue
+		}
+
+		template := UnitTemplateFor(u)
+		prediction.ExpectedDamage += (template.MinDamage + template.MaxDamage) / 2
+
+	}
+
+	for _, u := range SquadUnits(em, defenderSquadID) {
+
+		hp := common.GetAttributes(u).CurrentHealth
+		if hp > 0 {
+			prediction.DefenderHealth += hp
+		}
+
+	}
+
+	prediction.Lethal = prediction.ExpectedDamage >= prediction.DefenderHealth && prediction.DefenderHealth > 0
+	return prediction
+}
+
+// ThreatScore is how much average damage a squad puts out per round
+func ThreatScore(em *common.EntityManager, squadID int) int {
+
+	score := 0
+	for _, u := range SquadUnits(em, squadID) {
+
+		if common.GetAttributes(u).CurrentHealth <= 0 {
+			continue
+		}
+
+		template := UnitTemplateFor(u)
+		score += (template.MinDamage + template.MaxDamage) / 2
+
+	}
+
+	return score
+}
+
+// ChooseTargetSquad picks which of the in-range enemy squads to attack.
+// Returns 0 if there are no candidates
+func ChooseTargetSquad(em *common.EntityManager, attackerSquadID int, candidateIDs []int, config AIConfig) int {
+
+	//Candidates the diplomacy matrix puts off-limits drop out here, so a
+	//stance change mid-game reshapes the AIs valid-target set immediately
+	if attacker := GetSquadByID(em, attackerSquadID); attacker != nil {
+
+		hostile := []int{}
+		for _, id := range candidateIDs {
+
+			if target := GetSquadByID(em, id); target != nil && ActiveDiplomacy.Hostile(attacker.Faction, target.Faction) {
+				hostile = append(hostile, id)
+			}
+
+		}
+		candidateIDs = hostile
+
+	}
+
+	if len(candidateIDs) == 0 {
+		return 0
+	}
+
+	if config.PreferFinishingBlows {
+
+		bestKillable := 0
+		bestHealth := 0
+		for _, id := range candidateIDs {
+
+			prediction := PredictCombatOutcome(em, attackerSquadID, id)
+			if !prediction.Lethal {
+				continue
+			}
+
+			//Among killable squads, finish the healthiest one we can still
+			//kill, since that removes the most incoming damage
+			if bestKillable == 0 || prediction.DefenderHealth > bestHealth {
+				bestKillable = id
+				bestHealth = prediction.DefenderHealth
+			}
+
+		}
+
+		if bestKillable != 0 {
+			return bestKillable
+		}
+
+	}
+
+	best := candidateIDs[0]
+	bestScore := ThreatScore(em, best)
+	for _, id := range candidateIDs[1:] {
+
+		if score := ThreatScore(em, id); score > bestScore {
+			best = id
+			bestScore = score
+		}
+
+	}
+
+	return best
+}
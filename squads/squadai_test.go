@@ -0,0 +1,70 @@
+package squads
+
+import (
+	"game_main/common"
+	"testing"
+)
+
+// A weak squad the attacker can kill this turn and a strong one that survives
+func killableAndStrongSquads(em *common.EntityManager) (attacker, weak, strong *SquadData) {
+
+	attacker = CreateSquad(em, "Attackers", "Player", common.Position{X: 1, Y: 1}, DirDown)
+	for col := 0; col < GridWidth; col++ {
+		CreateUnitFromTemplate(em, Units["TestKnight"], attacker.SquadID, 0, col)
+	}
+
+	weak = CreateSquad(em, "Stragglers", "Enemy", common.Position{X: 1, Y: 3}, DirUp)
+	wounded := CreateUnitFromTemplate(em, Units["TestRogue"], weak.SquadID, 0, 0)
+	common.GetAttributes(wounded).CurrentHealth = 2
+
+	strong = CreateSquad(em, "Shield Wall", "Enemy", common.Position{X: 3, Y: 3}, DirUp)
+	for col := 0; col < GridWidth; col++ {
+		CreateUnitFromTemplate(em, Units["TestKnight"], strong.SquadID, 0, col)
+		CreateUnitFromTemplate(em, Units["TestKnight"], strong.SquadID, 1, col)
+	}
+
+	return attacker, weak, strong
+}
+
+func TestAIPrefersKillableSquad(t *testing.T) {
+
+	em := newTestManager()
+	registerTestTemplates()
+
+	attacker, weak, strong := killableAndStrongSquads(em)
+	candidates := []int{strong.SquadID, weak.SquadID}
+
+	config := AIConfig{PreferFinishingBlows: true}
+	if got := ChooseTargetSquad(em, attacker.SquadID, candidates, config); got != weak.SquadID {
+		t.Errorf("expected the AI to finish the killable squad %d, chose %d", weak.SquadID, got)
+	}
+}
+
+func TestAIUsesThreatScoreWithToggleOff(t *testing.T) {
+
+	em := newTestManager()
+	registerTestTemplates()
+
+	attacker, weak, strong := killableAndStrongSquads(em)
+	candidates := []int{weak.SquadID, strong.SquadID}
+
+	config := AIConfig{PreferFinishingBlows: false}
+	if got := ChooseTargetSquad(em, attacker.SquadID, candidates, config); got != strong.SquadID {
+		t.Errorf("expected the AI to pick the biggest threat %d, chose %d", strong.SquadID, got)
+	}
+}
+
+func TestPredictCombatOutcomeLethal(t *testing.T) {
+
+	em := newTestManager()
+	registerTestTemplates()
+
+	attacker, weak, strong := killableAndStrongSquads(em)
+
+	if !PredictCombatOutcome(em, attacker.SquadID, weak.SquadID).Lethal {
+		t.Error("expected the weak squad to be predicted lethal")
+	}
+	if PredictCombatOutcome(em, attacker.SquadID, strong.SquadID).Lethal {
+		t.Error("expected the strong squad to survive the prediction")
+	}
+}
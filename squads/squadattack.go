@@ -0,0 +1,131 @@
+package squads
+
+import (
+	"game_main/common"
+	"sort"
+
+	"github.com/bytearena/ecs"
+)
+
+// Squad-level attack execution: every living unit attacks once per round in
+// initiative order, unless the squads MaxAttacksPerRound cap cuts the round
+// short. The simulator and the battle runner both go through this
+
+// Counterattack timing. Off, a full squad volley resolves and then the whole
+// defending squad answers. Interleaved, each defender counters immediately
+// after being attacked if it survived, which matters when a volley would
+// otherwise kill it before it ever swings
+type CounterattackConfig struct {
+	Interleaved bool
+}
+
+var Counterattack = CounterattackConfig{}
+
+// FrontmostLivingUnit is the default target: targets fill up from the front
+// row first, like the old all-or-nothing combat
+func FrontmostLivingUnit(em *common.EntityManager, squadID int) *ecs.Entity {
+
+	var best *ecs.Entity = nil
+	bestRow, bestCol := 0, 0
+
+	for _, u := range SquadUnits(em, squadID) {
+
+		if common.GetAttributes(u).CurrentHealth <= 0 {
+			continue
+		}
+
+		data := GetUnitData(u)
+		if best == nil || data.Row < bestRow || (data.Row == bestRow && data.Col < bestCol) {
+			best = u
+			bestRow, bestCol = data.Row, data.Col
+		}
+
+	}
+
+	return best
+}
+
+// The attack order inside a squad: initiative first, grid position breaks ties
+func attackersByInitiative(em *common.EntityManager, squadID int) []*ecs.Entity {
+
+	attackers := make([]*ecs.Entity, 0)
+	for _, u := range SquadUnits(em, squadID) {
+
+		if common.GetAttributes(u).CurrentHealth > 0 {
+			attackers = append(attackers, u)
+		}
+
+	}
+
+	sort.SliceStable(attackers, func(i, j int) bool {
+
+		a, b := UnitTemplateFor(attackers[i]), UnitTemplateFor(attackers[j])
+		if a.Initiative != b.Initiative {
+			return a.Initiative > b.Initiative
+		}
+
+		aData, bData := GetUnitData(attackers[i]), GetUnitData(attackers[j])
+		if aData.Row != bData.Row {
+			return aData.Row < bData.Row
+		}
+		return aData.Col < bData.Col
+
+	})
+
+	return attackers
+}
+
+// PreviewSquadAttack is the units that would swing this round, in order: the
+// living attackers by initiative, cut off at the squads attack cap. The GUI
+// preview shows exactly this set and ExecuteSquadAttack iterates it, so the
+// two can only diverge when the defender is wiped mid-round
+func PreviewSquadAttack(em *common.EntityManager, attackerSquadID int) []ecs.EntityID {
+
+	maxAttacks := 0
+	if squad := GetSquadByID(em, attackerSquadID); squad != nil {
+		maxAttacks = squad.MaxAttacksPerRound
+	}
+
+	participants := []ecs.EntityID{}
+	for _, attacker := range attackersByInitiative(em, attackerSquadID) {
+
+		if maxAttacks > 0 && len(participants) >= maxAttacks {
+			break
+		}
+
+		participants = append(participants, attacker.GetID())
+
+	}
+
+	return participants
+}
+
+// ExecuteSquadAttack runs one round of attacks from one squad against another
+// and returns the events. Attackers go in initiative order and the round stops
+// once the cap is reached or the defender has nothing left to hit
+func ExecuteSquadAttack(em *common.EntityManager, attackerSquadID int, defenderSquadID int, mapAttackDir Direction) []AttackEvent {
+
+	events := []AttackEvent{}
+	for _, attackerID := range PreviewSquadAttack(em, attackerSquadID) {
+
+		target := FrontmostLivingUnit(em, defenderSquadID)
+		if target == nil {
+			break
+		}
+
+		events = append(events, ResolveUnitAttack(em, attackerID, target.GetID(), mapAttackDir))
+
+		//A surviving target answers right away instead of waiting for its
+		//squads own volley
+		if Counterattack.Interleaved && common.GetAttributes(target).CurrentHealth > 0 {
+
+			counter := ResolveUnitAttack(em, target.GetID(), attackerID, mapAttackDir.Opposite())
+			counter.Counter = true
+			events = append(events, counter)
+
+		}
+
+	}
+
+	return events
+}
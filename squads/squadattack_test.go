@@ -0,0 +1,125 @@
+package squads
+
+import (
+	"game_main/common"
+	"testing"
+)
+
+// Five attackers with a cap of three: exactly three attacks happen and the
+// three highest initiative units are the ones that attack
+func TestMaxAttacksPerRoundHonorsInitiative(t *testing.T) {
+
+	em := newTestManager()
+
+	//Five attackers with distinct initiatives
+	for i := 1; i <= 5; i++ {
+		name := string(rune('A' + i - 1))
+		Units["Init"+name] = UnitTemplate{
+			Name:       "Init" + name,
+			Attr:       common.NewBaseAttributes(10, 0, 10, 0, 1, 0),
+			MinDamage:  1,
+			MaxDamage:  1,
+			Initiative: i,
+		}
+	}
+
+	att := CreateSquad(em, "Cappers", "Player", common.Position{X: 5, Y: 3}, DirDown)
+	att.MaxAttacksPerRound = 3
+
+	attackerIDs := make(map[string]uint32)
+	for i := 1; i <= 5; i++ {
+		name := "Init" + string(rune('A'+i-1))
+		u := CreateUnitFromTemplate(em, Units[name], att.SquadID, (i-1)/3, (i-1)%3)
+		attackerIDs[name] = uint32(u.GetID())
+	}
+
+	Units["Wall"] = UnitTemplate{Name: "Wall", Attr: common.NewBaseAttributes(100, 0, 10, 0, 1, 0), MinDamage: 1, MaxDamage: 1}
+	def := CreateSquad(em, "Wall", "Enemy", common.Position{X: 5, Y: 5}, DirUp)
+	CreateUnitFromTemplate(em, Units["Wall"], def.SquadID, 0, 0)
+
+	events := ExecuteSquadAttack(em, att.SquadID, def.SquadID, DirDown)
+
+	if len(events) != 3 {
+		t.Fatalf("expected exactly 3 attack events with a cap of 3, got %d", len(events))
+	}
+
+	//Initiative 5, 4, 3 are the ones that get to act, in that order
+	expected := []string{"InitE", "InitD", "InitC"}
+	for i, name := range expected {
+		if uint32(events[i].AttackerID) != attackerIDs[name] {
+			t.Errorf("attack %d should come from %s", i, name)
+		}
+	}
+}
+
+func TestUncappedSquadAttacksWithEveryUnit(t *testing.T) {
+
+	em := newTestManager()
+	registerTestTemplates()
+
+	att := CreateSquad(em, "Horde", "Player", common.Position{X: 5, Y: 3}, DirDown)
+	for col := 0; col < 3; col++ {
+		CreateUnitFromTemplate(em, Units["TestRogue"], att.SquadID, 0, col)
+	}
+
+	def := CreateSquad(em, "Wall", "Enemy", common.Position{X: 5, Y: 5}, DirUp)
+	CreateUnitFromTemplate(em, Units["TestKnight"], def.SquadID, 0, 0)
+
+	events := ExecuteSquadAttack(em, att.SquadID, def.SquadID, DirDown)
+
+	if len(events) != 3 {
+		t.Errorf("an uncapped squad of 3 should produce 3 events, got %d", len(events))
+	}
+}
+
+// Three rogues would kill the lone defender over the course of the volley.
+// Interleaved, the defender survives the first hit and answers it before the
+// rest of the volley finishes it off; in the default mode it never swings
+func TestInterleavedCountersLetTheDefenderAnswer(t *testing.T) {
+
+	runVolleyAgainstLoneDefender := func(interleaved bool) []AttackEvent {
+
+		em := newTestManager()
+		registerTestTemplates()
+
+		originalRoll := dodgeRoll
+		dodgeRoll = func() int { return 100 }
+		defer func() { dodgeRoll = originalRoll }()
+
+		Counterattack.Interleaved = interleaved
+		defer func() { Counterattack.Interleaved = false }()
+
+		att := CreateSquad(em, "Attackers", "Player", common.Position{X: 5, Y: 3}, DirDown)
+		for col := 0; col < GridWidth; col++ {
+			CreateUnitFromTemplate(em, Units["TestRogue"], att.SquadID, 0, col)
+		}
+
+		def := CreateSquad(em, "Defenders", "Enemy", common.Position{X: 5, Y: 4}, DirUp)
+		lone := CreateUnitFromTemplate(em, Units["TestKnight"], def.SquadID, 0, 0)
+		common.GetAttributes(lone).CurrentHealth = 8
+
+		events := ExecuteSquadAttack(em, att.SquadID, def.SquadID, DirDown)
+
+		if common.GetAttributes(lone).CurrentHealth > 0 {
+			t.Fatal("the volley should still kill the defender")
+		}
+
+		return events
+	}
+
+	counters := 0
+	for _, event := range runVolleyAgainstLoneDefender(true) {
+		if event.Counter {
+			counters++
+		}
+	}
+	if counters == 0 {
+		t.Error("interleaved mode should let the surviving defender counter at least once")
+	}
+
+	for _, event := range runVolleyAgainstLoneDefender(false) {
+		if event.Counter {
+			t.Error("the default mode must not produce counter events")
+		}
+	}
+}
@@ -0,0 +1,394 @@
+package squads
+
+import (
+	"game_main/common"
+	"game_main/gear"
+	"game_main/randgen"
+
+	"github.com/bytearena/ecs"
+)
+
+// AttackEvent records how a single unit-on-unit attack resolved.
+// Battle logs and the GUI both read these instead of recomputing anything
+type AttackEvent struct {
+	AttackerID     ecs.EntityID    `json:"attackerID"`
+	DefenderID     ecs.EntityID    `json:"defenderID"`
+	Damage         int             `json:"damage"`
+	AttackDir      AttackDirection `json:"attackDir"`
+	Backstab       bool            `json:"backstab,omitempty"`
+	CoverReduction int             `json:"coverReduction,omitempty"`
+
+	//True when the defender dodged and the attack dealt nothing
+	Dodged bool `json:"dodged,omitempty"`
+
+	//The final damage the attack would have dealt had it landed. Populated
+	//on every event, including dodges, so the balance tooling can price
+	//avoided damage
+	PotentialDamage int `json:"potentialDamage"`
+
+	//Template of the unit that got attacked, for per-template aggregation
+	DefenderTemplate string `json:"defenderTemplate,omitempty"`
+
+	//Set when a guardian stepped in front of this attack. RedirectedFrom is
+	//the unit the attack was originally aimed at
+	Redirected     bool         `json:"redirected,omitempty"`
+	RedirectedFrom ecs.EntityID `json:"redirectedFrom,omitempty"`
+
+	//How long an animated viewer should dwell on this event, in frames at
+	//normal speed. Text output ignores it. Backstabs linger, dodges are
+	//over quickly
+	DurationHint int `json:"durationHint"`
+
+	//Which battle round the event happened in, stamped by whoever runs the
+	//battle loop. The post-mortem analyzer uses it to place deaths in time
+	Round int `json:"round,omitempty"`
+
+	//Which round phase produced this event: "effects", "attack", or
+	//"counterattack". The battle runner sets it while building the record,
+	//and PhaseLog groups on it
+	Phase string `json:"phase,omitempty"`
+
+	//True when this attack was an interleaved counter, answered immediately
+	//after the unit was attacked
+	Counter bool `json:"counter,omitempty"`
+
+	//True when this attack was overwatch interrupt fire against a squad
+	//moving through the watchers firing line
+	Overwatch bool `json:"overwatch,omitempty"`
+
+	//True when the attacker landed a critical hit, counting the weapon roll
+	//twice. Driven by the attackers Focus
+	Crit bool `json:"crit,omitempty"`
+
+	//Protection the crit punched through, when Crits.ArmorPierce is on
+	CritPierce int `json:"critPierce,omitempty"`
+
+	//The per-stage damage breakdown, only attached when Tracing.Enabled
+	Trace *AttackTrace `json:"trace,omitempty"`
+}
+
+// Pacing tiers for DurationHint. Derived purely from how the attack
+// resolved so replaying the same log always paces the same way
+const (
+	durationHintDodge    = 10
+	durationHintHit      = 20
+	durationHintBackstab = 40
+)
+
+func durationHintFor(event AttackEvent) int {
+
+	switch {
+	case event.Dodged:
+		return durationHintDodge
+	case event.Backstab:
+		return durationHintBackstab
+	default:
+		return durationHintHit
+	}
+}
+
+// Finds a squad unit by entity ID. Returns nil if the ID does not belong to a living squad unit
+func FindUnitByID(em *common.EntityManager, id ecs.EntityID) *ecs.Entity {
+
+	for _, u := range em.World.Query(em.WorldTags["squadunits"]) {
+
+		if u.Entity.GetID() == id {
+			return u.Entity
+		}
+
+	}
+
+	return nil
+}
+
+// Cover only protects against attacks arriving from the front. A unit whose
+// footprint covers the defended column at a lower row number (closer to the
+// front) and has a CoverValue on its template soaks that much damage for the
+// cells behind it. A rear or flank attack bypasses the shield wall entirely.
+// row and col are the cell being defended - the anchor normally, or the
+// struck cell of a multi-cell unit under ModifiersUseHitCell, where a large
+// units own front half shields its rear cells
+func coverReductionFor(em *common.EntityManager, defender *ecs.Entity, attackDir AttackDirection, row int, col int) int {
+
+	if attackDir != AttackFront {
+		return 0
+	}
+
+	defData := GetUnitData(defender)
+	reduction := 0
+
+	for _, u := range SquadUnits(em, defData.SquadID) {
+
+		if !GetUnitData(u).coversColumnAhead(row, col) {
+			continue
+		}
+
+		if common.GetAttributes(u).CurrentHealth <= 0 {
+			continue
+		}
+
+		//Under bracing rules a unit that already attacked this round has
+		//dropped its guard and shields nothing. A wounded unit cannot hold
+		//the shield wall either
+		if Cover.RequiresNotActed && UnitActedThisRound(em, u) {
+			continue
+		}
+		if IsWounded(u) {
+			continue
+		}
+
+		reduction += UnitTemplateFor(u).CoverValue
+
+	}
+
+	return reduction
+}
+
+// Rolls the attackers weapon damage, then applies protection, directional cover,
+// and the backstab bonus. mapAttackDir is the direction the attack travels on the
+// map; MapGridForAttackDirection turns it into front/flank/rear for the defender.
+// Damage is not applied here, only calculated
+func calculateUnitDamageByID(em *common.EntityManager, attackerID ecs.EntityID, defenderID ecs.EntityID, mapAttackDir Direction) AttackEvent {
+	return calculateUnitDamageAt(em, attackerID, defenderID, mapAttackDir, -1, -1)
+}
+
+// The cell-aware variant: a non-negative hit cell keys the positional
+// modifiers off that cell instead of the defenders anchor
+func calculateUnitDamageAt(em *common.EntityManager, attackerID ecs.EntityID, defenderID ecs.EntityID, mapAttackDir Direction, hitRow int, hitCol int) AttackEvent {
+
+	attacker := FindUnitByID(em, attackerID)
+	defender := FindUnitByID(em, defenderID)
+
+	event := AttackEvent{AttackerID: attackerID, DefenderID: defenderID}
+
+	if attacker == nil || defender == nil {
+		return event
+	}
+
+	attTemplate := UnitTemplateFor(attacker)
+	attAttr := common.GetAttributes(attacker)
+	defAttr := common.GetAttributes(defender)
+
+	event.AttackDir = MapGridForAttackDirection(mapAttackDir, GetUnitFacing(em, defender))
+
+	var trace *AttackTrace
+	if Tracing.Enabled {
+		trace = &AttackTrace{}
+	}
+
+	//Focus raises the floor of the damage roll, never the ceiling, so a
+	//focused unit trades spikes for consistency
+	minDamage := attTemplate.MinDamage + attAttr.Focus
+	if minDamage > attTemplate.MaxDamage {
+		minDamage = attTemplate.MaxDamage
+	}
+
+	damage := randgen.GetRandomBetween(minDamage, attTemplate.MaxDamage)
+	trace.add("weapon roll", damage, damage)
+
+	//Each point of Focus is a percent of crit chance. The roll only happens
+	//for focused units so unfocused battles consume the same random sequence
+	//as before
+	if attAttr.Focus > 0 {
+
+		roll := critRoll()
+		if trace != nil {
+			trace.CritRoll = roll
+		}
+
+		if roll <= attAttr.Focus {
+			damage *= 2
+			event.Crit = true
+			trace.add("crit", damage/2, damage)
+		}
+
+	}
+
+	if bonus := gear.UnitWeaponDamage(attacker); bonus != 0 {
+		damage += bonus
+		trace.add("weapon bonus", bonus, damage)
+	}
+	if bonus := EnrageBonus(attacker); bonus != 0 {
+		damage += bonus
+		trace.add("enrage", bonus, damage)
+	}
+	if delta := environmentDamageDelta(&attTemplate); delta != 0 {
+		damage += delta
+		trace.add("environment", delta, damage)
+	}
+
+	//A wounded attacker swings at a fraction of its strength
+	if IsWounded(attacker) && Wounds.HitPenalty > 0 {
+		reduced := int(float64(damage) * (1 - Wounds.HitPenalty))
+		trace.add("wounded", reduced-damage, reduced)
+		damage = reduced
+	}
+
+	if attSquad := GetSquadByID(em, GetUnitData(attacker).SquadID); attSquad != nil {
+
+		fatigued := int(float64(damage) * fatigueMultiplier(attSquad.CurrentRound))
+		if fatigued != damage {
+			trace.add("fatigue", fatigued-damage, fatigued)
+		}
+		damage = fatigued
+
+		if bonus := SquadBuffBonus(em, attSquad.SquadID, BuffHit); bonus != 0 {
+			damage += bonus
+			trace.add("hit buff", bonus, damage)
+		}
+
+	}
+
+	protection := effectiveProtection(defender, defAttr)
+
+	//A crit punches through part of the armor, so crit builds keep their
+	//payoff against tanks instead of losing it all to protection
+	if event.Crit && Crits.ArmorPierce > 0 {
+
+		if pierced := int(float64(protection) * Crits.ArmorPierce); pierced > 0 {
+			protection -= pierced
+			event.CritPierce = pierced
+		}
+
+	}
+
+	if protection != 0 {
+		damage -= protection
+		trace.add("protection", -protection, damage)
+	}
+	if bonus := SquadBuffBonus(em, GetUnitData(defender).SquadID, BuffArmor); bonus != 0 {
+		damage -= bonus
+		trace.add("armor buff", -bonus, damage)
+	}
+
+	//A negative hit cell means the anchor, the single-cell default
+	coverRow, coverCol := GetUnitData(defender).Row, GetUnitData(defender).Col
+	if hitRow >= 0 {
+		coverRow, coverCol = hitRow, hitCol
+	}
+
+	event.CoverReduction = coverReductionFor(em, defender, event.AttackDir, coverRow, coverCol)
+	if event.CoverReduction != 0 {
+		damage -= event.CoverReduction
+		trace.add("cover", -event.CoverReduction, damage)
+	}
+
+	if event.AttackDir == AttackRear && attTemplate.BackstabBonus > 0 {
+		damage += attTemplate.BackstabBonus
+		event.Backstab = true
+		trace.add("backstab", attTemplate.BackstabBonus, damage)
+	}
+
+	//The lethality dial scales the finished number, after every per-unit
+	//modifier, so relative balance between templates stays intact
+	if Lethality.Multiplier > 0 && Lethality.Multiplier != 1 {
+		scaled := int(float64(damage) * Lethality.Multiplier)
+		trace.add("lethality", scaled-damage, scaled)
+		damage = scaled
+	}
+
+	if damage < 1 {
+		trace.add("floor", 1-damage, 1)
+		damage = 1
+	}
+
+	event.PotentialDamage = damage
+	event.DefenderTemplate = GetUnitData(defender).TemplateName
+
+	//The dodge roll happens after the damage is known so a dodged attack
+	//still records what it would have dealt
+	roll := dodgeRoll()
+	if trace != nil {
+		trace.DodgeRoll = roll
+		trace.DodgeThreshold = int(defAttr.TotalDodgeChance * 100)
+		event.Trace = trace
+	}
+
+	if float32(roll) <= defAttr.TotalDodgeChance*100 {
+		event.Dodged = true
+		event.DurationHint = durationHintFor(event)
+		return event
+	}
+
+	event.Damage = damage
+	event.DurationHint = durationHintFor(event)
+	return event
+}
+
+// CritConfig holds the critical hit rules. ArmorPierce is the fraction of
+// the defenders protection a crit ignores, 0 to 1. Off by default so crits
+// keep their historical behavior
+type CritConfig struct {
+	ArmorPierce float64
+}
+
+var Crits = CritConfig{}
+
+// LethalityConfig is the global damage dial: every final damage value gets
+// scaled by the multiplier, so a designer can speed battles up or slow them
+// down without touching any template
+type LethalityConfig struct {
+	Multiplier float64
+}
+
+var Lethality = LethalityConfig{Multiplier: 1.0}
+
+// Swappable so tests can script the rolls. 1-100, dodges at or below the
+// defenders dodge chance
+var dodgeRoll = func() int {
+	return randgen.GetDiceRoll(100)
+}
+
+// Same deal for crits: 1-100, crits at or below the attackers Focus
+var critRoll = func() int {
+	return randgen.GetDiceRoll(100)
+}
+
+// Resolves a single unit attack and applies the damage to the defender
+func ResolveUnitAttack(em *common.EntityManager, attackerID ecs.EntityID, defenderID ecs.EntityID, mapAttackDir Direction) AttackEvent {
+	return resolveUnitAttackAt(em, attackerID, defenderID, mapAttackDir, -1, -1)
+}
+
+// ResolveUnitAttackAtCell is ResolveUnitAttack with the cell the attack
+// struck, so positional modifiers can key off it for multi-cell units
+func ResolveUnitAttackAtCell(em *common.EntityManager, attackerID ecs.EntityID, defenderID ecs.EntityID, mapAttackDir Direction, hitRow int, hitCol int) AttackEvent {
+	return resolveUnitAttackAt(em, attackerID, defenderID, mapAttackDir, hitRow, hitCol)
+}
+
+func resolveUnitAttackAt(em *common.EntityManager, attackerID ecs.EntityID, defenderID ecs.EntityID, mapAttackDir Direction, hitRow int, hitCol int) AttackEvent {
+
+	event := calculateUnitDamageAt(em, attackerID, defenderID, mapAttackDir, hitRow, hitCol)
+
+	if attacker := FindUnitByID(em, attackerID); attacker != nil {
+		markUnitActed(em, attacker)
+	}
+
+	if defender := FindUnitByID(em, defenderID); defender != nil && !event.Dodged {
+
+		attr := common.GetAttributes(defender)
+		wasAlive := attr.CurrentHealth > 0
+		attr.CurrentHealth -= event.Damage
+
+		//Dead is dead: overkill never drives health negative, so nothing
+		//downstream has to reason about units at -12 HP
+		if attr.CurrentHealth < 0 {
+			attr.CurrentHealth = 0
+		}
+
+		//Every hit taken opens the wound a little further and chips the armor
+		AddBleedStack(defender)
+		WearArmor(defender)
+
+		RefreshWoundedState(defender)
+
+		//An ally death enrages the survivors, and a leaders death runs the
+		//squads succession policy
+		if wasAlive && attr.CurrentHealth <= 0 {
+			EnrageSquad(em, GetUnitData(defender).SquadID)
+			ResolveLeaderDeath(em, defender)
+		}
+
+	}
+
+	return event
+}
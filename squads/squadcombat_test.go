@@ -0,0 +1,150 @@
+package squads
+
+import (
+	"game_main/common"
+	"testing"
+
+	"github.com/bytearena/ecs"
+)
+
+// The squad tests run without the game init in game_main, so the shared
+// components have to be registered here
+func newTestManager() *common.EntityManager {
+
+	manager := ecs.NewManager()
+	tags := make(map[string]ecs.Tag)
+
+	common.PositionComponent = manager.NewComponent()
+	common.NameComponent = manager.NewComponent()
+	common.AttributeComponent = manager.NewComponent()
+
+	InitializeSquadComponents(manager, tags)
+
+	return &common.EntityManager{World: manager, WorldTags: tags}
+}
+
+func registerTestTemplates() {
+
+	Units["TestKnight"] = UnitTemplate{
+		Name:       "TestKnight",
+		Class:      "Knight",
+		Attr:       common.NewBaseAttributes(20, 2, 10, 2, 1, 0),
+		MinDamage:  4,
+		MaxDamage:  4,
+		CoverValue: 3,
+	}
+
+	Units["TestRogue"] = UnitTemplate{
+		Name:          "TestRogue",
+		Class:         "Rogue",
+		Attr:          common.NewBaseAttributes(10, 3, 8, 0, 1, 0),
+		MinDamage:     5,
+		MaxDamage:     5,
+		BackstabBonus: 4,
+	}
+}
+
+// The same engagement from the front and from the rear. Cover only applies to
+// the frontal attack and the rogues backstab bonus only to the rear attack
+func TestCoverAndBackstabAreDirectional(t *testing.T) {
+
+	em := newTestManager()
+	registerTestTemplates()
+
+	defSquad := CreateSquad(em, "Defenders", "Enemy", common.Position{X: 5, Y: 5}, DirUp)
+	CreateUnitFromTemplate(em, Units["TestKnight"], defSquad.SquadID, 0, 0)
+	target := CreateUnitFromTemplate(em, Units["TestKnight"], defSquad.SquadID, 1, 0)
+
+	attSquad := CreateSquad(em, "Attackers", "Player", common.Position{X: 5, Y: 3}, DirDown)
+	rogue := CreateUnitFromTemplate(em, Units["TestRogue"], attSquad.SquadID, 0, 0)
+
+	//Attacker is above the defender, so the attack travels down into the defenders front
+	front := calculateUnitDamageByID(em, rogue.GetID(), target.GetID(), DirDown)
+
+	if front.AttackDir != AttackFront {
+		t.Fatalf("expected a frontal attack, got %v", front.AttackDir)
+	}
+	if front.CoverReduction != 3 {
+		t.Errorf("expected the front row knight to provide 3 cover, got %d", front.CoverReduction)
+	}
+	if front.Backstab {
+		t.Error("backstab bonus should not apply to a frontal attack")
+	}
+	//5 base - 2 protection - 3 cover, floored at 1
+	if front.Damage != 1 {
+		t.Errorf("expected 1 frontal damage, got %d", front.Damage)
+	}
+
+	//Same engagement from behind. The attack travels the same direction the defender faces
+	rear := calculateUnitDamageByID(em, rogue.GetID(), target.GetID(), DirUp)
+
+	if rear.AttackDir != AttackRear {
+		t.Fatalf("expected a rear attack, got %v", rear.AttackDir)
+	}
+	if rear.CoverReduction != 0 {
+		t.Errorf("rear attack should bypass cover, got %d", rear.CoverReduction)
+	}
+	if !rear.Backstab {
+		t.Error("expected the rogue backstab bonus to apply from the rear")
+	}
+	//5 base - 2 protection + 4 backstab
+	if rear.Damage != 7 {
+		t.Errorf("expected 7 rear damage, got %d", rear.Damage)
+	}
+}
+
+// Backstabs get a longer dwell than dodges, and replaying the same events
+// always yields the same hints
+func TestDurationHintsPaceByOutcome(t *testing.T) {
+
+	em := newTestManager()
+	registerTestTemplates()
+
+	defSquad := CreateSquad(em, "Defenders", "Enemy", common.Position{X: 5, Y: 5}, DirUp)
+	target := CreateUnitFromTemplate(em, Units["TestKnight"], defSquad.SquadID, 0, 0)
+
+	attSquad := CreateSquad(em, "Attackers", "Player", common.Position{X: 5, Y: 3}, DirDown)
+	rogue := CreateUnitFromTemplate(em, Units["TestRogue"], attSquad.SquadID, 0, 0)
+
+	//Rear attack backstabs, the lingering case
+	backstab := calculateUnitDamageByID(em, rogue.GetID(), target.GetID(), DirUp)
+	if !backstab.Backstab {
+		t.Fatal("expected the rear attack to backstab")
+	}
+
+	//A scripted dodge, the quick case
+	defer scriptDodgeRolls([]int{1})()
+	common.GetAttributes(target).TotalDodgeChance = 0.5
+
+	dodge := calculateUnitDamageByID(em, rogue.GetID(), target.GetID(), DirDown)
+	if !dodge.Dodged {
+		t.Fatal("expected the scripted roll to dodge")
+	}
+
+	if backstab.DurationHint <= dodge.DurationHint {
+		t.Errorf("a backstab should linger longer than a dodge, got %d vs %d", backstab.DurationHint, dodge.DurationHint)
+	}
+
+	//The hint is a pure function of how the event resolved
+	if durationHintFor(backstab) != backstab.DurationHint || durationHintFor(dodge) != dodge.DurationHint {
+		t.Error("duration hints must be deterministic for identical events")
+	}
+}
+
+func TestUnitFacingDerivedFromSquad(t *testing.T) {
+
+	em := newTestManager()
+	registerTestTemplates()
+
+	squad := CreateSquad(em, "Defenders", "Enemy", common.Position{X: 0, Y: 0}, DirLeft)
+	unit := CreateUnitFromTemplate(em, Units["TestKnight"], squad.SquadID, 0, 0)
+
+	if GetUnitFacing(em, unit) != DirLeft {
+		t.Error("unit facing should match the squad facing")
+	}
+
+	squad.Facing = DirRight
+	if GetUnitFacing(em, unit) != DirRight {
+		t.Error("unit facing should follow when the squad turns")
+	}
+}
@@ -0,0 +1,398 @@
+package squads
+
+import (
+	"game_main/common"
+
+	"github.com/bytearena/ecs"
+)
+
+// Squads are a grid of units that move and fight as one entity on the map.
+// The squad entity holds the SquadData and every unit in the squad is its own
+// entity with a UnitData component pointing back at the squad.
+
+// The default grid dimensions. A squad can override them through GridW and
+// GridH on its SquadData, e.g. a boss fighting alone on a 1x1
+const (
+	GridWidth  = 3
+	GridHeight = 3
+)
+
+var (
+	SquadComponent *ecs.Component
+	UnitComponent  *ecs.Component
+)
+
+// Direction is shared between the map and the squad grid.
+// A squads facing and the direction an attack travels both use it,
+// so flanking and cover can share one direction model.
+type Direction int
+
+const (
+	DirUp Direction = iota
+	DirDown
+	DirLeft
+	DirRight
+)
+
+func (d Direction) Opposite() Direction {
+	switch d {
+	case DirUp:
+		return DirDown
+	case DirDown:
+		return DirUp
+	case DirLeft:
+		return DirRight
+	default:
+		return DirLeft
+	}
+}
+
+func (d Direction) String() string {
+	switch d {
+	case DirUp:
+		return "Up"
+	case DirDown:
+		return "Down"
+	case DirLeft:
+		return "Left"
+	default:
+		return "Right"
+	}
+}
+
+// AttackDirection is the direction an attack arrives from relative to the defenders facing.
+type AttackDirection int
+
+const (
+	AttackFront AttackDirection = iota
+	AttackFlank
+	AttackRear
+)
+
+func (a AttackDirection) String() string {
+	switch a {
+	case AttackFront:
+		return "Front"
+	case AttackFlank:
+		return "Flank"
+	default:
+		return "Rear"
+	}
+}
+
+// MapGridForAttackDirection transforms a map-level attack direction into the
+// direction relative to the defenders facing. attackDir is the direction the
+// attack travels on the map, from the attacker towards the defender.
+// Both flanking and directional cover go through this so they cannot disagree.
+func MapGridForAttackDirection(attackDir Direction, defenderFacing Direction) AttackDirection {
+
+	if attackDir == defenderFacing.Opposite() {
+		return AttackFront
+	}
+
+	if attackDir == defenderFacing {
+		return AttackRear
+	}
+
+	return AttackFlank
+}
+
+type SquadData struct {
+	SquadID int
+	Name    string
+	Faction string
+	MapPos  common.Position
+	Facing  Direction
+
+	//How many combat rounds this squad has fought in the current battle.
+	//Drives the optional fatigue penalty
+	CurrentRound int
+
+	//Squad-level morale. Rally effects raise it and clear the shaken state
+	Morale int
+	Shaken bool
+
+	//Action economy cap: how many attacks the squad gets per round, highest
+	//initiative first. 0 means every unit attacks
+	MaxAttacksPerRound int
+
+	//Whether the squad has spent its action this round, e.g. on a support
+	//ability. Reset by AdvanceSquadRound
+	ActionSpent bool
+
+	//What happens when the squads leader dies. Zero is no succession
+	LeadershipPolicy SquadLeadershipPolicy
+
+	//Set when the leader died without a successor. The grace rule applies:
+	//existing units stay even over capacity, but nothing joins
+	Leaderless bool
+
+	//One line describing the latest succession, for the post-battle summary
+	SuccessionNote string
+
+	//Grid dimensions for this squad. Zero means the default 3x3, so existing
+	//squads and saves need no migration. Attacker and defender grids may
+	//differ - targeting always resolves against the defenders dimensions
+	GridW int
+	GridH int
+
+	//An optional movement target the AI heads for before engaging, for
+	//chokepoint defense tactics. Nil means advance at the enemy as usual
+	RallyPoint *common.Position
+}
+
+// GridSize is the squads grid dimensions, falling back to the 3x3 default
+// when nothing ever set them
+func (s *SquadData) GridSize() (int, int) {
+
+	w, h := s.GridW, s.GridH
+	if w < 1 {
+		w = GridWidth
+	}
+	if h < 1 {
+		h = GridHeight
+	}
+
+	return w, h
+}
+
+// SquadGridSize is GridSize by squad ID, with the default for unknown squads
+func SquadGridSize(em *common.EntityManager, squadID int) (int, int) {
+
+	if squad := GetSquadByID(em, squadID); squad != nil {
+		return squad.GridSize()
+	}
+
+	return GridWidth, GridHeight
+}
+
+// UnitData ties a unit entity to its squad and its position in the 3x3 grid.
+// Row 0 is the front row in the direction the squad is facing.
+type UnitData struct {
+	SquadID      int
+	Row          int
+	Col          int
+	TemplateName string
+
+	//Footprint in grid cells for large units, anchored at (Row, Col). Zero
+	//means 1, so single-cell units need no migration
+	Width  int
+	Height int
+
+	//Explicit footprint as (row, col) offsets from the anchor, for shapes a
+	//rectangle cannot express: an L-shaped serpent, a phalanx with a tail.
+	//When present it supersedes Width and Height. Must include {0, 0} so the
+	//anchor is always part of the footprint
+	CellOffsets [][2]int
+
+	//How many extra units this unit supports when leading the squad, and
+	//what succession ranks candidates by
+	Leadership int
+
+	//Set when difficulty scaling touched this units stats at spawn, so
+	//balance logs can tell difficulty apart from template changes
+	DifficultyScaled bool
+
+	//The squad round this unit last attacked in, stored as round+1 so the
+	//zero value means never. Bracing cover reads it through UnitActedThisRound
+	ActedRound int
+}
+
+// Span is the units footprint, never smaller than 1x1
+func (u *UnitData) Span() (int, int) {
+
+	w, h := u.Width, u.Height
+	if w < 1 {
+		w = 1
+	}
+	if h < 1 {
+		h = 1
+	}
+
+	return w, h
+}
+
+// OccupiesCell reports whether the units footprint covers the given cell.
+// The rectangle is the fast default; the offset footprint wins when present
+func (u *UnitData) OccupiesCell(row int, col int) bool {
+
+	if len(u.CellOffsets) > 0 {
+
+		for _, off := range u.CellOffsets {
+			if u.Row+off[0] == row && u.Col+off[1] == col {
+				return true
+			}
+		}
+
+		return false
+	}
+
+	w, h := u.Span()
+	return row >= u.Row && row < u.Row+h && col >= u.Col && col < u.Col+w
+}
+
+// OccupiedCells is every grid cell the footprint covers, in absolute grid
+// coordinates
+func (u *UnitData) OccupiedCells() [][2]int {
+
+	if len(u.CellOffsets) > 0 {
+
+		cells := make([][2]int, 0, len(u.CellOffsets))
+		for _, off := range u.CellOffsets {
+			cells = append(cells, [2]int{u.Row + off[0], u.Col + off[1]})
+		}
+
+		return cells
+	}
+
+	w, h := u.Span()
+	cells := make([][2]int, 0, w*h)
+	for row := 0; row < h; row++ {
+		for col := 0; col < w; col++ {
+			cells = append(cells, [2]int{u.Row + row, u.Col + col})
+		}
+	}
+
+	return cells
+}
+
+// Whether any cell of the footprint stands in the given column closer to the
+// front than the given row, which is what directional cover keys off
+func (u *UnitData) coversColumnAhead(row int, col int) bool {
+
+	if len(u.CellOffsets) == 0 {
+		w, _ := u.Span()
+		return col >= u.Col && col < u.Col+w && u.Row < row
+	}
+
+	for _, cell := range u.OccupiedCells() {
+		if cell[1] == col && cell[0] < row {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Register the squad components and tags. Called during ECS initialization
+func InitializeSquadComponents(manager *ecs.Manager, tags map[string]ecs.Tag) {
+
+	SquadComponent = manager.NewComponent()
+	UnitComponent = manager.NewComponent()
+	GuardianComponent = manager.NewComponent()
+	SquadAppearanceComponent = manager.NewComponent()
+	UnitLevelComponent = manager.NewComponent()
+	ConsumableBagComponent = manager.NewComponent()
+	UnitEffectsComponent = manager.NewComponent()
+	SupportAbilityComponent = manager.NewComponent()
+	SquadBuffsComponent = manager.NewComponent()
+	PinComponent = manager.NewComponent()
+	OverwatchComponent = manager.NewComponent()
+	ObjectiveComponent = manager.NewComponent()
+	ArtifactComponent = manager.NewComponent()
+	LeaderComponent = manager.NewComponent()
+
+	squads := ecs.BuildTag(SquadComponent)
+	tags["squads"] = squads
+
+	objectives := ecs.BuildTag(ObjectiveComponent)
+	tags["objectives"] = objectives
+
+	artifacts := ecs.BuildTag(ArtifactComponent)
+	tags["artifacts"] = artifacts
+
+	squadunits := ecs.BuildTag(UnitComponent, common.AttributeComponent)
+	tags["squadunits"] = squadunits
+
+}
+
+// Getters for the components that get referenced frequently.
+func GetSquadData(e *ecs.Entity) *SquadData {
+	return common.GetComponentType[*SquadData](e, SquadComponent)
+}
+
+func GetUnitData(e *ecs.Entity) *UnitData {
+	return common.GetComponentType[*UnitData](e, UnitComponent)
+}
+
+func GetSquadByID(em *common.EntityManager, squadID int) *SquadData {
+
+	if squad := GetSquadEntity(em, squadID); squad != nil {
+		return GetSquadData(squad)
+	}
+
+	return nil
+}
+
+// GetSquadEntity finds the squad entity itself, for callers that need to
+// attach or read optional components on it
+func GetSquadEntity(em *common.EntityManager, squadID int) *ecs.Entity {
+
+	for _, s := range em.World.Query(em.WorldTags["squads"]) {
+
+		if GetSquadData(s.Entity).SquadID == squadID {
+			return s.Entity
+		}
+
+	}
+
+	return nil
+}
+
+// Returns every living unit in the squad
+func SquadUnits(em *common.EntityManager, squadID int) []*ecs.Entity {
+
+	units := make([]*ecs.Entity, 0)
+	for _, u := range em.World.Query(em.WorldTags["squadunits"]) {
+
+		if GetUnitData(u.Entity).SquadID == squadID {
+			units = append(units, u.Entity)
+		}
+
+	}
+
+	return units
+}
+
+// UnitOccupyingCell finds the unit whose footprint covers the cell, which for
+// multi-cell units is not the same as being anchored there
+func UnitOccupyingCell(em *common.EntityManager, squadID int, row int, col int) *ecs.Entity {
+
+	for _, u := range SquadUnits(em, squadID) {
+
+		if GetUnitData(u).OccupiesCell(row, col) {
+			return u
+		}
+
+	}
+
+	return nil
+}
+
+// UnitAtGridPos finds the unit anchored at the cell. Area effects resolve
+// against anchors, so a multi-cell unit only matches on its anchor here
+func UnitAtGridPos(em *common.EntityManager, squadID int, row int, col int) *ecs.Entity {
+
+	for _, u := range SquadUnits(em, squadID) {
+
+		data := GetUnitData(u)
+		if data.Row == row && data.Col == col {
+			return u
+		}
+
+	}
+
+	return nil
+}
+
+// Units face whatever direction their squad faces. Facing is not individually controllable,
+// so it is derived here instead of stored on the unit.
+func GetUnitFacing(em *common.EntityManager, unit *ecs.Entity) Direction {
+
+	squad := GetSquadByID(em, GetUnitData(unit).SquadID)
+	if squad == nil {
+		return DirUp
+	}
+
+	return squad.Facing
+}
@@ -0,0 +1,117 @@
+package squads
+
+import (
+	"fmt"
+	"sort"
+
+	"game_main/common"
+)
+
+// Named squad templates capture a build - which unit templates stand where -
+// so a composition the player likes can be stamped out again by name. Unlike
+// the savegame serializer this keeps no live state: no health, no effects,
+// no entity IDs, just the recipe. The registry mirrors the unit template one:
+// an in-memory map keyed by name
+
+type SquadTemplateUnit struct {
+	Template string `json:"template"`
+	Row      int    `json:"row"`
+	Col      int    `json:"col"`
+	Leader   bool   `json:"leader,omitempty"`
+}
+
+type SquadTemplate struct {
+	Name   string              `json:"name"`
+	Facing Direction           `json:"facing"`
+	Units  []SquadTemplateUnit `json:"units"`
+}
+
+var squadTemplates = make(map[string]SquadTemplate)
+
+// SaveSquadTemplate records the squads current composition under the given
+// name, replacing any template already there. Only living units make it into
+// the recipe
+func SaveSquadTemplate(em *common.EntityManager, name string, squadID int) error {
+
+	squad := GetSquadByID(em, squadID)
+	if squad == nil {
+		return fmt.Errorf("no squad %d to save as template %q", squadID, name)
+	}
+
+	template := SquadTemplate{Name: name, Facing: squad.Facing}
+	for _, u := range SquadUnits(em, squadID) {
+
+		unit := GetUnitData(u)
+		template.Units = append(template.Units, SquadTemplateUnit{
+			Template: unit.TemplateName,
+			Row:      unit.Row,
+			Col:      unit.Col,
+			Leader:   u.HasComponent(LeaderComponent),
+		})
+
+	}
+
+	sort.Slice(template.Units, func(i, j int) bool {
+
+		if template.Units[i].Row != template.Units[j].Row {
+			return template.Units[i].Row < template.Units[j].Row
+		}
+		return template.Units[i].Col < template.Units[j].Col
+
+	})
+
+	squadTemplates[name] = template
+
+	return nil
+}
+
+// InstantiateSquadTemplate builds a fresh squad from the named recipe. Every
+// unit spawns at full health from its current unit template, so a stale
+// recipe whose unit templates were unregistered fails rather than spawning
+// half a squad
+func InstantiateSquadTemplate(em *common.EntityManager, name string, faction string, mapPos common.Position) (*SquadData, error) {
+
+	template, ok := squadTemplates[name]
+	if !ok {
+		return nil, fmt.Errorf("no squad template named %q", name)
+	}
+
+	for _, recipe := range template.Units {
+		if _, ok := UnitTemplateByName(recipe.Template); !ok {
+			return nil, fmt.Errorf("squad template %q needs unit template %q, which is not registered", name, recipe.Template)
+		}
+	}
+
+	squad := CreateSquad(em, name, faction, mapPos, template.Facing)
+	for _, recipe := range template.Units {
+
+		unitTemplate, _ := UnitTemplateByName(recipe.Template)
+		unit := CreateUnitFromTemplate(em, unitTemplate, squad.SquadID, recipe.Row, recipe.Col)
+
+		if recipe.Leader {
+			MakeLeader(em, unit)
+		}
+
+	}
+
+	return squad, nil
+}
+
+// SquadTemplateByName looks a template up without exposing the mutable map
+func SquadTemplateByName(name string) (SquadTemplate, bool) {
+	template, ok := squadTemplates[name]
+	return template, ok
+}
+
+// SquadTemplateNames is a read-only view of whats registered, sorted so
+// callers iterate deterministically
+func SquadTemplateNames() []string {
+
+	names := make([]string, 0, len(squadTemplates))
+	for name := range squadTemplates {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
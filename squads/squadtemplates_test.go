@@ -0,0 +1,79 @@
+package squads
+
+import (
+	"testing"
+
+	"game_main/common"
+)
+
+// Saving a squad and stamping it back out reproduces the composition: same
+// unit templates on the same grid cells, leadership included, at full health
+func TestSquadTemplateRoundTrip(t *testing.T) {
+
+	em := newTestManager()
+	registerTestTemplates()
+	defer delete(squadTemplates, "Vanguard")
+
+	squad := CreateSquad(em, "Originals", "Player", common.Position{X: 2, Y: 2}, DirRight)
+	leader := CreateUnitFromTemplate(em, Units["TestKnight"], squad.SquadID, 0, 0)
+	GetUnitData(leader).Leadership = 2
+	MakeLeader(em, leader)
+	CreateUnitFromTemplate(em, Units["TestKnight"], squad.SquadID, 1, 2)
+
+	//Live state must not leak into the recipe
+	common.GetAttributes(leader).CurrentHealth = 1
+
+	if err := SaveSquadTemplate(em, "Vanguard", squad.SquadID); err != nil {
+		t.Fatalf("saving the template should work, got %v", err)
+	}
+
+	fresh, err := InstantiateSquadTemplate(em, "Vanguard", "Enemy", common.Position{X: 8, Y: 8})
+	if err != nil {
+		t.Fatalf("instantiating the template should work, got %v", err)
+	}
+
+	if fresh.SquadID == squad.SquadID || fresh.Faction != "Enemy" || fresh.Facing != DirRight {
+		t.Fatalf("the instance should be a fresh squad with the recipes facing, got %+v", fresh)
+	}
+
+	units := SquadUnits(em, fresh.SquadID)
+	if len(units) != 2 {
+		t.Fatalf("the instance should field both units, got %d", len(units))
+	}
+
+	positions := map[[2]int]bool{}
+	for _, u := range units {
+
+		data := GetUnitData(u)
+		positions[[2]int{data.Row, data.Col}] = true
+
+		if attr := common.GetAttributes(u); attr.CurrentHealth != attr.MaxHealth {
+			t.Errorf("instantiated units should spawn at full health, got %d", attr.CurrentHealth)
+		}
+
+	}
+	if !positions[[2]int{0, 0}] || !positions[[2]int{1, 2}] {
+		t.Errorf("the grid positions should match the original, got %v", positions)
+	}
+
+	newLeader := SquadLeader(em, fresh.SquadID)
+	if newLeader == nil || GetUnitData(newLeader).Row != 0 {
+		t.Error("the recipes leader slot should carry over")
+	}
+
+}
+
+func TestSquadTemplateErrors(t *testing.T) {
+
+	em := newTestManager()
+	registerTestTemplates()
+
+	if _, err := InstantiateSquadTemplate(em, "NoSuchBuild", "Player", common.Position{}); err == nil {
+		t.Error("an unknown template name should error")
+	}
+
+	if err := SaveSquadTemplate(em, "Ghost", 999); err == nil {
+		t.Error("saving a squad that does not exist should error")
+	}
+
+}
@@ -0,0 +1,214 @@
+package squads
+
+import (
+	"fmt"
+	"game_main/common"
+)
+
+// Tile stacking lets several squads share one map tile, so armies can move
+// together and squads can garrison inside a fort tile. The map used to hold
+// at most one squad per tile implicitly; SquadStacks makes the occupancy a
+// per-tile list with an explicit limit. Movement and destruction both go
+// through it so the bookkeeping cannot drift from the squads MapPos
+
+// EngagementMode decides what attacking a stacked tile means
+type EngagementMode int
+
+const (
+	//Only the top squad of the stack defends; the rest wait underneath
+	EngageTopSquad EngagementMode = iota
+
+	//Every squad on the tile defends, top of the stack first
+	EngageWholeStack
+)
+
+const DefaultStackLimit = 3
+
+type SquadStacks struct {
+	Limit int
+	Mode  EngagementMode
+
+	//Squad IDs per tile, bottom of the stack first. The last entry is the
+	//top squad
+	tiles map[common.Position][]int
+}
+
+func NewSquadStacks(limit int) *SquadStacks {
+
+	if limit <= 0 {
+		limit = DefaultStackLimit
+	}
+
+	return &SquadStacks{
+		Limit: limit,
+		tiles: make(map[common.Position][]int),
+	}
+}
+
+// StackAt returns the squads on a tile, bottom first. The slice is a copy so
+// callers cannot corrupt the bookkeeping
+func (s *SquadStacks) StackAt(pos common.Position) []int {
+
+	stack := s.tiles[pos]
+	return append([]int{}, stack...)
+}
+
+// TopSquad is the squad that defends the tile in EngageTopSquad mode.
+// Returns false when the tile is empty
+func (s *SquadStacks) TopSquad(pos common.Position) (int, bool) {
+
+	stack := s.tiles[pos]
+	if len(stack) == 0 {
+		return 0, false
+	}
+
+	return stack[len(stack)-1], true
+}
+
+// Join places a squad on top of the stack at pos. Joining fails when the
+// tile is full or when an enemy squad already holds it - enemy stacks must
+// be attacked, not walked into
+func (s *SquadStacks) Join(em *common.EntityManager, squadID int, pos common.Position) error {
+
+	squad := GetSquadByID(em, squadID)
+	if squad == nil {
+		return fmt.Errorf("no squad with ID %d", squadID)
+	}
+
+	stack := s.tiles[pos]
+	if len(stack) >= s.Limit {
+		return fmt.Errorf("tile (%d,%d) is full: stack limit is %d", pos.X, pos.Y, s.Limit)
+	}
+
+	for _, other := range stack {
+
+		if occupant := GetSquadByID(em, other); occupant != nil && occupant.Faction != squad.Faction {
+			return fmt.Errorf("tile (%d,%d) is held by enemy squad %s: attack it instead", pos.X, pos.Y, occupant.Name)
+		}
+
+	}
+
+	s.tiles[pos] = append(stack, squadID)
+	syncSquadPosition(em, squad, pos)
+
+	return nil
+}
+
+// tileOf finds the tile a squad is stacked on, for the consistency check
+func (s *SquadStacks) tileOf(squadID int) (common.Position, bool) {
+
+	for pos, stack := range s.tiles {
+		for _, id := range stack {
+			if id == squadID {
+				return pos, true
+			}
+		}
+	}
+
+	return common.Position{}, false
+}
+
+// Leave removes a squad from whatever stack it is in. Safe to call for a
+// squad that was never placed
+func (s *SquadStacks) Leave(squadID int) {
+
+	for pos, stack := range s.tiles {
+
+		for i, id := range stack {
+
+			if id == squadID {
+
+				s.tiles[pos] = append(stack[:i], stack[i+1:]...)
+				if len(s.tiles[pos]) == 0 {
+					delete(s.tiles, pos)
+				}
+				return
+
+			}
+
+		}
+
+	}
+
+}
+
+// MoveSquad relocates a squad to dest, joining any friendly stack there.
+// The squad stays where it was if the destination rejects it
+func (s *SquadStacks) MoveSquad(em *common.EntityManager, squadID int, dest common.Position) error {
+
+	squad := GetSquadByID(em, squadID)
+	if squad == nil {
+		return fmt.Errorf("no squad with ID %d", squadID)
+	}
+
+	origin := squad.MapPos
+	s.Leave(squadID)
+
+	if err := s.Join(em, squadID, dest); err != nil {
+
+		//Put the squad back so a failed move changes nothing
+		s.tiles[origin] = append(s.tiles[origin], squadID)
+		return err
+
+	}
+
+	return nil
+}
+
+// Promote moves a squad to the top of its stack, which is how the stack
+// inspector reorders who defends first
+func (s *SquadStacks) Promote(pos common.Position, squadID int) {
+
+	stack := s.tiles[pos]
+	for i, id := range stack {
+
+		if id == squadID {
+			s.tiles[pos] = append(append(stack[:i], stack[i+1:]...), squadID)
+			return
+		}
+
+	}
+
+}
+
+// EngagementTargets resolves who defends when a stacked tile is attacked.
+// Top-squad mode returns just the top squad; whole-stack mode returns every
+// squad on the tile, top first
+func (s *SquadStacks) EngagementTargets(pos common.Position) []int {
+
+	stack := s.tiles[pos]
+	if len(stack) == 0 {
+		return nil
+	}
+
+	if s.Mode == EngageTopSquad {
+		return []int{stack[len(stack)-1]}
+	}
+
+	targets := make([]int, 0, len(stack))
+	for i := len(stack) - 1; i >= 0; i-- {
+		targets = append(targets, stack[i])
+	}
+
+	return targets
+}
+
+// ProcessSquadDestruction removes a destroyed squad from its stack and
+// disposes the squad entity along with every unit still in it
+func ProcessSquadDestruction(em *common.EntityManager, stacks *SquadStacks, squadID int) {
+
+	if stacks != nil {
+		stacks.Leave(squadID)
+	}
+
+	for _, unit := range SquadUnits(em, squadID) {
+		em.World.DisposeEntity(unit)
+	}
+
+	if squad := GetSquadEntity(em, squadID); squad != nil {
+		em.World.DisposeEntity(squad)
+	}
+
+	em.NotifyChanged(common.RosterChanged)
+	em.NotifyChanged(common.SquadsChanged)
+}
@@ -0,0 +1,169 @@
+package squads
+
+import (
+	"game_main/common"
+	"strings"
+	"testing"
+)
+
+// Joining and leaving keeps the per-tile lists and the squads MapPos in step
+func TestStackJoinAndLeaveBookkeeping(t *testing.T) {
+
+	em := newTestManager()
+	stacks := NewSquadStacks(3)
+
+	tile := common.Position{X: 4, Y: 4}
+	first := CreateSquad(em, "First", "Player", common.Position{X: 1, Y: 1}, DirUp)
+	second := CreateSquad(em, "Second", "Player", common.Position{X: 2, Y: 2}, DirUp)
+
+	if err := stacks.Join(em, first.SquadID, tile); err != nil {
+		t.Fatalf("joining an empty tile failed: %v", err)
+	}
+	if err := stacks.Join(em, second.SquadID, tile); err != nil {
+		t.Fatalf("joining a friendly stack under the limit failed: %v", err)
+	}
+
+	stack := stacks.StackAt(tile)
+	if len(stack) != 2 || stack[0] != first.SquadID || stack[1] != second.SquadID {
+		t.Fatalf("expected [%d %d] bottom first, got %v", first.SquadID, second.SquadID, stack)
+	}
+
+	if top, ok := stacks.TopSquad(tile); !ok || top != second.SquadID {
+		t.Errorf("the last squad to join should be on top, got %d", top)
+	}
+
+	if first.MapPos != tile || second.MapPos != tile {
+		t.Error("joining a stack must move the squads MapPos to the tile")
+	}
+
+	//Moving away empties the slot it left
+	dest := common.Position{X: 4, Y: 5}
+	if err := stacks.MoveSquad(em, second.SquadID, dest); err != nil {
+		t.Fatalf("moving off the stack failed: %v", err)
+	}
+
+	if got := stacks.StackAt(tile); len(got) != 1 || got[0] != first.SquadID {
+		t.Errorf("expected only the first squad left on the tile, got %v", got)
+	}
+	if got := stacks.StackAt(dest); len(got) != 1 || got[0] != second.SquadID {
+		t.Errorf("expected the second squad alone on the destination, got %v", got)
+	}
+}
+
+// A full tile rejects another squad and a failed move changes nothing
+func TestStackLimitRejectsJoin(t *testing.T) {
+
+	em := newTestManager()
+	stacks := NewSquadStacks(2)
+
+	tile := common.Position{X: 4, Y: 4}
+	for _, name := range []string{"First", "Second"} {
+
+		squad := CreateSquad(em, name, "Player", common.Position{X: 1, Y: 1}, DirUp)
+		if err := stacks.Join(em, squad.SquadID, tile); err != nil {
+			t.Fatalf("filling the stack failed: %v", err)
+		}
+
+	}
+
+	origin := common.Position{X: 9, Y: 9}
+	third := CreateSquad(em, "Third", "Player", common.Position{X: 1, Y: 1}, DirUp)
+	if err := stacks.Join(em, third.SquadID, origin); err != nil {
+		t.Fatalf("placing the third squad failed: %v", err)
+	}
+
+	err := stacks.MoveSquad(em, third.SquadID, tile)
+	if err == nil {
+		t.Fatal("expected the stack limit to reject the move")
+	}
+	if !strings.Contains(err.Error(), "stack limit") {
+		t.Errorf("the error should mention the stack limit, got %v", err)
+	}
+
+	if got := stacks.StackAt(origin); len(got) != 1 || got[0] != third.SquadID {
+		t.Errorf("a rejected move must leave the squad where it was, got %v", got)
+	}
+}
+
+// An enemy-held tile cannot be joined, it has to be attacked
+func TestEnemyStackRejectsJoin(t *testing.T) {
+
+	em := newTestManager()
+	stacks := NewSquadStacks(3)
+
+	tile := common.Position{X: 4, Y: 4}
+	enemy := CreateSquad(em, "Raiders", "Enemy", common.Position{X: 1, Y: 1}, DirUp)
+	if err := stacks.Join(em, enemy.SquadID, tile); err != nil {
+		t.Fatalf("placing the enemy failed: %v", err)
+	}
+
+	friendly := CreateSquad(em, "Guards", "Player", common.Position{X: 1, Y: 1}, DirUp)
+	if err := stacks.Join(em, friendly.SquadID, tile); err == nil {
+		t.Fatal("expected joining an enemy stack to fail")
+	}
+}
+
+// Engagement against a two-squad stack resolves per the configured mode
+func TestEngagementTargetsPerMode(t *testing.T) {
+
+	em := newTestManager()
+	stacks := NewSquadStacks(3)
+
+	tile := common.Position{X: 4, Y: 4}
+	bottom := CreateSquad(em, "Bottom", "Enemy", common.Position{X: 1, Y: 1}, DirUp)
+	top := CreateSquad(em, "Top", "Enemy", common.Position{X: 1, Y: 1}, DirUp)
+
+	for _, squad := range []*SquadData{bottom, top} {
+		if err := stacks.Join(em, squad.SquadID, tile); err != nil {
+			t.Fatalf("building the stack failed: %v", err)
+		}
+	}
+
+	stacks.Mode = EngageTopSquad
+	targets := stacks.EngagementTargets(tile)
+	if len(targets) != 1 || targets[0] != top.SquadID {
+		t.Errorf("top-squad mode should target only the top squad, got %v", targets)
+	}
+
+	stacks.Mode = EngageWholeStack
+	targets = stacks.EngagementTargets(tile)
+	if len(targets) != 2 || targets[0] != top.SquadID || targets[1] != bottom.SquadID {
+		t.Errorf("whole-stack mode should target every squad top first, got %v", targets)
+	}
+
+	//Promoting the bottom squad changes who defends first
+	stacks.Promote(tile, bottom.SquadID)
+	stacks.Mode = EngageTopSquad
+	targets = stacks.EngagementTargets(tile)
+	if len(targets) != 1 || targets[0] != bottom.SquadID {
+		t.Errorf("promotion should put the bottom squad on top, got %v", targets)
+	}
+}
+
+// Destroying a squad clears it out of its stack and the world
+func TestDestructionLeavesTheStack(t *testing.T) {
+
+	em := newTestManager()
+	registerTestTemplates()
+	stacks := NewSquadStacks(3)
+
+	tile := common.Position{X: 4, Y: 4}
+	squad := CreateSquad(em, "Doomed", "Enemy", common.Position{X: 1, Y: 1}, DirUp)
+	CreateUnitFromTemplate(em, Units["TestKnight"], squad.SquadID, 0, 0)
+
+	if err := stacks.Join(em, squad.SquadID, tile); err != nil {
+		t.Fatalf("placing the squad failed: %v", err)
+	}
+
+	ProcessSquadDestruction(em, stacks, squad.SquadID)
+
+	if got := stacks.StackAt(tile); len(got) != 0 {
+		t.Errorf("the destroyed squad must leave the stack, got %v", got)
+	}
+	if GetSquadByID(em, squad.SquadID) != nil {
+		t.Error("the squad entity should be disposed")
+	}
+	if len(SquadUnits(em, squad.SquadID)) != 0 {
+		t.Error("the squads units should be disposed with it")
+	}
+}
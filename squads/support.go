@@ -0,0 +1,259 @@
+package squads
+
+import (
+	"fmt"
+	"game_main/common"
+
+	"github.com/bytearena/ecs"
+)
+
+// Squad-targeted support abilities: a banner-bearer or similar unit can
+// spend its squads action to buff an allied squad within range. The buffs
+// live on the squad entity and expire through the same round-start tick as
+// bleed, so nothing has to remember to clean them up
+
+var (
+	SupportAbilityComponent *ecs.Component
+	SquadBuffsComponent     *ecs.Component
+)
+
+type SupportEffect int
+
+const (
+	//Flat bonus damage on every outgoing attack
+	BuffHit SupportEffect = iota
+
+	//Flat damage soaked from every incoming attack
+	BuffArmor
+
+	//Raises the squads morale and clears the shaken state
+	BuffMorale
+)
+
+func (e SupportEffect) String() string {
+	switch e {
+	case BuffHit:
+		return "Hit"
+	case BuffArmor:
+		return "Armor"
+	default:
+		return "Morale"
+	}
+}
+
+type SupportAbilityData struct {
+	AbilityID string
+	Effect    SupportEffect
+	Amount    int
+
+	//Map tiles between the casters squad and the target squad
+	Range int
+
+	//How many rounds the buff lasts on the target squad
+	DurationRounds int
+
+	//Rounds before the ability can be used again, counted down at round start
+	Cooldown     int
+	CooldownLeft int
+}
+
+func GetSupportAbilityData(e *ecs.Entity) *SupportAbilityData {
+	return common.GetComponentType[*SupportAbilityData](e, SupportAbilityComponent)
+}
+
+// AssignSupportAbility gives a unit a castable support ability
+func AssignSupportAbility(unit *ecs.Entity, data SupportAbilityData) {
+	unit.AddComponent(SupportAbilityComponent, &data)
+}
+
+// SquadBuff is one active buff on a squad
+type SquadBuff struct {
+	AbilityID  string        `json:"abilityID"`
+	Effect     SupportEffect `json:"effect"`
+	Amount     int           `json:"amount"`
+	RoundsLeft int           `json:"roundsLeft"`
+}
+
+type SquadBuffs struct {
+	Active []SquadBuff `json:"active"`
+}
+
+// GetSquadBuffs returns the squads buff state, attaching an empty one on
+// first use so callers never nil check
+func GetSquadBuffs(em *common.EntityManager, squadID int) *SquadBuffs {
+
+	squad := GetSquadEntity(em, squadID)
+	if squad == nil {
+		return &SquadBuffs{}
+	}
+
+	if buffs := common.GetComponentType[*SquadBuffs](squad, SquadBuffsComponent); buffs != nil {
+		return buffs
+	}
+
+	buffs := &SquadBuffs{}
+	squad.AddComponent(SquadBuffsComponent, buffs)
+	return buffs
+}
+
+// SquadBuffBonus is the total active bonus of one effect type on a squad.
+// The damage pipeline reads it on both sides of every attack
+func SquadBuffBonus(em *common.EntityManager, squadID int, effect SupportEffect) int {
+
+	total := 0
+	for _, buff := range GetSquadBuffs(em, squadID).Active {
+		if buff.Effect == effect {
+			total += buff.Amount
+		}
+	}
+
+	return total
+}
+
+// SquadHasActiveBuffs is what the turn-order timeline polls to badge squads
+func SquadHasActiveBuffs(em *common.EntityManager, squadID int) bool {
+	return len(GetSquadBuffs(em, squadID).Active) > 0
+}
+
+// BuffEvent is the combat log entry for a buff application
+type BuffEvent struct {
+	CasterID    ecs.EntityID `json:"casterID"`
+	AbilityID   string       `json:"abilityID"`
+	TargetSquad string       `json:"targetSquad"`
+	Rounds      int          `json:"rounds"`
+}
+
+// UseSupportAbility spends the casting squads action to buff an allied
+// squad. Fails without side effects when the target is hostile or out of
+// range, the ability is cooling down, or the squad already acted this round
+func UseSupportAbility(em *common.EntityManager, casterID ecs.EntityID, targetSquadID int) (BuffEvent, error) {
+
+	caster := FindUnitByID(em, casterID)
+	if caster == nil || common.GetAttributes(caster).CurrentHealth <= 0 {
+		return BuffEvent{}, fmt.Errorf("no living caster with ID %d", casterID)
+	}
+
+	ability := GetSupportAbilityData(caster)
+	if ability == nil {
+		return BuffEvent{}, fmt.Errorf("unit %d has no support ability", casterID)
+	}
+	if ability.CooldownLeft > 0 {
+		return BuffEvent{}, fmt.Errorf("%s is on cooldown for %d more rounds", ability.AbilityID, ability.CooldownLeft)
+	}
+
+	casterSquad := GetSquadByID(em, GetUnitData(caster).SquadID)
+	target := GetSquadByID(em, targetSquadID)
+	if casterSquad == nil || target == nil {
+		return BuffEvent{}, fmt.Errorf("no squad with ID %d", targetSquadID)
+	}
+
+	if casterSquad.ActionSpent {
+		return BuffEvent{}, fmt.Errorf("squad %s has already acted this round", casterSquad.Name)
+	}
+	if target.Faction != casterSquad.Faction {
+		return BuffEvent{}, fmt.Errorf("support abilities only target allied squads")
+	}
+	if !CanSquadAttack(em, casterSquad.SquadID, targetSquadID, ability.Range) {
+		return BuffEvent{}, fmt.Errorf("squad %s is out of range for %s", target.Name, ability.AbilityID)
+	}
+
+	if ability.Effect == BuffMorale {
+		target.Morale += ability.Amount
+		target.Shaken = false
+	} else {
+		GetSquadBuffs(em, targetSquadID).Active = append(GetSquadBuffs(em, targetSquadID).Active, SquadBuff{
+			AbilityID:  ability.AbilityID,
+			Effect:     ability.Effect,
+			Amount:     ability.Amount,
+			RoundsLeft: ability.DurationRounds,
+		})
+	}
+
+	casterSquad.ActionSpent = true
+	ability.CooldownLeft = ability.Cooldown
+	em.NotifyChanged(common.SquadsChanged)
+
+	return BuffEvent{
+		CasterID:    casterID,
+		AbilityID:   ability.AbilityID,
+		TargetSquad: target.Name,
+		Rounds:      ability.DurationRounds,
+	}, nil
+}
+
+// Round-start upkeep for a squads buffs and its units ability cooldowns.
+// TickEffects calls this so the whole effect lifetime runs through one place
+func tickSupportLifetimes(em *common.EntityManager, squadID int) {
+
+	buffs := GetSquadBuffs(em, squadID)
+	active := buffs.Active[:0]
+	for _, buff := range buffs.Active {
+
+		buff.RoundsLeft--
+		if buff.RoundsLeft > 0 {
+			active = append(active, buff)
+		}
+
+	}
+	buffs.Active = active
+
+	for _, u := range SquadUnits(em, squadID) {
+
+		if ability := GetSupportAbilityData(u); ability != nil && ability.CooldownLeft > 0 {
+			ability.CooldownLeft--
+		}
+
+	}
+
+}
+
+// ChooseSupportTarget is the defensive AI rule: buff the most wounded
+// in-range ally. Returns 0 when nothing qualifies
+func ChooseSupportTarget(em *common.EntityManager, casterID ecs.EntityID, candidateIDs []int) int {
+
+	caster := FindUnitByID(em, casterID)
+	if caster == nil {
+		return 0
+	}
+
+	ability := GetSupportAbilityData(caster)
+	if ability == nil || ability.CooldownLeft > 0 {
+		return 0
+	}
+
+	casterSquad := GetSquadByID(em, GetUnitData(caster).SquadID)
+	if casterSquad == nil {
+		return 0
+	}
+
+	best := 0
+	bestFraction := 2.0
+	for _, id := range candidateIDs {
+
+		target := GetSquadByID(em, id)
+		if target == nil || target.Faction != casterSquad.Faction {
+			continue
+		}
+		if !CanSquadAttack(em, casterSquad.SquadID, id, ability.Range) {
+			continue
+		}
+
+		current, max := 0, 0
+		for _, u := range SquadUnits(em, id) {
+			current += common.GetAttributes(u).CurrentHealth
+			max += common.GetAttributes(u).MaxHealth
+		}
+		if max == 0 {
+			continue
+		}
+
+		fraction := float64(current) / float64(max)
+		if fraction < bestFraction {
+			best = id
+			bestFraction = fraction
+		}
+
+	}
+
+	return best
+}
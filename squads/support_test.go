@@ -0,0 +1,168 @@
+package squads
+
+import (
+	"game_main/common"
+	"strings"
+	"testing"
+
+	"github.com/bytearena/ecs"
+)
+
+// Builds the three squads the support tests share: a banner squad with the
+// caster, the allied line squad it buffs, and an enemy squad to attack
+func supportFixture(t *testing.T, em *common.EntityManager, ability SupportAbilityData) (caster *SquadData, line *SquadData, enemy *SquadData, bearerID, attackerID, defenderID ecs.EntityID) {
+
+	t.Helper()
+
+	casterSquad := CreateSquad(em, "Banner", "Player", common.Position{X: 5, Y: 3}, DirDown)
+	bearer := CreateUnitFromTemplate(em, Units["TestKnight"], casterSquad.SquadID, 0, 0)
+	AssignSupportAbility(bearer, ability)
+
+	lineSquad := CreateSquad(em, "Line", "Player", common.Position{X: 5, Y: 4}, DirDown)
+	attacker := CreateUnitFromTemplate(em, Units["TestKnight"], lineSquad.SquadID, 0, 0)
+
+	enemySquad := CreateSquad(em, "Enemy", "Enemy", common.Position{X: 5, Y: 5}, DirUp)
+	defender := CreateUnitFromTemplate(em, Units["TestKnight"], enemySquad.SquadID, 0, 0)
+
+	return casterSquad, lineSquad, enemySquad,
+		bearer.GetID(), attacker.GetID(), defender.GetID()
+}
+
+// The buffed squads attacks show the raised damage for exactly the buffs
+// duration, then fall back to the base numbers
+func TestBuffRaisesDamageForExactlyNRounds(t *testing.T) {
+
+	em := newTestManager()
+	registerTestTemplates()
+
+	originalRoll := dodgeRoll
+	dodgeRoll = func() int { return 100 }
+	defer func() { dodgeRoll = originalRoll }()
+
+	_, line, _, bearer, attacker, defender := supportFixture(t, em, SupportAbilityData{
+		AbilityID:      "Rally Banner",
+		Effect:         BuffHit,
+		Amount:         3,
+		Range:          1,
+		DurationRounds: 2,
+		Cooldown:       3,
+	})
+
+	if _, err := UseSupportAbility(em, bearer, line.SquadID); err != nil {
+		t.Fatalf("casting on an adjacent ally should work: %v", err)
+	}
+
+	//TestKnight deals 4 and the defender soaks 2, so 2 base and 5 buffed
+	expected := []int{5, 5, 2}
+
+	for round, want := range expected {
+
+		event := calculateUnitDamageByID(em, attacker, defender, DirDown)
+		if event.Damage != want {
+			t.Errorf("round %d: expected %d damage, got %d", round+1, want, event.Damage)
+		}
+
+		TickEffects(em, line.SquadID)
+		AdvanceSquadRound(line)
+
+	}
+
+	if SquadHasActiveBuffs(em, line.SquadID) {
+		t.Error("the buff should be gone after its duration expires")
+	}
+}
+
+// Casting spends the squads action for the round, and the round advance
+// hands it back
+func TestCastingConsumesTheSquadAction(t *testing.T) {
+
+	em := newTestManager()
+	registerTestTemplates()
+
+	ability := SupportAbilityData{
+		AbilityID:      "Rally Banner",
+		Effect:         BuffHit,
+		Amount:         3,
+		Range:          1,
+		DurationRounds: 2,
+		Cooldown:       0,
+	}
+
+	caster, line, _, bearer, _, _ := supportFixture(t, em, ability)
+
+	second := CreateUnitFromTemplate(em, Units["TestKnight"], caster.SquadID, 0, 1)
+	AssignSupportAbility(second, ability)
+
+	if _, err := UseSupportAbility(em, bearer, line.SquadID); err != nil {
+		t.Fatalf("the first cast should work: %v", err)
+	}
+
+	_, err := UseSupportAbility(em, second.GetID(), line.SquadID)
+	if err == nil || !strings.Contains(err.Error(), "already acted") {
+		t.Fatalf("a second cast in the same round should report the spent action, got %v", err)
+	}
+
+	TickEffects(em, caster.SquadID)
+	AdvanceSquadRound(caster)
+
+	if _, err := UseSupportAbility(em, second.GetID(), line.SquadID); err != nil {
+		t.Errorf("the action should be fresh again after the round advance: %v", err)
+	}
+}
+
+// The cooldown blocks reuse even once the action is back, until it has
+// ticked down
+func TestCooldownBlocksImmediateReuse(t *testing.T) {
+
+	em := newTestManager()
+	registerTestTemplates()
+
+	caster, line, _, bearer, _, _ := supportFixture(t, em, SupportAbilityData{
+		AbilityID:      "Rally Banner",
+		Effect:         BuffArmor,
+		Amount:         2,
+		Range:          1,
+		DurationRounds: 1,
+		Cooldown:       2,
+	})
+
+	if _, err := UseSupportAbility(em, bearer, line.SquadID); err != nil {
+		t.Fatalf("the first cast should work: %v", err)
+	}
+
+	TickEffects(em, caster.SquadID)
+	AdvanceSquadRound(caster)
+
+	_, err := UseSupportAbility(em, bearer, line.SquadID)
+	if err == nil || !strings.Contains(err.Error(), "cooldown") {
+		t.Fatalf("reuse next round should be blocked by the cooldown, got %v", err)
+	}
+
+	TickEffects(em, caster.SquadID)
+	AdvanceSquadRound(caster)
+
+	if _, err := UseSupportAbility(em, bearer, line.SquadID); err != nil {
+		t.Errorf("the ability should be castable once the cooldown runs out: %v", err)
+	}
+}
+
+// The defensive AI buffs the most wounded ally it can reach
+func TestChooseSupportTargetPrefersTheWoundedAlly(t *testing.T) {
+
+	em := newTestManager()
+	registerTestTemplates()
+
+	caster, line, enemy, bearer, attacker, _ := supportFixture(t, em, SupportAbilityData{
+		AbilityID: "Rally Banner",
+		Effect:    BuffHit,
+		Amount:    3,
+		Range:     1,
+	})
+
+	common.GetAttributes(FindUnitByID(em, attacker)).CurrentHealth = 5
+
+	candidates := []int{caster.SquadID, line.SquadID, enemy.SquadID}
+	if got := ChooseSupportTarget(em, bearer, candidates); got != line.SquadID {
+		t.Errorf("expected the AI to pick the wounded Line squad %d, got %d", line.SquadID, got)
+	}
+}
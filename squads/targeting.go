@@ -0,0 +1,313 @@
+package squads
+
+import (
+	"game_main/common"
+	"sort"
+
+	"github.com/bytearena/ecs"
+)
+
+// Row and column attacks hit every unit in their line by default. The
+// MaxTargetsPerAttack cap lets a designer express "heavy axe cleaves up to 2"
+// versus "halberd hits the whole column"
+
+type TargetMode int
+
+const (
+	TargetSingle TargetMode = iota
+	TargetRow
+	TargetColumn
+)
+
+// TargetPreference decides which units to keep when an attack caps out
+type TargetPreference int
+
+const (
+	PreferNearestColumn TargetPreference = iota
+	PreferLowestHP
+)
+
+// TargetRowData describes the shape of a units attack against the enemy grid
+type TargetRowData struct {
+	Mode  TargetMode
+	Index int //Which row or column of the defender grid gets hit
+
+	//Maximum number of distinct units one attack damages. 0 means unlimited,
+	//which is the old all-or-nothing behavior
+	MaxTargetsPerAttack int
+
+	Preference TargetPreference
+
+	//Melee attacks can be intercepted by guardians
+	Melee bool
+
+	//Class this attack hunts for, like an assassin going after Support.
+	//A soft filter: when any unit of the preferred class is in the attack
+	//shape only those get hit, otherwise targeting proceeds as normal
+	PreferredTargetRole string
+}
+
+// TargetingConfig holds the grid-shape targeting rules a design can toggle
+type TargetingConfig struct {
+	//When an attackers column holds no living defender, wrap the search to
+	//the next column around the grid. Off, the search clamps to the column
+	//itself and finds nothing, so a column 2 attacker never spills to
+	//column 0
+	ColumnTargetingWraps bool
+
+	//When a multi-cell unit is hit, evaluate positional damage modifiers
+	//(cover) against the cell the attack actually struck instead of the
+	//units anchor cell. Off, large units behave like their anchor, which is
+	//the historical behavior
+	ModifiersUseHitCell bool
+}
+
+// Wrapping is the historical behavior, so it stays the default
+var Targeting = TargetingConfig{ColumnTargetingWraps: true}
+
+// ColumnTarget finds the frontmost living defender in the given column.
+// With wrapping on, an empty column spills to the next one around the grid;
+// clamped, an empty column means no target
+func ColumnTarget(em *common.EntityManager, defenderSquadID int, col int) *ecs.Entity {
+
+	//The wrap runs over the defenders grid, which may be narrower or wider
+	//than the attackers: an attacker in column 2 facing a 1-wide boss grid
+	//lands on column 0
+	width, _ := SquadGridSize(em, defenderSquadID)
+
+	for offset := 0; offset < width; offset++ {
+
+		if target := frontmostInColumn(em, defenderSquadID, (col+offset)%width); target != nil {
+			return target
+		}
+
+		if !Targeting.ColumnTargetingWraps {
+			return nil
+		}
+
+	}
+
+	return nil
+}
+
+func frontmostInColumn(em *common.EntityManager, squadID int, col int) *ecs.Entity {
+
+	var best *ecs.Entity = nil
+	bestRow := 0
+
+	for _, u := range SquadUnits(em, squadID) {
+
+		if common.GetAttributes(u).CurrentHealth <= 0 {
+			continue
+		}
+
+		data := GetUnitData(u)
+		if data.Col != col {
+			continue
+		}
+
+		if best == nil || data.Row < bestRow {
+			best = u
+			bestRow = data.Row
+		}
+
+	}
+
+	return best
+}
+
+// TargetInfo records how target selection resolved, for battle logs and the
+// balance reports per-attack-type breakdown
+type TargetInfo struct {
+	TargetIDs          []ecs.EntityID
+	TotalCandidates    int
+	CappedByMaxTargets bool
+
+	//Guardian substitutions that happened, keyed by the guardian that
+	//stepped in, holding the unit it covered
+	Redirects map[ecs.EntityID]ecs.EntityID
+
+	//The (row, col) cell of each target the attack shape actually struck.
+	//Only differs from the anchor for multi-cell units
+	HitCells map[ecs.EntityID][2]int
+}
+
+// SelectTargets gathers the units of the defending squad that the attack shape
+// covers, deduplicates them, and applies the MaxTargetsPerAttack cap using the
+// attacks preference to decide which units to keep
+func SelectTargets(em *common.EntityManager, defenderSquadID int, data TargetRowData) TargetInfo {
+
+	candidates := make([]*ecs.Entity, 0)
+	seen := make(map[ecs.EntityID]bool)
+	hitCells := make(map[ecs.EntityID][2]int)
+
+	for _, u := range SquadUnits(em, defenderSquadID) {
+
+		unitData := GetUnitData(u)
+
+		//A multi-cell unit is in the shape when any of its cells is, and the
+		//matching cell is the one the attack struck
+		inShape := false
+		hitRow, hitCol := unitData.Row, unitData.Col
+
+		if len(unitData.CellOffsets) > 0 {
+
+			for _, cell := range unitData.OccupiedCells() {
+
+				switch data.Mode {
+				case TargetRow:
+					if cell[0] == data.Index {
+						inShape = true
+						hitRow, hitCol = cell[0], cell[1]
+					}
+				case TargetColumn:
+					if cell[1] == data.Index {
+						inShape = true
+						hitRow, hitCol = cell[0], cell[1]
+					}
+				case TargetSingle:
+					inShape = unitData.Row == data.Index && len(candidates) == 0
+				}
+
+				if inShape {
+					break
+				}
+
+			}
+
+		} else {
+
+			w, h := unitData.Span()
+			switch data.Mode {
+			case TargetRow:
+				if data.Index >= unitData.Row && data.Index < unitData.Row+h {
+					inShape = true
+					hitRow = data.Index
+				}
+			case TargetColumn:
+				if data.Index >= unitData.Col && data.Index < unitData.Col+w {
+					inShape = true
+					hitCol = data.Index
+				}
+			case TargetSingle:
+				inShape = unitData.Row == data.Index && len(candidates) == 0
+			}
+
+		}
+
+		if !inShape || common.GetAttributes(u).CurrentHealth <= 0 {
+			continue
+		}
+
+		//Multi-cell units show up once per cell, only count them once
+		if seen[u.GetID()] {
+			continue
+		}
+		seen[u.GetID()] = true
+		hitCells[u.GetID()] = [2]int{hitRow, hitCol}
+
+		candidates = append(candidates, u)
+
+	}
+
+	//The role preference narrows the pool before the cap so a capped attack
+	//spends its hits on the units it hunts for
+	if data.PreferredTargetRole != "" {
+
+		preferred := make([]*ecs.Entity, 0, len(candidates))
+		for _, c := range candidates {
+			if UnitTemplateFor(c).Class == data.PreferredTargetRole {
+				preferred = append(preferred, c)
+			}
+		}
+
+		if len(preferred) > 0 {
+			candidates = preferred
+		}
+
+	}
+
+	info := TargetInfo{TotalCandidates: len(candidates), HitCells: hitCells}
+
+	switch data.Preference {
+	case PreferLowestHP:
+		sort.SliceStable(candidates, func(i, j int) bool {
+			return common.GetAttributes(candidates[i]).CurrentHealth < common.GetAttributes(candidates[j]).CurrentHealth
+		})
+	default:
+		sort.SliceStable(candidates, func(i, j int) bool {
+			return GetUnitData(candidates[i]).Col < GetUnitData(candidates[j]).Col
+		})
+	}
+
+	if data.MaxTargetsPerAttack > 0 && len(candidates) > data.MaxTargetsPerAttack {
+		candidates = candidates[:data.MaxTargetsPerAttack]
+		info.CappedByMaxTargets = true
+	}
+
+	for _, c := range candidates {
+		info.TargetIDs = append(info.TargetIDs, c.GetID())
+	}
+
+	//Guardians substitute themselves for their charge against melee attacks.
+	//If one guardian covers several targets it is only hit once
+	if data.Melee {
+
+		intercepted := make([]ecs.EntityID, 0, len(info.TargetIDs))
+		taken := make(map[ecs.EntityID]bool)
+
+		for _, id := range info.TargetIDs {
+
+			sub := InterceptWithGuardian(em, id)
+			if taken[sub] {
+				continue
+			}
+			taken[sub] = true
+			intercepted = append(intercepted, sub)
+
+			if sub != id {
+
+				if info.Redirects == nil {
+					info.Redirects = make(map[ecs.EntityID]ecs.EntityID)
+				}
+				info.Redirects[sub] = id
+
+			}
+
+		}
+
+		info.TargetIDs = intercepted
+
+	}
+
+	return info
+}
+
+// ResolveRowAttack runs one attack against every selected target and returns
+// the events along with how the targets were chosen
+func ResolveRowAttack(em *common.EntityManager, attackerID ecs.EntityID, defenderSquadID int, data TargetRowData, mapAttackDir Direction) ([]AttackEvent, TargetInfo) {
+
+	info := SelectTargets(em, defenderSquadID, data)
+
+	events := make([]AttackEvent, 0, len(info.TargetIDs))
+	for _, targetID := range info.TargetIDs {
+
+		var event AttackEvent
+		if cell, ok := info.HitCells[targetID]; ok && Targeting.ModifiersUseHitCell {
+			event = ResolveUnitAttackAtCell(em, attackerID, targetID, mapAttackDir, cell[0], cell[1])
+		} else {
+			event = ResolveUnitAttack(em, attackerID, targetID, mapAttackDir)
+		}
+
+		//Mark guardian interceptions so both units stats stay attributable
+		if original, ok := info.Redirects[targetID]; ok {
+			event.Redirected = true
+			event.RedirectedFrom = original
+		}
+
+		events = append(events, event)
+
+	}
+
+	return events, info
+}
@@ -0,0 +1,207 @@
+package squads
+
+import (
+	"game_main/common"
+	"testing"
+)
+
+func fullFrontRowSquad(em *common.EntityManager) *SquadData {
+
+	squad := CreateSquad(em, "Defenders", "Enemy", common.Position{X: 5, Y: 5}, DirUp)
+	for col := 0; col < GridWidth; col++ {
+		CreateUnitFromTemplate(em, Units["TestKnight"], squad.SquadID, 0, col)
+	}
+
+	return squad
+}
+
+// A MaxTargets 2 row attacker against a full front row damages exactly 2 units
+// chosen by the active preference
+func TestMaxTargetsPerAttackCapsRowAttack(t *testing.T) {
+
+	em := newTestManager()
+	registerTestTemplates()
+
+	defenders := fullFrontRowSquad(em)
+
+	attack := TargetRowData{
+		Mode:                TargetRow,
+		Index:               0,
+		MaxTargetsPerAttack: 2,
+		Preference:          PreferNearestColumn,
+	}
+
+	info := SelectTargets(em, defenders.SquadID, attack)
+
+	if len(info.TargetIDs) != 2 {
+		t.Fatalf("expected exactly 2 targets, got %d", len(info.TargetIDs))
+	}
+	if !info.CappedByMaxTargets {
+		t.Error("expected the cap to be recorded in TargetInfo")
+	}
+	if info.TotalCandidates != 3 {
+		t.Errorf("expected 3 candidates before the cap, got %d", info.TotalCandidates)
+	}
+
+	//Nearest column preference keeps columns 0 and 1
+	for i, id := range info.TargetIDs {
+		if GetUnitData(FindUnitByID(em, id)).Col != i {
+			t.Errorf("expected target %d to be in column %d", i, i)
+		}
+	}
+}
+
+// MaxTargets 0 behaves identically to the old all-or-nothing behavior
+func TestMaxTargetsZeroIsUnlimited(t *testing.T) {
+
+	em := newTestManager()
+	registerTestTemplates()
+
+	defenders := fullFrontRowSquad(em)
+
+	attack := TargetRowData{Mode: TargetRow, Index: 0}
+	info := SelectTargets(em, defenders.SquadID, attack)
+
+	if len(info.TargetIDs) != 3 {
+		t.Fatalf("expected the whole row to be hit, got %d targets", len(info.TargetIDs))
+	}
+	if info.CappedByMaxTargets {
+		t.Error("an uncapped attack should not record a cap")
+	}
+}
+
+// An assassin hunting Support picks the support unit out of the row
+func TestPreferredRolePicksSupportWhenReachable(t *testing.T) {
+
+	em := newTestManager()
+	registerTestTemplates()
+
+	Units["TestHealer"] = UnitTemplate{
+		Name:      "TestHealer",
+		Class:     "Support",
+		Attr:      common.NewBaseAttributes(8, 0, 8, 0, 1, 0),
+		MinDamage: 1,
+		MaxDamage: 1,
+	}
+
+	defenders := CreateSquad(em, "Defenders", "Enemy", common.Position{X: 5, Y: 5}, DirUp)
+	CreateUnitFromTemplate(em, Units["TestKnight"], defenders.SquadID, 0, 0)
+	healer := CreateUnitFromTemplate(em, Units["TestHealer"], defenders.SquadID, 0, 1)
+	CreateUnitFromTemplate(em, Units["TestKnight"], defenders.SquadID, 0, 2)
+
+	attack := TargetRowData{
+		Mode:                TargetRow,
+		Index:               0,
+		MaxTargetsPerAttack: 1,
+		PreferredTargetRole: "Support",
+	}
+
+	info := SelectTargets(em, defenders.SquadID, attack)
+
+	if len(info.TargetIDs) != 1 || info.TargetIDs[0] != healer.GetID() {
+		t.Error("expected the support unit to be singled out by the role preference")
+	}
+}
+
+// With no preferred target in the shape the preference is ignored
+func TestPreferredRoleFallsBackToNormalTargeting(t *testing.T) {
+
+	em := newTestManager()
+	registerTestTemplates()
+
+	defenders := fullFrontRowSquad(em)
+
+	attack := TargetRowData{
+		Mode:                TargetRow,
+		Index:               0,
+		PreferredTargetRole: "Support",
+	}
+
+	info := SelectTargets(em, defenders.SquadID, attack)
+
+	if len(info.TargetIDs) != 3 {
+		t.Errorf("with no support unit in the row all 3 knights should be hit, got %d", len(info.TargetIDs))
+	}
+}
+
+// The lowest HP preference keeps the most wounded units when capping
+func TestLowestHPPreference(t *testing.T) {
+
+	em := newTestManager()
+	registerTestTemplates()
+
+	defenders := fullFrontRowSquad(em)
+
+	wounded := UnitAtGridPos(em, defenders.SquadID, 0, 2)
+	common.GetAttributes(wounded).CurrentHealth = 3
+
+	attack := TargetRowData{
+		Mode:                TargetRow,
+		Index:               0,
+		MaxTargetsPerAttack: 1,
+		Preference:          PreferLowestHP,
+	}
+
+	info := SelectTargets(em, defenders.SquadID, attack)
+
+	if len(info.TargetIDs) != 1 || info.TargetIDs[0] != wounded.GetID() {
+		t.Error("expected the wounded unit to be kept by the lowest HP preference")
+	}
+}
+
+// With wrapping on, a column 2 attacker facing an empty column 2 spills to
+// column 0, the historical behavior
+func TestColumnTargetWrapsToTheNextColumn(t *testing.T) {
+
+	em := newTestManager()
+	registerTestTemplates()
+
+	defenders := CreateSquad(em, "Defenders", "Enemy", common.Position{X: 5, Y: 5}, DirUp)
+	CreateUnitFromTemplate(em, Units["TestKnight"], defenders.SquadID, 0, 0)
+
+	target := ColumnTarget(em, defenders.SquadID, 2)
+	if target == nil {
+		t.Fatal("expected the search to wrap around to column 0")
+	}
+	if GetUnitData(target).Col != 0 {
+		t.Errorf("expected the column 0 knight, got column %d", GetUnitData(target).Col)
+	}
+}
+
+// Clamped, the same empty column finds no target at all
+func TestColumnTargetClampsWhenWrappingIsOff(t *testing.T) {
+
+	Targeting.ColumnTargetingWraps = false
+	defer func() { Targeting.ColumnTargetingWraps = true }()
+
+	em := newTestManager()
+	registerTestTemplates()
+
+	defenders := CreateSquad(em, "Defenders", "Enemy", common.Position{X: 5, Y: 5}, DirUp)
+	CreateUnitFromTemplate(em, Units["TestKnight"], defenders.SquadID, 0, 0)
+
+	if target := ColumnTarget(em, defenders.SquadID, 2); target != nil {
+		t.Errorf("expected no target with wrapping off, got column %d", GetUnitData(target).Col)
+	}
+
+	//A defender straight ahead is still found
+	if target := ColumnTarget(em, defenders.SquadID, 0); target == nil {
+		t.Error("clamping should not hide the defender in the attackers own column")
+	}
+}
+
+// The frontmost unit of the column wins, not the first one registered
+func TestColumnTargetPrefersTheFrontRow(t *testing.T) {
+
+	em := newTestManager()
+	registerTestTemplates()
+
+	defenders := CreateSquad(em, "Defenders", "Enemy", common.Position{X: 5, Y: 5}, DirUp)
+	CreateUnitFromTemplate(em, Units["TestKnight"], defenders.SquadID, 2, 1)
+	front := CreateUnitFromTemplate(em, Units["TestRogue"], defenders.SquadID, 1, 1)
+
+	target := ColumnTarget(em, defenders.SquadID, 1)
+	if target == nil || target.GetID() != front.GetID() {
+		t.Error("expected the row 1 rogue ahead of the row 2 knight")
+	}
+}
@@ -0,0 +1,43 @@
+package squads
+
+import (
+	"game_main/entitytemplates"
+)
+
+// Bridges the monster JSON templates into squad unit templates so squads can
+// be built from the same data the spawner uses
+
+// CreateUnitTemplates registers a unit template for every monster template.
+// Armor protection folds into the units attributes since squad units don't
+// manage their starting armor as separate gear entities
+func CreateUnitTemplates(monsters []entitytemplates.JSONMonster) {
+
+	for _, m := range monsters {
+
+		template := UnitTemplate{
+			Name:        m.Name,
+			Class:       m.Name,
+			Attr:        m.Attributes.NewAttributesFromJson(),
+			Cost:        m.Cost,
+			CellOffsets: m.Footprint,
+		}
+
+		if m.Armor != nil {
+			template.Attr.BaseProtection += m.Armor.Protection
+			template.Attr.BaseArmorClass += m.Armor.ArmorClass
+			template.Attr.BaseDodgeChance += m.Armor.DodgeChance
+		}
+
+		if m.MeleeWeapon != nil {
+			template.MinDamage = m.MeleeWeapon.MinDamage
+			template.MaxDamage = m.MeleeWeapon.MaxDamage
+		} else if m.RangedWeapon != nil {
+			template.MinDamage = m.RangedWeapon.MinDamage
+			template.MaxDamage = m.RangedWeapon.MaxDamage
+		}
+
+		RegisterUnitTemplate(template)
+
+	}
+
+}
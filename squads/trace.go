@@ -0,0 +1,44 @@
+package squads
+
+// Combat tracing for debugging damage discrepancies. With Tracing.Enabled
+// every attack carries a structured breakdown of its damage pipeline on the
+// event, one step per stage that changed the number, plus the dodge and crit
+// rolls. Disabled by default so regular battles never pay for it
+
+type TraceConfig struct {
+	Enabled bool
+}
+
+var Tracing = TraceConfig{}
+
+// TraceStep is one stage of the damage pipeline: what it added or removed
+// and the running total afterwards
+type TraceStep struct {
+	Stage string `json:"stage"`
+	Delta int    `json:"delta"`
+	Total int    `json:"total"`
+}
+
+// AttackTrace is the full breakdown attached to an AttackEvent. More
+// detailed than the event itself, which only keeps the results
+type AttackTrace struct {
+	Steps []TraceStep `json:"steps"`
+
+	//The crit roll against the attackers Focus. Zero when no roll happened
+	CritRoll int `json:"critRoll,omitempty"`
+
+	//The dodge roll against the defenders dodge chance in percent
+	DodgeRoll      int `json:"dodgeRoll"`
+	DodgeThreshold int `json:"dodgeThreshold"`
+}
+
+// add records a pipeline stage. Safe on a nil trace so the combat code can
+// call it unconditionally
+func (t *AttackTrace) add(stage string, delta int, total int) {
+
+	if t == nil {
+		return
+	}
+
+	t.Steps = append(t.Steps, TraceStep{Stage: stage, Delta: delta, Total: total})
+}
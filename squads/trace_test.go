@@ -0,0 +1,118 @@
+package squads
+
+import (
+	"game_main/common"
+	"testing"
+)
+
+// A fully deterministic knight-on-knight attack: the 4-4 weapon roll minus 2
+// protection, nothing else. The trace must list exactly those stages
+func TestTraceListsEveryPipelineStage(t *testing.T) {
+
+	Tracing.Enabled = true
+	defer func() { Tracing.Enabled = false }()
+
+	originalDodge := dodgeRoll
+	dodgeRoll = func() int { return 100 }
+	defer func() { dodgeRoll = originalDodge }()
+
+	em := newTestManager()
+	registerTestTemplates()
+
+	att := CreateSquad(em, "Attackers", "Player", common.Position{X: 5, Y: 5}, DirDown)
+	attacker := CreateUnitFromTemplate(em, Units["TestKnight"], att.SquadID, 0, 0)
+
+	def := CreateSquad(em, "Defenders", "Enemy", common.Position{X: 5, Y: 6}, DirUp)
+	defender := CreateUnitFromTemplate(em, Units["TestKnight"], def.SquadID, 0, 0)
+
+	event := calculateUnitDamageByID(em, attacker.GetID(), defender.GetID(), DirDown)
+
+	if event.Trace == nil {
+		t.Fatal("expected a trace on the event while tracing is enabled")
+	}
+
+	want := []TraceStep{
+		{Stage: "weapon roll", Delta: 4, Total: 4},
+		{Stage: "protection", Delta: -2, Total: 2},
+	}
+
+	if len(event.Trace.Steps) != len(want) {
+		t.Fatalf("expected %d trace steps, got %v", len(want), event.Trace.Steps)
+	}
+	for i, step := range want {
+		if event.Trace.Steps[i] != step {
+			t.Errorf("step %d: expected %+v, got %+v", i, step, event.Trace.Steps[i])
+		}
+	}
+
+	if event.Trace.DodgeRoll != 100 {
+		t.Errorf("expected the dodge roll of 100 in the trace, got %d", event.Trace.DodgeRoll)
+	}
+	if event.Trace.DodgeThreshold != 0 {
+		t.Errorf("expected a dodge threshold of 0, got %d", event.Trace.DodgeThreshold)
+	}
+	if event.Damage != event.Trace.Steps[len(event.Trace.Steps)-1].Total {
+		t.Errorf("the trace total %d and the event damage %d disagree",
+			event.Trace.Steps[len(event.Trace.Steps)-1].Total, event.Damage)
+	}
+}
+
+// A rear attack adds backstab and floor stages, and the trace follows
+func TestTraceCoversBackstabAndFloor(t *testing.T) {
+
+	Tracing.Enabled = true
+	defer func() { Tracing.Enabled = false }()
+
+	originalDodge := dodgeRoll
+	dodgeRoll = func() int { return 100 }
+	defer func() { dodgeRoll = originalDodge }()
+
+	em := newTestManager()
+	registerTestTemplates()
+
+	att := CreateSquad(em, "Knives", "Player", common.Position{X: 5, Y: 5}, DirDown)
+	attacker := CreateUnitFromTemplate(em, Units["TestRogue"], att.SquadID, 0, 0)
+
+	//Facing away, so the attack arrives at the rear
+	def := CreateSquad(em, "Turned", "Enemy", common.Position{X: 5, Y: 6}, DirDown)
+	defender := CreateUnitFromTemplate(em, Units["TestRogue"], def.SquadID, 0, 0)
+
+	event := calculateUnitDamageByID(em, attacker.GetID(), defender.GetID(), DirDown)
+
+	if !event.Backstab {
+		t.Fatal("expected a backstab against a defender facing away")
+	}
+
+	//Rogue on rogue: 5 rolled, no protection, +4 backstab = 9
+	want := []TraceStep{
+		{Stage: "weapon roll", Delta: 5, Total: 5},
+		{Stage: "backstab", Delta: 4, Total: 9},
+	}
+
+	if len(event.Trace.Steps) != len(want) {
+		t.Fatalf("expected %d trace steps, got %v", len(want), event.Trace.Steps)
+	}
+	for i, step := range want {
+		if event.Trace.Steps[i] != step {
+			t.Errorf("step %d: expected %+v, got %+v", i, step, event.Trace.Steps[i])
+		}
+	}
+}
+
+// With tracing off the event stays lean
+func TestTraceIsAbsentWhenDisabled(t *testing.T) {
+
+	em := newTestManager()
+	registerTestTemplates()
+
+	att := CreateSquad(em, "Attackers", "Player", common.Position{X: 5, Y: 5}, DirDown)
+	attacker := CreateUnitFromTemplate(em, Units["TestKnight"], att.SquadID, 0, 0)
+
+	def := CreateSquad(em, "Defenders", "Enemy", common.Position{X: 5, Y: 6}, DirUp)
+	defender := CreateUnitFromTemplate(em, Units["TestKnight"], def.SquadID, 0, 0)
+
+	event := calculateUnitDamageByID(em, attacker.GetID(), defender.GetID(), DirDown)
+	if event.Trace != nil {
+		t.Error("expected no trace while tracing is disabled")
+	}
+}
@@ -0,0 +1,152 @@
+package squads
+
+import (
+	"fmt"
+	"game_main/common"
+	"sort"
+)
+
+// Squad combat runs faction by faction, and within a faction the squads act
+// in a fixed, deterministic order. The order is computed once when combat
+// starts - initiative first, squad power as the tie breaker - and then only
+// shrinks as squads get destroyed, so a round never reshuffles who goes
+// when. The turn manager holds one of these through its CombatOrder
+// interface, since timesystem cannot import this package
+
+type CombatTurnOrder struct {
+	Factions []string
+
+	//Index into Factions of the faction whose turn it is
+	Current int
+
+	//Upper bound on squads per faction, enforced when squads join after
+	//combat start. 0 means unlimited, which keeps large simulations honest
+	//about choosing their own bound
+	MaxSquadsPerFaction int
+
+	em     *common.EntityManager
+	orders map[string][]int
+}
+
+// NewCombatTurnOrder fixes the turn order for a battle: the factions cycle
+// from startingIndex, and each factions squads are ordered by initiative
+func NewCombatTurnOrder(em *common.EntityManager, factions []string, startingIndex int) *CombatTurnOrder {
+
+	combat := &CombatTurnOrder{
+		Factions: factions,
+		em:       em,
+		orders:   make(map[string][]int),
+	}
+
+	if len(factions) > 0 {
+		combat.Current = startingIndex % len(factions)
+	}
+
+	for _, s := range em.World.Query(em.WorldTags["squads"]) {
+
+		data := GetSquadData(s.Entity)
+		combat.orders[data.Faction] = append(combat.orders[data.Faction], data.SquadID)
+
+	}
+
+	for faction, squadIDs := range combat.orders {
+
+		sort.SliceStable(squadIDs, func(i, j int) bool {
+
+			ii, pi := squadInitiative(em, squadIDs[i])
+			ij, pj := squadInitiative(em, squadIDs[j])
+
+			if ii != ij {
+				return ii > ij
+			}
+			if pi != pj {
+				return pi > pj
+			}
+			return squadIDs[i] < squadIDs[j]
+
+		})
+
+		combat.orders[faction] = squadIDs
+
+	}
+
+	return combat
+}
+
+// GetTurnOrder is the order a factions squads act in this round: the order
+// fixed at combat start, minus any squad that has since been wiped out
+func (c *CombatTurnOrder) GetTurnOrder(factionID string) []int {
+
+	order := []int{}
+	for _, squadID := range c.orders[factionID] {
+
+		if squadHasLivingUnits(c.em, squadID) {
+			order = append(order, squadID)
+		}
+
+	}
+
+	return order
+}
+
+// AddSquadToFaction registers a squad that joined after combat start, e.g.
+// a reinforcement. It acts after the squads already in the order, so the
+// existing order never reshuffles. Fails once the faction is at its cap
+func (c *CombatTurnOrder) AddSquadToFaction(factionID string, squadID int) error {
+
+	if c.MaxSquadsPerFaction > 0 && len(c.orders[factionID]) >= c.MaxSquadsPerFaction {
+		return fmt.Errorf("faction %s is at its cap of %d squads", factionID, c.MaxSquadsPerFaction)
+	}
+
+	c.orders[factionID] = append(c.orders[factionID], squadID)
+	return nil
+}
+
+// AdvanceFaction hands the turn to the next faction in the cycle and
+// returns it
+func (c *CombatTurnOrder) AdvanceFaction() string {
+
+	if len(c.Factions) == 0 {
+		return ""
+	}
+
+	c.Current = (c.Current + 1) % len(c.Factions)
+
+	//Wrapping back to the first faction closes the round, which is when
+	//objective tiles capture and contest
+	if c.Current == 0 {
+		ResolveObjectives(c.em)
+	}
+
+	return c.Factions[c.Current]
+}
+
+// The initiative of a squad is the sum over its living units, with the
+// squads raw power (total max health) breaking ties
+func squadInitiative(em *common.EntityManager, squadID int) (int, int) {
+
+	initiative, power := 0, 0
+	for _, u := range SquadUnits(em, squadID) {
+
+		if common.GetAttributes(u).CurrentHealth <= 0 {
+			continue
+		}
+
+		initiative += UnitTemplateFor(u).Initiative
+		power += common.GetAttributes(u).MaxHealth
+
+	}
+
+	return initiative, power
+}
+
+func squadHasLivingUnits(em *common.EntityManager, squadID int) bool {
+
+	for _, u := range SquadUnits(em, squadID) {
+		if common.GetAttributes(u).CurrentHealth > 0 {
+			return true
+		}
+	}
+
+	return false
+}
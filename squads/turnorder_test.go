@@ -0,0 +1,127 @@
+package squads
+
+import (
+	"game_main/common"
+	"testing"
+)
+
+// Three player squads with distinct initiative, slowest created first
+func initiativeFixture(em *common.EntityManager) (int, int, int) {
+
+	for name, initiative := range map[string]int{"Slow": 1, "Middle": 5, "Fast": 9} {
+		Units[name] = UnitTemplate{
+			Name:       name,
+			Attr:       common.NewBaseAttributes(10, 0, 10, 0, 1, 0),
+			MinDamage:  1,
+			MaxDamage:  1,
+			Initiative: initiative,
+		}
+	}
+
+	slow := CreateSquad(em, "Slow", "Player", common.Position{X: 1, Y: 1}, DirDown)
+	middle := CreateSquad(em, "Middle", "Player", common.Position{X: 2, Y: 1}, DirDown)
+	fast := CreateSquad(em, "Fast", "Player", common.Position{X: 3, Y: 1}, DirDown)
+
+	CreateUnitFromTemplate(em, Units["Slow"], slow.SquadID, 0, 0)
+	CreateUnitFromTemplate(em, Units["Middle"], middle.SquadID, 0, 0)
+	CreateUnitFromTemplate(em, Units["Fast"], fast.SquadID, 0, 0)
+
+	return slow.SquadID, middle.SquadID, fast.SquadID
+}
+
+func TestTurnOrderFollowsInitiative(t *testing.T) {
+
+	em := newTestManager()
+	slow, middle, fast := initiativeFixture(em)
+
+	combat := NewCombatTurnOrder(em, []string{"Player", "Enemy"}, 0)
+
+	order := combat.GetTurnOrder("Player")
+	want := []int{fast, middle, slow}
+
+	if len(order) != 3 {
+		t.Fatalf("expected all 3 squads in the order, got %v", order)
+	}
+	for i := range want {
+		if order[i] != want[i] {
+			t.Fatalf("expected order %v, got %v", want, order)
+		}
+	}
+}
+
+// The order does not move between rounds until a squad is destroyed, and
+// then only that squad drops out
+func TestTurnOrderStableUntilDestruction(t *testing.T) {
+
+	em := newTestManager()
+	slow, middle, fast := initiativeFixture(em)
+
+	combat := NewCombatTurnOrder(em, []string{"Player", "Enemy"}, 0)
+
+	first := combat.GetTurnOrder("Player")
+	second := combat.GetTurnOrder("Player")
+
+	for i := range first {
+		if first[i] != second[i] {
+			t.Fatalf("the order moved between rounds: %v then %v", first, second)
+		}
+	}
+
+	//Wipe the middle squad
+	for _, u := range SquadUnits(em, middle) {
+		common.GetAttributes(u).CurrentHealth = 0
+	}
+
+	order := combat.GetTurnOrder("Player")
+	if len(order) != 2 || order[0] != fast || order[1] != slow {
+		t.Errorf("expected the destroyed squad to drop out without reshuffling, got %v", order)
+	}
+}
+
+// Squads join up to the cap, the next add fails, and an unset cap never does
+func TestSquadCapPerFaction(t *testing.T) {
+
+	em := newTestManager()
+	initiativeFixture(em)
+
+	combat := NewCombatTurnOrder(em, []string{"Player", "Enemy"}, 0)
+	combat.MaxSquadsPerFaction = 2
+
+	if err := combat.AddSquadToFaction("Enemy", 101); err != nil {
+		t.Fatalf("adding below the cap failed: %v", err)
+	}
+	if err := combat.AddSquadToFaction("Enemy", 102); err != nil {
+		t.Fatalf("adding up to the cap failed: %v", err)
+	}
+	if err := combat.AddSquadToFaction("Enemy", 103); err == nil {
+		t.Error("adding past the cap should fail")
+	}
+
+	//The player faction already carries 3 squads from the fixture, over the
+	//cap, so any further add fails
+	if err := combat.AddSquadToFaction("Player", 104); err == nil {
+		t.Error("a faction already over the cap should reject new squads")
+	}
+
+	uncapped := NewCombatTurnOrder(em, []string{"Player", "Enemy"}, 0)
+	for id := 200; id < 210; id++ {
+		if err := uncapped.AddSquadToFaction("Enemy", id); err != nil {
+			t.Fatalf("an unset cap should allow unlimited squads, failed at %d: %v", id, err)
+		}
+	}
+}
+
+func TestAdvanceFactionCycles(t *testing.T) {
+
+	em := newTestManager()
+	initiativeFixture(em)
+
+	combat := NewCombatTurnOrder(em, []string{"Player", "Enemy"}, 0)
+
+	if combat.AdvanceFaction() != "Enemy" {
+		t.Error("expected the enemy faction after the player")
+	}
+	if combat.AdvanceFaction() != "Player" {
+		t.Error("expected the cycle to wrap back to the player")
+	}
+}
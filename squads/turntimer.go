@@ -0,0 +1,236 @@
+package squads
+
+import (
+	"fmt"
+	"math"
+
+	"game_main/common"
+)
+
+// An optional turn timer for tactical combat: when a player factions clock
+// runs out, the active squads remaining actions resolve through a minimal
+// fallback policy and the turn moves on. The machine is pure Go driven by
+// Update(deltaSeconds), so the pressure rules are testable without a frame
+// loop. Disabled by default, and AI factions never run on the clock
+
+type TurnTimerScope int
+
+const (
+	//The clock refills for every squad activation
+	TimerPerActivation TurnTimerScope = iota
+
+	//One clock covers the whole faction turn across its activations
+	TimerPerFactionTurn
+)
+
+type TurnTimerConfig struct {
+	Enabled bool
+
+	//The budget in seconds, per activation or per faction turn by Scope
+	Seconds float64
+	Scope   TurnTimerScope
+
+	//How many seconds before expiry the countdown turns urgent. 0 means 10
+	WarningSeconds float64
+}
+
+func (c TurnTimerConfig) withDefaults() TurnTimerConfig {
+
+	if c.WarningSeconds <= 0 {
+		c.WarningSeconds = 10
+	}
+
+	return c
+}
+
+type TurnTimerState int
+
+const (
+	TimerIdle TurnTimerState = iota
+	TimerRunning
+	TimerPaused
+	TimerExpired
+)
+
+// What a frame of Update reports back. Warning and Expired each fire once;
+// every other frame is None
+type TurnTimerEvent int
+
+const (
+	TimerEventNone TurnTimerEvent = iota
+	TimerEventWarning
+	TimerEventExpired
+)
+
+type TurnTimer struct {
+	config TurnTimerConfig
+
+	state     TurnTimerState
+	remaining float64
+	warned    bool
+
+	//Set once the expiry event fired for the current activation, so a squad
+	//never gets its actions fallback-resolved twice
+	resolved bool
+
+	//Pauses nest: a modal dialog over an active playback both hold the
+	//clock, and it resumes only once every holder lets go
+	pauseDepth int
+}
+
+func NewTurnTimer(config TurnTimerConfig) *TurnTimer {
+	return &TurnTimer{config: config.withDefaults()}
+}
+
+// StartFactionTurn winds the clock at the top of a faction turn. AI factions
+// and a disabled timer leave it idle
+func (t *TurnTimer) StartFactionTurn(aiControlled bool) {
+
+	if !t.config.Enabled || aiControlled {
+		t.state = TimerIdle
+		return
+	}
+
+	t.remaining = t.config.Seconds
+	t.warned = false
+	t.resolved = false
+	t.state = t.runningOrPaused()
+}
+
+// StartActivation re-arms the timer for the next squad. Per-activation scope
+// refills the clock; per-faction-turn scope keeps counting down, and a squad
+// activating on an already-dead clock gets its one expiry event on the next
+// Update
+func (t *TurnTimer) StartActivation(aiControlled bool) {
+
+	if !t.config.Enabled || aiControlled {
+		t.state = TimerIdle
+		return
+	}
+
+	t.resolved = false
+
+	if t.config.Scope == TimerPerActivation {
+		t.remaining = t.config.Seconds
+		t.warned = false
+		t.state = t.runningOrPaused()
+	}
+
+}
+
+func (t *TurnTimer) runningOrPaused() TurnTimerState {
+
+	if t.pauseDepth > 0 {
+		return TimerPaused
+	}
+
+	return TimerRunning
+}
+
+// Pause holds the clock, for modal dialogs and battle playback. Calls nest
+func (t *TurnTimer) Pause() {
+
+	t.pauseDepth++
+	if t.state == TimerRunning {
+		t.state = TimerPaused
+	}
+
+}
+
+// Resume releases one Pause. The clock only runs again once the last holder
+// has released
+func (t *TurnTimer) Resume() {
+
+	if t.pauseDepth > 0 {
+		t.pauseDepth--
+	}
+
+	if t.pauseDepth == 0 && t.state == TimerPaused {
+		t.state = TimerRunning
+	}
+
+}
+
+// Update advances the clock and reports the edge it crossed this frame, if
+// any. Expiry fires exactly once per activation; after that the timer sits
+// in TimerExpired until the next activation or faction turn re-arms it
+func (t *TurnTimer) Update(deltaSeconds float64) TurnTimerEvent {
+
+	if t.state == TimerExpired && !t.resolved {
+		t.resolved = true
+		return TimerEventExpired
+	}
+
+	if t.state != TimerRunning {
+		return TimerEventNone
+	}
+
+	t.remaining -= deltaSeconds
+
+	if t.remaining <= 0 {
+		t.remaining = 0
+		t.state = TimerExpired
+		t.resolved = true
+		return TimerEventExpired
+	}
+
+	if !t.warned && t.remaining <= t.config.WarningSeconds {
+		t.warned = true
+		return TimerEventWarning
+	}
+
+	return TimerEventNone
+}
+
+func (t *TurnTimer) State() TurnTimerState {
+	return t.state
+}
+
+func (t *TurnTimer) Remaining() float64 {
+	return t.remaining
+}
+
+// InWarning is true while the countdown is inside the warning window, for
+// the HUD to tint the label
+func (t *TurnTimer) InWarning() bool {
+	return t.warned && t.state != TimerIdle
+}
+
+// HUDLabel is the countdown the HUD shows: m:ss, with a bang inside the
+// warning window. Empty while the timer does not apply
+func (t *TurnTimer) HUDLabel() string {
+
+	if t.state == TimerIdle {
+		return ""
+	}
+
+	total := int(math.Ceil(t.remaining))
+	label := fmt.Sprintf("%d:%02d", total/60, total%60)
+	if t.InWarning() {
+		label += " !"
+	}
+
+	return label
+}
+
+// The fallback resolution for a squad whose clock ran out
+type FallbackAction int
+
+const (
+	FallbackEndActivation FallbackAction = iota
+	FallbackGuard
+)
+
+// ChooseFallbackAction resolves an expired squads remaining actions with the
+// same threat scoring the battle AI targets by: guard if any enemy in range
+// still threatens damage, otherwise just end the activation
+func ChooseFallbackAction(em *common.EntityManager, enemyIDs []int) FallbackAction {
+
+	for _, id := range enemyIDs {
+		if ThreatScore(em, id) > 0 {
+			return FallbackGuard
+		}
+	}
+
+	return FallbackEndActivation
+}
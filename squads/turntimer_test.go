@@ -0,0 +1,156 @@
+package squads
+
+import (
+	"testing"
+
+	"game_main/common"
+)
+
+func runningTimer(seconds float64, scope TurnTimerScope) *TurnTimer {
+
+	timer := NewTurnTimer(TurnTimerConfig{Enabled: true, Seconds: seconds, Scope: scope})
+	timer.StartFactionTurn(false)
+
+	return timer
+}
+
+// The clock holds while dialogs are up, and nested holders - a dialog over
+// playback - must all release before it runs again
+func TestTurnTimerPauseAndResumeNest(t *testing.T) {
+
+	timer := runningTimer(30, TimerPerActivation)
+
+	timer.Update(5)
+	timer.Pause()
+
+	if timer.Update(100) != TimerEventNone || timer.Remaining() != 25 {
+		t.Fatalf("a paused clock should not tick, got %f remaining", timer.Remaining())
+	}
+
+	timer.Pause()
+	timer.Resume()
+	if timer.State() != TimerPaused {
+		t.Fatal("one release of two holds should leave the clock paused")
+	}
+
+	timer.Resume()
+	timer.Update(5)
+	if timer.State() != TimerRunning || timer.Remaining() != 20 {
+		t.Errorf("the clock should run again after the last release, got %f remaining", timer.Remaining())
+	}
+
+}
+
+// The warning fires once when the countdown enters the window, and expiry
+// fires exactly once per activation
+func TestTurnTimerWarningAndExpiryFireOnce(t *testing.T) {
+
+	timer := runningTimer(30, TimerPerActivation)
+
+	if got := timer.Update(21); got != TimerEventWarning {
+		t.Fatalf("crossing 10 seconds should warn, got %v", got)
+	}
+	if got := timer.Update(1); got != TimerEventNone {
+		t.Fatalf("the warning should fire once, got %v", got)
+	}
+
+	if got := timer.Update(10); got != TimerEventExpired {
+		t.Fatalf("running out should expire, got %v", got)
+	}
+	for i := 0; i < 3; i++ {
+		if got := timer.Update(1); got != TimerEventNone {
+			t.Fatalf("expiry should resolve one fallback, not %d", i+2)
+		}
+	}
+
+	//The next squads activation re-arms a per-activation clock
+	timer.StartActivation(false)
+	if timer.State() != TimerRunning || timer.Remaining() != 30 {
+		t.Errorf("the next activation should refill the clock, got %f", timer.Remaining())
+	}
+
+}
+
+// On a per-faction-turn clock, every squad that activates after expiry gets
+// its single fallback resolution and no more
+func TestTurnTimerFactionScopeExpiresEachLateSquadOnce(t *testing.T) {
+
+	timer := runningTimer(10, TimerPerFactionTurn)
+
+	timer.StartActivation(false)
+	if got := timer.Update(15); got != TimerEventExpired {
+		t.Fatalf("the first squad should see the expiry, got %v", got)
+	}
+
+	timer.StartActivation(false)
+	if got := timer.Update(1); got != TimerEventExpired {
+		t.Fatalf("a squad activating on a dead clock should fall back too, got %v", got)
+	}
+	if got := timer.Update(1); got != TimerEventNone {
+		t.Fatalf("but only once, got %v", got)
+	}
+
+	timer.StartFactionTurn(false)
+	if timer.State() != TimerRunning {
+		t.Error("the next faction turn should wind the clock back up")
+	}
+
+}
+
+// Disabled timers and AI factions never run on the clock
+func TestTurnTimerStaysIdleWhenItDoesNotApply(t *testing.T) {
+
+	disabled := NewTurnTimer(TurnTimerConfig{Seconds: 30})
+	disabled.StartFactionTurn(false)
+	if disabled.State() != TimerIdle || disabled.Update(100) != TimerEventNone {
+		t.Error("a disabled timer should stay idle")
+	}
+
+	ai := NewTurnTimer(TurnTimerConfig{Enabled: true, Seconds: 30})
+	ai.StartFactionTurn(true)
+	ai.StartActivation(true)
+	if ai.State() != TimerIdle || ai.HUDLabel() != "" {
+		t.Error("AI factions should never run on the clock")
+	}
+
+}
+
+func TestTurnTimerHUDLabel(t *testing.T) {
+
+	timer := runningTimer(90, TimerPerActivation)
+
+	if got := timer.HUDLabel(); got != "1:30" {
+		t.Errorf("the countdown should format as m:ss, got %q", got)
+	}
+
+	timer.Update(81)
+	if got := timer.HUDLabel(); got != "0:09 !" {
+		t.Errorf("inside the warning window the label turns urgent, got %q", got)
+	}
+
+}
+
+// The fallback guards while a live enemy still threatens damage, otherwise
+// it just ends the activation
+func TestChooseFallbackAction(t *testing.T) {
+
+	em := newTestManager()
+	registerTestTemplates()
+
+	enemy := CreateSquad(em, "Raiders", "Enemy", common.Position{X: 5, Y: 5}, DirUp)
+	unit := CreateUnitFromTemplate(em, Units["TestKnight"], enemy.SquadID, 0, 0)
+
+	if got := ChooseFallbackAction(em, []int{enemy.SquadID}); got != FallbackGuard {
+		t.Errorf("a threatening enemy should make the squad guard, got %v", got)
+	}
+
+	common.GetAttributes(unit).CurrentHealth = 0
+	if got := ChooseFallbackAction(em, []int{enemy.SquadID}); got != FallbackEndActivation {
+		t.Errorf("a toothless field should just end the activation, got %v", got)
+	}
+
+	if got := ChooseFallbackAction(em, nil); got != FallbackEndActivation {
+		t.Errorf("no enemies should end the activation, got %v", got)
+	}
+
+}
@@ -0,0 +1,179 @@
+package squads
+
+import (
+	"game_main/common"
+	"sort"
+	"sync"
+
+	"github.com/bytearena/ecs"
+)
+
+// A UnitTemplate is the blueprint a squad unit is created from.
+// Templates are looked up by name in the Units map
+type UnitTemplate struct {
+	Name  string
+	Class string //Knight, Rogue, Archer, etc. Some combat rules key off of the class
+	Attr  common.Attributes
+
+	MinDamage int
+	MaxDamage int
+
+	//Flat damage soaked from attacks for units standing behind this one.
+	//Only applies against attacks arriving from the direction this unit faces
+	CoverValue int
+
+	//Extra flat damage this unit deals when its attack arrives at the defenders rear
+	BackstabBonus int
+
+	//Decides attack order inside the squad, highest goes first
+	Initiative int
+
+	//Nocturnal units get the night combat bonus from the environment cycle
+	Nocturnal bool
+
+	//Footprint as (row, col) offsets from the anchor for units that occupy
+	//several cells, like an L-shaped serpent. Empty means a single cell.
+	//Validated at data load, so templates in the registry are always sound
+	CellOffsets [][2]int
+
+	//Explicit budget cost. When non-zero it overrides the formula in
+	//UnitCost, so designers can price a unit independent of its stats
+	Cost float64
+}
+
+// All unit templates by name. Populated from JSON data or by tests. Code
+// outside the game, tools included, goes through the accessor functions
+// below instead of reaching into the map: the parallel sweep and the
+// background squad tester read templates from several goroutines, and only
+// the accessors hold the lock. Touching the map directly is only safe in
+// single-goroutine setup code and tests
+var Units = make(map[string]UnitTemplate)
+
+var unitTemplateMu sync.RWMutex
+
+// RegisterUnitTemplate adds or replaces a template in the registry
+func RegisterUnitTemplate(template UnitTemplate) {
+
+	unitTemplateMu.Lock()
+	defer unitTemplateMu.Unlock()
+	Units[template.Name] = template
+}
+
+// UnregisterUnitTemplate removes a template, for sessions that register
+// throwaway templates and clean up after themselves
+func UnregisterUnitTemplate(name string) {
+
+	unitTemplateMu.Lock()
+	defer unitTemplateMu.Unlock()
+	delete(Units, name)
+}
+
+// GetUnitTemplate looks a template up under the read lock, safe to call from
+// any goroutine
+func GetUnitTemplate(name string) (UnitTemplate, bool) {
+
+	unitTemplateMu.RLock()
+	defer unitTemplateMu.RUnlock()
+
+	template, ok := Units[name]
+	return template, ok
+}
+
+// UnitTemplateByName looks a template up without exposing the mutable map
+func UnitTemplateByName(name string) (UnitTemplate, bool) {
+	return GetUnitTemplate(name)
+}
+
+// UnitTemplateNames is a read-only view of whats registered, sorted so
+// callers iterate deterministically
+func UnitTemplateNames() []string {
+
+	unitTemplateMu.RLock()
+	names := make([]string, 0, len(Units))
+	for name := range Units {
+		names = append(names, name)
+	}
+	unitTemplateMu.RUnlock()
+
+	sort.Strings(names)
+
+	return names
+}
+
+var squadIDCounter = 0
+
+// NextSquadID hands out squad IDs so callers don't have to track them
+func NextSquadID() int {
+	squadIDCounter++
+	return squadIDCounter
+}
+
+// Creates the squad entity. Units are added with CreateUnitFromTemplate
+func CreateSquad(em *common.EntityManager, name string, faction string, mapPos common.Position, facing Direction) *SquadData {
+	return CreateSquadWithGrid(em, name, faction, mapPos, facing, 0, 0)
+}
+
+// CreateSquadWithGrid creates a squad on its own grid dimensions, e.g. a boss
+// alone on a 1x1 or a horde on a 5x5. Zero dimensions mean the 3x3 default
+func CreateSquadWithGrid(em *common.EntityManager, name string, faction string, mapPos common.Position, facing Direction, gridW int, gridH int) *SquadData {
+
+	data := &SquadData{
+		SquadID: NextSquadID(),
+		Name:    name,
+		Faction: faction,
+		MapPos:  mapPos,
+		Facing:  facing,
+		GridW:   gridW,
+		GridH:   gridH,
+	}
+
+	em.World.NewEntity().
+		AddComponent(SquadComponent, data).
+		AddComponent(common.PositionComponent, &common.Position{X: mapPos.X, Y: mapPos.Y})
+
+	em.NotifyChanged(common.RosterChanged)
+	return data
+}
+
+// Creates a unit entity from a template and places it in the squads grid.
+// Row 0 is the front row in the direction the squad faces
+func CreateUnitFromTemplate(em *common.EntityManager, template UnitTemplate, squadID int, row int, col int) *ecs.Entity {
+
+	attr := template.Attr
+	attr.CurrentHealth = attr.MaxHealth
+	attr.TotalArmorClass = attr.BaseArmorClass
+	attr.TotalProtection = attr.BaseProtection
+	attr.TotalDodgeChance = attr.BaseDodgeChance
+	attr.TotalMovementSpeed = attr.BaseMovementSpeed
+
+	unit := em.World.NewEntity().
+		AddComponent(UnitComponent, &UnitData{
+			SquadID:      squadID,
+			Row:          row,
+			Col:          col,
+			TemplateName: template.Name,
+			CellOffsets:  append([][2]int(nil), template.CellOffsets...),
+		}).
+		AddComponent(common.NameComponent, &common.Name{NameStr: template.Name}).
+		AddComponent(common.AttributeComponent, &attr).
+		AddComponent(UnitLevelComponent, &UnitLevelData{Level: 1})
+
+	//Spawn-time overrides (scenario health, difficulty scaling) can put a
+	//unit below the wound threshold from the start
+	RefreshWoundedState(unit)
+
+	em.NotifyChanged(common.SquadsChanged)
+	return unit
+}
+
+// Looks up the template a unit was created from. Returns a zero template if the
+// name is no longer registered, so callers don't have to nil check
+func UnitTemplateFor(unit *ecs.Entity) UnitTemplate {
+
+	data := GetUnitData(unit)
+	if t, ok := GetUnitTemplate(data.TemplateName); ok {
+		return t
+	}
+
+	return UnitTemplate{}
+}
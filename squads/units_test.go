@@ -0,0 +1,57 @@
+package squads
+
+import (
+	"sync"
+	"testing"
+)
+
+// Many goroutines reading the template registry through the accessors while
+// registrations land, the way the parallel sweep and a background squad test
+// overlap with setup. The race detector is the real assertion here; the
+// lookups just confirm readers see whole templates
+func TestUnitTemplateAccessorsAreConcurrencySafe(t *testing.T) {
+
+	RegisterUnitTemplate(UnitTemplate{Name: "RaceKnight", Class: "Knight", MinDamage: 4, MaxDamage: 4})
+	defer delete(Units, "RaceKnight")
+	defer delete(Units, "RaceLatecomer")
+
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+
+		wg.Add(1)
+		go func() {
+
+			defer wg.Done()
+			for i := 0; i < 500; i++ {
+
+				template, ok := GetUnitTemplate("RaceKnight")
+				if !ok || template.MinDamage != 4 || template.MaxDamage != 4 {
+					t.Error("a concurrent lookup should always see the whole template")
+					return
+				}
+
+				if _, ok := UnitTemplateByName("NoSuchUnit"); ok {
+					t.Error("an unregistered name should stay absent")
+					return
+				}
+
+				UnitTemplateNames()
+
+			}
+
+		}()
+
+	}
+
+	wg.Add(1)
+	go func() {
+
+		defer wg.Done()
+		for i := 0; i < 500; i++ {
+			RegisterUnitTemplate(UnitTemplate{Name: "RaceLatecomer", MinDamage: i})
+		}
+
+	}()
+
+	wg.Wait()
+}
@@ -0,0 +1,90 @@
+package squads
+
+import (
+	"game_main/common"
+
+	"github.com/bytearena/ecs"
+)
+
+// Wound states: a unit whose health falls below a fraction of its maximum is
+// Wounded, fighting on at a penalty instead of flipping straight from fully
+// effective to dead. The state lives in the unified effects component and
+// recomputes whenever health changes, so healing above the threshold clears
+// it with no separate bookkeeping
+
+// WoundConfig holds the wound threshold and penalties. Off by default so
+// existing battles and their checksums keep their binary alive-or-dead
+// behavior
+type WoundConfig struct {
+	Enabled bool
+
+	//Fraction of MaxHealth below which a living unit counts as wounded
+	Threshold float64
+
+	//Fraction of outgoing damage a wounded attacker loses
+	HitPenalty float64
+
+	//Flat map movement speed a wounded unit loses when it sets the squads
+	//pace
+	SpeedPenalty int
+}
+
+var Wounds = WoundConfig{Threshold: 0.3, HitPenalty: 0.2, SpeedPenalty: 1}
+
+// RefreshWoundedState recomputes the units wounded flag from its current
+// health. Every code path that changes health calls this, so the flag can
+// never go stale
+func RefreshWoundedState(unit *ecs.Entity) {
+
+	if !Wounds.Enabled {
+		return
+	}
+
+	attr := common.GetAttributes(unit)
+	wounded := attr.CurrentHealth > 0 &&
+		float64(attr.CurrentHealth) < float64(attr.MaxHealth)*Wounds.Threshold
+
+	GetUnitEffects(unit).Wounded = wounded
+}
+
+// IsWounded reports whether the unit is below the wound threshold. Always
+// false while wounds are disabled
+func IsWounded(unit *ecs.Entity) bool {
+
+	if !Wounds.Enabled {
+		return false
+	}
+
+	return GetUnitEffects(unit).Wounded
+}
+
+// SquadMovementSpeed is the squads pace on the map: the slowest living unit
+// sets it, and wounded units contribute their speed reduced by the penalty.
+// An empty squad moves at 0
+func SquadMovementSpeed(em *common.EntityManager, squadID int) int {
+
+	slowest := 0
+	for _, u := range SquadUnits(em, squadID) {
+
+		if common.GetAttributes(u).CurrentHealth <= 0 {
+			continue
+		}
+
+		speed := common.GetAttributes(u).TotalMovementSpeed
+		if IsWounded(u) {
+
+			speed -= Wounds.SpeedPenalty
+			if speed < 1 {
+				speed = 1
+			}
+
+		}
+
+		if slowest == 0 || speed < slowest {
+			slowest = speed
+		}
+
+	}
+
+	return slowest
+}
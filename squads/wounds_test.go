@@ -0,0 +1,134 @@
+package squads
+
+import (
+	"game_main/common"
+	"testing"
+)
+
+// Two veteran squads: hard enough hitters that two blows cross the wound
+// threshold, fast enough that the speed penalty is visible
+func woundsFixture() (*common.EntityManager, *SquadData, *SquadData) {
+
+	em := newTestManager()
+	registerTestTemplates()
+
+	Units["TestVeteran"] = UnitTemplate{
+		Name:      "TestVeteran",
+		Class:     "Knight",
+		Attr:      common.NewBaseAttributes(20, 0, 10, 0, 3, 0),
+		MinDamage: 8,
+		MaxDamage: 8,
+	}
+
+	att := CreateSquad(em, "Attackers", "Player", common.Position{X: 5, Y: 3}, DirDown)
+	CreateUnitFromTemplate(em, Units["TestVeteran"], att.SquadID, 0, 0)
+
+	def := CreateSquad(em, "Defenders", "Enemy", common.Position{X: 5, Y: 5}, DirUp)
+	CreateUnitFromTemplate(em, Units["TestVeteran"], def.SquadID, 0, 0)
+	CreateUnitFromTemplate(em, Units["TestVeteran"], def.SquadID, 0, 1)
+
+	return em, att, def
+}
+
+// Crossing the threshold under fire wounds the unit: its next attack loses a
+// fifth of its damage and it drags the squads pace down. Healing back above
+// the threshold clears all of it
+func TestWoundPenaltiesApplyAndClear(t *testing.T) {
+
+	em, att, def := woundsFixture()
+
+	restore := Wounds
+	Wounds.Enabled = true
+	defer func() { Wounds = restore }()
+
+	attacker := UnitAtGridPos(em, att.SquadID, 0, 0)
+	victim := UnitAtGridPos(em, def.SquadID, 0, 0)
+
+	//First blow: 20 -> 12, still above the 30% threshold of 6
+	ResolveUnitAttack(em, attacker.GetID(), victim.GetID(), DirDown)
+	if IsWounded(victim) {
+		t.Fatal("a unit above the threshold should not be wounded")
+	}
+
+	//Second blow: 12 -> 4, across the threshold
+	ResolveUnitAttack(em, attacker.GetID(), victim.GetID(), DirDown)
+	if !IsWounded(victim) {
+		t.Fatal("dropping below 30%% health should wound the unit")
+	}
+
+	hurt := ResolveUnitAttack(em, victim.GetID(), attacker.GetID(), DirUp)
+	if hurt.Damage != 6 {
+		t.Errorf("a wounded veteran should swing for 6 instead of 8, got %d", hurt.Damage)
+	}
+
+	if speed := SquadMovementSpeed(em, def.SquadID); speed != 2 {
+		t.Errorf("the wounded unit should drag the squad from 3 to 2, got %d", speed)
+	}
+
+	//Healing above the threshold clears the state with no separate step
+	common.GetAttributes(victim).CurrentHealth = 15
+	RefreshWoundedState(victim)
+
+	if IsWounded(victim) {
+		t.Fatal("healing above the threshold should clear the wound")
+	}
+
+	healed := ResolveUnitAttack(em, victim.GetID(), attacker.GetID(), DirUp)
+	if healed.Damage != 8 {
+		t.Errorf("a healed veteran should swing for the full 8, got %d", healed.Damage)
+	}
+	if speed := SquadMovementSpeed(em, def.SquadID); speed != 3 {
+		t.Errorf("the squad should move at full pace again, got %d", speed)
+	}
+
+}
+
+// A wounded unit cannot hold the shield wall: its cover drops with it
+func TestWoundedUnitsProvideNoCover(t *testing.T) {
+
+	em, att, def := woundsFixture()
+
+	restore := Wounds
+	Wounds.Enabled = true
+	defer func() { Wounds = restore }()
+
+	attacker := UnitAtGridPos(em, att.SquadID, 0, 0)
+	shieldman := CreateUnitFromTemplate(em, Units["TestKnight"], def.SquadID, 0, 2)
+	protected := CreateUnitFromTemplate(em, Units["TestKnight"], def.SquadID, 1, 2)
+
+	before := ResolveUnitAttack(em, attacker.GetID(), protected.GetID(), DirDown)
+	if before.CoverReduction != 3 {
+		t.Fatalf("the healthy shieldman should cover for 3, got %d", before.CoverReduction)
+	}
+
+	common.GetAttributes(shieldman).CurrentHealth = 5
+	RefreshWoundedState(shieldman)
+
+	after := ResolveUnitAttack(em, attacker.GetID(), protected.GetID(), DirDown)
+	if after.CoverReduction != 0 {
+		t.Errorf("a wounded shieldman should cover nothing, got %d", after.CoverReduction)
+	}
+
+}
+
+// Off, low health changes nothing: the historical binary alive-or-dead rule
+func TestWoundsAreOffByDefault(t *testing.T) {
+
+	em, att, def := woundsFixture()
+
+	attacker := UnitAtGridPos(em, att.SquadID, 0, 0)
+	victim := UnitAtGridPos(em, def.SquadID, 0, 0)
+
+	common.GetAttributes(victim).CurrentHealth = 4
+	RefreshWoundedState(victim)
+
+	if IsWounded(victim) {
+		t.Fatal("wounds are opt-in and should be off by default")
+	}
+
+	event := ResolveUnitAttack(em, victim.GetID(), attacker.GetID(), DirUp)
+	if event.Damage != 8 {
+		t.Errorf("without wounds the unit should swing for the full 8, got %d", event.Damage)
+	}
+
+}
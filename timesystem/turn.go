@@ -17,6 +17,23 @@ type GameTurn struct {
 	TurnCounter      int
 	ActionDispatcher ActionManager
 	TotalNumTurns    int
+
+	//Set by InitializeCombat while squad combat is running, nil otherwise
+	Combat CombatOrder
+}
+
+// CombatOrder is the squad turn ordering for a battle. The implementation
+// lives in the squads package, which this package cannot import without a
+// cycle, so the turn manager only sees the interface
+type CombatOrder interface {
+	GetTurnOrder(factionID string) []int
+	AdvanceFaction() string
+}
+
+// InitializeCombat installs the turn order for the battle that is starting.
+// Pass nil when combat ends
+func (t *GameTurn) InitializeCombat(order CombatOrder) {
+	t.Combat = order
 }
 
 // A "Unit of Time" is 10 turns. After that reset all action points
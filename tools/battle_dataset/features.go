@@ -0,0 +1,124 @@
+package main
+
+import (
+	"game_main/squads"
+	"strconv"
+	"strings"
+
+	"github.com/bytearena/ecs"
+)
+
+// Feature extraction turns a BattleRecord into one flat CSV row for offline
+// balance analysis. Everything is computed from the squad snapshots on the
+// record, so old logs stay usable without the ECS world they came from
+
+// The classes that get their own count column. Anything else lands in other
+var roleClasses = []string{"Knight", "Rogue", "Archer"}
+
+func featureHeader() []string {
+
+	header := []string{}
+	for _, side := range []string{"attacker", "defender"} {
+
+		header = append(header, side+"_power", side+"_units", side+"_front", side+"_mid", side+"_back")
+		for _, class := range roleClasses {
+			header = append(header, side+"_"+strings.ToLower(class)+"s")
+		}
+		header = append(header, side+"_other", side+"_avoided")
+
+	}
+
+	header = append(header, "distance", "rounds", "attacker_won")
+	return header
+}
+
+// featureRow flattens one battle into the columns featureHeader describes.
+// The label is the last column
+func featureRow(record *squads.BattleRecord) []string {
+
+	row := []string{}
+	row = append(row, sideFeatures(record.Attacker, avoidedDamage(record, record.Attacker))...)
+	row = append(row, sideFeatures(record.Defender, avoidedDamage(record, record.Defender))...)
+
+	dx := record.Attacker.MapPos.X - record.Defender.MapPos.X
+	dy := record.Attacker.MapPos.Y - record.Defender.MapPos.Y
+	row = append(row, strconv.Itoa(abs(dx)+abs(dy)))
+
+	row = append(row, strconv.Itoa(record.Rounds))
+
+	won := "0"
+	if record.WinnerSquadID == record.AttackerSquadID {
+		won = "1"
+	}
+	row = append(row, won)
+
+	return row
+}
+
+// Damage this sides units dodged away over the battle, attributed through
+// the entity IDs the snapshot recorded
+func avoidedDamage(record *squads.BattleRecord, snapshot squads.SquadSnapshot) int {
+
+	sideUnits := make(map[ecs.EntityID]bool)
+	for _, u := range snapshot.Units {
+		sideUnits[u.EntityID] = true
+	}
+
+	avoided := 0
+	for _, e := range record.Events {
+		if e.Dodged && sideUnits[e.DefenderID] {
+			avoided += e.PotentialDamage
+		}
+	}
+
+	return avoided
+}
+
+func sideFeatures(snapshot squads.SquadSnapshot, avoided int) []string {
+
+	power := 0
+	front, mid, back := 0, 0, 0
+	classCounts := make(map[string]int)
+
+	for _, u := range snapshot.Units {
+
+		//A rough scalar for how strong a unit is: bulk plus weighted damage
+		power += u.MaxHealth + 2*u.Protection + 3*(u.MinDamage+u.MaxDamage)/2
+
+		switch u.Row {
+		case 0:
+			front++
+		case 1:
+			mid++
+		default:
+			back++
+		}
+
+		classCounts[u.Class]++
+
+	}
+
+	features := []string{
+		strconv.Itoa(power),
+		strconv.Itoa(len(snapshot.Units)),
+		strconv.Itoa(front),
+		strconv.Itoa(mid),
+		strconv.Itoa(back),
+	}
+
+	other := len(snapshot.Units)
+	for _, class := range roleClasses {
+		features = append(features, strconv.Itoa(classCounts[class]))
+		other -= classCounts[class]
+	}
+	features = append(features, strconv.Itoa(other), strconv.Itoa(avoided))
+
+	return features
+}
+
+func abs(v int) int {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"game_main/common"
+	"game_main/squads"
+	"strings"
+	"testing"
+)
+
+// A known record must flatten into exactly the row the header promises
+func TestFeatureRowFromKnownRecord(t *testing.T) {
+
+	record := &squads.BattleRecord{
+		AttackerSquadID: 1,
+		DefenderSquadID: 2,
+		WinnerSquadID:   1,
+		Rounds:          4,
+		Attacker: squads.SquadSnapshot{
+			SquadID: 1,
+			MapPos:  common.Position{X: 5, Y: 3},
+			Units: []squads.UnitSnapshot{
+				{Class: "Knight", Row: 0, MaxHealth: 20, Protection: 2, MinDamage: 4, MaxDamage: 4},
+				{Class: "Rogue", Row: 1, MaxHealth: 10, MinDamage: 5, MaxDamage: 5},
+			},
+		},
+		Defender: squads.SquadSnapshot{
+			SquadID: 2,
+			MapPos:  common.Position{X: 5, Y: 6},
+			Units: []squads.UnitSnapshot{
+				{Class: "Shaman", Row: 2, MaxHealth: 8, MinDamage: 2, MaxDamage: 6},
+			},
+		},
+	}
+
+	header := featureHeader()
+	row := featureRow(record)
+
+	if len(row) != len(header) {
+		t.Fatalf("row has %d columns but the header has %d", len(row), len(header))
+	}
+
+	expected := []string{
+		//Attacker: knight power 20+4+12=36, rogue power 10+0+15=25, nothing dodged
+		"61", "2", "1", "1", "0", "1", "1", "0", "0", "0",
+		//Defender: shaman power 8+0+12=20, one back row unit of an unlisted class
+		"20", "1", "0", "0", "1", "0", "0", "0", "1", "0",
+		//Manhattan distance, rounds, attacker won
+		"3", "4", "1",
+	}
+
+	for i, want := range expected {
+		if row[i] != want {
+			t.Errorf("column %s: expected %s, got %s (full row %s)", header[i], want, row[i], strings.Join(row, ","))
+		}
+	}
+}
+
+// The label flips when the defender wins
+func TestVictorLabel(t *testing.T) {
+
+	record := &squads.BattleRecord{AttackerSquadID: 1, DefenderSquadID: 2, WinnerSquadID: 2}
+
+	row := featureRow(record)
+	if row[len(row)-1] != "0" {
+		t.Errorf("expected label 0 for a defender win, got %s", row[len(row)-1])
+	}
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"encoding/csv"
+	"flag"
+	"fmt"
+	"game_main/squads"
+	"game_main/tools"
+	"os"
+)
+
+// battle_dataset walks a directory of battle record JSON files and emits one
+// CSV row per battle, features first and the victor label last, for offline
+// ML balance analysis. Corrupted files are skipped and summarized instead of
+// failing the run, since a killed generator leaves truncated JSON behind
+
+func main() {
+
+	logDir := flag.String("logs", squads.DefaultLogDir, "directory containing battle record JSON files")
+	dataRoot := flag.String("data-root", "", "resolve relative paths against this directory instead of the repository root")
+	strict := flag.Bool("strict", false, "exit non-zero when too much of the dataset had to be skipped")
+	strictThreshold := flag.Float64("strict-threshold", 0.1, "skipped fraction (0 to 1) that fails a -strict run")
+	quarantine := flag.String("quarantine", "", "move unreadable or invalid files into this directory")
+	flag.Parse()
+
+	resolved := tools.ResolveDataPath(*dataRoot, *logDir)
+	if err := tools.ValidateInputDir(resolved); err != nil {
+		fmt.Fprintln(os.Stderr, "battle_dataset:", err)
+		os.Exit(1)
+	}
+
+	records, report, err := squads.LoadBattleRecords(resolved, *quarantine)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "battle_dataset:", err)
+		os.Exit(1)
+	}
+
+	writer := csv.NewWriter(os.Stdout)
+	writer.Write(featureHeader())
+
+	for _, record := range records {
+		writer.Write(featureRow(record))
+	}
+
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		fmt.Fprintln(os.Stderr, "battle_dataset:", err)
+		os.Exit(1)
+	}
+
+	fmt.Fprintln(os.Stderr, report.Summary())
+
+	if *strict && report.ExceedsThreshold(*strictThreshold) {
+		fmt.Fprintf(os.Stderr, "battle_dataset: strict mode: skipped fraction %.2f exceeds threshold %.2f\n",
+			report.SkippedFraction(), *strictThreshold)
+		os.Exit(1)
+	}
+}
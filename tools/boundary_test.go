@@ -0,0 +1,28 @@
+package tools
+
+import (
+	"os/exec"
+	"strings"
+	"testing"
+)
+
+// The tools are external consumers of the game: they get the public packages
+// (squads, simulation, balance, common, ...) and nothing under internal/.
+// This walks the real dependency graph with go list, so a stray import in
+// any tool fails the suite instead of surviving until a refactor breaks it
+func TestToolsStayOutOfInternal(t *testing.T) {
+
+	cmd := exec.Command("go", "list", "-deps", "./tools/...")
+	cmd.Dir = ".."
+
+	out, err := cmd.Output()
+	if err != nil {
+		t.Fatalf("go list failed: %v", err)
+	}
+
+	for _, dep := range strings.Fields(string(out)) {
+		if strings.Contains(dep, "game_main/internal/") {
+			t.Errorf("tools depend on %s, which is internal to the game", dep)
+		}
+	}
+}
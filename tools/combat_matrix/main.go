@@ -0,0 +1,102 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"game_main/randgen"
+	"game_main/simulation"
+	"game_main/tools"
+)
+
+// combat_matrix fights every unit template of a roster against every other
+// in 1v1 squad battles and emits the N by N attacker win-rate matrix as CSV.
+// The roster comes from a scenario file, which already carries standalone
+// template definitions for the rest of the balance tooling
+
+func main() {
+
+	scenarioPath := flag.String("scenarios", "", "scenario JSON file whose templates form the roster")
+	iterations := flag.Int("iterations", 100, "battles per matchup cell")
+	seed := flag.Int64("seed", 0, "seed the battles for a reproducible matrix")
+	out := flag.String("out", "", "path to write the CSV to (default stdout)")
+	heatmap := flag.String("heatmap", "", "also render the matrix as a PNG heatmap at this path")
+	minSamples := flag.Int("min-samples", 0, "grey out heatmap cells with fewer battles than this")
+	flag.Parse()
+
+	if *scenarioPath == "" {
+		fail(fmt.Errorf("-scenarios is required"))
+	}
+
+	file, err := simulation.LoadScenarioFile(*scenarioPath)
+	if err != nil {
+		fail(err)
+	}
+	if len(file.Templates) == 0 {
+		fail(fmt.Errorf("scenario file %s carries no templates to build a roster from", *scenarioPath))
+	}
+
+	simulation.RegisterScenarioTemplates(file.Templates)
+
+	roster := make([]string, 0, len(file.Templates))
+	for _, t := range file.Templates {
+		roster = append(roster, t.Name)
+	}
+
+	if *seed != 0 {
+		randgen.Seed(*seed)
+		defer randgen.ClearSeed()
+	}
+
+	sim := simulation.NewSimulator()
+	matrix, err := sim.BuildWinRateMatrix(roster, *iterations)
+	if err != nil {
+		fail(err)
+	}
+
+	output := os.Stdout
+	if *out != "" {
+
+		if err := tools.EnsureOutputDir(filepath.Dir(*out)); err != nil {
+			fail(err)
+		}
+
+		f, err := os.Create(*out)
+		if err != nil {
+			fail(err)
+		}
+		defer f.Close()
+		output = f
+
+	}
+
+	report := matrix.Report()
+	if err := report.WriteTo(simulation.NewCSVReportSink(output)); err != nil {
+		fail(err)
+	}
+
+	if *heatmap != "" {
+
+		if err := tools.EnsureOutputDir(filepath.Dir(*heatmap)); err != nil {
+			fail(err)
+		}
+
+		images := simulation.RenderWinRateHeatmap(matrix, simulation.HeatmapOptions{MinSamples: *minSamples})
+		paths, err := simulation.WriteHeatmapPNGs(*heatmap, images)
+		if err != nil {
+			fail(err)
+		}
+
+		for _, p := range paths {
+			fmt.Fprintln(os.Stderr, "combat_matrix: wrote", p)
+		}
+
+	}
+}
+
+func fail(err error) {
+	fmt.Fprintln(os.Stderr, "combat_matrix:", err)
+	os.Exit(1)
+}
@@ -0,0 +1,215 @@
+package main
+
+import (
+	"container/heap"
+	"flag"
+	"fmt"
+	"game_main/squads"
+	"game_main/tools"
+	"os"
+	"path/filepath"
+)
+
+// combat_visualizer renders battle logs from a log directory as text. The
+// -all pass is a streaming pipeline: every file gets the cheap partial
+// header decode first, filters run against that, and only matching battles
+// are fully loaded and rendered. Sorting keeps a bounded heap instead of
+// loading the whole directory, so piping 20k logs through -summary-only
+// starts printing immediately
+
+func main() {
+
+	logDir := flag.String("logs", squads.DefaultLogDir, "directory containing battle record JSON files")
+	dataRoot := flag.String("data-root", "", "resolve relative paths against this directory instead of the repository root")
+	all := flag.Bool("all", false, "walk every battle log in the directory")
+	filterExpr := flag.String("filter", "", "filter expression, e.g. 'victor=Raiders, rounds>=3, outcome=wipe'")
+	limit := flag.Int("limit", 0, "stop after this many battles (0 means no limit)")
+	sortBy := flag.String("sort-by", "", "order the output by 'rounds' or 'damage', highest first")
+	summaryOnly := flag.Bool("summary-only", false, "print one line per battle instead of the full rendering")
+	flag.Parse()
+
+	if !*all {
+		fmt.Fprintln(os.Stderr, "combat_visualizer: -all is required")
+		os.Exit(1)
+	}
+	if *sortBy != "" && *sortBy != "rounds" && *sortBy != "damage" {
+		fmt.Fprintf(os.Stderr, "combat_visualizer: unknown -sort-by %q, expected rounds or damage\n", *sortBy)
+		os.Exit(1)
+	}
+
+	filter, err := squads.ParseBattleFilter(*filterExpr)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "combat_visualizer:", err)
+		os.Exit(1)
+	}
+
+	resolved := tools.ResolveDataPath(*dataRoot, *logDir)
+	if err := tools.ValidateInputDir(resolved); err != nil {
+		fmt.Fprintln(os.Stderr, "combat_visualizer:", err)
+		os.Exit(1)
+	}
+
+	paths, err := squads.FindAllBattles(resolved)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "combat_visualizer:", err)
+		os.Exit(1)
+	}
+
+	skipped := 0
+	shown := 0
+	top := &headerHeap{key: *sortBy}
+
+	for _, path := range paths {
+
+		if *sortBy == "" && *limit > 0 && shown >= *limit {
+			break
+		}
+
+		header, err := squads.ReadBattleHeader(path)
+		if err != nil {
+			skipped++
+			continue
+		}
+
+		if !filter.Matches(&header) {
+			continue
+		}
+
+		//Unsorted output streams as the scan goes. Sorted output collects
+		//into a heap bounded at -limit and prints at the end
+		if *sortBy == "" {
+			printBattle(header, *summaryOnly)
+			shown++
+			continue
+		}
+
+		heap.Push(top, header)
+		if *limit > 0 && top.Len() > *limit {
+			heap.Pop(top)
+		}
+
+	}
+
+	for _, header := range top.Sorted() {
+		printBattle(header, *summaryOnly)
+	}
+
+	if skipped > 0 {
+		fmt.Fprintf(os.Stderr, "combat_visualizer: skipped %d unreadable files\n", skipped)
+	}
+}
+
+func printBattle(header squads.BattleHeader, summaryOnly bool) {
+
+	victor := header.VictorName()
+	if victor == "" {
+		victor = "nobody"
+	}
+
+	fmt.Printf("%s: %s vs %s, %s wins in %d rounds, %d damage (%s)\n",
+		filepath.Base(header.Path), header.AttackerName, header.DefenderName,
+		victor, header.Rounds, header.TotalDamage, header.Outcome())
+
+	if summaryOnly {
+		return
+	}
+
+	record, err := squads.LoadBattleRecord(header.Path)
+	if err != nil {
+		fmt.Printf("  (full record unavailable: %v)\n", err)
+		return
+	}
+
+	for _, squadID := range []int{record.AttackerSquadID, record.DefenderSquadID} {
+
+		name := record.Attacker.Name
+		units := len(record.Attacker.Units)
+		if squadID == record.DefenderSquadID {
+			name = record.Defender.Name
+			units = len(record.Defender.Units)
+		}
+
+		snapshot := record.Attacker
+		if squadID == record.DefenderSquadID {
+			snapshot = record.Defender
+		}
+		wounded := 0
+		for _, u := range snapshot.Units {
+			if u.Wounded {
+				wounded++
+			}
+		}
+
+		if wounded > 0 {
+			fmt.Printf("  %s: %d units (%d wounded ~), lost %d\n", name, units, wounded, len(record.UnitsLost[squadID]))
+		} else {
+			fmt.Printf("  %s: %d units, lost %d\n", name, units, len(record.UnitsLost[squadID]))
+		}
+
+	}
+
+	//Cumulative net damage per round: positive numbers mean the attacker
+	//was ahead at that point
+	if curve := record.MomentumCurve(); len(curve) > 0 {
+
+		fmt.Printf("  momentum:")
+		for _, point := range curve {
+			fmt.Printf(" %+d", point.Momentum)
+		}
+		fmt.Println()
+
+	}
+
+	fmt.Printf("  %d attacks, %d dodged\n", len(record.Events), countDodges(record))
+	fmt.Println()
+}
+
+func countDodges(record *squads.BattleRecord) int {
+
+	dodges := 0
+	for _, e := range record.Events {
+		if e.Dodged {
+			dodges++
+		}
+	}
+
+	return dodges
+}
+
+// A min-heap on the sort key so the weakest kept battle is always on top,
+// ready to be evicted when a better one arrives
+type headerHeap struct {
+	key     string
+	headers []squads.BattleHeader
+}
+
+func (h *headerHeap) keyOf(header squads.BattleHeader) int {
+
+	if h.key == "damage" {
+		return header.TotalDamage
+	}
+
+	return header.Rounds
+}
+
+func (h *headerHeap) Len() int           { return len(h.headers) }
+func (h *headerHeap) Less(i, j int) bool { return h.keyOf(h.headers[i]) < h.keyOf(h.headers[j]) }
+func (h *headerHeap) Swap(i, j int)      { h.headers[i], h.headers[j] = h.headers[j], h.headers[i] }
+func (h *headerHeap) Push(x interface{}) { h.headers = append(h.headers, x.(squads.BattleHeader)) }
+func (h *headerHeap) Pop() interface{} {
+
+	last := h.headers[len(h.headers)-1]
+	h.headers = h.headers[:len(h.headers)-1]
+	return last
+}
+
+// Sorted drains the heap into highest-first order
+func (h *headerHeap) Sorted() []squads.BattleHeader {
+
+	sorted := make([]squads.BattleHeader, h.Len())
+	for i := h.Len() - 1; i >= 0; i-- {
+		sorted[i] = heap.Pop(h).(squads.BattleHeader)
+	}
+
+	return sorted
+}
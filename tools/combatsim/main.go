@@ -0,0 +1,298 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"game_main/simulation"
+	"game_main/squads"
+	"game_main/tools"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// combatsim runs squad battle scenarios headlessly from a scenario JSON file.
+// By default it prints the human-readable balance report. With -json-output it
+// emits machine readable JSON to stdout for external orchestration instead,
+// and with -stream it emits one NDJSON line per scenario as results come in
+
+func main() {
+
+	scenarioPath := flag.String("scenarios", "", "path to a scenario JSON file")
+	iterations := flag.Int("iterations", 0, "override the iteration count of every scenario")
+	seed := flag.Int64("seed", 0, "seed recorded in the output for run correlation")
+	jsonOutput := flag.Bool("json-output", false, "suppress text output and emit a JSON document to stdout")
+	stream := flag.Bool("stream", false, "with -json-output, emit one NDJSON line per scenario")
+	checksum := flag.Bool("checksum", false, "fight each scenario once under -seed and print its combat checksum")
+	importLog := flag.String("import-log", "", "convert a recorded battle log into a scenario file instead of running scenarios")
+	out := flag.String("out", "", "with -import-log, the path to write the scenario file to (default stdout)")
+	durability := flag.Bool("durability", false, "enable armor durability wear during the battles")
+	lethality := flag.Float64("lethality", 1.0, "scale all final damage by this factor to speed battles up or down")
+	tags := flag.String("tags", "", "comma-separated tags; only run scenarios carrying at least one of them")
+	excludeTags := flag.String("exclude-tags", "", "comma-separated tags; skip scenarios carrying any of them")
+	list := flag.Bool("list", false, "list the scenarios that would run, with their tags, without fighting them")
+	exportJSON := flag.String("export-json", "", "write the run report as JSON to this path")
+	exportCSV := flag.String("export-csv", "", "write the run report as CSV to this path")
+	exportHTML := flag.String("export-html", "", "write the run report as an HTML page to this path")
+	strict := flag.Bool("strict-scenarios", false, "fail on pre-flight scenario warnings instead of running anyway")
+	tune := flag.Bool("tune", false, "search one attribute for the value that hits -target-winrate")
+	tuneScenarioName := flag.String("scenario", "", "with -tune, the name of the scenario to tune")
+	targetWinRate := flag.Float64("target-winrate", 0.5, "with -tune, the attacker win rate to aim for")
+	tuneAttr := flag.String("tune-attr", "", "with -tune, the attribute to search, e.g. Damage or Protection")
+	tuneTarget := flag.String("tune-target", "Defender/*", "with -tune, which templates the knob turns, as Side/Template")
+	tuneMin := flag.Int("tune-min", 0, "with -tune, the low end of the value range")
+	tuneMax := flag.Int("tune-max", 0, "with -tune, the high end of the value range")
+	tuneStep := flag.Int("tune-step", 0, "with -tune, the coarse sweep stride (0 picks one)")
+	aiA := flag.String("ai-a", "", "drive the attacker with this registered AI controller (implies an AI duel)")
+	aiB := flag.String("ai-b", "", "drive the defender with this registered AI controller (implies an AI duel)")
+	flag.Parse()
+
+	squads.Durability.Enabled = *durability
+	squads.Lethality.Multiplier = *lethality
+
+	if *importLog != "" {
+		importBattleLog(*importLog, *out, *jsonOutput)
+		return
+	}
+
+	if *scenarioPath == "" {
+		fail(*jsonOutput, fmt.Errorf("-scenarios is required"), "")
+	}
+
+	file, err := simulation.LoadScenarioFile(*scenarioPath)
+	if err != nil {
+		fail(*jsonOutput, err, "")
+	}
+
+	if *iterations > 0 {
+		for i := range file.Scenarios {
+			file.Scenarios[i].Iterations = *iterations
+		}
+	}
+
+	filtered, warnings := simulation.FilterScenarios(file.Scenarios, simulation.ParseTagList(*tags), simulation.ParseTagList(*excludeTags))
+	for _, warning := range warnings {
+		fmt.Fprintln(os.Stderr, "combatsim:", warning)
+	}
+	file.Scenarios = filtered
+
+	if *list {
+		listScenarios(file.Scenarios)
+		return
+	}
+
+	if *tune {
+		runTuning(file, *tuneScenarioName, *targetWinRate, *tuneAttr, *tuneTarget, *tuneMin, *tuneMax, *tuneStep, *iterations)
+		return
+	}
+
+	if *checksum {
+
+		simulation.RegisterScenarioTemplates(file.Templates)
+		sim := simulation.NewSimulator()
+
+		for _, scenario := range file.Scenarios {
+
+			sum, err := sim.ChecksumScenario(scenario, *seed)
+			if err != nil {
+				fail(*jsonOutput, err, scenario.Name)
+			}
+			fmt.Printf("%s %s\n", sum, scenario.Name)
+
+		}
+
+		return
+	}
+
+	if *jsonOutput {
+		if err := simulation.RunScenariosJSON(os.Stdout, os.Stderr, file, *seed, *stream); err != nil {
+			os.Exit(1)
+		}
+		return
+	}
+
+	simulation.RegisterScenarioTemplates(file.Templates)
+	sim := simulation.NewSimulator()
+	sim.StrictScenarios = *strict
+
+	//Either AI flag switches every scenario to an AI-versus-AI duel, with the
+	//unset side falling back to the default profile
+	duel := *aiA != "" || *aiB != ""
+	controllerA, controllerB := *aiA, *aiB
+	if controllerA == "" {
+		controllerA = "default"
+	}
+	if controllerB == "" {
+		controllerB = "default"
+	}
+
+	results := make([]simulation.SimulationResult, 0, len(file.Scenarios))
+	for _, scenario := range file.Scenarios {
+
+		var result simulation.SimulationResult
+		var err error
+		if duel {
+			result, err = sim.RunAIDuel(scenario, controllerA, controllerB)
+		} else {
+			result, err = sim.RunScenario(scenario)
+		}
+		if err != nil {
+			fail(false, err, scenario.Name)
+		}
+
+		results = append(results, result)
+
+	}
+
+	simulation.FormatBalanceReport(os.Stdout, results)
+
+	if err := exportReport(*scenarioPath, *seed, results, *exportJSON, *exportCSV, *exportHTML); err != nil {
+		fail(false, err, "")
+	}
+}
+
+// All requested export formats consume one traversal of the same report
+func exportReport(title string, seed int64, results []simulation.SimulationResult, jsonPath string, csvPath string, htmlPath string) error {
+
+	sinks := []simulation.ReportSink{}
+	files := []*os.File{}
+
+	for _, target := range []struct {
+		path string
+		sink func(f *os.File) simulation.ReportSink
+	}{
+		{jsonPath, func(f *os.File) simulation.ReportSink { return simulation.NewJSONReportSink(f) }},
+		{csvPath, func(f *os.File) simulation.ReportSink { return simulation.NewCSVReportSink(f) }},
+		{htmlPath, func(f *os.File) simulation.ReportSink { return simulation.NewHTMLReportSink(f) }},
+	} {
+
+		if target.path == "" {
+			continue
+		}
+
+		if err := tools.EnsureOutputDir(filepath.Dir(target.path)); err != nil {
+			return err
+		}
+
+		f, err := os.Create(target.path)
+		if err != nil {
+			return err
+		}
+
+		files = append(files, f)
+		sinks = append(sinks, target.sink(f))
+
+	}
+
+	if len(sinks) == 0 {
+		return nil
+	}
+
+	report := simulation.BuildSimulationReport(title, seed, results)
+	err := report.WriteTo(sinks...)
+
+	for _, f := range files {
+		if closeErr := f.Close(); err == nil {
+			err = closeErr
+		}
+	}
+
+	return err
+}
+
+// Run the tuning search and print the probe history with the answer
+func runTuning(file simulation.ScenarioFile, scenarioName string, targetWinRate float64, attr string, target string, min int, max int, step int, iterations int) {
+
+	var scenario *simulation.Scenario
+	for i := range file.Scenarios {
+		if file.Scenarios[i].Name == scenarioName {
+			scenario = &file.Scenarios[i]
+		}
+	}
+	if scenario == nil {
+		fail(false, fmt.Errorf("-tune needs -scenario naming one scenario in the file"), scenarioName)
+	}
+
+	sim := simulation.NewSimulator()
+	result, err := sim.Tune(simulation.TuneRequest{
+		Scenario:        *scenario,
+		Templates:       file.Templates,
+		TargetWinRate:   targetWinRate,
+		Attribute:       attr,
+		Target:          target,
+		Min:             min,
+		Max:             max,
+		Step:            step,
+		ProbeIterations: iterations,
+	})
+	if err != nil {
+		fail(false, err, scenarioName)
+	}
+
+	fmt.Printf("tuning %s on %s towards %.2f attacker win rate\n", result.Attribute, scenarioName, targetWinRate)
+	for _, probe := range result.Probes {
+		fmt.Printf("  probe %s=%d: win rate %.3f over %d iterations\n", result.Attribute, probe.Value, probe.WinRate, probe.Iterations)
+	}
+	fmt.Printf("best %s=%d with win rate %.3f, residual %+.3f\n", result.Attribute, result.Value, result.WinRate, result.Residual)
+}
+
+// One line per scenario that survived the tag filter
+func listScenarios(scenarios []simulation.Scenario) {
+
+	for _, scenario := range scenarios {
+
+		if len(scenario.Tags) == 0 {
+			fmt.Println(scenario.Name)
+			continue
+		}
+
+		fmt.Printf("%s [%s]\n", scenario.Name, strings.Join(scenario.Tags, ", "))
+
+	}
+
+}
+
+// Promote a recorded battle into a rerunnable scenario file
+func importBattleLog(logPath string, outPath string, jsonOutput bool) {
+
+	record, err := simulation.LoadBattleRecord(logPath)
+	if err != nil {
+		fail(jsonOutput, err, "")
+	}
+
+	file, err := simulation.ScenarioFileFromBattleRecord(record)
+	if err != nil {
+		fail(jsonOutput, err, "")
+	}
+
+	contents, err := json.MarshalIndent(file, "", "  ")
+	if err != nil {
+		fail(jsonOutput, err, "")
+	}
+	contents = append(contents, '\n')
+
+	if outPath == "" {
+		os.Stdout.Write(contents)
+		return
+	}
+
+	if err := tools.EnsureOutputDir(filepath.Dir(outPath)); err != nil {
+		fail(jsonOutput, err, "")
+	}
+	if err := os.WriteFile(outPath, contents, 0644); err != nil {
+		fail(jsonOutput, err, "")
+	}
+}
+
+// Errors stay parseable in JSON mode, stdout is never touched
+func fail(jsonOutput bool, err error, scenario string) {
+
+	if jsonOutput {
+		fmt.Fprintf(os.Stderr, "{\"error\": %q, \"scenario\": %q}\n", err.Error(), scenario)
+	} else {
+		fmt.Fprintln(os.Stderr, "combatsim:", err)
+	}
+
+	os.Exit(1)
+}
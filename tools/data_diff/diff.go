@@ -0,0 +1,240 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"game_main/entitytemplates"
+	"game_main/squads"
+)
+
+// loadMonsterPack reads a data roots monsterdata.json through the same
+// pipeline the game uses: inheritance resolved first, then the validation the
+// loader applies. The two sides of a diff therefore compare what the game
+// would actually field, not what the raw JSON happens to say
+func loadMonsterPack(root string) (map[string]entitytemplates.JSONMonster, error) {
+
+	path := filepath.Join(root, "monsterdata.json")
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var raw struct {
+		Monsters []json.RawMessage `json:"monsters"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	resolved, err := entitytemplates.ResolveTemplateInheritance(raw.Monsters)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	pack := make(map[string]entitytemplates.JSONMonster)
+	for _, entry := range resolved {
+
+		var monster entitytemplates.JSONMonster
+		if err := json.Unmarshal(entry, &monster); err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+
+		if monster.MaxTargetsPerAttack < 0 {
+			return nil, fmt.Errorf("%s: maxTargetsPerAttack must not be negative for %s", path, monster.Name)
+		}
+		if err := entitytemplates.ValidateFootprint(monster.Footprint, squads.GridWidth, squads.GridHeight); err != nil {
+			return nil, fmt.Errorf("%s: footprint of %s: %w", path, monster.Name, err)
+		}
+
+		pack[monster.Name] = entitytemplates.NewJSONMonster(monster)
+
+	}
+
+	return pack, nil
+}
+
+// The derived stats a field change cascades into, computed with the same
+// formulas combat and the budget use. Hit rate is the chance for
+// 1d20+AttackBonus to meet a reference armor class, so attack bonus changes
+// read as percentages
+type derivedStats struct {
+	HP         int
+	Damage     float64
+	Resistance int
+	HitRate    float64
+	Cost       float64
+}
+
+const referenceArmorClass = 12
+
+func derive(m entitytemplates.JSONMonster) derivedStats {
+
+	stats := derivedStats{HP: m.Attributes.MaxHealth}
+
+	stats.Resistance = m.Attributes.BaseProtection
+	if m.Armor != nil {
+		stats.Resistance += m.Armor.Protection
+	}
+
+	if m.MeleeWeapon != nil {
+		stats.Damage = float64(m.MeleeWeapon.MinDamage+m.MeleeWeapon.MaxDamage) / 2
+	} else if m.RangedWeapon != nil {
+		stats.Damage = float64(m.RangedWeapon.MinDamage+m.RangedWeapon.MaxDamage) / 2
+	}
+
+	//A d20 roll of at least AC minus the bonus hits
+	hitting := 21 - referenceArmorClass + m.Attributes.AttackBonus
+	if hitting < 0 {
+		hitting = 0
+	}
+	if hitting > 20 {
+		hitting = 20
+	}
+	stats.HitRate = float64(hitting) / 20
+
+	//Price the template the way the squad budget would field it
+	squads.CreateUnitTemplates([]entitytemplates.JSONMonster{m})
+	if template, ok := squads.GetUnitTemplate(m.Name); ok {
+		stats.Cost = squads.UnitCost(template)
+	}
+
+	return stats
+}
+
+// deltas lists the derived stats the change moved, already formatted
+func (s derivedStats) deltas(to derivedStats) []string {
+
+	out := []string{}
+
+	if s.HP != to.HP {
+		out = append(out, fmt.Sprintf("HP %d -> %d", s.HP, to.HP))
+	}
+	if s.Damage != to.Damage {
+		out = append(out, fmt.Sprintf("damage %g -> %g", s.Damage, to.Damage))
+	}
+	if s.Resistance != to.Resistance {
+		out = append(out, fmt.Sprintf("resistance %d -> %d", s.Resistance, to.Resistance))
+	}
+	if s.HitRate != to.HitRate {
+		out = append(out, fmt.Sprintf("hit rate %.0f%% -> %.0f%%", s.HitRate*100, to.HitRate*100))
+	}
+	if s.Cost != to.Cost {
+		out = append(out, fmt.Sprintf("cost %.1f -> %.1f", s.Cost, to.Cost))
+	}
+
+	return out
+}
+
+type templateChange struct {
+	Name    string
+	Fields  []entitytemplates.AttributeDiff
+	Derived []string
+}
+
+type packDiff struct {
+	Added   []string
+	Removed []string
+	Changed []templateChange
+}
+
+func diffPacks(oldPack map[string]entitytemplates.JSONMonster, newPack map[string]entitytemplates.JSONMonster) packDiff {
+
+	diff := packDiff{}
+
+	for name, after := range newPack {
+
+		before, ok := oldPack[name]
+		if !ok {
+			diff.Added = append(diff.Added, name)
+			continue
+		}
+
+		fields := entitytemplates.DiffTemplates(before, after)
+		if len(fields) == 0 {
+			continue
+		}
+
+		diff.Changed = append(diff.Changed, templateChange{
+			Name:    name,
+			Fields:  fields,
+			Derived: derive(before).deltas(derive(after)),
+		})
+
+	}
+
+	for name := range oldPack {
+		if _, ok := newPack[name]; !ok {
+			diff.Removed = append(diff.Removed, name)
+		}
+	}
+
+	sort.Strings(diff.Added)
+	sort.Strings(diff.Removed)
+	sort.Slice(diff.Changed, func(i, j int) bool { return diff.Changed[i].Name < diff.Changed[j].Name })
+
+	return diff
+}
+
+// Text is the terminal report
+func (d packDiff) Text() string {
+
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "data diff: %d added, %d removed, %d changed\n", len(d.Added), len(d.Removed), len(d.Changed))
+
+	for _, name := range d.Added {
+		fmt.Fprintf(&b, "  + %s\n", name)
+	}
+	for _, name := range d.Removed {
+		fmt.Fprintf(&b, "  - %s\n", name)
+	}
+
+	for _, change := range d.Changed {
+
+		fmt.Fprintf(&b, "\n%s:\n", change.Name)
+		for _, field := range change.Fields {
+			fmt.Fprintf(&b, "  %s\n", field)
+		}
+		if len(change.Derived) > 0 {
+			fmt.Fprintf(&b, "  derived: %s\n", strings.Join(change.Derived, ", "))
+		}
+
+	}
+
+	return b.String()
+}
+
+// Markdown is the same report shaped for a PR description
+func (d packDiff) Markdown() string {
+
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "## Data diff\n\n%d added, %d removed, %d changed\n", len(d.Added), len(d.Removed), len(d.Changed))
+
+	if len(d.Added) > 0 {
+		fmt.Fprintf(&b, "\n**Added:** %s\n", strings.Join(d.Added, ", "))
+	}
+	if len(d.Removed) > 0 {
+		fmt.Fprintf(&b, "\n**Removed:** %s\n", strings.Join(d.Removed, ", "))
+	}
+
+	for _, change := range d.Changed {
+
+		fmt.Fprintf(&b, "\n### %s\n\n", change.Name)
+		fmt.Fprint(&b, "| field | old | new | delta |\n|---|---|---|---|\n")
+		for _, field := range change.Fields {
+			fmt.Fprintf(&b, "| %s | %g | %g | %+g |\n", field.Field, field.A, field.B, field.Delta)
+		}
+		if len(change.Derived) > 0 {
+			fmt.Fprintf(&b, "\nDerived: %s\n", strings.Join(change.Derived, ", "))
+		}
+
+	}
+
+	return b.String()
+}
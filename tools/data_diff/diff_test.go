@@ -0,0 +1,92 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"game_main/squads"
+)
+
+// The fixture packs carry known edits: the goblin got an attack and damage
+// buff the elder inherits through extends, the slime is gone and an archer
+// is new. The reports golden-compare so the exact wording reviewers paste
+// into PRs stays stable
+//
+// Regenerate after intentional format changes with:
+//
+//	UPDATE_GOLDEN=1 go test ./tools/data_diff
+func fixtureDiff(t *testing.T) packDiff {
+
+	t.Helper()
+
+	for _, name := range []string{"FixtureGoblin", "FixtureGoblinElder", "FixtureSlime", "FixtureOrcArcher"} {
+		defer delete(squads.Units, name)
+	}
+
+	oldPack, err := loadMonsterPack(filepath.Join("testdata", "old"))
+	if err != nil {
+		t.Fatalf("loading the old pack failed: %v", err)
+	}
+	newPack, err := loadMonsterPack(filepath.Join("testdata", "new"))
+	if err != nil {
+		t.Fatalf("loading the new pack failed: %v", err)
+	}
+
+	return diffPacks(oldPack, newPack)
+}
+
+func TestDiffSeesThroughInheritance(t *testing.T) {
+
+	diff := fixtureDiff(t)
+
+	if len(diff.Added) != 1 || diff.Added[0] != "FixtureOrcArcher" {
+		t.Errorf("the archer should read as added, got %v", diff.Added)
+	}
+	if len(diff.Removed) != 1 || diff.Removed[0] != "FixtureSlime" {
+		t.Errorf("the slime should read as removed, got %v", diff.Removed)
+	}
+
+	//The elder never changed in the raw JSON - only its parent did - so a
+	//diff that resolves inheritance must still flag it
+	if len(diff.Changed) != 2 || diff.Changed[0].Name != "FixtureGoblin" || diff.Changed[1].Name != "FixtureGoblinElder" {
+		names := []string{}
+		for _, c := range diff.Changed {
+			names = append(names, c.Name)
+		}
+		t.Fatalf("both goblins should read as changed, got %v", names)
+	}
+
+}
+
+func TestDiffReportsMatchGolden(t *testing.T) {
+
+	diff := fixtureDiff(t)
+
+	goldenCompare(t, diff.Text(), filepath.Join("testdata", "report.golden.txt"))
+	goldenCompare(t, diff.Markdown(), filepath.Join("testdata", "report.golden.md"))
+}
+
+func goldenCompare(t *testing.T, got string, path string) {
+
+	t.Helper()
+
+	if os.Getenv("UPDATE_GOLDEN") != "" {
+
+		if err := os.WriteFile(path, []byte(got), 0644); err != nil {
+			t.Fatalf("updating %s failed: %v", path, err)
+		}
+		return
+
+	}
+
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("no golden for %s, run with UPDATE_GOLDEN=1 to create it: %v", path, err)
+	}
+
+	if got != string(want) {
+		t.Errorf("%s drifted from its golden:\n--- got ---\n%s\n--- want ---\n%s", path, got, want)
+	}
+
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"game_main/tools"
+)
+
+// data_diff compares two versions of the monster data through the template
+// pipeline - inheritance resolved, validation applied - and summarizes the
+// gameplay-relevant changes. Raw JSON diffs bury the signal under reordered
+// keys and whitespace and never compute derived effects; this reports added
+// and removed templates, changed fields with old and new values, and the
+// derived-stat deltas (HP, damage, resistance, hit rate, budget cost) a
+// reviewer actually cares about
+
+func main() {
+
+	oldRoot := flag.String("old", "", "data root holding the old monsterdata.json")
+	newRoot := flag.String("new", "", "data root holding the new monsterdata.json")
+	format := flag.String("format", "text", "output format: text or markdown")
+	out := flag.String("out", "", "path to write the report to (default stdout)")
+	flag.Parse()
+
+	if *oldRoot == "" || *newRoot == "" {
+		fail(fmt.Errorf("-old and -new data roots are both required"))
+	}
+	if *format != "text" && *format != "markdown" {
+		fail(fmt.Errorf("unknown format %q, want text or markdown", *format))
+	}
+
+	oldPack, err := loadMonsterPack(*oldRoot)
+	if err != nil {
+		fail(err)
+	}
+	newPack, err := loadMonsterPack(*newRoot)
+	if err != nil {
+		fail(err)
+	}
+
+	diff := diffPacks(oldPack, newPack)
+
+	report := diff.Text()
+	if *format == "markdown" {
+		report = diff.Markdown()
+	}
+
+	output := os.Stdout
+	if *out != "" {
+
+		if err := tools.EnsureOutputDir(filepath.Dir(*out)); err != nil {
+			fail(err)
+		}
+
+		f, err := os.Create(*out)
+		if err != nil {
+			fail(err)
+		}
+		defer f.Close()
+		output = f
+
+	}
+
+	fmt.Fprint(output, report)
+}
+
+func fail(err error) {
+	fmt.Fprintln(os.Stderr, "data_diff:", err)
+	os.Exit(1)
+}
@@ -0,0 +1,108 @@
+package tools
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// Shared path handling for the CLI tools. Relative defaults like
+// "combat_logs" only work from the right working directory, so the tools
+// resolve them against the repository root (found by walking up for go.mod)
+// or an explicit -data-root flag. Everything goes through filepath so the
+// behavior is identical across path separator conventions
+
+// FindRepoRoot walks up from the working directory until it finds go.mod
+func FindRepoRoot() (string, error) {
+
+	cwd, err := os.Getwd()
+	if err != nil {
+		return "", err
+	}
+
+	return FindRepoRootFrom(cwd)
+}
+
+// FindRepoRootFrom walks up from the given directory until it finds go.mod
+func FindRepoRootFrom(start string) (string, error) {
+
+	dir, err := filepath.Abs(start)
+	if err != nil {
+		return "", err
+	}
+
+	for {
+
+		if _, err := os.Stat(filepath.Join(dir, "go.mod")); err == nil {
+			return dir, nil
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", fmt.Errorf("no go.mod found walking up from %s", start)
+		}
+		dir = parent
+
+	}
+}
+
+// ResolveDataPath turns a possibly relative path into an absolute one under
+// the data root. An empty dataRoot means the repository root; when even that
+// cannot be found the path is used as given
+func ResolveDataPath(dataRoot string, path string) string {
+
+	if filepath.IsAbs(path) {
+		return path
+	}
+
+	if dataRoot == "" {
+
+		root, err := FindRepoRoot()
+		if err != nil {
+			return path
+		}
+		dataRoot = root
+
+	}
+
+	return filepath.Join(dataRoot, path)
+}
+
+// EnsureOutputDir creates the directory for an output path before anything
+// writes to it, with the resolved path in the error
+func EnsureOutputDir(dir string) error {
+
+	if dir == "" || dir == "." {
+		return nil
+	}
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("creating output directory %s: %w", dir, err)
+	}
+
+	return nil
+}
+
+// ValidateInputDir fails up front when an input directory is missing, with
+// the absolute path in the message so "no such file" never points at a
+// mystery relative path
+func ValidateInputDir(dir string) error {
+
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		abs = dir
+	}
+
+	info, err := os.Stat(dir)
+	if os.IsNotExist(err) {
+		return fmt.Errorf("input directory %s does not exist", abs)
+	}
+	if err != nil {
+		return fmt.Errorf("input directory %s: %w", abs, err)
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("input path %s is not a directory", abs)
+	}
+
+	return nil
+}
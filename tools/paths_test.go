@@ -0,0 +1,90 @@
+package tools
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// Root discovery walks up from arbitrarily nested directories
+func TestFindRepoRootFromNestedDirectories(t *testing.T) {
+
+	root := t.TempDir()
+	if err := os.WriteFile(filepath.Join(root, "go.mod"), []byte("module fixture\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	nested := filepath.Join(root, "tools", "deeper", "still")
+	if err := os.MkdirAll(nested, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	found, err := FindRepoRootFrom(nested)
+	if err != nil {
+		t.Fatalf("expected to find the root from %s, got %v", nested, err)
+	}
+	if found != root {
+		t.Errorf("expected root %s, got %s", root, found)
+	}
+
+	if _, err := FindRepoRootFrom(string(filepath.Separator)); err == nil {
+		t.Error("expected an error when no go.mod exists anywhere above")
+	}
+}
+
+func TestResolveDataPathLeavesAbsolutePathsAlone(t *testing.T) {
+
+	abs := filepath.Join(t.TempDir(), "logs")
+	if got := ResolveDataPath("ignored", abs); got != abs {
+		t.Errorf("expected the absolute path unchanged, got %s", got)
+	}
+
+	root := t.TempDir()
+	want := filepath.Join(root, "combat_logs")
+	if got := ResolveDataPath(root, "combat_logs"); got != want {
+		t.Errorf("expected %s, got %s", want, got)
+	}
+}
+
+func TestEnsureOutputDirCreatesNestedDirectories(t *testing.T) {
+
+	dir := filepath.Join(t.TempDir(), "out", "reports", "daily")
+	if err := EnsureOutputDir(dir); err != nil {
+		t.Fatalf("expected the directory to be created, got %v", err)
+	}
+
+	info, err := os.Stat(dir)
+	if err != nil || !info.IsDir() {
+		t.Errorf("expected %s to exist as a directory", dir)
+	}
+
+	if err := EnsureOutputDir(""); err != nil {
+		t.Errorf("an empty output directory is a no-op, got %v", err)
+	}
+}
+
+// The error names the resolved absolute path, never a mystery relative one
+func TestValidateInputDirReportsTheAbsolutePath(t *testing.T) {
+
+	missing := filepath.Join(t.TempDir(), "nope")
+	err := ValidateInputDir(missing)
+	if err == nil {
+		t.Fatal("expected a missing directory to fail validation")
+	}
+	if !strings.Contains(err.Error(), missing) {
+		t.Errorf("expected the absolute path %s in the error, got %q", missing, err)
+	}
+
+	file := filepath.Join(t.TempDir(), "afile")
+	if err := os.WriteFile(file, []byte("x"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := ValidateInputDir(file); err == nil {
+		t.Error("expected a plain file to fail directory validation")
+	}
+
+	if err := ValidateInputDir(t.TempDir()); err != nil {
+		t.Errorf("expected an existing directory to validate, got %v", err)
+	}
+}